@@ -0,0 +1,104 @@
+// Command import-vouchers bulk imports vouchers from a CSV or JSON file.
+//
+// Usage:
+//
+//	go run ./cmd/import-vouchers -file offers.csv [-format csv|json] [-merchant-id 98765] [-dry-run]
+//
+// The import report is printed as JSON; the command exits with status 1 when any row was rejected.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"4SaleBackendSkeleton/internal/adapters/repository"
+	"4SaleBackendSkeleton/internal/application/dto"
+	"4SaleBackendSkeleton/internal/application/services"
+	"4SaleBackendSkeleton/internal/infrastructure/config"
+	"4SaleBackendSkeleton/internal/infrastructure/database"
+	"4SaleBackendSkeleton/internal/infrastructure/logger"
+)
+
+func main() {
+	rejected, err := run()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import-vouchers:", err)
+		os.Exit(2)
+	}
+	if rejected {
+		os.Exit(1)
+	}
+}
+
+// run imports the file named on the command line and reports whether any row was rejected
+func run() (bool, error) {
+	file := flag.String("file", "", "CSV or JSON file of vouchers to import")
+	format := flag.String("format", "", "file format, csv or json (default: from the file extension)")
+	merchantID := flag.Int64("merchant-id", 0, "merchant owning every imported voucher (default: the user_id of each row)")
+	dryRun := flag.Bool("dry-run", false, "validate the file without importing")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		return false, fmt.Errorf("-file is required")
+	}
+
+	req := dto.ImportVouchersRequest{
+		Format: *format,
+		DryRun: *dryRun,
+	}
+	if req.Format == "" {
+		req.Format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+	}
+	if *merchantID != 0 {
+		req.MerchantID = merchantID
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return false, fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.Import.BatchSize <= 0 {
+		return false, fmt.Errorf("invalid IMPORT_BATCH_SIZE %d", cfg.Import.BatchSize)
+	}
+
+	log := logger.NewLogger()
+
+	db, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		return false, fmt.Errorf("failed to initialize database: %w", err)
+	}
+	defer db.Close()
+
+	data, err := os.Open(*file)
+	if err != nil {
+		return false, fmt.Errorf("failed to open import file: %w", err)
+	}
+	defer data.Close()
+
+	importService := services.NewVoucherImportService(
+		repository.NewVoucherRepository(db),
+		repository.NewCategoryRepository(db),
+		services.NewAuditService(repository.NewAuditRepository(db), cfg.Audit.StaffUserIDs, log),
+		cfg.Import.BatchSize,
+		log,
+	)
+
+	report, err := importService.ImportVouchers(context.Background(), &req, data)
+	if err != nil {
+		return false, err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return false, fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return report.Failed > 0, nil
+}
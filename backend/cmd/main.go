@@ -7,9 +7,11 @@ import (
         "database/sql"
         "encoding/base64"
         "encoding/json"
+        "errors"
         "fmt"
         "io/ioutil"
         "log"
+        "net"
         "net/http"
         "os"
         "path/filepath"
@@ -17,67 +19,20 @@ import (
         "strings"
         "time"
 
+        "4SaleBackendSkeleton/internal/adapters/handlers"
+        "4SaleBackendSkeleton/internal/app"
+        "4SaleBackendSkeleton/internal/application/dto"
+        "4SaleBackendSkeleton/internal/application/validation"
+        "4SaleBackendSkeleton/internal/infrastructure/auth"
+        "4SaleBackendSkeleton/internal/infrastructure/ratelimit"
         _ "github.com/go-sql-driver/mysql"
+        "github.com/gorilla/mux"
 )
 
-// Domain Models
-type Voucher struct {
-        ID          string     `json:"id"`
-        AdvID       int64      `json:"adv_id"`
-        UserID      int64      `json:"user_id"`
-        Title       string     `json:"title"`
-        Description *string    `json:"description"`
-        Price       float64    `json:"price"`
-        PhotoURL    *string    `json:"photo_url"`
-        CreatedAt   time.Time  `json:"created_at"`
-}
-
-type VoucherPurchase struct {
-        ID         string     `json:"id"`
-        VoucherID  string     `json:"voucher_id"`
-        BuyerID    int64      `json:"buyer_id"`
-        QRCode     string     `json:"qr_code"`
-        Status     string     `json:"status"`
-        RedeemedAt *time.Time `json:"redeemed_at"`
-        CreatedAt  time.Time  `json:"created_at"`
-}
-
-type UserVoucherResponse struct {
-        ID          string     `json:"id"`
-        Title       string     `json:"title"`
-        Description *string    `json:"description"`
-        Status      string     `json:"status"`
-        PhotoURL    *string    `json:"photo_url"`
-        QRCode      *string    `json:"qr_code,omitempty"`
-        Price       float64    `json:"price"`
-        PurchasedAt time.Time  `json:"purchased_at"`
-        RedeemedAt  *time.Time `json:"redeemed_at"`
-}
-
-// Request DTOs
-type CreateVoucherRequest struct {
-        AdvID       int64   `json:"adv_id"`
-        UserID      int64   `json:"user_id"`
-        Title       string  `json:"title"`
-        Description *string `json:"description"`
-        Price       float64 `json:"price"`
-        Photo       *string `json:"photo"`
-}
-
-type PurchaseVoucherRequest struct {
-        VoucherID string `json:"voucher_id"`
-        BuyerID   int64  `json:"buyer_id"`
-}
-
-type RedeemVoucherRequest struct {
-        VoucherID  string    `json:"voucher_id"`
-        RedeemedAt time.Time `json:"redeemed_at"`
-}
-
 // Authentication DTOs
 type LoginRequest struct {
-        Phone    string `json:"phone"`
-        Password string `json:"password"`
+        Phone    string `json:"phone" validate:"required,min=8,max=15"`
+        Password string `json:"password" validate:"required,max=128"`
 }
 
 type TokenInfo struct {
@@ -118,8 +73,13 @@ type UserInfo struct {
 }
 
 type LoginData struct {
-        Token TokenInfo `json:"token"`
-        User  UserInfo  `json:"user"`
+        Token   TokenInfo       `json:"token"`
+        User    UserInfo        `json:"user"`
+        Session *auth.TokenPair `json:"session,omitempty"`
+}
+
+type RefreshSessionRequest struct {
+        RefreshToken string `json:"refresh_token"`
 }
 
 type LoginResponse struct {
@@ -141,12 +101,20 @@ type APIResponse struct {
         Success bool        `json:"success"`
         Message string      `json:"message"`
         Data    interface{} `json:"data,omitempty"`
-        Error   string      `json:"error,omitempty"`
 }
 
 // Database connection
 var db *sql.DB
 
+// Session token issuer
+var tokenIssuer *auth.TokenIssuer
+
+// Login rate limiters
+var (
+        loginPhoneLimiter *ratelimit.Limiter
+        loginIPLimiter    *ratelimit.Limiter
+)
+
 // Utility functions
 func generateUUID() string {
         b := make([]byte, 16)
@@ -154,13 +122,6 @@ func generateUUID() string {
         return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
 }
 
-func generateQRCode(data string) string {
-        // Simple base64 encoded QR data for demonstration
-        timestamp := time.Now().Unix()
-        qrData := fmt.Sprintf("%s-%d-%s", data, timestamp, generateUUID())
-        return base64.StdEncoding.EncodeToString([]byte(qrData))
-}
-
 // Authentication utility functions
 func generateToken(userID int64) string {
         // Simple token generation - in production, use JWT or proper token generation
@@ -169,11 +130,6 @@ func generateToken(userID int64) string {
         return base64.StdEncoding.EncodeToString([]byte(tokenData))
 }
 
-func validatePhoneNumber(phone string) bool {
-        // Basic phone validation - add more sophisticated validation as needed
-        return len(phone) >= 8 && len(phone) <= 15
-}
-
 func extractBearerToken(authHeader string) string {
         if authHeader == "" {
                 return ""
@@ -185,122 +141,55 @@ func extractBearerToken(authHeader string) string {
         return parts[1]
 }
 
-// Database operations
-func createVoucher(ctx context.Context, voucher *Voucher) error {
-        query := `
-                INSERT INTO vouchers (id, adv_id, user_id, title, description, price, photo_url, created_at)
-                VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
-
-        _, err := db.ExecContext(ctx, query,
-                voucher.ID, voucher.AdvID, voucher.UserID, voucher.Title,
-                voucher.Description, voucher.Price, voucher.PhotoURL, voucher.CreatedAt)
-
-        return err
-}
-
-func getVoucherByID(ctx context.Context, id string) (*Voucher, error) {
-        query := `
-                SELECT id, adv_id, user_id, title, description, price, photo_url, created_at
-                FROM vouchers WHERE id = ?`
-
-        var voucher Voucher
-        err := db.QueryRowContext(ctx, query, id).Scan(
-                &voucher.ID, &voucher.AdvID, &voucher.UserID, &voucher.Title,
-                &voucher.Description, &voucher.Price, &voucher.PhotoURL, &voucher.CreatedAt)
-
-        if err == sql.ErrNoRows {
-                return nil, fmt.Errorf("voucher not found")
-        }
-        return &voucher, err
-}
-
-func createPurchase(ctx context.Context, purchase *VoucherPurchase) error {
-        query := `
-                INSERT INTO voucher_purchases (id, voucher_id, buyer_id, qr_code, status, redeemed_at, created_at)
-                VALUES (?, ?, ?, ?, ?, ?, ?)`
-
-        _, err := db.ExecContext(ctx, query,
-                purchase.ID, purchase.VoucherID, purchase.BuyerID, purchase.QRCode,
-                purchase.Status, purchase.RedeemedAt, purchase.CreatedAt)
-
-        return err
-}
-
-func getPurchaseByVoucherID(ctx context.Context, voucherID string) (*VoucherPurchase, error) {
-        query := `
-                SELECT id, voucher_id, buyer_id, qr_code, status, redeemed_at, created_at
-                FROM voucher_purchases WHERE voucher_id = ?`
-
-        var purchase VoucherPurchase
-        err := db.QueryRowContext(ctx, query, voucherID).Scan(
-                &purchase.ID, &purchase.VoucherID, &purchase.BuyerID, &purchase.QRCode,
-                &purchase.Status, &purchase.RedeemedAt, &purchase.CreatedAt)
-
-        if err == sql.ErrNoRows {
-                return nil, fmt.Errorf("purchase not found")
+// clientIP returns the address of the caller, honouring proxy headers only when trusted.
+// Clients can prepend anything to X-Forwarded-For, so the address is the entry appended by
+// the outermost of the TRUSTED_PROXY_HOPS proxies, counted from the right.
+func clientIP(r *http.Request) string {
+        if getEnv("TRUST_PROXY_HEADERS", "false") == "true" {
+                if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+                        hops, err := strconv.Atoi(getEnv("TRUSTED_PROXY_HOPS", "1"))
+                        if err != nil || hops < 1 {
+                                hops = 1
+                        }
+                        entries := strings.Split(forwarded, ",")
+                        if hops > len(entries) {
+                                hops = len(entries)
+                        }
+                        return strings.TrimSpace(entries[len(entries)-hops])
+                }
+                if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+                        return strings.TrimSpace(realIP)
+                }
         }
-        return &purchase, err
-}
 
-func getPurchaseByQRCode(ctx context.Context, qrCode string) (*VoucherPurchase, error) {
-        query := `
-                SELECT id, voucher_id, buyer_id, qr_code, status, redeemed_at, created_at
-                FROM voucher_purchases WHERE qr_code = ?`
-
-        var purchase VoucherPurchase
-        err := db.QueryRowContext(ctx, query, qrCode).Scan(
-                &purchase.ID, &purchase.VoucherID, &purchase.BuyerID, &purchase.QRCode,
-                &purchase.Status, &purchase.RedeemedAt, &purchase.CreatedAt)
-
-        if err == sql.ErrNoRows {
-                return nil, fmt.Errorf("purchase not found")
+        host, _, err := net.SplitHostPort(r.RemoteAddr)
+        if err != nil {
+                return r.RemoteAddr
         }
-        return &purchase, err
+        return host
 }
 
-func updatePurchaseStatus(ctx context.Context, voucherID, status string, redeemedAt *time.Time) error {
-        query := `UPDATE voucher_purchases SET status = ?, redeemed_at = ? WHERE voucher_id = ?`
-        result, err := db.ExecContext(ctx, query, status, redeemedAt, voucherID)
+// checkLoginLimit returns how long the key is locked out for, failing open on store errors
+func checkLoginLimit(ctx context.Context, limiter *ratelimit.Limiter, key string) time.Duration {
+        retryAfter, err := limiter.Check(ctx, key)
         if err != nil {
-                return err
-        }
-
-        rowsAffected, _ := result.RowsAffected()
-        if rowsAffected == 0 {
-                return fmt.Errorf("purchase not found")
+                log.Printf("Failed to check login rate limit for %s: %v", key, err)
+                return 0
         }
-        return nil
+        return retryAfter
 }
 
-func getUserVouchers(ctx context.Context, userID int64) ([]*UserVoucherResponse, error) {
-        query := `
-                SELECT vp.id, v.title, v.description, vp.status, v.photo_url,
-                        CASE WHEN vp.status = 'active' THEN vp.qr_code ELSE NULL END as qr_code,
-                        v.price, vp.created_at, vp.redeemed_at
-                FROM voucher_purchases vp
-                JOIN vouchers v ON vp.voucher_id = v.id
-                WHERE vp.buyer_id = ?
-                ORDER BY vp.created_at DESC`
-
-        rows, err := db.QueryContext(ctx, query, userID)
+// hitLoginLimit records a login attempt, failing open on store errors
+func hitLoginLimit(ctx context.Context, limiter *ratelimit.Limiter, key string) time.Duration {
+        retryAfter, err := limiter.Hit(ctx, key)
         if err != nil {
-                return nil, err
+                log.Printf("Failed to record login attempt for %s: %v", key, err)
+                return 0
         }
-        defer rows.Close()
-
-        // Initialize with empty slice to ensure JSON returns [] instead of null
-        vouchers := make([]*UserVoucherResponse, 0)
-        for rows.Next() {
-                var voucher UserVoucherResponse
-                err := rows.Scan(&voucher.ID, &voucher.Title, &voucher.Description,
-                        &voucher.Status, &voucher.PhotoURL, &voucher.QRCode,
-                        &voucher.Price, &voucher.PurchasedAt, &voucher.RedeemedAt)
-                if err != nil {
-                        return nil, err
-                }
-                vouchers = append(vouchers, &voucher)
+        if retryAfter > 0 {
+                log.Printf("Login locked out: Key=%s, RetryAfter=%v", key, retryAfter)
         }
-        return vouchers, nil
+        return retryAfter
 }
 
 // Authentication database operations (mock implementation)
@@ -416,39 +305,53 @@ func writeJSONResponse(w http.ResponseWriter, status int, response APIResponse)
         json.NewEncoder(w).Encode(response)
 }
 
+// writeErrorResponse writes the shared error envelope with an explicit code
+func writeErrorResponse(w http.ResponseWriter, r *http.Request, status int, code dto.ErrorCode, message string) {
+        handlers.WriteErrorResponse(w, r, status, dto.NewErrorResponse(code, message))
+}
+
+// writeTooManyRequests writes a 429 response with a Retry-After header in whole seconds
+func writeTooManyRequests(w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+        seconds := int64((retryAfter + time.Second - 1) / time.Second)
+        w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+        writeErrorResponse(w, r, http.StatusTooManyRequests, dto.ErrorCodeRateLimited, "Too many login attempts, please try again later")
+}
+
 // Authentication handlers
 func loginHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		writeJSONResponse(w, http.StatusMethodNotAllowed, APIResponse{
-			Success: false,
-			Error:   "Method not allowed",
-		})
+		writeErrorResponse(w, r, http.StatusMethodNotAllowed, dto.ErrorCodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
 	var req LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSONResponse(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   "Invalid request body",
-		})
+		writeErrorResponse(w, r, http.StatusBadRequest, dto.ErrorCodeInvalidRequest, "Invalid request body")
 		return
 	}
 
 	// Validation
-	if req.Phone == "" || req.Password == "" {
-		writeJSONResponse(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   "Phone and password are required",
-		})
+	if err := validation.Validate(&req); err != nil {
+		handlers.WriteError(w, r, err)
 		return
 	}
 
-	if !validatePhoneNumber(req.Phone) {
-		writeJSONResponse(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   "Invalid phone number format",
-		})
+	// Throttle per phone and per IP before reaching the 4Sale API
+	phoneKey := "phone:" + req.Phone
+	ipKey := "ip:" + clientIP(r)
+
+	if retryAfter := checkLoginLimit(r.Context(), loginPhoneLimiter, phoneKey); retryAfter > 0 {
+		writeTooManyRequests(w, r, retryAfter)
+		return
+	}
+	if retryAfter := checkLoginLimit(r.Context(), loginIPLimiter, ipKey); retryAfter > 0 {
+		writeTooManyRequests(w, r, retryAfter)
+		return
+	}
+
+	// Every attempt counts against the IP, only failures count against the phone
+	if retryAfter := hitLoginLimit(r.Context(), loginIPLimiter, ipKey); retryAfter > 0 {
+		writeTooManyRequests(w, r, retryAfter)
 		return
 	}
 
@@ -464,10 +367,7 @@ func loginHandler(w http.ResponseWriter, r *http.Request) {
 	jsonBody, err := json.Marshal(requestBody)
 	if err != nil {
 		log.Printf("Failed to marshal request body: %v", err)
-		writeJSONResponse(w, http.StatusInternalServerError, APIResponse{
-			Success: false,
-			Error:   "Internal server error",
-		})
+		writeErrorResponse(w, r, http.StatusInternalServerError, dto.ErrorCodeInternal, "Internal server error")
 		return
 	}
 	
@@ -475,10 +375,7 @@ func loginHandler(w http.ResponseWriter, r *http.Request) {
 	httpReq, err := http.NewRequestWithContext(r.Context(), "POST", apiURL, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		log.Printf("Failed to create request: %v", err)
-		writeJSONResponse(w, http.StatusInternalServerError, APIResponse{
-			Success: false,
-			Error:   "Internal server error",
-		})
+		writeErrorResponse(w, r, http.StatusInternalServerError, dto.ErrorCodeInternal, "Internal server error")
 		return
 	}
 	
@@ -494,10 +391,7 @@ func loginHandler(w http.ResponseWriter, r *http.Request) {
 	resp, err := client.Do(httpReq)
 	if err != nil {
 		log.Printf("Failed to make request to 4Sale API: %v", err)
-		writeJSONResponse(w, http.StatusInternalServerError, APIResponse{
-			Success: false,
-			Error:   "Authentication service unavailable",
-		})
+		writeErrorResponse(w, r, http.StatusInternalServerError, dto.ErrorCodeUpstreamFailure, "Authentication service unavailable")
 		return
 	}
 	defer resp.Body.Close()
@@ -506,20 +400,15 @@ func loginHandler(w http.ResponseWriter, r *http.Request) {
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		log.Printf("Failed to read response body: %v", err)
-		writeJSONResponse(w, http.StatusInternalServerError, APIResponse{
-			Success: false,
-			Error:   "Internal server error",
-		})
+		writeErrorResponse(w, r, http.StatusInternalServerError, dto.ErrorCodeInternal, "Internal server error")
 		return
 	}
 	
 	// Check if request was successful
 	if resp.StatusCode != http.StatusOK {
 		log.Printf("4Sale API authentication failed: Status %d, Body: %s", resp.StatusCode, string(body))
-		writeJSONResponse(w, http.StatusUnauthorized, APIResponse{
-			Success: false,
-			Error:   "Invalid credentials",
-		})
+		hitLoginLimit(r.Context(), loginPhoneLimiter, phoneKey)
+		writeErrorResponse(w, r, http.StatusUnauthorized, dto.ErrorCodeUnauthorized, "Invalid credentials")
 		return
 	}
 	
@@ -527,17 +416,32 @@ func loginHandler(w http.ResponseWriter, r *http.Request) {
 	var loginResponse LoginResponse
 	if err := json.Unmarshal(body, &loginResponse); err != nil {
 		log.Printf("Failed to parse 4Sale API response: %v", err)
-		writeJSONResponse(w, http.StatusInternalServerError, APIResponse{
-			Success: false,
-			Error:   "Internal server error",
-		})
+		writeErrorResponse(w, r, http.StatusInternalServerError, dto.ErrorCodeInternal, "Internal server error")
 		return
 	}
 
 	log.Printf("User authenticated successfully via 4Sale API: UserID=%d, Phone=%s", 
 		loginResponse.Data.User.UserID, loginResponse.Data.User.Phone)
 
-	// Return the response directly from 4Sale API
+	if err := loginPhoneLimiter.Reset(r.Context(), phoneKey); err != nil {
+		log.Printf("Failed to reset login rate limit for %s: %v", phoneKey, err)
+	}
+
+	// Mint a local session so later calls do not need to round-trip to 4Sale
+	sessionUser := auth.SessionUser{UserID: loginResponse.Data.User.UserID}
+	if userType := loginResponse.Data.User.UserType; userType != nil {
+		sessionUser.UserType = userType.UserTypeName
+	}
+
+	session, err := tokenIssuer.Issue(r.Context(), sessionUser)
+	if err != nil {
+		log.Printf("Failed to issue session token: %v", err)
+		writeErrorResponse(w, r, http.StatusInternalServerError, dto.ErrorCodeInternal, "Internal server error")
+		return
+	}
+	loginResponse.Data.Session = session
+
+	// Return the response from 4Sale API along with the local session
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(loginResponse)
@@ -545,10 +449,7 @@ func loginHandler(w http.ResponseWriter, r *http.Request) {
 
 func validateTokenHandler(w http.ResponseWriter, r *http.Request) {
         if r.Method != http.MethodGet {
-                writeJSONResponse(w, http.StatusMethodNotAllowed, APIResponse{
-                        Success: false,
-                        Error:   "Method not allowed",
-                })
+                writeErrorResponse(w, r, http.StatusMethodNotAllowed, dto.ErrorCodeMethodNotAllowed, "Method not allowed")
                 return
         }
 
@@ -557,10 +458,7 @@ func validateTokenHandler(w http.ResponseWriter, r *http.Request) {
         token := extractBearerToken(authHeader)
         
         if token == "" {
-                writeJSONResponse(w, http.StatusUnauthorized, APIResponse{
-                        Success: false,
-                        Error:   "Authorization token required",
-                })
+                writeErrorResponse(w, r, http.StatusUnauthorized, dto.ErrorCodeUnauthorized, "Authorization token required")
                 return
         }
 
@@ -568,10 +466,7 @@ func validateTokenHandler(w http.ResponseWriter, r *http.Request) {
         user, _, err := validateToken(r.Context(), token)
         if err != nil {
                 log.Printf("Token validation failed: %v", err)
-                writeJSONResponse(w, http.StatusUnauthorized, APIResponse{
-                        Success: false,
-                        Error:   "Invalid or expired token",
-                })
+                writeErrorResponse(w, r, http.StatusUnauthorized, dto.ErrorCodeUnauthorized, "Invalid or expired token")
                 return
         }
 
@@ -591,235 +486,58 @@ func validateTokenHandler(w http.ResponseWriter, r *http.Request) {
         json.NewEncoder(w).Encode(response)
 }
 
-func createVoucherHandler(w http.ResponseWriter, r *http.Request) {
+func refreshSessionHandler(w http.ResponseWriter, r *http.Request) {
         if r.Method != http.MethodPost {
-                writeJSONResponse(w, http.StatusMethodNotAllowed, APIResponse{
-                        Success: false,
-                        Error:   "Method not allowed",
-                })
+                writeErrorResponse(w, r, http.StatusMethodNotAllowed, dto.ErrorCodeMethodNotAllowed, "Method not allowed")
                 return
         }
 
-        var req CreateVoucherRequest
-        if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-                writeJSONResponse(w, http.StatusBadRequest, APIResponse{
-                        Success: false,
-                        Error:   "Invalid request body",
-                })
+        var req RefreshSessionRequest
+        if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+                writeErrorResponse(w, r, http.StatusBadRequest, dto.ErrorCodeInvalidRequest, "Refresh token is required")
                 return
         }
 
-        // Validation
-        if req.AdvID <= 0 || req.UserID <= 0 || req.Title == "" || req.Price <= 0 {
-                writeJSONResponse(w, http.StatusBadRequest, APIResponse{
-                        Success: false,
-                        Error:   "Invalid request data",
-                })
-                return
-        }
-
-        // Create voucher
-        voucher := &Voucher{
-                ID:          generateUUID(),
-                AdvID:       req.AdvID,
-                UserID:      req.UserID,
-                Title:       req.Title,
-                Description: req.Description,
-                Price:       req.Price,
-                PhotoURL:    req.Photo,
-                CreatedAt:   time.Now(),
-        }
-
-        if err := createVoucher(r.Context(), voucher); err != nil {
-                log.Printf("Failed to create voucher: %v", err)
-                writeJSONResponse(w, http.StatusInternalServerError, APIResponse{
-                        Success: false,
-                        Error:   "Failed to create voucher",
-                })
-                return
-        }
-
-        log.Printf("Voucher created: ID=%s, Title=%s, UserID=%d", voucher.ID, voucher.Title, voucher.UserID)
-
-        writeJSONResponse(w, http.StatusCreated, APIResponse{
-                Success: true,
-                Message: "Voucher created successfully",
-                Data:    voucher,
-        })
-}
-
-func purchaseVoucherHandler(w http.ResponseWriter, r *http.Request) {
-        if r.Method != http.MethodPost {
-                writeJSONResponse(w, http.StatusMethodNotAllowed, APIResponse{
-                        Success: false,
-                        Error:   "Method not allowed",
-                })
-                return
-        }
-
-        var req PurchaseVoucherRequest
-        if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-                writeJSONResponse(w, http.StatusBadRequest, APIResponse{
-                        Success: false,
-                        Error:   "Invalid request body",
-                })
-                return
-        }
-
-        // Validation
-        if req.VoucherID == "" || req.BuyerID <= 0 {
-                writeJSONResponse(w, http.StatusBadRequest, APIResponse{
-                        Success: false,
-                        Error:   "Invalid request data",
-                })
-                return
-        }
-
-        // Check if voucher exists
-        voucher, err := getVoucherByID(r.Context(), req.VoucherID)
+        session, err := tokenIssuer.Refresh(r.Context(), req.RefreshToken)
         if err != nil {
-                writeJSONResponse(w, http.StatusNotFound, APIResponse{
-                        Success: false,
-                        Error:   "Voucher not found",
-                })
+                log.Printf("Session refresh failed: %v", err)
+                writeErrorResponse(w, r, http.StatusUnauthorized, dto.ErrorCodeUnauthorized, "Invalid or expired refresh token")
                 return
         }
 
-        // Create purchase
-        purchase := &VoucherPurchase{
-                ID:         generateUUID(),
-                VoucherID:  req.VoucherID,
-                BuyerID:    req.BuyerID,
-                QRCode:     generateQRCode(req.VoucherID),
-                Status:     "active",
-                CreatedAt:  time.Now(),
-        }
-
-        if err := createPurchase(r.Context(), purchase); err != nil {
-                log.Printf("Failed to create purchase: %v", err)
-                writeJSONResponse(w, http.StatusInternalServerError, APIResponse{
-                        Success: false,
-                        Error:   "Failed to purchase voucher",
-                })
-                return
-        }
-
-        log.Printf("Voucher purchased: VoucherID=%s, BuyerID=%d, PurchaseID=%s", 
-                req.VoucherID, req.BuyerID, purchase.ID)
-
-        writeJSONResponse(w, http.StatusCreated, APIResponse{
-                Success: true,
-                Message: "Voucher purchased successfully",
-                Data:    map[string]interface{}{
-                        "purchase": purchase,
-                        "voucher":  voucher,
-                },
-        })
-}
-
-func redeemVoucherHandler(w http.ResponseWriter, r *http.Request) {
-        if r.Method != http.MethodPost {
-                writeJSONResponse(w, http.StatusMethodNotAllowed, APIResponse{
-                        Success: false,
-                        Error:   "Method not allowed",
-                })
-                return
-        }
-
-        var req RedeemVoucherRequest
-        if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-                writeJSONResponse(w, http.StatusBadRequest, APIResponse{
-                        Success: false,
-                        Error:   "Invalid request body",
-                })
-                return
-        }
-
-        // Validation
-        if req.VoucherID == "" {
-                writeJSONResponse(w, http.StatusBadRequest, APIResponse{
-                        Success: false,
-                        Error:   "Invalid request data",
-                })
-                return
-        }
-
-        // Check if purchase exists by QR code (since QR scanner sends the actual QR code data)
-        purchase, err := getPurchaseByQRCode(r.Context(), req.VoucherID)
-        if err != nil {
-                writeJSONResponse(w, http.StatusNotFound, APIResponse{
-                        Success: false,
-                        Error:   "Purchase not found",
-                })
-                return
-        }
-
-        // Check if already redeemed
-        if purchase.Status == "redeemed" {
-                writeJSONResponse(w, http.StatusConflict, APIResponse{
-                        Success: false,
-                        Error:   "Voucher already redeemed",
-                })
-                return
-        }
-
-        // Update status using the actual voucher ID from the purchase record
-        redeemedAt := req.RedeemedAt
-        if err := updatePurchaseStatus(r.Context(), purchase.VoucherID, "redeemed", &redeemedAt); err != nil {
-                log.Printf("Failed to redeem voucher: %v", err)
-                writeJSONResponse(w, http.StatusInternalServerError, APIResponse{
-                        Success: false,
-                        Error:   "Failed to redeem voucher",
-                })
-                return
-        }
-
-        log.Printf("Voucher redeemed: VoucherID=%s, QRCode=%s, RedeemedAt=%v", purchase.VoucherID, req.VoucherID, req.RedeemedAt)
-
         writeJSONResponse(w, http.StatusOK, APIResponse{
                 Success: true,
-                Message: "Voucher redeemed successfully",
+                Message: "Session refreshed successfully",
+                Data:    session,
         })
 }
 
-func getUserVouchersHandler(w http.ResponseWriter, r *http.Request) {
-        if r.Method != http.MethodGet {
-                writeJSONResponse(w, http.StatusMethodNotAllowed, APIResponse{
-                        Success: false,
-                        Error:   "Method not allowed",
-                })
+func logoutHandler(w http.ResponseWriter, r *http.Request) {
+        if r.Method != http.MethodPost {
+                writeErrorResponse(w, r, http.StatusMethodNotAllowed, dto.ErrorCodeMethodNotAllowed, "Method not allowed")
                 return
         }
 
-        // Extract user_id from URL path
-        path := strings.TrimPrefix(r.URL.Path, "/vouchers/")
-        userIDStr := strings.Split(path, "/")[0]
-
-        userID, err := strconv.ParseInt(userIDStr, 10, 64)
-        if err != nil || userID <= 0 {
-                writeJSONResponse(w, http.StatusBadRequest, APIResponse{
-                        Success: false,
-                        Error:   "Invalid user ID",
-                })
-                return
-        }
+        // The refresh token is optional; without it only the access token is revoked
+        var req RefreshSessionRequest
+        json.NewDecoder(r.Body).Decode(&req)
 
-        vouchers, err := getUserVouchers(r.Context(), userID)
-        if err != nil {
-                log.Printf("Failed to get user vouchers: %v", err)
-                writeJSONResponse(w, http.StatusInternalServerError, APIResponse{
-                        Success: false,
-                        Error:   "Failed to get vouchers",
-                })
+        claims, _ := auth.ClaimsFromContext(r.Context())
+        if err := tokenIssuer.Revoke(r.Context(), req.RefreshToken, claims); err != nil {
+                if errors.Is(err, auth.ErrInvalidToken) {
+                        writeErrorResponse(w, r, http.StatusUnauthorized, dto.ErrorCodeUnauthorized, "Invalid or expired token")
+                        return
+                }
+                log.Printf("Failed to revoke session: %v", err)
+                writeErrorResponse(w, r, http.StatusInternalServerError, dto.ErrorCodeInternal, "Failed to log out")
                 return
         }
 
-        log.Printf("Retrieved %d vouchers for user %d", len(vouchers), userID)
+        log.Printf("Session revoked: UserID=%d", claims.UserID)
 
         writeJSONResponse(w, http.StatusOK, APIResponse{
                 Success: true,
-                Message: "Vouchers retrieved successfully",
-                Data:    vouchers,
+                Message: "Logged out successfully",
         })
 }
 
@@ -867,6 +585,26 @@ func corsMiddleware(next http.HandlerFunc) http.HandlerFunc {
         }
 }
 
+// Session middleware validates the locally issued session token without calling 4Sale
+func requireSession(next http.HandlerFunc) http.HandlerFunc {
+        return func(w http.ResponseWriter, r *http.Request) {
+                token := extractBearerToken(r.Header.Get("Authorization"))
+                if token == "" {
+                        writeErrorResponse(w, r, http.StatusUnauthorized, dto.ErrorCodeUnauthorized, "Authorization token required")
+                        return
+                }
+
+                claims, err := tokenIssuer.Validate(r.Context(), token)
+                if err != nil {
+                        log.Printf("Session validation failed: %v", err)
+                        writeErrorResponse(w, r, http.StatusUnauthorized, dto.ErrorCodeUnauthorized, "Invalid or expired token")
+                        return
+                }
+
+                next(w, r.WithContext(auth.ContextWithClaims(r.Context(), claims)))
+        }
+}
+
 // Logging middleware
 func loggingMiddleware(next http.HandlerFunc) http.HandlerFunc {
         return func(w http.ResponseWriter, r *http.Request) {
@@ -905,6 +643,47 @@ func initDatabase() error {
         return nil
 }
 
+func initLoginLimiters() {
+        phonePolicy := ratelimit.Policy{
+                MaxAttempts: getEnvAsInt("LOGIN_PHONE_MAX_ATTEMPTS", 5),
+                Window:      getEnvAsDuration("LOGIN_PHONE_WINDOW", 15*time.Minute),
+                BaseLockout: getEnvAsDuration("LOGIN_LOCKOUT_BASE", time.Minute),
+                MaxLockout:  getEnvAsDuration("LOGIN_LOCKOUT_MAX", time.Hour),
+                StrikeDecay: getEnvAsDuration("LOGIN_LOCKOUT_DECAY", 24*time.Hour),
+        }
+        ipPolicy := phonePolicy
+        ipPolicy.MaxAttempts = getEnvAsInt("LOGIN_IP_MAX_ATTEMPTS", 20)
+        ipPolicy.Window = getEnvAsDuration("LOGIN_IP_WINDOW", 15*time.Minute)
+
+        // Keep entries long enough to remember strikes between lockouts
+        store := ratelimit.NewMemoryStore(phonePolicy.MaxLockout + phonePolicy.StrikeDecay)
+
+        loginPhoneLimiter = ratelimit.NewLimiter(store, phonePolicy)
+        loginIPLimiter = ratelimit.NewLimiter(store, ipPolicy)
+}
+
+func initTokenIssuer() error {
+        signingKey := []byte(getEnv("SESSION_SIGNING_KEY", ""))
+        if len(signingKey) == 0 {
+                log.Printf("Warning: SESSION_SIGNING_KEY not set, using a random key; sessions will not survive restarts")
+
+                var err error
+                signingKey, err = auth.GenerateSigningKey()
+                if err != nil {
+                        return err
+                }
+        }
+
+        var err error
+        tokenIssuer, err = auth.NewTokenIssuer(auth.TokenConfig{
+                SigningKey: signingKey,
+                Issuer:     getEnv("SESSION_ISSUER", "voucher-system"),
+                AccessTTL:  getEnvAsDuration("SESSION_ACCESS_TTL", 15*time.Minute),
+                RefreshTTL: getEnvAsDuration("SESSION_REFRESH_TTL", 30*24*time.Hour),
+        }, auth.NewMemorySessionStore())
+        return err
+}
+
 func getEnv(key, defaultValue string) string {
         if value := os.Getenv(key); value != "" {
                 return value
@@ -912,6 +691,41 @@ func getEnv(key, defaultValue string) string {
         return defaultValue
 }
 
+func getEnvAsInt(key string, defaultValue int) int {
+        if value := os.Getenv(key); value != "" {
+                if intValue, err := strconv.Atoi(value); err == nil {
+                        return intValue
+                }
+        }
+        return defaultValue
+}
+
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+        if value := os.Getenv(key); value != "" {
+                if duration, err := time.ParseDuration(value); err == nil {
+                        return duration
+                }
+        }
+        return defaultValue
+}
+
+// logRoutes logs the method and path of every route served by router
+func logRoutes(router *mux.Router) {
+        router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+                methods, err := route.GetMethods()
+                if err != nil {
+                        // Subrouter prefixes have no methods of their own
+                        return nil
+                }
+                path, err := route.GetPathTemplate()
+                if err != nil {
+                        return nil
+                }
+                log.Printf("  %s %s", strings.Join(methods, ","), path)
+                return nil
+        })
+}
+
 func main() {
         log.Println("Starting Combined Voucher System (Frontend + Backend)...")
 
@@ -921,21 +735,43 @@ func main() {
         }
         defer db.Close()
 
+        // Initialize login rate limiting
+        initLoginLimiters()
+
+        // Initialize session tokens
+        if err := initTokenIssuer(); err != nil {
+                log.Fatalf("Session token initialization failed: %v", err)
+        }
+
         // Setup API routes
         http.HandleFunc("/health", corsMiddleware(loggingMiddleware(healthHandler)))
         
         // Authentication routes
         http.HandleFunc("/api/v1/users/auth/login", corsMiddleware(loggingMiddleware(loginHandler)))
         http.HandleFunc("/api/v1/users/auth/validate", corsMiddleware(loggingMiddleware(validateTokenHandler)))
+        http.HandleFunc("/api/v1/users/auth/refresh", corsMiddleware(loggingMiddleware(refreshSessionHandler)))
+        http.HandleFunc("/api/v1/users/auth/logout", corsMiddleware(loggingMiddleware(requireSession(logoutHandler))))
         
-        // Voucher webhook routes
-        http.HandleFunc("/webhook/voucher-created", corsMiddleware(loggingMiddleware(createVoucherHandler)))
-        http.HandleFunc("/webhook/voucher-purchased", corsMiddleware(loggingMiddleware(purchaseVoucherHandler)))
-        http.HandleFunc("/webhook/voucher-redeemed", corsMiddleware(loggingMiddleware(redeemVoucherHandler)))
-        http.HandleFunc("/vouchers/", corsMiddleware(loggingMiddleware(getUserVouchersHandler)))
-        
+        // Voucher routes are served by the voucher system, so webhooks go through the same
+        // services as the rest of the API and sessions are shared with the login routes
+        voucherSystem, err := app.NewAppWithDB(db)
+        if err != nil {
+                log.Fatalf("Voucher system initialization failed: %v", err)
+        }
+        voucherRoutes, err := voucherSystem.Routes(tokenIssuer)
+        if err != nil {
+                log.Fatalf("Voucher system initialization failed: %v", err)
+        }
+
         // Setup static file serving for frontend (all other routes)
-        http.HandleFunc("/", loggingMiddleware(staticFileHandler))
+        http.HandleFunc("/", corsMiddleware(func(w http.ResponseWriter, r *http.Request) {
+                var match mux.RouteMatch
+                if voucherRoutes.Match(r, &match) {
+                        voucherRoutes.ServeHTTP(w, r)
+                        return
+                }
+                loggingMiddleware(staticFileHandler)(w, r)
+        }))
 
         port := getEnv("PORT", "3001")
         host := getEnv("HOST", "0.0.0.0")
@@ -947,10 +783,9 @@ func main() {
         log.Printf("API endpoints:")
         log.Printf("  POST /api/v1/users/auth/login")
         log.Printf("  GET /api/v1/users/auth/validate")
-        log.Printf("  POST /webhook/voucher-created")
-        log.Printf("  POST /webhook/voucher-purchased")
-        log.Printf("  POST /webhook/voucher-redeemed")
-        log.Printf("  GET /vouchers/{user_id}")
+        log.Printf("  POST /api/v1/users/auth/refresh")
+        log.Printf("  POST /api/v1/users/auth/logout")
+        logRoutes(voucherRoutes)
 
         if err := http.ListenAndServe(addr, nil); err != nil {
                 log.Fatalf("Server failed to start: %v", err)
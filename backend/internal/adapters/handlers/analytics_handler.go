@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"4SaleBackendSkeleton/internal/application/dto"
+	"4SaleBackendSkeleton/internal/ports"
+	"github.com/rs/zerolog"
+)
+
+// AnalyticsHandler handles merchant analytics HTTP requests
+type AnalyticsHandler struct {
+	analyticsService ports.AnalyticsService
+	logger           zerolog.Logger
+}
+
+// NewAnalyticsHandler creates a new analytics handler
+func NewAnalyticsHandler(analyticsService ports.AnalyticsService, logger zerolog.Logger) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyticsService: analyticsService,
+		logger:           logger,
+	}
+}
+
+// GetMerchantAnalytics handles the GET /merchants/{merchant_id}/analytics endpoint
+func (h *AnalyticsHandler) GetMerchantAnalytics(w http.ResponseWriter, r *http.Request) {
+	merchantID, ok := sessionMerchantID(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	req := dto.MerchantAnalyticsRequest{
+		MerchantID: merchantID,
+		From:       query.Get("from"),
+		To:         query.Get("to"),
+	}
+	if value := query.Get("top"); value != "" {
+		top, err := strconv.Atoi(value)
+		if err != nil {
+			WriteErrorResponse(w, r, http.StatusBadRequest, dto.NewErrorResponse(dto.ErrorCodeInvalidRequest, "Invalid top"))
+			return
+		}
+		req.Top = top
+	}
+
+	analytics, err := h.analyticsService.GetMerchantAnalytics(r.Context(), &req)
+	if err != nil {
+		h.logger.Error().Err(err).Int64("merchant_id", merchantID).Msg("Failed to get merchant analytics")
+		WriteError(w, r, err)
+		return
+	}
+
+	writeSuccess(w, http.StatusOK, "Merchant analytics retrieved successfully", analytics)
+}
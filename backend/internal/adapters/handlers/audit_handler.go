@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"4SaleBackendSkeleton/internal/application/dto"
+	"4SaleBackendSkeleton/internal/ports"
+	"github.com/rs/zerolog"
+)
+
+// AuditHandler handles audit log HTTP requests
+type AuditHandler struct {
+	auditService ports.AuditService
+	logger       zerolog.Logger
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(auditService ports.AuditService, logger zerolog.Logger) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// ListAuditEvents handles the GET /audit-events endpoint for support staff
+func (h *AuditHandler) ListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	userID, ok := sessionUserID(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	req := dto.AuditEventListRequest{
+		Action:      query.Get("action"),
+		TargetType:  query.Get("target_type"),
+		TargetID:    query.Get("target_id"),
+		From:        query.Get("from"),
+		To:          query.Get("to"),
+		Cursor:      query.Get("cursor"),
+		RequesterID: userID,
+	}
+
+	var err error
+	if req.ActorID, ok = queryInt64(w, r, "actor_id"); !ok {
+		return
+	}
+	if value := query.Get("limit"); value != "" {
+		if req.Limit, err = strconv.Atoi(value); err != nil {
+			WriteErrorResponse(w, r, http.StatusBadRequest, dto.NewErrorResponse(dto.ErrorCodeInvalidRequest, "Invalid limit"))
+			return
+		}
+	}
+
+	page, err := h.auditService.ListAuditEvents(r.Context(), &req)
+	if err != nil {
+		h.logger.Error().Err(err).Int64("user_id", userID).Msg("Failed to list audit events")
+		WriteError(w, r, err)
+		return
+	}
+
+	writeSuccessWithMeta(w, http.StatusOK, "Audit events retrieved successfully", page.Events, page.Meta)
+}
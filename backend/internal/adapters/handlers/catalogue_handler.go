@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"4SaleBackendSkeleton/internal/application/dto"
+	"4SaleBackendSkeleton/internal/infrastructure/i18n"
+	"4SaleBackendSkeleton/internal/ports"
+	"github.com/rs/zerolog"
+)
+
+// CatalogueHandler handles public catalogue HTTP requests
+type CatalogueHandler struct {
+	catalogueService ports.CatalogueService
+	logger           zerolog.Logger
+}
+
+// NewCatalogueHandler creates a new catalogue handler
+func NewCatalogueHandler(catalogueService ports.CatalogueService, logger zerolog.Logger) *CatalogueHandler {
+	return &CatalogueHandler{
+		catalogueService: catalogueService,
+		logger:           logger,
+	}
+}
+
+// Browse handles the GET /catalogue endpoint
+func (h *CatalogueHandler) Browse(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := dto.CatalogueRequest{
+		Query:        query.Get("q"),
+		MinPrice:     query.Get("min_price"),
+		MaxPrice:     query.Get("max_price"),
+		Currency:     query.Get("currency"),
+		Tag:          query.Get("tag"),
+		Availability: query.Get("availability"),
+		Sort:         query.Get("sort"),
+		Cursor:       query.Get("cursor"),
+		Language:     i18n.FromRequest(r),
+	}
+
+	var err error
+	var ok bool
+	if req.MerchantID, ok = queryInt64(w, r, "merchant_id"); !ok {
+		return
+	}
+	if req.Category, ok = queryInt64(w, r, "category"); !ok {
+		return
+	}
+	if value := query.Get("limit"); value != "" {
+		if req.Limit, err = strconv.Atoi(value); err != nil {
+			WriteErrorResponse(w, r, http.StatusBadRequest, dto.NewErrorResponse(dto.ErrorCodeInvalidRequest, "Invalid limit"))
+			return
+		}
+	}
+
+	page, err := h.catalogueService.Search(r.Context(), &req)
+	if err != nil {
+		h.logger.Error().Err(err).Str("q", req.Query).Msg("Failed to search catalogue")
+		WriteError(w, r, err)
+		return
+	}
+
+	setContentLanguage(w, req.Language)
+	writeSuccessWithMeta(w, http.StatusOK, "Catalogue retrieved successfully", page.Vouchers, page.Meta)
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+
+	"4SaleBackendSkeleton/internal/ports"
+	"github.com/rs/zerolog"
+)
+
+// CategoryHandler handles voucher category HTTP requests
+type CategoryHandler struct {
+	categoryService ports.CategoryService
+	logger          zerolog.Logger
+}
+
+// NewCategoryHandler creates a new category handler
+func NewCategoryHandler(categoryService ports.CategoryService, logger zerolog.Logger) *CategoryHandler {
+	return &CategoryHandler{
+		categoryService: categoryService,
+		logger:          logger,
+	}
+}
+
+// GetCategories handles the GET /categories endpoint
+func (h *CategoryHandler) GetCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.categoryService.GetCategoryTree(r.Context())
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to get categories")
+		WriteError(w, r, err)
+		return
+	}
+
+	writeSuccess(w, http.StatusOK, "Categories retrieved successfully", categories)
+}
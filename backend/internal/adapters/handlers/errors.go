@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"4SaleBackendSkeleton/internal/application/dto"
+	"4SaleBackendSkeleton/internal/domain"
+	"4SaleBackendSkeleton/internal/infrastructure/i18n"
+)
+
+// MapError maps an error to its HTTP status and error envelope.
+// Unknown errors become a generic 500 so internal messages never reach the client.
+func MapError(err error) (int, dto.ErrorResponse) {
+	var validationErr *domain.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		response := dto.NewErrorResponse(dto.ErrorCodeValidationFailed, "Request validation failed")
+		response.Error.Details = validationErr.Fields
+		return http.StatusUnprocessableEntity, response
+	case errors.Is(err, domain.ErrValidation):
+		return http.StatusUnprocessableEntity, dto.NewErrorResponse(dto.ErrorCodeValidationFailed, "Request validation failed")
+	case errors.Is(err, domain.ErrInvalidCursor):
+		return http.StatusBadRequest, dto.NewErrorResponse(dto.ErrorCodeInvalidCursor, "Invalid pagination cursor")
+	case errors.Is(err, domain.ErrVoucherNotFound):
+		return http.StatusNotFound, dto.NewErrorResponse(dto.ErrorCodeVoucherNotFound, "Voucher not found")
+	case errors.Is(err, domain.ErrPurchaseNotFound):
+		return http.StatusNotFound, dto.NewErrorResponse(dto.ErrorCodePurchaseNotFound, "Voucher purchase not found")
+	case errors.Is(err, domain.ErrAlreadyPurchased):
+		return http.StatusConflict, dto.NewErrorResponse(dto.ErrorCodeAlreadyPurchased, "Voucher already purchased")
+	case errors.Is(err, domain.ErrAlreadyRedeemed):
+		return http.StatusConflict, dto.NewErrorResponse(dto.ErrorCodeAlreadyRedeemed, "Voucher already redeemed")
+	case errors.Is(err, domain.ErrPurchaseRefunded):
+		return http.StatusConflict, dto.NewErrorResponse(dto.ErrorCodePurchaseRefunded, "Voucher purchase was refunded")
+	case errors.Is(err, domain.ErrQRCodeMismatch):
+		return http.StatusConflict, dto.NewErrorResponse(dto.ErrorCodeQRCodeMismatch, "QR code is no longer valid for this voucher")
+	case errors.Is(err, domain.ErrVoucherExpired):
+		return http.StatusConflict, dto.NewErrorResponse(dto.ErrorCodeVoucherExpired, "Voucher has expired")
+	case errors.Is(err, domain.ErrVoucherUnavailable):
+		return http.StatusConflict, dto.NewErrorResponse(dto.ErrorCodeVoucherUnavailable, "Voucher is not available for sale")
+	case errors.Is(err, domain.ErrVoucherSold):
+		return http.StatusConflict, dto.NewErrorResponse(dto.ErrorCodeVoucherSold, "Voucher cannot be changed after it has been sold")
+	case errors.Is(err, domain.ErrVersionConflict):
+		return http.StatusConflict, dto.NewErrorResponse(dto.ErrorCodeVersionConflict, "Voucher was modified by another request")
+	case errors.Is(err, domain.ErrCodePoolEmpty):
+		return http.StatusConflict, dto.NewErrorResponse(dto.ErrorCodeCodePoolEmpty, "Voucher code pool is empty")
+	case errors.Is(err, domain.ErrCodeTaken):
+		return http.StatusConflict, dto.NewErrorResponse(dto.ErrorCodeCodeTaken, "Voucher code was taken, try again")
+	case errors.Is(err, domain.ErrTransferNotFound):
+		return http.StatusNotFound, dto.NewErrorResponse(dto.ErrorCodeTransferNotFound, "Voucher transfer not found")
+	case errors.Is(err, domain.ErrTransferNotPending):
+		return http.StatusConflict, dto.NewErrorResponse(dto.ErrorCodeTransferNotPending, "Voucher transfer is no longer pending")
+	case errors.Is(err, domain.ErrTransferPending):
+		return http.StatusConflict, dto.NewErrorResponse(dto.ErrorCodeTransferPending, "Voucher already has a pending transfer")
+	case errors.Is(err, domain.ErrTransferPooledCode):
+		return http.StatusConflict, dto.NewErrorResponse(dto.ErrorCodeTransferPooled, "Merchant code vouchers cannot be transferred")
+	case errors.Is(err, domain.ErrPromoNotFound):
+		return http.StatusNotFound, dto.NewErrorResponse(dto.ErrorCodePromoNotFound, "Promo code not found")
+	case errors.Is(err, domain.ErrPromoNotApplicable):
+		return http.StatusConflict, dto.NewErrorResponse(dto.ErrorCodePromoNotApplicable, "Promo code does not apply to this purchase")
+	case errors.Is(err, domain.ErrPromoExhausted):
+		return http.StatusConflict, dto.NewErrorResponse(dto.ErrorCodePromoExhausted, "Promo code usage limit reached")
+	case errors.Is(err, domain.ErrPromoCodeExists):
+		return http.StatusConflict, dto.NewErrorResponse(dto.ErrorCodePromoCodeExists, "Promo code already exists")
+	case errors.Is(err, domain.ErrReservationNotFound):
+		return http.StatusNotFound, dto.NewErrorResponse(dto.ErrorCodeReservationMissing, "Reservation not found")
+	case errors.Is(err, domain.ErrReservationNotActive):
+		return http.StatusConflict, dto.NewErrorResponse(dto.ErrorCodeReservationEnded, "Reservation is no longer held")
+	case errors.Is(err, domain.ErrVoucherReserved):
+		return http.StatusConflict, dto.NewErrorResponse(dto.ErrorCodeVoucherReserved, "Voucher is reserved by another buyer")
+	case errors.Is(err, domain.ErrFraudBlocked):
+		return http.StatusForbidden, dto.NewErrorResponse(dto.ErrorCodeOperationBlocked, "Operation blocked for review")
+	case errors.Is(err, domain.ErrFraudDelayed):
+		return http.StatusTooManyRequests, dto.NewErrorResponse(dto.ErrorCodeOperationDelayed, "Operation delayed, try again later")
+	case errors.Is(err, domain.ErrLedgerTransactionNotFound):
+		return http.StatusNotFound, dto.NewErrorResponse(dto.ErrorCodeLedgerTxNotFound, "Ledger transaction not found")
+	case errors.Is(err, domain.ErrAlreadyReversed):
+		return http.StatusConflict, dto.NewErrorResponse(dto.ErrorCodeAlreadyReversed, "Ledger transaction already reversed")
+	case errors.Is(err, domain.ErrPurchaseNotRefunded):
+		return http.StatusConflict, dto.NewErrorResponse(dto.ErrorCodeNotRefunded, "Purchase must be refunded before reversal")
+	case errors.Is(err, domain.ErrPayoutNotReversible):
+		return http.StatusConflict, dto.NewErrorResponse(dto.ErrorCodePayoutReversal, "Payouts cannot be reversed")
+	case errors.Is(err, domain.ErrForbidden):
+		return http.StatusForbidden, dto.NewErrorResponse(dto.ErrorCodeForbidden, "Access not allowed")
+	case errors.Is(err, domain.ErrRateUnavailable):
+		return http.StatusServiceUnavailable, dto.NewErrorResponse(dto.ErrorCodeRateUnavailable, "Exchange rate unavailable")
+	default:
+		return http.StatusInternalServerError, dto.NewErrorResponse(dto.ErrorCodeInternal, "An unexpected error occurred")
+	}
+}
+
+// WriteError writes the error envelope for err
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	statusCode, response := MapError(err)
+	WriteErrorResponse(w, r, statusCode, response)
+}
+
+// WriteErrorResponse writes an error envelope with the given status,
+// translated into the language negotiated for r
+func WriteErrorResponse(w http.ResponseWriter, r *http.Request, statusCode int, response dto.ErrorResponse) {
+	lang := i18n.FromRequest(r)
+	response = localizeErrorResponse(lang, response)
+
+	setContentLanguage(w, lang)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// localizeErrorResponse translates the messages of response into lang
+func localizeErrorResponse(lang string, response dto.ErrorResponse) dto.ErrorResponse {
+	response.Error.Message = i18n.Message(lang, response.Error.Message)
+	if len(response.Error.Details) == 0 {
+		return response
+	}
+
+	// Copy the details so the caller's validation error is left untouched
+	details := make([]domain.FieldError, len(response.Error.Details))
+	for i, field := range response.Error.Details {
+		field.Message = i18n.FieldMessage(lang, field)
+		details[i] = field
+	}
+	response.Error.Details = details
+	return response
+}
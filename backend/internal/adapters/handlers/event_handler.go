@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"4SaleBackendSkeleton/internal/application/dto"
+	"4SaleBackendSkeleton/internal/domain"
+	"4SaleBackendSkeleton/internal/ports"
+	"github.com/rs/zerolog"
+)
+
+// lastEventIDHeader is sent by reconnecting EventSource clients with the ID of the last event they received
+const lastEventIDHeader = "Last-Event-ID"
+
+// EventHandler streams real-time events as Server-Sent Events
+type EventHandler struct {
+	events ports.EventSubscriber
+	// heartbeat is how often idle streams send a comment so proxies keep them open
+	heartbeat time.Duration
+	logger    zerolog.Logger
+}
+
+// NewEventHandler creates a new event handler
+func NewEventHandler(events ports.EventSubscriber, heartbeat time.Duration, logger zerolog.Logger) *EventHandler {
+	return &EventHandler{
+		events:    events,
+		heartbeat: heartbeat,
+		logger:    logger,
+	}
+}
+
+// StreamUserEvents handles the GET /events endpoint, streaming the session user's purchase
+// and redemption events. Clients resume with the Last-Event-ID header, or last_event_id
+// when reconnecting by hand.
+func (h *EventHandler) StreamUserEvents(w http.ResponseWriter, r *http.Request) {
+	userID, ok := sessionUserID(w, r)
+	if !ok {
+		return
+	}
+
+	h.stream(w, r, domain.UserTopic(userID))
+}
+
+// StreamMerchantRedemptions handles the GET /merchants/{merchant_id}/redemptions/stream endpoint,
+// streaming each redemption of the merchant's vouchers at any of its branches
+func (h *EventHandler) StreamMerchantRedemptions(w http.ResponseWriter, r *http.Request) {
+	merchantID, ok := sessionMerchantID(w, r)
+	if !ok {
+		return
+	}
+
+	h.stream(w, r, domain.MerchantTopic(merchantID))
+}
+
+// stream subscribes to topic and writes its events until the client disconnects or falls behind
+func (h *EventHandler) stream(w http.ResponseWriter, r *http.Request, topic string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		WriteErrorResponse(w, r, http.StatusInternalServerError, dto.NewErrorResponse(dto.ErrorCodeInternal, "Streaming unsupported"))
+		return
+	}
+
+	lastEventID := r.Header.Get(lastEventIDHeader)
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	sub := h.events.Subscribe(topic, lastEventID)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if sub.Reset {
+		fmt.Fprintf(w, "event: %s\ndata: {}\n\n", domain.EventReset)
+	}
+	for _, event := range sub.Replay {
+		writeEvent(w, event)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				// Dropped for falling behind; the client reconnects and replays what it missed
+				return
+			}
+			writeEvent(w, event)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// writeEvent writes one event in the Server-Sent Events format
+func writeEvent(w http.ResponseWriter, event *domain.Event) {
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+}
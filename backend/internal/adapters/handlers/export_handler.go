@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"4SaleBackendSkeleton/internal/application/dto"
+	"4SaleBackendSkeleton/internal/domain"
+	"4SaleBackendSkeleton/internal/infrastructure/xlsx"
+	"4SaleBackendSkeleton/internal/ports"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+)
+
+// formatXLSX selects an Excel workbook export
+const formatXLSX = "xlsx"
+
+// exportColumns are the columns of purchase and redemption exports, in the order of exportValues;
+// numeric columns are written as numbers to XLSX
+var exportColumns = []struct {
+	name    string
+	numeric bool
+}{
+	{"purchase_id", false},
+	{"voucher_id", false},
+	{"voucher_title", false},
+	{"merchant_id", true},
+	{"adv_id", true},
+	{"buyer_id", true},
+	{"status", false},
+	{"price", true},
+	{"currency", false},
+	{"reporting_price", true},
+	{"reporting_currency", false},
+	{"purchased_at", false},
+	{"redeemed_at", false},
+	{"refunded_at", false},
+}
+
+// exportWriter writes export rows in one file format
+type exportWriter interface {
+	WriteRow(row *domain.PurchaseExportRow) error
+	Close() error
+}
+
+// ExportHandler handles purchase and redemption export HTTP requests
+type ExportHandler struct {
+	exportService ports.ExportService
+	logger        zerolog.Logger
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(exportService ports.ExportService, logger zerolog.Logger) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+		logger:        logger,
+	}
+}
+
+// ExportPurchases handles the GET /exports/{kind} endpoint, where kind is purchases or redemptions.
+// Rows are streamed as CSV, or as an XLSX workbook with format=xlsx.
+func (h *ExportHandler) ExportPurchases(w http.ResponseWriter, r *http.Request) {
+	userID, ok := sessionUserID(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = formatCSV
+	}
+	if format != formatCSV && format != formatXLSX {
+		WriteErrorResponse(w, r, http.StatusBadRequest, dto.NewErrorResponse(dto.ErrorCodeInvalidRequest, "Invalid format"))
+		return
+	}
+
+	merchantID, ok := queryInt64(w, r, "merchant_id")
+	if !ok {
+		return
+	}
+
+	req := dto.ExportRequest{
+		Kind:        mux.Vars(r)["kind"],
+		MerchantID:  merchantID,
+		From:        query.Get("from"),
+		To:          query.Get("to"),
+		RequesterID: userID,
+	}
+	filename := fmt.Sprintf("%s-%s-%s.%s", req.Kind, req.From, req.To, format)
+
+	// The response starts with the first row so request errors can still be answered as JSON
+	var out exportWriter
+	start := func() error {
+		var err error
+		out, err = newExportWriter(w, format, filename)
+		return err
+	}
+
+	rows := 0
+	err := h.exportService.ExportPurchases(r.Context(), &req, func(row *domain.PurchaseExportRow) error {
+		if out == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		rows++
+		return out.WriteRow(row)
+	})
+	if err == nil && out == nil {
+		err = start()
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Int64("user_id", userID).Str("kind", req.Kind).Int("rows", rows).Msg("Failed to export purchases")
+		if out == nil {
+			WriteError(w, r, err)
+		}
+		// A partially written file is left truncated, which clients detect as a broken download
+		return
+	}
+
+	if err := out.Close(); err != nil {
+		h.logger.Error().Err(err).Int64("user_id", userID).Str("kind", req.Kind).Msg("Failed to complete export")
+		return
+	}
+
+	h.logger.Info().
+		Int64("user_id", userID).
+		Str("kind", req.Kind).
+		Str("format", format).
+		Int("rows", rows).
+		Msg("Purchases exported")
+}
+
+// newExportWriter writes the response headers and the header row of an export file
+func newExportWriter(w http.ResponseWriter, format, filename string) (exportWriter, error) {
+	contentType := "text/csv; charset=utf-8"
+	if format == formatXLSX {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+
+	if format == formatXLSX {
+		out, err := xlsx.NewWriter(w, "Export")
+		if err != nil {
+			return nil, err
+		}
+		header := make([]xlsx.Cell, len(exportColumns))
+		for i, column := range exportColumns {
+			header[i] = xlsx.Text(column.name)
+		}
+		return &xlsxExportWriter{out: out}, out.WriteRow(header...)
+	}
+
+	header := make([]string, len(exportColumns))
+	for i, column := range exportColumns {
+		header[i] = column.name
+	}
+	out := csv.NewWriter(w)
+	out.Write(header)
+	return &csvExportWriter{out: out}, out.Error()
+}
+
+// exportValues returns the value of each export column for row
+func exportValues(row *domain.PurchaseExportRow) []string {
+	purchase := row.Purchase
+	reportingPrice, reportingCurrency := "", ""
+	if purchase.ReportingPrice != nil {
+		reportingPrice = purchase.ReportingPrice.Decimal()
+		reportingCurrency = purchase.ReportingPrice.Currency
+	}
+
+	return []string{
+		purchase.ID.String(),
+		purchase.VoucherID.String(),
+		row.VoucherTitle,
+		strconv.FormatInt(row.MerchantID, 10),
+		strconv.FormatInt(row.AdvID, 10),
+		strconv.FormatInt(purchase.BuyerID, 10),
+		purchase.Status,
+		purchase.Price.Decimal(),
+		purchase.Price.Currency,
+		reportingPrice,
+		reportingCurrency,
+		purchase.CreatedAt.UTC().Format(time.RFC3339),
+		formatOptionalTime(purchase.RedeemedAt),
+		formatOptionalTime(purchase.RefundedAt),
+	}
+}
+
+// formatOptionalTime formats t as RFC 3339 in UTC, or as empty when it is not set
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// csvExportWriter writes export rows as CSV
+type csvExportWriter struct {
+	out *csv.Writer
+}
+
+// WriteRow writes one CSV record; csv.Writer flushes to the response as its buffer fills
+func (c *csvExportWriter) WriteRow(row *domain.PurchaseExportRow) error {
+	return c.out.Write(exportValues(row))
+}
+
+// Close flushes the buffered records
+func (c *csvExportWriter) Close() error {
+	c.out.Flush()
+	return c.out.Error()
+}
+
+// xlsxExportWriter writes export rows to a worksheet
+type xlsxExportWriter struct {
+	out *xlsx.Writer
+}
+
+// WriteRow writes one worksheet row
+func (x *xlsxExportWriter) WriteRow(row *domain.PurchaseExportRow) error {
+	values := exportValues(row)
+	cells := make([]xlsx.Cell, len(values))
+	for i, value := range values {
+		if exportColumns[i].numeric {
+			cells[i] = xlsx.Number(value)
+		} else {
+			cells[i] = xlsx.Text(value)
+		}
+	}
+	return x.out.WriteRow(cells...)
+}
+
+// Close completes the workbook
+func (x *xlsxExportWriter) Close() error {
+	return x.out.Close()
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"4SaleBackendSkeleton/internal/application/dto"
+	"4SaleBackendSkeleton/internal/domain"
+	"4SaleBackendSkeleton/internal/ports"
+	"github.com/rs/zerolog"
+)
+
+// Statement formats selected with the format query parameter
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+// LedgerHandler handles payout statement and ledger correction HTTP requests
+type LedgerHandler struct {
+	ledgerService ports.LedgerService
+	logger        zerolog.Logger
+}
+
+// NewLedgerHandler creates a new ledger handler
+func NewLedgerHandler(ledgerService ports.LedgerService, logger zerolog.Logger) *LedgerHandler {
+	return &LedgerHandler{
+		ledgerService: ledgerService,
+		logger:        logger,
+	}
+}
+
+// GetStatement handles the GET /merchant/statement endpoint.
+// The statement is returned as JSON, or as CSV with format=csv.
+func (h *LedgerHandler) GetStatement(w http.ResponseWriter, r *http.Request) {
+	merchantID, ok := sessionUserID(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format != "" && format != formatJSON && format != formatCSV {
+		WriteErrorResponse(w, r, http.StatusBadRequest, dto.NewErrorResponse(dto.ErrorCodeInvalidRequest, "Invalid format"))
+		return
+	}
+
+	req := dto.StatementRequest{
+		MerchantID: merchantID,
+		From:       query.Get("from"),
+		To:         query.Get("to"),
+	}
+
+	statement, err := h.ledgerService.GetStatement(r.Context(), &req)
+	if err != nil {
+		h.logger.Error().Err(err).Int64("merchant_id", merchantID).Msg("Failed to get payout statement")
+		WriteError(w, r, err)
+		return
+	}
+
+	if format == formatCSV {
+		filename := fmt.Sprintf("statement-%d-%s-%s.csv", merchantID, req.From, req.To)
+		if err := writeStatementCSV(w, filename, statement); err != nil {
+			h.logger.Error().Err(err).Int64("merchant_id", merchantID).Msg("Failed to write payout statement CSV")
+		}
+		return
+	}
+
+	writeSuccess(w, http.StatusOK, "Payout statement retrieved successfully", statement)
+}
+
+// SettleStatementWebhook handles the POST /webhook/merchant-payout endpoint
+func (h *LedgerHandler) SettleStatementWebhook(w http.ResponseWriter, r *http.Request) {
+	var req dto.StatementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to decode merchant payout request")
+		WriteErrorResponse(w, r, http.StatusBadRequest, dto.NewErrorResponse(dto.ErrorCodeInvalidRequest, "Invalid request body"))
+		return
+	}
+
+	statement, err := h.ledgerService.SettleStatement(r.Context(), &req)
+	if err != nil {
+		h.logger.Error().Err(err).Int64("merchant_id", req.MerchantID).Msg("Failed to settle payout statement")
+		WriteError(w, r, err)
+		return
+	}
+
+	h.logger.Info().
+		Int64("merchant_id", req.MerchantID).
+		Str("from", req.From).
+		Str("to", req.To).
+		Int("settlements", len(statement.Settlements)).
+		Bool("reconciled", statement.Reconciled).
+		Msg("Payout statement settled")
+
+	writeSuccess(w, http.StatusOK, "Payout statement settled successfully", statement)
+}
+
+// ReverseTransactionWebhook handles the POST /webhook/ledger-reversal endpoint
+func (h *LedgerHandler) ReverseTransactionWebhook(w http.ResponseWriter, r *http.Request) {
+	var req dto.ReverseLedgerTransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to decode ledger reversal request")
+		WriteErrorResponse(w, r, http.StatusBadRequest, dto.NewErrorResponse(dto.ErrorCodeInvalidRequest, "Invalid request body"))
+		return
+	}
+
+	reversal, err := h.ledgerService.ReverseTransaction(r.Context(), &req)
+	if err != nil {
+		h.logger.Error().Err(err).Str("transaction_id", req.TransactionID.String()).Msg("Failed to reverse ledger transaction")
+		WriteError(w, r, err)
+		return
+	}
+
+	h.logger.Info().
+		Str("transaction_id", req.TransactionID.String()).
+		Str("reversal_id", reversal.ID.String()).
+		Msg("Ledger transaction reversed")
+
+	writeSuccess(w, http.StatusOK, "Ledger transaction reversed successfully", reversal)
+}
+
+// writeStatementCSV writes the statement lines followed by one total row per currency
+func writeStatementCSV(w http.ResponseWriter, filename string, statement *domain.PayoutStatement) error {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+
+	out := csv.NewWriter(w)
+	out.Write([]string{"posted_at", "type", "entry_id", "transaction_id", "purchase_id", "voucher_id", "currency", "gross", "commission", "net", "settlement_id"})
+	for _, line := range statement.Lines {
+		settlementID := ""
+		if line.SettlementID != nil {
+			settlementID = line.SettlementID.String()
+		}
+		out.Write([]string{
+			line.PostedAt.UTC().Format(time.RFC3339),
+			line.Type,
+			line.EntryID.String(),
+			line.TransactionID.String(),
+			line.PurchaseID.String(),
+			line.VoucherID.String(),
+			line.Net.Currency,
+			line.Gross.Decimal(),
+			line.Commission.Decimal(),
+			line.Net.Decimal(),
+			settlementID,
+		})
+	}
+	for _, total := range statement.Totals {
+		out.Write([]string{"", "total", "", "", "", "", total.Currency, total.Gross.Decimal(), total.Commission.Decimal(), total.Net.Decimal(), ""})
+	}
+
+	out.Flush()
+	return out.Error()
+}
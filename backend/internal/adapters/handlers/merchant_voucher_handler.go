@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"4SaleBackendSkeleton/internal/application/dto"
+	"4SaleBackendSkeleton/internal/domain"
+	"4SaleBackendSkeleton/internal/ports"
+	"github.com/rs/zerolog"
+)
+
+// MerchantVoucherHandler handles merchant voucher management HTTP requests
+type MerchantVoucherHandler struct {
+	merchantVoucherService ports.MerchantVoucherService
+	logger                 zerolog.Logger
+}
+
+// NewMerchantVoucherHandler creates a new merchant voucher handler
+func NewMerchantVoucherHandler(merchantVoucherService ports.MerchantVoucherService, logger zerolog.Logger) *MerchantVoucherHandler {
+	return &MerchantVoucherHandler{
+		merchantVoucherService: merchantVoucherService,
+		logger:                 logger,
+	}
+}
+
+// ListVouchers handles the GET /merchant/vouchers endpoint
+func (h *MerchantVoucherHandler) ListVouchers(w http.ResponseWriter, r *http.Request) {
+	merchantID, ok := sessionUserID(w, r)
+	if !ok {
+		return
+	}
+
+	vouchers, err := h.merchantVoucherService.ListVouchers(r.Context(), merchantID)
+	if err != nil {
+		h.logger.Error().Err(err).Int64("merchant_id", merchantID).Msg("Failed to list merchant vouchers")
+		WriteError(w, r, err)
+		return
+	}
+
+	writeSuccess(w, http.StatusOK, "Merchant vouchers retrieved successfully", vouchers)
+}
+
+// GetVoucher handles the GET /merchant/vouchers/{voucher_id} endpoint
+func (h *MerchantVoucherHandler) GetVoucher(w http.ResponseWriter, r *http.Request) {
+	merchantID, ok := sessionUserID(w, r)
+	if !ok {
+		return
+	}
+	voucherID, ok := pathUUID(w, r, "voucher_id")
+	if !ok {
+		return
+	}
+
+	voucher, err := h.merchantVoucherService.GetVoucher(r.Context(), merchantID, voucherID)
+	if err != nil {
+		h.logger.Error().Err(err).Str("voucher_id", voucherID.String()).Msg("Failed to get merchant voucher")
+		WriteError(w, r, err)
+		return
+	}
+
+	writeSuccess(w, http.StatusOK, "Voucher retrieved successfully", voucher)
+}
+
+// UpdateVoucher handles the PATCH /merchant/vouchers/{voucher_id} endpoint
+func (h *MerchantVoucherHandler) UpdateVoucher(w http.ResponseWriter, r *http.Request) {
+	merchantID, ok := sessionUserID(w, r)
+	if !ok {
+		return
+	}
+	voucherID, ok := pathUUID(w, r, "voucher_id")
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateVoucherRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to decode update voucher request")
+		WriteErrorResponse(w, r, http.StatusBadRequest, dto.NewErrorResponse(dto.ErrorCodeInvalidRequest, "Invalid request body"))
+		return
+	}
+
+	voucher, err := h.merchantVoucherService.UpdateVoucher(r.Context(), merchantID, voucherID, &req)
+	if err != nil {
+		h.logger.Error().Err(err).Str("voucher_id", voucherID.String()).Msg("Failed to update voucher")
+		WriteError(w, r, err)
+		return
+	}
+
+	h.logger.Info().
+		Str("voucher_id", voucher.ID.String()).
+		Int64("merchant_id", merchantID).
+		Int("version", voucher.Version).
+		Msg("Voucher updated successfully")
+
+	writeSuccess(w, http.StatusOK, "Voucher updated successfully", voucher)
+}
+
+// UploadCodes handles the POST /merchant/vouchers/{voucher_id}/codes endpoint
+func (h *MerchantVoucherHandler) UploadCodes(w http.ResponseWriter, r *http.Request) {
+	merchantID, ok := sessionUserID(w, r)
+	if !ok {
+		return
+	}
+	voucherID, ok := pathUUID(w, r, "voucher_id")
+	if !ok {
+		return
+	}
+
+	var req dto.UploadVoucherCodesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to decode upload voucher codes request")
+		WriteErrorResponse(w, r, http.StatusBadRequest, dto.NewErrorResponse(dto.ErrorCodeInvalidRequest, "Invalid request body"))
+		return
+	}
+
+	result, err := h.merchantVoucherService.UploadCodes(r.Context(), merchantID, voucherID, &req)
+	if err != nil {
+		h.logger.Error().Err(err).Str("voucher_id", voucherID.String()).Msg("Failed to upload voucher codes")
+		WriteError(w, r, err)
+		return
+	}
+
+	h.logger.Info().
+		Str("voucher_id", voucherID.String()).
+		Int64("merchant_id", merchantID).
+		Int("added", result.Added).
+		Int("duplicates", result.Duplicates).
+		Msg("Voucher codes uploaded successfully")
+
+	writeSuccess(w, http.StatusOK, "Voucher codes uploaded successfully", result)
+}
+
+// GetCodePool handles the GET /merchant/vouchers/{voucher_id}/codes endpoint
+func (h *MerchantVoucherHandler) GetCodePool(w http.ResponseWriter, r *http.Request) {
+	merchantID, ok := sessionUserID(w, r)
+	if !ok {
+		return
+	}
+	voucherID, ok := pathUUID(w, r, "voucher_id")
+	if !ok {
+		return
+	}
+
+	pool, err := h.merchantVoucherService.GetCodePool(r.Context(), merchantID, voucherID)
+	if err != nil {
+		h.logger.Error().Err(err).Str("voucher_id", voucherID.String()).Msg("Failed to get voucher code pool")
+		WriteError(w, r, err)
+		return
+	}
+
+	writeSuccess(w, http.StatusOK, "Voucher code pool retrieved successfully", pool)
+}
+
+// GetVoucherHistory handles the GET /merchant/vouchers/{voucher_id}/history endpoint
+func (h *MerchantVoucherHandler) GetVoucherHistory(w http.ResponseWriter, r *http.Request) {
+	merchantID, ok := sessionUserID(w, r)
+	if !ok {
+		return
+	}
+	voucherID, ok := pathUUID(w, r, "voucher_id")
+	if !ok {
+		return
+	}
+
+	changes, err := h.merchantVoucherService.GetVoucherHistory(r.Context(), merchantID, voucherID)
+	if err != nil {
+		h.logger.Error().Err(err).Str("voucher_id", voucherID.String()).Msg("Failed to get voucher history")
+		WriteError(w, r, err)
+		return
+	}
+
+	writeSuccess(w, http.StatusOK, "Voucher history retrieved successfully", changes)
+}
+
+// PauseVoucher handles the POST /merchant/vouchers/{voucher_id}/pause endpoint
+func (h *MerchantVoucherHandler) PauseVoucher(w http.ResponseWriter, r *http.Request) {
+	h.setVoucherStatus(w, r, domain.VoucherStatusPaused, "Voucher sales paused")
+}
+
+// ResumeVoucher handles the POST /merchant/vouchers/{voucher_id}/resume endpoint
+func (h *MerchantVoucherHandler) ResumeVoucher(w http.ResponseWriter, r *http.Request) {
+	h.setVoucherStatus(w, r, domain.VoucherStatusActive, "Voucher sales resumed")
+}
+
+// DeleteVoucher handles the DELETE /merchant/vouchers/{voucher_id} endpoint
+func (h *MerchantVoucherHandler) DeleteVoucher(w http.ResponseWriter, r *http.Request) {
+	merchantID, ok := sessionUserID(w, r)
+	if !ok {
+		return
+	}
+	voucherID, ok := pathUUID(w, r, "voucher_id")
+	if !ok {
+		return
+	}
+
+	if err := h.merchantVoucherService.DeleteVoucher(r.Context(), merchantID, voucherID); err != nil {
+		h.logger.Error().Err(err).Str("voucher_id", voucherID.String()).Msg("Failed to delete voucher")
+		WriteError(w, r, err)
+		return
+	}
+
+	h.logger.Info().
+		Str("voucher_id", voucherID.String()).
+		Int64("merchant_id", merchantID).
+		Msg("Voucher deleted successfully")
+
+	writeSuccess(w, http.StatusOK, "Voucher deleted successfully", nil)
+}
+
+// setVoucherStatus changes the sale status of the voucher in the path
+func (h *MerchantVoucherHandler) setVoucherStatus(w http.ResponseWriter, r *http.Request, status, message string) {
+	merchantID, ok := sessionUserID(w, r)
+	if !ok {
+		return
+	}
+	voucherID, ok := pathUUID(w, r, "voucher_id")
+	if !ok {
+		return
+	}
+
+	voucher, err := h.merchantVoucherService.SetVoucherStatus(r.Context(), merchantID, voucherID, status)
+	if err != nil {
+		h.logger.Error().Err(err).Str("voucher_id", voucherID.String()).Msg("Failed to update voucher status")
+		WriteError(w, r, err)
+		return
+	}
+
+	h.logger.Info().
+		Str("voucher_id", voucherID.String()).
+		Str("status", status).
+		Msg(message)
+
+	writeSuccess(w, http.StatusOK, message, voucher)
+}
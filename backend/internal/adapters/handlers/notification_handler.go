@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"4SaleBackendSkeleton/internal/application/dto"
+	"4SaleBackendSkeleton/internal/ports"
+	"github.com/rs/zerolog"
+)
+
+// NotificationHandler handles notification preference HTTP requests
+type NotificationHandler struct {
+	notificationService ports.NotificationService
+	logger              zerolog.Logger
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationService ports.NotificationService, logger zerolog.Logger) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		logger:              logger,
+	}
+}
+
+// GetPreferences handles the GET /notification-preferences endpoint
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := sessionUserID(w, r)
+	if !ok {
+		return
+	}
+
+	prefs, err := h.notificationService.GetPreferences(r.Context(), userID)
+	if err != nil {
+		h.logger.Error().Err(err).Int64("user_id", userID).Msg("Failed to get notification preferences")
+		WriteError(w, r, err)
+		return
+	}
+
+	writeSuccess(w, http.StatusOK, "Notification preferences retrieved successfully", prefs)
+}
+
+// UpdatePreferences handles the PATCH /notification-preferences endpoint
+func (h *NotificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := sessionUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateNotificationPreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to decode notification preferences request")
+		WriteErrorResponse(w, r, http.StatusBadRequest, dto.NewErrorResponse(dto.ErrorCodeInvalidRequest, "Invalid request body"))
+		return
+	}
+
+	prefs, err := h.notificationService.UpdatePreferences(r.Context(), userID, &req)
+	if err != nil {
+		h.logger.Error().Err(err).Int64("user_id", userID).Msg("Failed to update notification preferences")
+		WriteError(w, r, err)
+		return
+	}
+
+	writeSuccess(w, http.StatusOK, "Notification preferences updated successfully", prefs)
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"4SaleBackendSkeleton/internal/application/dto"
+	"4SaleBackendSkeleton/internal/ports"
+	"github.com/rs/zerolog"
+)
+
+// PromoHandler handles promo code HTTP requests
+type PromoHandler struct {
+	promoService ports.PromoService
+	logger       zerolog.Logger
+}
+
+// NewPromoHandler creates a new promo handler
+func NewPromoHandler(promoService ports.PromoService, logger zerolog.Logger) *PromoHandler {
+	return &PromoHandler{
+		promoService: promoService,
+		logger:       logger,
+	}
+}
+
+// CreatePromoCodeWebhook handles the POST /webhook/promo-code-created endpoint
+func (h *PromoHandler) CreatePromoCodeWebhook(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreatePromoCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to decode create promo code request")
+		WriteErrorResponse(w, r, http.StatusBadRequest, dto.NewErrorResponse(dto.ErrorCodeInvalidRequest, "Invalid request body"))
+		return
+	}
+
+	promo, err := h.promoService.CreatePromoCode(r.Context(), &req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to create promo code")
+		WriteError(w, r, err)
+		return
+	}
+
+	h.logger.Info().
+		Int64("promo_code_id", promo.ID).
+		Str("code", promo.Code).
+		Str("funded_by", promo.FundedBy).
+		Msg("Promo code created successfully")
+
+	writeSuccess(w, http.StatusOK, "Promo code created successfully", promo)
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"4SaleBackendSkeleton/internal/application/dto"
+	"4SaleBackendSkeleton/internal/ports"
+	"github.com/rs/zerolog"
+)
+
+// RedemptionHandler handles merchant redemption history HTTP requests
+type RedemptionHandler struct {
+	redemptionService ports.RedemptionService
+	logger            zerolog.Logger
+}
+
+// NewRedemptionHandler creates a new redemption handler
+func NewRedemptionHandler(redemptionService ports.RedemptionService, logger zerolog.Logger) *RedemptionHandler {
+	return &RedemptionHandler{
+		redemptionService: redemptionService,
+		logger:            logger,
+	}
+}
+
+// ListRedemptions handles the GET /merchants/{merchant_id}/redemptions endpoint
+func (h *RedemptionHandler) ListRedemptions(w http.ResponseWriter, r *http.Request) {
+	merchantID, ok := sessionMerchantID(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	req := dto.RedemptionListRequest{
+		MerchantID: merchantID,
+		From:       query.Get("from"),
+		To:         query.Get("to"),
+		Cursor:     query.Get("cursor"),
+	}
+
+	var err error
+	if req.BranchID, ok = queryInt64(w, r, "branch_id"); !ok {
+		return
+	}
+	if value := query.Get("limit"); value != "" {
+		if req.Limit, err = strconv.Atoi(value); err != nil {
+			WriteErrorResponse(w, r, http.StatusBadRequest, dto.NewErrorResponse(dto.ErrorCodeInvalidRequest, "Invalid limit"))
+			return
+		}
+	}
+
+	page, err := h.redemptionService.ListRedemptions(r.Context(), &req)
+	if err != nil {
+		h.logger.Error().Err(err).Int64("merchant_id", merchantID).Msg("Failed to list redemptions")
+		WriteError(w, r, err)
+		return
+	}
+
+	writeSuccessWithMeta(w, http.StatusOK, "Redemptions retrieved successfully", page.Redemptions, page.Meta)
+}
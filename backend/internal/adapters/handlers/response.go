@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"4SaleBackendSkeleton/internal/application/dto"
+	"4SaleBackendSkeleton/internal/infrastructure/auth"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// writeSuccess writes a success envelope with the given status
+func writeSuccess(w http.ResponseWriter, statusCode int, message string, data interface{}) {
+	writeSuccessWithMeta(w, statusCode, message, data, nil)
+}
+
+// writeSuccessWithMeta writes a success envelope carrying list metadata such as pagination
+func writeSuccessWithMeta(w http.ResponseWriter, statusCode int, message string, data, meta interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := dto.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    data,
+		Meta:    meta,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// sessionUserID returns the user ID of the authenticated session, writing a 401 if there is none
+func sessionUserID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok {
+		WriteErrorResponse(w, r, http.StatusUnauthorized, dto.NewErrorResponse(dto.ErrorCodeUnauthorized, "Authorization token required"))
+		return 0, false
+	}
+	return claims.UserID, true
+}
+
+// queryInt64 parses an optional integer query parameter, writing a 400 if it is malformed
+func queryInt64(w http.ResponseWriter, r *http.Request, name string) (*int64, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, true
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, dto.NewErrorResponse(dto.ErrorCodeInvalidRequest, "Invalid "+name))
+		return nil, false
+	}
+	return &n, true
+}
+
+// pathInt64 parses an integer path variable, writing a 400 if it is malformed
+func pathInt64(w http.ResponseWriter, r *http.Request, name string) (int64, bool) {
+	n, err := strconv.ParseInt(mux.Vars(r)[name], 10, 64)
+	if err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, dto.NewErrorResponse(dto.ErrorCodeInvalidRequest, "Invalid "+name))
+		return 0, false
+	}
+	return n, true
+}
+
+// sessionMerchantID returns the merchant ID in the path, writing a 403 unless it is the
+// authenticated session's own
+func sessionMerchantID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	userID, ok := sessionUserID(w, r)
+	if !ok {
+		return 0, false
+	}
+	merchantID, ok := pathInt64(w, r, "merchant_id")
+	if !ok {
+		return 0, false
+	}
+	if merchantID != userID {
+		WriteErrorResponse(w, r, http.StatusForbidden, dto.NewErrorResponse(dto.ErrorCodeForbidden, "Access to this merchant is not allowed"))
+		return 0, false
+	}
+	return merchantID, true
+}
+
+// pathUUID parses a UUID path variable, writing a 400 if it is malformed
+func pathUUID(w http.ResponseWriter, r *http.Request, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(mux.Vars(r)[name])
+	if err != nil {
+		WriteErrorResponse(w, r, http.StatusBadRequest, dto.NewErrorResponse(dto.ErrorCodeInvalidRequest, "Invalid "+name))
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// setContentLanguage records the negotiated language of a localized response
+func setContentLanguage(w http.ResponseWriter, lang string) {
+	w.Header().Set("Content-Language", lang)
+	w.Header().Add("Vary", "Accept-Language")
+}
@@ -1,23 +1,89 @@
 package handlers
 
 import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
 	"net/http"
 
+	"4SaleBackendSkeleton/internal/application/dto"
+	"4SaleBackendSkeleton/internal/domain"
+	"4SaleBackendSkeleton/internal/infrastructure/auth"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/rs/zerolog"
 )
 
+// requestIDHeader carries the request ID a caller sets, or the one generated for it
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the caller-supplied request IDs that are kept
+const maxRequestIDLength = 64
+
+// maxWebhookBytes bounds the body of a signed webhook read to check its signature
+const maxWebhookBytes = 1 << 20
+
 // Router handles HTTP routing
 type Router struct {
-	voucherHandler *VoucherHandler
-	logger         zerolog.Logger
+	voucherHandler         *VoucherHandler
+	merchantVoucherHandler *MerchantVoucherHandler
+	voucherImportHandler   *VoucherImportHandler
+	catalogueHandler       *CatalogueHandler
+	categoryHandler        *CategoryHandler
+	ledgerHandler          *LedgerHandler
+	analyticsHandler       *AnalyticsHandler
+	exportHandler          *ExportHandler
+	transferHandler        *TransferHandler
+	promoHandler           *PromoHandler
+	auditHandler           *AuditHandler
+	notificationHandler    *NotificationHandler
+	eventHandler           *EventHandler
+	redemptionHandler      *RedemptionHandler
+	tokenIssuer            *auth.TokenIssuer
+	// webhookSecret signs the webhooks that move money
+	webhookSecret []byte
+	logger        zerolog.Logger
 }
 
 // NewRouter creates a new router
-func NewRouter(voucherHandler *VoucherHandler, logger zerolog.Logger) *Router {
+func NewRouter(
+	voucherHandler *VoucherHandler,
+	merchantVoucherHandler *MerchantVoucherHandler,
+	voucherImportHandler *VoucherImportHandler,
+	catalogueHandler *CatalogueHandler,
+	categoryHandler *CategoryHandler,
+	ledgerHandler *LedgerHandler,
+	analyticsHandler *AnalyticsHandler,
+	exportHandler *ExportHandler,
+	transferHandler *TransferHandler,
+	promoHandler *PromoHandler,
+	auditHandler *AuditHandler,
+	notificationHandler *NotificationHandler,
+	eventHandler *EventHandler,
+	redemptionHandler *RedemptionHandler,
+	tokenIssuer *auth.TokenIssuer,
+	webhookSecret []byte,
+	logger zerolog.Logger,
+) *Router {
 	return &Router{
-		voucherHandler: voucherHandler,
-		logger:         logger,
+		voucherHandler:         voucherHandler,
+		merchantVoucherHandler: merchantVoucherHandler,
+		voucherImportHandler:   voucherImportHandler,
+		catalogueHandler:       catalogueHandler,
+		categoryHandler:        categoryHandler,
+		ledgerHandler:          ledgerHandler,
+		analyticsHandler:       analyticsHandler,
+		exportHandler:          exportHandler,
+		transferHandler:        transferHandler,
+		promoHandler:           promoHandler,
+		auditHandler:           auditHandler,
+		notificationHandler:    notificationHandler,
+		eventHandler:           eventHandler,
+		redemptionHandler:      redemptionHandler,
+		tokenIssuer:            tokenIssuer,
+		webhookSecret:          webhookSecret,
+		logger:                 logger,
 	}
 }
 
@@ -26,6 +92,7 @@ func (rt *Router) SetupRoutes() *mux.Router {
 	r := mux.NewRouter()
 
 	// Apply middleware
+	r.Use(rt.auditMiddleware)
 	r.Use(rt.loggingMiddleware)
 	r.Use(rt.corsMiddleware)
 
@@ -36,12 +103,87 @@ func (rt *Router) SetupRoutes() *mux.Router {
 	webhookRouter := r.PathPrefix("/webhook").Subrouter()
 	webhookRouter.HandleFunc("/voucher-created", rt.voucherHandler.CreateVoucherWebhook).Methods("POST")
 	webhookRouter.HandleFunc("/voucher-purchased", rt.voucherHandler.PurchaseVoucherWebhook).Methods("POST")
+	webhookRouter.HandleFunc("/voucher-reserved", rt.voucherHandler.ReserveVoucherWebhook).Methods("POST")
+	webhookRouter.HandleFunc("/reservation-confirmed", rt.voucherHandler.ConfirmReservationWebhook).Methods("POST")
+	webhookRouter.HandleFunc("/reservation-released", rt.voucherHandler.ReleaseReservationWebhook).Methods("POST")
 	webhookRouter.HandleFunc("/voucher-redeemed", rt.voucherHandler.RedeemVoucherWebhook).Methods("POST")
+	webhookRouter.HandleFunc("/voucher-updated", rt.voucherHandler.UpdateVoucherWebhook).Methods("POST")
+	webhookRouter.HandleFunc("/voucher-deleted", rt.voucherHandler.DeleteVoucherWebhook).Methods("POST")
+	webhookRouter.Handle("/merchant-payout", rt.signedWebhook(rt.ledgerHandler.SettleStatementWebhook)).Methods("POST")
+	webhookRouter.Handle("/ledger-reversal", rt.signedWebhook(rt.ledgerHandler.ReverseTransactionWebhook)).Methods("POST")
+	webhookRouter.Handle("/promo-code-created", rt.signedWebhook(rt.promoHandler.CreatePromoCodeWebhook)).Methods("POST")
 
-	// WebView API endpoints
+	// WebView API endpoints (session required, own vouchers only)
 	apiRouter := r.PathPrefix("/vouchers").Subrouter()
+	apiRouter.Use(rt.authMiddleware)
 	apiRouter.HandleFunc("/{user_id:[0-9]+}", rt.voucherHandler.GetUserVouchers).Methods("GET")
 
+	// Public catalogue endpoints
+	r.HandleFunc("/catalogue", rt.catalogueHandler.Browse).Methods("GET")
+	r.HandleFunc("/categories", rt.categoryHandler.GetCategories).Methods("GET")
+
+	// Merchant voucher management endpoints (session required)
+	merchantRouter := r.PathPrefix("/merchant/vouchers").Subrouter()
+	merchantRouter.Use(rt.authMiddleware)
+	merchantRouter.HandleFunc("", rt.merchantVoucherHandler.ListVouchers).Methods("GET")
+	merchantRouter.HandleFunc("/import", rt.voucherImportHandler.ImportVouchers).Methods("POST")
+	merchantRouter.HandleFunc("/{voucher_id}", rt.merchantVoucherHandler.GetVoucher).Methods("GET")
+	merchantRouter.HandleFunc("/{voucher_id}", rt.merchantVoucherHandler.UpdateVoucher).Methods("PATCH")
+	merchantRouter.HandleFunc("/{voucher_id}", rt.merchantVoucherHandler.DeleteVoucher).Methods("DELETE")
+	merchantRouter.HandleFunc("/{voucher_id}/history", rt.merchantVoucherHandler.GetVoucherHistory).Methods("GET")
+	merchantRouter.HandleFunc("/{voucher_id}/codes", rt.merchantVoucherHandler.GetCodePool).Methods("GET")
+	merchantRouter.HandleFunc("/{voucher_id}/codes", rt.merchantVoucherHandler.UploadCodes).Methods("POST")
+	merchantRouter.HandleFunc("/{voucher_id}/pause", rt.merchantVoucherHandler.PauseVoucher).Methods("POST")
+	merchantRouter.HandleFunc("/{voucher_id}/resume", rt.merchantVoucherHandler.ResumeVoucher).Methods("POST")
+
+	// Merchant payout statement endpoint (session required)
+	statementRouter := r.PathPrefix("/merchant/statement").Subrouter()
+	statementRouter.Use(rt.authMiddleware)
+	statementRouter.HandleFunc("", rt.ledgerHandler.GetStatement).Methods("GET")
+
+	// Merchant live redemption feed (session required, own merchant only, token may be passed as access_token)
+	redemptionStreamRouter := r.PathPrefix("/merchants/{merchant_id:[0-9]+}/redemptions/stream").Subrouter()
+	redemptionStreamRouter.Use(rt.streamAuthMiddleware)
+	redemptionStreamRouter.HandleFunc("", rt.eventHandler.StreamMerchantRedemptions).Methods("GET")
+
+	// Merchant analytics and redemption history endpoints (session required, own merchant only)
+	merchantsRouter := r.PathPrefix("/merchants/{merchant_id:[0-9]+}").Subrouter()
+	merchantsRouter.Use(rt.authMiddleware)
+	merchantsRouter.HandleFunc("/analytics", rt.analyticsHandler.GetMerchantAnalytics).Methods("GET")
+	merchantsRouter.HandleFunc("/redemptions", rt.redemptionHandler.ListRedemptions).Methods("GET")
+
+	// Purchase and redemption export endpoints (session required)
+	exportRouter := r.PathPrefix("/exports").Subrouter()
+	exportRouter.Use(rt.authMiddleware)
+	exportRouter.HandleFunc("/{kind:purchases|redemptions}", rt.exportHandler.ExportPurchases).Methods("GET")
+
+	// Voucher gift transfer endpoints (session required)
+	purchaseRouter := r.PathPrefix("/purchases/{purchase_id}").Subrouter()
+	purchaseRouter.Use(rt.authMiddleware)
+	purchaseRouter.HandleFunc("/transfers", rt.transferHandler.CreateTransfer).Methods("POST")
+	purchaseRouter.HandleFunc("/transfers", rt.transferHandler.GetTransferHistory).Methods("GET")
+
+	transferRouter := r.PathPrefix("/transfers/{transfer_id}").Subrouter()
+	transferRouter.Use(rt.authMiddleware)
+	transferRouter.HandleFunc("/accept", rt.transferHandler.AcceptTransfer).Methods("POST")
+	transferRouter.HandleFunc("/cancel", rt.transferHandler.CancelTransfer).Methods("POST")
+
+	// Audit log search endpoint (session required, support staff only)
+	auditRouter := r.PathPrefix("/audit-events").Subrouter()
+	auditRouter.Use(rt.authMiddleware)
+	auditRouter.HandleFunc("", rt.auditHandler.ListAuditEvents).Methods("GET")
+
+	// Notification preference endpoints (session required)
+	notificationRouter := r.PathPrefix("/notification-preferences").Subrouter()
+	notificationRouter.Use(rt.authMiddleware)
+	notificationRouter.HandleFunc("", rt.notificationHandler.GetPreferences).Methods("GET")
+	notificationRouter.HandleFunc("", rt.notificationHandler.UpdatePreferences).Methods("PATCH")
+
+	// Real-time event stream endpoint (session required, token may be passed as access_token)
+	eventRouter := r.PathPrefix("/events").Subrouter()
+	eventRouter.Use(rt.streamAuthMiddleware)
+	eventRouter.HandleFunc("", rt.eventHandler.StreamUserEvents).Methods("GET")
+
 	return r
 }
 
@@ -52,6 +194,27 @@ func (rt *Router) healthCheck(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(`{"status": "healthy", "service": "voucher-api"}`))
 }
 
+// auditMiddleware tags each request with an ID, echoed in the X-Request-ID response header,
+// and records the request ID and client IP for the audit log. Requests act for the platform
+// until authMiddleware identifies a session user.
+func (rt *Router) auditMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(requestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.New().String()
+		}
+		w.Header().Set(requestIDHeader, requestID)
+
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+
+		source := domain.AuditSource{RequestID: requestID, IP: ip, ActorType: domain.AuditActorPlatform}
+		next.ServeHTTP(w, r.WithContext(domain.ContextWithAuditSource(r.Context(), source)))
+	})
+}
+
 // loggingMiddleware logs HTTP requests
 func (rt *Router) loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -59,6 +222,7 @@ func (rt *Router) loggingMiddleware(next http.Handler) http.Handler {
 			Str("method", r.Method).
 			Str("path", r.URL.Path).
 			Str("remote_addr", r.RemoteAddr).
+			Str("request_id", w.Header().Get(requestIDHeader)).
 			Msg("HTTP request")
 
 		next.ServeHTTP(w, r)
@@ -69,8 +233,8 @@ func (rt *Router) loggingMiddleware(next http.Handler) http.Handler {
 func (rt *Router) corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID, Last-Event-ID")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -79,4 +243,71 @@ func (rt *Router) corsMiddleware(next http.Handler) http.Handler {
 
 		next.ServeHTTP(w, r)
 	})
+}
+
+// authMiddleware validates the session token locally and stores its claims in the request context
+func (rt *Router) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := auth.BearerToken(r.Header.Get("Authorization"))
+		if token == "" {
+			rt.writeUnauthorized(w, r, "Authorization token required")
+			return
+		}
+
+		claims, err := rt.tokenIssuer.Validate(r.Context(), token)
+		if err != nil {
+			if !errors.Is(err, auth.ErrInvalidToken) && !errors.Is(err, auth.ErrExpiredToken) && !errors.Is(err, auth.ErrRevokedToken) {
+				rt.logger.Error().Err(err).Msg("Failed to validate session token")
+			}
+			rt.writeUnauthorized(w, r, "Invalid or expired token")
+			return
+		}
+
+		ctx := auth.ContextWithClaims(r.Context(), claims)
+		if source, ok := domain.AuditSourceFromContext(ctx); ok {
+			userID := claims.UserID
+			source.ActorType = domain.AuditActorUser
+			source.ActorID = &userID
+			ctx = domain.ContextWithAuditSource(ctx, source)
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// streamAuthMiddleware authenticates event streams like authMiddleware, also accepting the
+// token in the access_token query parameter since browsers' EventSource cannot set headers
+func (rt *Router) streamAuthMiddleware(next http.Handler) http.Handler {
+	authenticated := rt.authMiddleware(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("access_token"); token != "" && r.Header.Get("Authorization") == "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		authenticated.ServeHTTP(w, r)
+	})
+}
+
+// signedWebhook serves a webhook only when its body carries the marketplace's signature
+// in the X-Webhook-Signature header, for webhooks that move money or create promo codes
+func (rt *Router) signedWebhook(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBytes))
+		if err != nil {
+			WriteErrorResponse(w, r, http.StatusBadRequest, dto.NewErrorResponse(dto.ErrorCodeInvalidRequest, "Invalid request body"))
+			return
+		}
+
+		if !auth.VerifyWebhook(rt.webhookSecret, body, r.Header.Get(auth.WebhookSignatureHeader)) {
+			rt.writeUnauthorized(w, r, "Invalid webhook signature")
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeUnauthorized writes a 401 error response
+func (rt *Router) writeUnauthorized(w http.ResponseWriter, r *http.Request, message string) {
+	WriteErrorResponse(w, r, http.StatusUnauthorized, dto.NewErrorResponse(dto.ErrorCodeUnauthorized, message))
 }
\ No newline at end of file
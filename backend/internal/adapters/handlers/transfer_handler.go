@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"4SaleBackendSkeleton/internal/application/dto"
+	"4SaleBackendSkeleton/internal/ports"
+	"github.com/rs/zerolog"
+)
+
+// TransferHandler handles voucher gift transfer HTTP requests
+type TransferHandler struct {
+	transferService ports.TransferService
+	logger          zerolog.Logger
+}
+
+// NewTransferHandler creates a new transfer handler
+func NewTransferHandler(transferService ports.TransferService, logger zerolog.Logger) *TransferHandler {
+	return &TransferHandler{
+		transferService: transferService,
+		logger:          logger,
+	}
+}
+
+// CreateTransfer handles the POST /purchases/{purchase_id}/transfers endpoint
+func (h *TransferHandler) CreateTransfer(w http.ResponseWriter, r *http.Request) {
+	userID, ok := sessionUserID(w, r)
+	if !ok {
+		return
+	}
+	purchaseID, ok := pathUUID(w, r, "purchase_id")
+	if !ok {
+		return
+	}
+
+	var req dto.CreateTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to decode create transfer request")
+		WriteErrorResponse(w, r, http.StatusBadRequest, dto.NewErrorResponse(dto.ErrorCodeInvalidRequest, "Invalid request body"))
+		return
+	}
+
+	transfer, err := h.transferService.CreateTransfer(r.Context(), userID, purchaseID, &req)
+	if err != nil {
+		h.logger.Error().Err(err).Str("purchase_id", purchaseID.String()).Msg("Failed to create transfer")
+		WriteError(w, r, err)
+		return
+	}
+
+	h.logger.Info().
+		Str("transfer_id", transfer.ID.String()).
+		Str("purchase_id", purchaseID.String()).
+		Int64("from_buyer_id", userID).
+		Msg("Transfer created successfully")
+
+	writeSuccess(w, http.StatusOK, "Transfer created successfully", transfer)
+}
+
+// GetTransferHistory handles the GET /purchases/{purchase_id}/transfers endpoint
+func (h *TransferHandler) GetTransferHistory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := sessionUserID(w, r)
+	if !ok {
+		return
+	}
+	purchaseID, ok := pathUUID(w, r, "purchase_id")
+	if !ok {
+		return
+	}
+
+	transfers, err := h.transferService.GetTransferHistory(r.Context(), userID, purchaseID)
+	if err != nil {
+		h.logger.Error().Err(err).Str("purchase_id", purchaseID.String()).Msg("Failed to get transfer history")
+		WriteError(w, r, err)
+		return
+	}
+
+	writeSuccess(w, http.StatusOK, "Transfer history retrieved successfully", transfers)
+}
+
+// AcceptTransfer handles the POST /transfers/{transfer_id}/accept endpoint
+func (h *TransferHandler) AcceptTransfer(w http.ResponseWriter, r *http.Request) {
+	userID, ok := sessionUserID(w, r)
+	if !ok {
+		return
+	}
+	transferID, ok := pathUUID(w, r, "transfer_id")
+	if !ok {
+		return
+	}
+
+	// The body is optional: only transfers to a phone number need a claim token
+	var req dto.AcceptTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.Error().Err(err).Msg("Failed to decode accept transfer request")
+		WriteErrorResponse(w, r, http.StatusBadRequest, dto.NewErrorResponse(dto.ErrorCodeInvalidRequest, "Invalid request body"))
+		return
+	}
+
+	transfer, err := h.transferService.AcceptTransfer(r.Context(), userID, transferID, &req)
+	if err != nil {
+		h.logger.Error().Err(err).Str("transfer_id", transferID.String()).Msg("Failed to accept transfer")
+		WriteError(w, r, err)
+		return
+	}
+
+	h.logger.Info().
+		Str("transfer_id", transferID.String()).
+		Str("purchase_id", transfer.PurchaseID.String()).
+		Int64("recipient_id", userID).
+		Msg("Transfer accepted successfully")
+
+	writeSuccess(w, http.StatusOK, "Transfer accepted successfully", transfer)
+}
+
+// CancelTransfer handles the POST /transfers/{transfer_id}/cancel endpoint
+func (h *TransferHandler) CancelTransfer(w http.ResponseWriter, r *http.Request) {
+	userID, ok := sessionUserID(w, r)
+	if !ok {
+		return
+	}
+	transferID, ok := pathUUID(w, r, "transfer_id")
+	if !ok {
+		return
+	}
+
+	if err := h.transferService.CancelTransfer(r.Context(), userID, transferID); err != nil {
+		h.logger.Error().Err(err).Str("transfer_id", transferID.String()).Msg("Failed to cancel transfer")
+		WriteError(w, r, err)
+		return
+	}
+
+	h.logger.Info().Str("transfer_id", transferID.String()).Msg("Transfer cancelled successfully")
+
+	writeSuccess(w, http.StatusOK, "Transfer cancelled successfully", nil)
+}
@@ -6,8 +6,8 @@ import (
 	"strconv"
 
 	"4SaleBackendSkeleton/internal/application/dto"
+	"4SaleBackendSkeleton/internal/infrastructure/i18n"
 	"4SaleBackendSkeleton/internal/ports"
-	"github.com/gorilla/mux"
 	"github.com/rs/zerolog"
 )
 
@@ -32,14 +32,14 @@ func (h *VoucherHandler) CreateVoucherWebhook(w http.ResponseWriter, r *http.Req
 	var req dto.CreateVoucherRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.Error().Err(err).Msg("Failed to decode create voucher request")
-		h.writeErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, dto.ErrorCodeInvalidRequest, "Invalid request body")
 		return
 	}
 
 	voucher, err := h.voucherService.CreateVoucher(ctx, &req)
 	if err != nil {
 		h.logger.Error().Err(err).Msg("Failed to create voucher")
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		WriteError(w, r, err)
 		return
 	}
 
@@ -59,14 +59,14 @@ func (h *VoucherHandler) PurchaseVoucherWebhook(w http.ResponseWriter, r *http.R
 	var req dto.PurchaseVoucherRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.Error().Err(err).Msg("Failed to decode purchase voucher request")
-		h.writeErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, dto.ErrorCodeInvalidRequest, "Invalid request body")
 		return
 	}
 
 	purchase, err := h.voucherService.PurchaseVoucher(ctx, &req)
 	if err != nil {
 		h.logger.Error().Err(err).Msg("Failed to purchase voucher")
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		WriteError(w, r, err)
 		return
 	}
 
@@ -79,6 +79,84 @@ func (h *VoucherHandler) PurchaseVoucherWebhook(w http.ResponseWriter, r *http.R
 	h.writeSuccessResponse(w, "Voucher purchased successfully", purchase)
 }
 
+// ReserveVoucherWebhook handles the POST /webhook/voucher-reserved endpoint
+func (h *VoucherHandler) ReserveVoucherWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req dto.ReserveVoucherRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to decode reserve voucher request")
+		h.writeErrorResponse(w, r, http.StatusBadRequest, dto.ErrorCodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	reservation, err := h.voucherService.ReserveVoucher(ctx, &req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to reserve voucher")
+		WriteError(w, r, err)
+		return
+	}
+
+	h.logger.Info().
+		Str("reservation_id", reservation.ID.String()).
+		Str("voucher_id", reservation.VoucherID.String()).
+		Int64("buyer_id", reservation.BuyerID).
+		Time("expires_at", reservation.ExpiresAt).
+		Msg("Voucher reserved successfully")
+
+	h.writeSuccessResponse(w, "Voucher reserved successfully", reservation)
+}
+
+// ConfirmReservationWebhook handles the POST /webhook/reservation-confirmed endpoint
+func (h *VoucherHandler) ConfirmReservationWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req dto.ConfirmReservationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to decode confirm reservation request")
+		h.writeErrorResponse(w, r, http.StatusBadRequest, dto.ErrorCodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	purchase, err := h.voucherService.ConfirmReservation(ctx, &req)
+	if err != nil {
+		h.logger.Error().Err(err).Str("reservation_id", req.ReservationID.String()).Msg("Failed to confirm reservation")
+		WriteError(w, r, err)
+		return
+	}
+
+	h.logger.Info().
+		Str("reservation_id", req.ReservationID.String()).
+		Str("purchase_id", purchase.ID.String()).
+		Str("voucher_id", purchase.VoucherID.String()).
+		Int64("buyer_id", purchase.BuyerID).
+		Msg("Reservation confirmed successfully")
+
+	h.writeSuccessResponse(w, "Voucher purchased successfully", purchase)
+}
+
+// ReleaseReservationWebhook handles the POST /webhook/reservation-released endpoint
+func (h *VoucherHandler) ReleaseReservationWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req dto.ReleaseReservationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to decode release reservation request")
+		h.writeErrorResponse(w, r, http.StatusBadRequest, dto.ErrorCodeInvalidRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.voucherService.ReleaseReservation(ctx, &req); err != nil {
+		h.logger.Error().Err(err).Str("reservation_id", req.ReservationID.String()).Msg("Failed to release reservation")
+		WriteError(w, r, err)
+		return
+	}
+
+	h.logger.Info().Str("reservation_id", req.ReservationID.String()).Msg("Reservation released successfully")
+
+	h.writeSuccessResponse(w, "Reservation released successfully", nil)
+}
+
 // RedeemVoucherWebhook handles the POST /webhook/voucher-redeemed endpoint
 func (h *VoucherHandler) RedeemVoucherWebhook(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -86,13 +164,13 @@ func (h *VoucherHandler) RedeemVoucherWebhook(w http.ResponseWriter, r *http.Req
 	var req dto.RedeemVoucherRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.logger.Error().Err(err).Msg("Failed to decode redeem voucher request")
-		h.writeErrorResponse(w, "Invalid request body", http.StatusBadRequest)
+		h.writeErrorResponse(w, r, http.StatusBadRequest, dto.ErrorCodeInvalidRequest, "Invalid request body")
 		return
 	}
 
 	if err := h.voucherService.RedeemVoucher(ctx, &req); err != nil {
 		h.logger.Error().Err(err).Msg("Failed to redeem voucher")
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		WriteError(w, r, err)
 		return
 	}
 
@@ -104,61 +182,124 @@ func (h *VoucherHandler) RedeemVoucherWebhook(w http.ResponseWriter, r *http.Req
 	h.writeSuccessResponse(w, "Voucher redeemed successfully", nil)
 }
 
-// GetUserVouchers handles the GET /vouchers/:user_id endpoint
-func (h *VoucherHandler) GetUserVouchers(w http.ResponseWriter, r *http.Request) {
+// UpdateVoucherWebhook handles the POST /webhook/voucher-updated endpoint
+func (h *VoucherHandler) UpdateVoucherWebhook(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	vars := mux.Vars(r)
-	userIDStr, exists := vars["user_id"]
-	if !exists {
-		h.writeErrorResponse(w, "user_id is required", http.StatusBadRequest)
+	var req dto.UpdateListingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to decode update listing request")
+		h.writeErrorResponse(w, r, http.StatusBadRequest, dto.ErrorCodeInvalidRequest, "Invalid request body")
 		return
 	}
 
-	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	voucher, err := h.voucherService.UpdateVoucherFromListing(ctx, &req)
 	if err != nil {
-		h.writeErrorResponse(w, "Invalid user_id", http.StatusBadRequest)
+		h.logger.Error().Err(err).Int64("adv_id", req.AdvID).Msg("Failed to update voucher from listing")
+		WriteError(w, r, err)
+		return
+	}
+
+	h.logger.Info().
+		Str("voucher_id", voucher.ID.String()).
+		Int64("adv_id", voucher.AdvID).
+		Int("version", voucher.Version).
+		Msg("Voucher updated from listing successfully")
+
+	h.writeSuccessResponse(w, "Voucher updated successfully", voucher)
+}
+
+// DeleteVoucherWebhook handles the POST /webhook/voucher-deleted endpoint
+func (h *VoucherHandler) DeleteVoucherWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req dto.DeleteListingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to decode delete listing request")
+		h.writeErrorResponse(w, r, http.StatusBadRequest, dto.ErrorCodeInvalidRequest, "Invalid request body")
 		return
 	}
 
-	vouchers, err := h.voucherService.GetUserVouchers(ctx, userID)
+	withdrawal, err := h.voucherService.DeleteVoucherFromListing(ctx, &req)
 	if err != nil {
-		h.logger.Error().Err(err).Int64("user_id", userID).Msg("Failed to get user vouchers")
-		h.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		h.logger.Error().Err(err).Int64("adv_id", req.AdvID).Msg("Failed to delete voucher from listing")
+		WriteError(w, r, err)
 		return
 	}
 
 	h.logger.Info().
-		Int64("user_id", userID).
-		Int("voucher_count", len(vouchers)).
-		Msg("Retrieved user vouchers successfully")
+		Str("voucher_id", withdrawal.VoucherID.String()).
+		Int64("adv_id", req.AdvID).
+		Bool("purchase_refunded", withdrawal.PurchaseRefunded).
+		Bool("already_withdrawn", withdrawal.AlreadyWithdrawn).
+		Msg("Voucher withdrawn from sale successfully")
 
-	h.writeSuccessResponse(w, "User vouchers retrieved successfully", vouchers)
+	h.writeSuccessResponse(w, "Voucher deleted successfully", withdrawal)
 }
 
-// writeErrorResponse writes an error response
-func (h *VoucherHandler) writeErrorResponse(w http.ResponseWriter, message string, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
+// GetUserVouchers handles the GET /vouchers/:user_id endpoint; users may only list their own vouchers
+func (h *VoucherHandler) GetUserVouchers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
 
-	response := dto.ErrorResponse{
-		Error:   "error",
-		Message: message,
-		Code:    statusCode,
+	sessionUser, ok := sessionUserID(w, r)
+	if !ok {
+		return
+	}
+	userID, ok := pathInt64(w, r, "user_id")
+	if !ok {
+		return
+	}
+	if userID != sessionUser {
+		h.writeErrorResponse(w, r, http.StatusForbidden, dto.ErrorCodeForbidden, "Access to these vouchers is not allowed")
+		return
 	}
 
-	json.NewEncoder(w).Encode(response)
+	query := r.URL.Query()
+	req := dto.UserVoucherListRequest{
+		UserID:   userID,
+		Status:   query.Get("status"),
+		From:     query.Get("from"),
+		To:       query.Get("to"),
+		Tag:      query.Get("tag"),
+		Sort:     query.Get("sort"),
+		Cursor:   query.Get("cursor"),
+		Language: i18n.FromRequest(r),
+	}
+	if req.Category, ok = queryInt64(w, r, "category"); !ok {
+		return
+	}
+	if limit := query.Get("limit"); limit != "" {
+		var err error
+		req.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			h.writeErrorResponse(w, r, http.StatusBadRequest, dto.ErrorCodeInvalidRequest, "Invalid limit")
+			return
+		}
+	}
+
+	page, err := h.voucherService.GetUserVouchers(ctx, &req)
+	if err != nil {
+		h.logger.Error().Err(err).Int64("user_id", userID).Msg("Failed to get user vouchers")
+		WriteError(w, r, err)
+		return
+	}
+
+	h.logger.Info().
+		Int64("user_id", userID).
+		Int("voucher_count", len(page.Vouchers)).
+		Bool("has_more", page.Meta.NextCursor != nil).
+		Msg("Retrieved user vouchers successfully")
+
+	setContentLanguage(w, req.Language)
+	writeSuccessWithMeta(w, http.StatusOK, "User vouchers retrieved successfully", page.Vouchers, page.Meta)
+}
+
+// writeErrorResponse writes an error response with an explicit code
+func (h *VoucherHandler) writeErrorResponse(w http.ResponseWriter, r *http.Request, statusCode int, code dto.ErrorCode, message string) {
+	WriteErrorResponse(w, r, statusCode, dto.NewErrorResponse(code, message))
 }
 
 // writeSuccessResponse writes a success response
 func (h *VoucherHandler) writeSuccessResponse(w http.ResponseWriter, message string, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
-	response := dto.SuccessResponse{
-		Message: message,
-		Data:    data,
-	}
-
-	json.NewEncoder(w).Encode(response)
+	writeSuccess(w, http.StatusOK, message, data)
 }
\ No newline at end of file
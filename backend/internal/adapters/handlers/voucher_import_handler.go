@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"4SaleBackendSkeleton/internal/application/dto"
+	"4SaleBackendSkeleton/internal/ports"
+	"github.com/rs/zerolog"
+)
+
+// maxImportBytes bounds the size of an uploaded voucher import file
+const maxImportBytes = 10 << 20
+
+// VoucherImportHandler handles bulk voucher import HTTP requests
+type VoucherImportHandler struct {
+	importService ports.VoucherImportService
+	logger        zerolog.Logger
+}
+
+// NewVoucherImportHandler creates a new voucher import handler
+func NewVoucherImportHandler(importService ports.VoucherImportService, logger zerolog.Logger) *VoucherImportHandler {
+	return &VoucherImportHandler{
+		importService: importService,
+		logger:        logger,
+	}
+}
+
+// ImportVouchers handles the POST /merchant/vouchers/import endpoint.
+// The body is a CSV or JSON file of vouchers owned by the session merchant; the format is
+// taken from the format query parameter or the Content-Type, and dry_run=true only validates.
+func (h *VoucherImportHandler) ImportVouchers(w http.ResponseWriter, r *http.Request) {
+	merchantID, ok := sessionUserID(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	dryRun := false
+	if value := query.Get("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			WriteErrorResponse(w, r, http.StatusBadRequest, dto.NewErrorResponse(dto.ErrorCodeInvalidRequest, "Invalid dry_run"))
+			return
+		}
+	}
+
+	format := query.Get("format")
+	if format == "" {
+		format = importFormat(r.Header.Get("Content-Type"))
+	}
+
+	req := dto.ImportVouchersRequest{
+		Format:     format,
+		DryRun:     dryRun,
+		MerchantID: &merchantID,
+	}
+
+	report, err := h.importService.ImportVouchers(r.Context(), &req, http.MaxBytesReader(w, r.Body, maxImportBytes))
+	if err != nil {
+		h.logger.Error().Err(err).Int64("merchant_id", merchantID).Msg("Failed to import vouchers")
+		WriteError(w, r, err)
+		return
+	}
+
+	h.logger.Info().
+		Int64("merchant_id", merchantID).
+		Bool("dry_run", report.DryRun).
+		Int("total", report.Total).
+		Int("imported", report.Imported).
+		Int("failed", report.Failed).
+		Msg("Voucher import processed")
+
+	message := "Vouchers imported successfully"
+	if report.DryRun {
+		message = "Voucher import validated successfully"
+	}
+	writeSuccess(w, http.StatusOK, message, report)
+}
+
+// importFormat returns the import format of a Content-Type, or "" if it names none
+func importFormat(contentType string) string {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	switch mediaType {
+	case "text/csv":
+		return "csv"
+	case "application/json":
+		return "json"
+	default:
+		return ""
+	}
+}
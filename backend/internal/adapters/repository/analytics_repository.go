@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"4SaleBackendSkeleton/internal/domain"
+	"4SaleBackendSkeleton/internal/infrastructure/database"
+)
+
+// AnalyticsRepository implements the merchant analytics repository interface
+type AnalyticsRepository struct {
+	db *database.PostgresDB
+}
+
+// NewAnalyticsRepository creates a new analytics repository
+func NewAnalyticsRepository(db *database.PostgresDB) *AnalyticsRepository {
+	return &AnalyticsRepository{db: db}
+}
+
+// dailySalesAggregates are the per day and currency aggregates of voucher_purchases vp,
+// shared by the live query and the rollup refresh so both count the same way
+const dailySalesAggregates = `
+	COUNT(*),
+	SUM(vp.status = 'refunded'),
+	SUM(vp.status = 'redeemed'),
+	COALESCE(SUM(CASE WHEN vp.status = 'redeemed' THEN TIMESTAMPDIFF(SECOND, vp.created_at, vp.redeemed_at) END), 0),
+	COALESCE(SUM(CASE WHEN vp.status <> 'refunded' THEN vp.price END), 0)`
+
+// GetDailySales aggregates the purchases of a merchant's vouchers made in [from, to) per day and currency
+func (r *AnalyticsRepository) GetDailySales(ctx context.Context, merchantID int64, from, to time.Time) ([]*domain.DailySales, error) {
+	query := `
+		SELECT DATE(vp.created_at) AS day, vp.currency,` + dailySalesAggregates + `
+		FROM voucher_purchases vp
+		JOIN vouchers v ON vp.voucher_id = v.id
+		WHERE v.user_id = ? AND vp.created_at >= ? AND vp.created_at < ?
+		GROUP BY day, vp.currency
+		ORDER BY day, vp.currency`
+
+	return r.queryDailySales(ctx, query, merchantID, from, to)
+}
+
+// GetRollupDailySales reads the daily sales of a merchant in [from, to) from the rollup table
+func (r *AnalyticsRepository) GetRollupDailySales(ctx context.Context, merchantID int64, from, to time.Time) ([]*domain.DailySales, error) {
+	query := `
+		SELECT day, currency, sold_count, refunded_count, redeemed_count, redeem_seconds, revenue
+		FROM merchant_daily_sales
+		WHERE merchant_id = ? AND day >= ? AND day < ?
+		ORDER BY day, currency`
+
+	return r.queryDailySales(ctx, query, merchantID, from, to)
+}
+
+// queryDailySales runs a query selecting day, currency and the daily sales aggregates
+func (r *AnalyticsRepository) queryDailySales(ctx context.Context, query string, args ...interface{}) ([]*domain.DailySales, error) {
+	rows, err := r.db.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily sales: %w", err)
+	}
+	defer rows.Close()
+
+	sales := make([]*domain.DailySales, 0)
+	for rows.Next() {
+		var s domain.DailySales
+		var revenue moneyColumns
+		err := rows.Scan(
+			&s.Day,
+			&revenue.currency,
+			&s.Sold,
+			&s.Refunded,
+			&s.Redeemed,
+			&s.RedeemSeconds,
+			&revenue.amount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan daily sales: %w", err)
+		}
+		if s.Revenue, err = revenue.money(); err != nil {
+			return nil, err
+		}
+		s.Currency = s.Revenue.Currency
+		sales = append(sales, &s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating daily sales: %w", err)
+	}
+
+	return sales, nil
+}
+
+// GetTopVouchers retrieves a merchant's best selling vouchers bought in [from, to),
+// ranked by sales and then by revenue in the reporting currency
+func (r *AnalyticsRepository) GetTopVouchers(ctx context.Context, merchantID int64, from, to time.Time, limit int) ([]*domain.TopVoucher, error) {
+	query := `
+		SELECT v.id, v.title, vp.currency,
+			COUNT(*) AS sold,
+			SUM(vp.status = 'redeemed'),
+			COALESCE(SUM(CASE WHEN vp.status <> 'refunded' THEN vp.price END), 0),
+			COALESCE(SUM(CASE WHEN vp.status <> 'refunded' THEN vp.reporting_price END), 0) AS reporting_revenue
+		FROM voucher_purchases vp
+		JOIN vouchers v ON vp.voucher_id = v.id
+		WHERE v.user_id = ? AND vp.created_at >= ? AND vp.created_at < ?
+		GROUP BY v.id, v.title, vp.currency
+		ORDER BY sold DESC, reporting_revenue DESC, v.id
+		LIMIT ?`
+
+	rows, err := r.db.DB.QueryContext(ctx, query, merchantID, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top vouchers: %w", err)
+	}
+	defer rows.Close()
+
+	vouchers := make([]*domain.TopVoucher, 0, limit)
+	for rows.Next() {
+		var voucher domain.TopVoucher
+		var revenue moneyColumns
+		var reportingRevenue string
+		err := rows.Scan(
+			&voucher.VoucherID,
+			&voucher.Title,
+			&revenue.currency,
+			&voucher.Sold,
+			&voucher.Redeemed,
+			&revenue.amount,
+			&reportingRevenue,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan top voucher: %w", err)
+		}
+		if voucher.Revenue, err = revenue.money(); err != nil {
+			return nil, err
+		}
+		vouchers = append(vouchers, &voucher)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating top vouchers: %w", err)
+	}
+
+	return vouchers, nil
+}
+
+// RefreshDailySales recomputes the rollup rows of every merchant and purchase day with a
+// purchase changed since the given time, and returns the database time the refresh started
+// at to pass as since on the next refresh. The zero time rebuilds the whole rollup.
+func (r *AnalyticsRepository) RefreshDailySales(ctx context.Context, since time.Time) (time.Time, error) {
+	var startedAt time.Time
+	if err := r.db.DB.QueryRowContext(ctx, `SELECT NOW()`).Scan(&startedAt); err != nil {
+		return time.Time{}, fmt.Errorf("failed to read database time: %w", err)
+	}
+
+	query := `
+		INSERT INTO merchant_daily_sales
+			(merchant_id, day, currency, sold_count, refunded_count, redeemed_count, redeem_seconds, revenue, refreshed_at)
+		SELECT v.user_id, DATE(vp.created_at), vp.currency,` + dailySalesAggregates + `, ?
+		FROM voucher_purchases vp
+		JOIN vouchers v ON vp.voucher_id = v.id
+		JOIN (
+			SELECT DISTINCT cv.user_id AS merchant_id, DATE(cp.created_at) AS day
+			FROM voucher_purchases cp
+			JOIN vouchers cv ON cp.voucher_id = cv.id
+			WHERE cp.updated_at >= ?
+		) changed ON changed.merchant_id = v.user_id AND changed.day = DATE(vp.created_at)
+		GROUP BY v.user_id, DATE(vp.created_at), vp.currency
+		ON DUPLICATE KEY UPDATE
+			sold_count = VALUES(sold_count),
+			refunded_count = VALUES(refunded_count),
+			redeemed_count = VALUES(redeemed_count),
+			redeem_seconds = VALUES(redeem_seconds),
+			revenue = VALUES(revenue),
+			refreshed_at = VALUES(refreshed_at)`
+
+	if _, err := r.db.DB.ExecContext(ctx, query, startedAt, since); err != nil {
+		return time.Time{}, fmt.Errorf("failed to refresh daily sales: %w", err)
+	}
+
+	return startedAt, nil
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"4SaleBackendSkeleton/internal/domain"
+	"4SaleBackendSkeleton/internal/infrastructure/database"
+)
+
+// AuditRepository implements the audit log repository interface
+type AuditRepository struct {
+	db *database.PostgresDB
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *database.PostgresDB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// AppendEvent chains event after the last event and stores it. The chain head row is
+// locked for the transaction so concurrent appends are chained one after the other.
+func (r *AuditRepository) AppendEvent(ctx context.Context, event *domain.AuditEvent) error {
+	tx, err := r.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var lastHash string
+	err = tx.QueryRowContext(ctx, `SELECT last_hash FROM audit_chain WHERE id = 1 FOR UPDATE`).Scan(&lastHash)
+	if err != nil {
+		return fmt.Errorf("failed to lock audit chain: %w", err)
+	}
+	event.Chain(lastHash)
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO audit_events (actor_type, actor_id, action, target_type, target_id, before_state, after_state,
+			request_id, ip, created_at, prev_hash, hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		event.ActorType,
+		event.ActorID,
+		event.Action,
+		event.TargetType,
+		event.TargetID,
+		nullableJSON(event.Before),
+		nullableJSON(event.After),
+		event.RequestID,
+		event.IP,
+		event.CreatedAt,
+		event.PrevHash,
+		event.Hash,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create audit event: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE audit_chain SET last_hash = ? WHERE id = 1`, event.Hash)
+	if err != nil {
+		return fmt.Errorf("failed to advance audit chain: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit audit event: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get audit event ID: %w", err)
+	}
+	event.ID = id
+
+	return nil
+}
+
+// ListEvents returns the events matching filter, newest first
+func (r *AuditRepository) ListEvents(ctx context.Context, filter *domain.AuditFilter) ([]*domain.AuditEvent, error) {
+	conditions := []string{"1 = 1"}
+	var args []interface{}
+	if filter.ActorID != nil {
+		conditions = append(conditions, "actor_id = ?")
+		args = append(args, *filter.ActorID)
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, filter.Action)
+	}
+	if filter.TargetType != "" {
+		conditions = append(conditions, "target_type = ?")
+		args = append(args, filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		conditions = append(conditions, "target_id = ?")
+		args = append(args, filter.TargetID)
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, *filter.From)
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, *filter.To)
+	}
+	if filter.BeforeID != nil {
+		conditions = append(conditions, "id < ?")
+		args = append(args, *filter.BeforeID)
+	}
+	args = append(args, filter.Limit)
+
+	rows, err := r.db.DB.QueryContext(ctx, `
+		SELECT id, actor_type, actor_id, action, target_type, target_id, before_state, after_state,
+			request_id, ip, created_at, prev_hash, hash
+		FROM audit_events
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY id DESC
+		LIMIT ?`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []*domain.AuditEvent{}
+	for rows.Next() {
+		var event domain.AuditEvent
+		var actorID sql.NullInt64
+		var before, after sql.NullString
+		err := rows.Scan(
+			&event.ID,
+			&event.ActorType,
+			&actorID,
+			&event.Action,
+			&event.TargetType,
+			&event.TargetID,
+			&before,
+			&after,
+			&event.RequestID,
+			&event.IP,
+			&event.CreatedAt,
+			&event.PrevHash,
+			&event.Hash,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		if actorID.Valid {
+			event.ActorID = &actorID.Int64
+		}
+		if before.Valid {
+			event.Before = []byte(before.String)
+		}
+		if after.Valid {
+			event.After = []byte(after.String)
+		}
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+
+	return events, nil
+}
+
+// nullableJSON stores an absent state as NULL
+func nullableJSON(state []byte) sql.NullString {
+	if state == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(state), Valid: true}
+}
@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"4SaleBackendSkeleton/internal/domain"
+	"4SaleBackendSkeleton/internal/infrastructure/database"
+	"github.com/google/uuid"
+)
+
+// relevanceType is the SQL type relevance scores are rounded to for sorting and paging;
+// its scale matches domain.RelevanceScale
+const relevanceType = "DECIMAL(20,6)"
+
+// CatalogueRepository implements the public catalogue read model over the vouchers table
+type CatalogueRepository struct {
+	db *database.PostgresDB
+}
+
+// NewCatalogueRepository creates a new catalogue repository
+func NewCatalogueRepository(db *database.PostgresDB) *CatalogueRepository {
+	return &CatalogueRepository{db: db}
+}
+
+// SearchCatalogue retrieves one page of listed vouchers matching the query.
+// Only active, non-deleted vouchers are listed.
+func (r *CatalogueRepository) SearchCatalogue(ctx context.Context, q *domain.CatalogueQuery) (*domain.CataloguePage, error) {
+	// A voucher is sold once it has a purchase and can no longer be sold once expired
+	const (
+		unsold     = "NOT EXISTS (SELECT 1 FROM voucher_purchases vp WHERE vp.voucher_id = v.id)"
+		notExpired = "(v.expires_at IS NULL OR v.expires_at > ?)"
+		search     = "MATCH (v.title, v.title_ar, v.description, v.description_ar) AGAINST (? IN BOOLEAN MODE)"
+	)
+
+	var selectArgs, args []interface{}
+	joins := ""
+	sortKey := "v.created_at"
+
+	selectArgs = append(selectArgs, q.Now)
+	columns := "v.id, v.adv_id, v.user_id, v.title, v.description, v.title_ar, v.description_ar, v.price, v.currency, v.photo_url, v.category_id, v.expires_at, v.created_at, " +
+		"(" + unsold + " AND " + notExpired + ") AS available"
+
+	conditions := []string{"v.status = 'active'", "v.deleted_at IS NULL"}
+
+	if q.Search != "" {
+		conditions = append(conditions, search)
+		args = append(args, q.Search)
+	}
+	if q.Currency != "" {
+		conditions = append(conditions, "v.currency = ?")
+		args = append(args, q.Currency)
+	}
+	if q.MinPrice != nil {
+		conditions = append(conditions, "v.price >= CAST(? AS "+amountType+")")
+		args = append(args, q.MinPrice.Decimal())
+	}
+	if q.MaxPrice != nil {
+		conditions = append(conditions, "v.price <= CAST(? AS "+amountType+")")
+		args = append(args, q.MaxPrice.Decimal())
+	}
+	if q.MerchantID != nil {
+		conditions = append(conditions, "v.user_id = ?")
+		args = append(args, *q.MerchantID)
+	}
+	if len(q.CategoryIDs) > 0 {
+		placeholders, categoryArgs := int64Placeholders(q.CategoryIDs)
+		conditions = append(conditions, "v.category_id IN ("+placeholders+")")
+		args = append(args, categoryArgs...)
+	}
+	if q.Tag != "" {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM voucher_tags vt WHERE vt.voucher_id = v.id AND vt.tag = ?)")
+		args = append(args, q.Tag)
+	}
+
+	switch q.Availability {
+	case domain.AvailabilityAvailable:
+		conditions = append(conditions, unsold, notExpired)
+		args = append(args, q.Now)
+	case domain.AvailabilitySoldOut:
+		conditions = append(conditions, "NOT ("+unsold+" AND "+notExpired+")")
+		args = append(args, q.Now)
+	}
+
+	direction, comparison := "DESC", "<"
+	switch q.Sort {
+	case domain.SortRelevance:
+		// Scores are rounded to a fixed decimal so the cursor key compares exactly
+		sortKey = "CAST(" + search + " AS " + relevanceType + ")"
+		selectArgs = append(selectArgs, q.Search)
+	case domain.SortPriceAsc:
+		sortKey, direction, comparison = "v.price", "ASC", ">"
+	case domain.SortPriceDesc:
+		sortKey = "v.price"
+	case domain.SortPopularity:
+		// Popularity is the number of vouchers the merchant has sold, since each voucher sells once
+		joins = `
+		LEFT JOIN (
+			SELECT sv.user_id, COUNT(*) AS sales
+			FROM voucher_purchases sp
+			JOIN vouchers sv ON sv.id = sp.voucher_id
+			GROUP BY sv.user_id
+		) ms ON ms.user_id = v.user_id`
+		sortKey = "COALESCE(ms.sales, 0)"
+	}
+	// Numeric sort keys are selected to build the next cursor; newest uses created_at itself
+	orderBy := "sort_key"
+	if q.Sort == domain.SortNewest {
+		columns += ", 0 AS sort_key"
+		orderBy = sortKey
+	} else {
+		columns += ", " + sortKey + " AS sort_key"
+	}
+
+	if q.After != nil {
+		key, err := r.cursorKey(q)
+		if err != nil {
+			return nil, err
+		}
+
+		keyArgs := []interface{}{key, key, q.After.ID}
+		placeholder := "?"
+		switch q.Sort {
+		case domain.SortRelevance:
+			// The relevance expression takes the search string each time it appears
+			keyArgs = []interface{}{q.Search, key, q.Search, key, q.After.ID}
+			placeholder = "CAST(? AS " + relevanceType + ")"
+		case domain.SortPriceAsc, domain.SortPriceDesc:
+			placeholder = "CAST(? AS " + amountType + ")"
+		}
+		conditions = append(conditions, fmt.Sprintf("(%[1]s %[2]s %[3]s OR (%[1]s = %[3]s AND v.id %[2]s ?))", sortKey, comparison, placeholder))
+		args = append(args, keyArgs...)
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	args = append(args, q.Limit+1)
+
+	query := `
+		SELECT ` + columns + `
+		FROM vouchers v` + joins + `
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY ` + orderBy + ` ` + direction + `, v.id ` + direction + `
+		LIMIT ?`
+
+	rows, err := r.db.DB.QueryContext(ctx, query, append(selectArgs, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query catalogue: %w", err)
+	}
+	defer rows.Close()
+
+	vouchers := make([]*domain.CatalogueVoucher, 0, q.Limit)
+	var keys []string
+	for rows.Next() {
+		var voucher domain.CatalogueVoucher
+		var price moneyColumns
+		var key string
+		err := rows.Scan(
+			&voucher.ID,
+			&voucher.AdvID,
+			&voucher.MerchantID,
+			&voucher.Title,
+			&voucher.Description,
+			&voucher.TitleAR,
+			&voucher.DescriptionAR,
+			&price.amount,
+			&price.currency,
+			&voucher.PhotoURL,
+			&voucher.CategoryID,
+			&voucher.ExpiresAt,
+			&voucher.CreatedAt,
+			&voucher.Available,
+			&key,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan catalogue voucher: %w", err)
+		}
+		if voucher.Price, err = price.money(); err != nil {
+			return nil, err
+		}
+		vouchers = append(vouchers, &voucher)
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating catalogue: %w", err)
+	}
+
+	page := &domain.CataloguePage{Vouchers: vouchers}
+	if len(vouchers) > q.Limit {
+		page.Vouchers = vouchers[:q.Limit]
+	}
+
+	voucherIDs := make([]uuid.UUID, len(page.Vouchers))
+	for i, voucher := range page.Vouchers {
+		voucherIDs[i] = voucher.ID
+	}
+	tags, err := loadVoucherTags(ctx, r.db.DB, voucherIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, voucher := range page.Vouchers {
+		voucher.Tags = tags[voucher.ID]
+	}
+
+	if len(vouchers) > q.Limit {
+		last := page.Vouchers[q.Limit-1]
+		switch q.Sort {
+		case domain.SortNewest:
+			page.Next = domain.NewTimeCursor(q.Sort, last.CreatedAt, last.ID)
+		case domain.SortPriceAsc, domain.SortPriceDesc:
+			page.Next = domain.NewAmountCursor(q.Sort, last.Price, last.ID)
+		case domain.SortPopularity:
+			sales, err := strconv.ParseInt(keys[q.Limit-1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse sales count %q: %w", keys[q.Limit-1], err)
+			}
+			page.Next = domain.NewCountCursor(q.Sort, sales, last.ID)
+		case domain.SortRelevance:
+			page.Next = domain.NewDecimalCursor(q.Sort, keys[q.Limit-1], last.ID)
+		}
+	}
+
+	return page, nil
+}
+
+// cursorKey returns the sort key of the query's cursor in the form the sort expression compares against
+func (r *CatalogueRepository) cursorKey(q *domain.CatalogueQuery) (interface{}, error) {
+	switch q.Sort {
+	case domain.SortNewest:
+		return q.After.TimeKey()
+	case domain.SortPriceAsc, domain.SortPriceDesc:
+		return q.After.AmountKey()
+	case domain.SortPopularity:
+		return q.After.CountKey()
+	case domain.SortRelevance:
+		return q.After.DecimalKey(domain.RelevanceScale)
+	default:
+		return nil, domain.ErrInvalidCursor
+	}
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"4SaleBackendSkeleton/internal/domain"
+	"4SaleBackendSkeleton/internal/infrastructure/database"
+)
+
+// CategoryRepository implements the category repository interface
+type CategoryRepository struct {
+	db *database.PostgresDB
+}
+
+// NewCategoryRepository creates a new category repository
+func NewCategoryRepository(db *database.PostgresDB) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+// GetCategories retrieves every category as a flat list ordered for display
+func (r *CategoryRepository) GetCategories(ctx context.Context) ([]*domain.Category, error) {
+	query := `
+		SELECT id, parent_id, slug, name_en, name_ar, sort_order
+		FROM categories
+		ORDER BY sort_order, name_en`
+
+	rows, err := r.db.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query categories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := make([]*domain.Category, 0)
+	for rows.Next() {
+		var category domain.Category
+		err := rows.Scan(
+			&category.ID,
+			&category.ParentID,
+			&category.Slug,
+			&category.NameEN,
+			&category.NameAR,
+			&category.SortOrder,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan category: %w", err)
+		}
+		categories = append(categories, &category)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating categories: %w", err)
+	}
+
+	return categories, nil
+}
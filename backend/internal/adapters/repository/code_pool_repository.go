@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"4SaleBackendSkeleton/internal/domain"
+	"4SaleBackendSkeleton/internal/infrastructure/database"
+	"github.com/google/uuid"
+)
+
+// codeInsertBatch is the number of codes inserted per statement
+const codeInsertBatch = 500
+
+// CodePoolRepository implements the voucher code pool repository interface
+type CodePoolRepository struct {
+	db *database.PostgresDB
+}
+
+// NewCodePoolRepository creates a new code pool repository
+func NewCodePoolRepository(db *database.PostgresDB) *CodePoolRepository {
+	return &CodePoolRepository{db: db}
+}
+
+// AddCodes adds codes to a voucher's pool in one transaction and returns how many were new;
+// codes already in the pool are skipped. When any code is new, change is recorded in the
+// voucher's history with the number added as its new value.
+func (r *CodePoolRepository) AddCodes(ctx context.Context, voucherID uuid.UUID, codes []string, at time.Time, change *domain.VoucherChange) (int, error) {
+	tx, err := r.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	added := 0
+	for start := 0; start < len(codes); start += codeInsertBatch {
+		end := start + codeInsertBatch
+		if end > len(codes) {
+			end = len(codes)
+		}
+
+		placeholders := make([]string, 0, end-start)
+		args := make([]interface{}, 0, 3*(end-start))
+		for _, code := range codes[start:end] {
+			placeholders = append(placeholders, "(?, ?, ?)")
+			args = append(args, voucherID, code, at)
+		}
+
+		result, err := tx.ExecContext(ctx, `
+			INSERT IGNORE INTO voucher_codes (voucher_id, code, created_at)
+			VALUES `+strings.Join(placeholders, ", "), args...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to add voucher codes: %w", err)
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get added voucher codes: %w", err)
+		}
+		added += int(rows)
+	}
+
+	if added > 0 {
+		count := strconv.Itoa(added)
+		change.NewValue = &count
+		if err := recordVoucherChanges(ctx, tx, []*domain.VoucherChange{change}); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit voucher codes: %w", err)
+	}
+
+	return added, nil
+}
+
+// NextCode returns the oldest unassigned code of a voucher, which CreatePurchase claims.
+// It reports false when the voucher has no code pool, and ErrCodePoolEmpty when every code is taken.
+func (r *CodePoolRepository) NextCode(ctx context.Context, voucherID uuid.UUID) (string, bool, error) {
+	var code string
+	err := r.db.DB.QueryRowContext(ctx, `
+		SELECT code
+		FROM voucher_codes
+		WHERE voucher_id = ? AND purchase_id IS NULL
+		ORDER BY id
+		LIMIT 1`, voucherID).Scan(&code)
+	if err == nil {
+		return code, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return "", false, fmt.Errorf("failed to get next voucher code: %w", err)
+	}
+
+	total, _, err := r.GetPoolCounts(ctx, voucherID)
+	if err != nil {
+		return "", false, err
+	}
+	if total == 0 {
+		return "", false, nil
+	}
+	return "", true, domain.ErrCodePoolEmpty
+}
+
+// claimCode assigns a voucher's code to a purchase within tx. The voucher's next unassigned
+// code is locked and must be the code given, so the pool's stock bounds its sales and
+// concurrent purchases never share a code.
+func claimCode(ctx context.Context, tx *sql.Tx, voucherID uuid.UUID, code string, purchaseID uuid.UUID, at time.Time) error {
+	var next string
+	err := tx.QueryRowContext(ctx, `
+		SELECT code
+		FROM voucher_codes
+		WHERE voucher_id = ? AND purchase_id IS NULL
+		ORDER BY id
+		LIMIT 1
+		FOR UPDATE`, voucherID).Scan(&next)
+	if err == sql.ErrNoRows {
+		return domain.ErrCodePoolEmpty
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock next voucher code: %w", err)
+	}
+	if next != code {
+		return domain.ErrCodeTaken
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE voucher_codes
+		SET purchase_id = ?, assigned_at = ?
+		WHERE voucher_id = ? AND code = ? AND purchase_id IS NULL`, purchaseID, at, voucherID, code)
+	if err != nil {
+		return fmt.Errorf("failed to assign voucher code: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get assigned voucher code: %w", err)
+	}
+
+	if rows == 0 {
+		return domain.ErrCodeTaken
+	}
+
+	return nil
+}
+
+// GetAssignedCode returns the code assigned to a purchase, reporting false if it has none
+func (r *CodePoolRepository) GetAssignedCode(ctx context.Context, purchaseID uuid.UUID) (string, bool, error) {
+	var code string
+	err := r.db.DB.QueryRowContext(ctx, `SELECT code FROM voucher_codes WHERE purchase_id = ?`, purchaseID).Scan(&code)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get assigned voucher code: %w", err)
+	}
+
+	return code, true, nil
+}
+
+// GetPoolCounts returns the total number of codes in a voucher's pool and how many are unassigned
+func (r *CodePoolRepository) GetPoolCounts(ctx context.Context, voucherID uuid.UUID) (int64, int64, error) {
+	var total int64
+	var available sql.NullInt64
+	err := r.db.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*), SUM(purchase_id IS NULL)
+		FROM voucher_codes
+		WHERE voucher_id = ?`, voucherID).Scan(&total, &available)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count voucher codes: %w", err)
+	}
+
+	return total, available.Int64, nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"4SaleBackendSkeleton/internal/domain"
+	"4SaleBackendSkeleton/internal/infrastructure/database"
+)
+
+// ExportRepository implements the purchase export repository interface
+type ExportRepository struct {
+	db *database.PostgresDB
+}
+
+// NewExportRepository creates a new export repository
+func NewExportRepository(db *database.PostgresDB) *ExportRepository {
+	return &ExportRepository{db: db}
+}
+
+// exportRowScanner scans a purchase followed by the voucher columns of an export row
+type exportRowScanner struct {
+	rowScanner
+	extra []interface{}
+}
+
+// Scan scans the purchase columns into dest and the remaining columns into extra
+func (s exportRowScanner) Scan(dest ...interface{}) error {
+	return s.rowScanner.Scan(append(dest, s.extra...)...)
+}
+
+// StreamPurchases streams the purchases, or redemptions, matching filter ordered by their
+// purchase or redemption time; rows are scanned one at a time as the driver reads them
+func (r *ExportRepository) StreamPurchases(ctx context.Context, filter *domain.ExportFilter, fn func(*domain.PurchaseExportRow) error) error {
+	timeColumn := "vp.created_at"
+	conditions := []string{}
+	if filter.Kind == domain.ExportRedemptions {
+		timeColumn = "vp.redeemed_at"
+		conditions = append(conditions, "vp.status = 'redeemed'")
+	}
+	conditions = append(conditions, timeColumn+" >= ?", timeColumn+" < ?")
+	args := []interface{}{filter.From, filter.To}
+	if filter.MerchantID != nil {
+		conditions = append(conditions, "v.user_id = ?")
+		args = append(args, *filter.MerchantID)
+	}
+
+	query := `
+		SELECT vp.id, vp.voucher_id, vp.buyer_id, vp.qr_code, vp.status, vp.redeemed_at, vp.refunded_at,
+			vp.price, vp.currency, vp.reporting_price, vp.reporting_currency, vp.exchange_rate, vp.rate_as_of,
+			vp.created_at, vp.discount, vp.promo_code, v.title, v.user_id, v.adv_id
+		FROM voucher_purchases vp
+		JOIN vouchers v ON vp.voucher_id = v.id
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY ` + timeColumn + `, vp.id`
+
+	rows, err := r.db.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query export rows: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row domain.PurchaseExportRow
+		purchase, err := scanPurchase(exportRowScanner{
+			rowScanner: rows,
+			extra:      []interface{}{&row.VoucherTitle, &row.MerchantID, &row.AdvID},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to scan export row: %w", err)
+		}
+		row.Purchase = purchase
+
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating export rows: %w", err)
+	}
+
+	return nil
+}
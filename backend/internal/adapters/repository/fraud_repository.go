@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"4SaleBackendSkeleton/internal/domain"
+	"4SaleBackendSkeleton/internal/infrastructure/database"
+	"github.com/google/uuid"
+)
+
+// FraudRepository implements the fraud signal and decision repository interface
+type FraudRepository struct {
+	db *database.PostgresDB
+}
+
+// NewFraudRepository creates a new fraud repository
+func NewFraudRepository(db *database.PostgresDB) *FraudRepository {
+	return &FraudRepository{db: db}
+}
+
+// CountPurchasesSince returns the number of purchases a buyer made at or after since
+func (r *FraudRepository) CountPurchasesSince(ctx context.Context, buyerID int64, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM voucher_purchases WHERE buyer_id = ? AND created_at >= ?`,
+		buyerID, since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count recent purchases: %w", err)
+	}
+	return count, nil
+}
+
+// CountFailedLookupsSince returns the number of failed QR lookups from a device at or after since
+func (r *FraudRepository) CountFailedLookupsSince(ctx context.Context, deviceID string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM qr_lookup_failures WHERE device_id = ? AND created_at >= ?`,
+		deviceID, since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count failed lookups: %w", err)
+	}
+	return count, nil
+}
+
+// RecordFailedLookup records a redemption from a device for a voucher with no purchase
+func (r *FraudRepository) RecordFailedLookup(ctx context.Context, deviceID string, voucherID uuid.UUID, at time.Time) error {
+	_, err := r.db.DB.ExecContext(ctx, `
+		INSERT INTO qr_lookup_failures (device_id, voucher_id, created_at)
+		VALUES (?, ?, ?)`,
+		deviceID, voucherID, at)
+	if err != nil {
+		return fmt.Errorf("failed to record failed lookup: %w", err)
+	}
+	return nil
+}
+
+// CreateDecision records a fraud decision for review
+func (r *FraudRepository) CreateDecision(ctx context.Context, decision *domain.FraudDecision) error {
+	_, err := r.db.DB.ExecContext(ctx, `
+		INSERT INTO fraud_decisions (id, operation, action, rules, buyer_id, merchant_id, voucher_id,
+			purchase_id, device_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		decision.ID,
+		decision.Operation,
+		decision.Action,
+		strings.Join(decision.Rules, ","),
+		decision.BuyerID,
+		decision.MerchantID,
+		decision.VoucherID,
+		decision.PurchaseID,
+		decision.DeviceID,
+		decision.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create fraud decision: %w", err)
+	}
+	return nil
+}
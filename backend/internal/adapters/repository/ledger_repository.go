@@ -0,0 +1,455 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"4SaleBackendSkeleton/internal/domain"
+	"4SaleBackendSkeleton/internal/infrastructure/database"
+	"github.com/google/uuid"
+)
+
+// LedgerRepository implements the settlement ledger repository interface
+type LedgerRepository struct {
+	db *database.PostgresDB
+}
+
+// NewLedgerRepository creates a new ledger repository
+func NewLedgerRepository(db *database.PostgresDB) *LedgerRepository {
+	return &LedgerRepository{db: db}
+}
+
+// PostTransaction stores a ledger transaction on its own. A transaction reversing
+// another is rejected if that one has already been reversed, and a correcting
+// reversal is rejected unless the purchase has been refunded.
+func (r *LedgerRepository) PostTransaction(ctx context.Context, t *domain.LedgerTransaction) error {
+	tx, err := r.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if t.Type == domain.LedgerReversal {
+		// A correction may only reverse postings of a purchase that is no longer active,
+		// so the ledger never shows money returned on a voucher that can still be redeemed
+		var status string
+		err := tx.QueryRowContext(ctx, `SELECT status FROM voucher_purchases WHERE id = ? FOR UPDATE`, t.PurchaseID).Scan(&status)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return domain.ErrPurchaseNotFound
+			}
+			return fmt.Errorf("failed to lock purchase: %w", err)
+		}
+		if status != domain.StatusRefunded {
+			return domain.ErrPurchaseNotRefunded
+		}
+	}
+
+	if err := insertLedgerTransaction(ctx, tx, t); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit ledger transaction: %w", err)
+	}
+
+	return nil
+}
+
+// insertLedgerTransaction checks that t balances and stores it with its entries within tx
+func insertLedgerTransaction(ctx context.Context, tx *sql.Tx, t *domain.LedgerTransaction) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	if t.ReversalOf != nil {
+		// Lock the original so concurrent reversals of it are serialized
+		var originalID uuid.UUID
+		err := tx.QueryRowContext(ctx, `SELECT id FROM ledger_transactions WHERE id = ? FOR UPDATE`, *t.ReversalOf).Scan(&originalID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return domain.ErrLedgerTransactionNotFound
+			}
+			return fmt.Errorf("failed to lock ledger transaction: %w", err)
+		}
+
+		var reversed bool
+		err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM ledger_transactions WHERE reversal_of = ?)`, *t.ReversalOf).Scan(&reversed)
+		if err != nil {
+			return fmt.Errorf("failed to check ledger reversal: %w", err)
+		}
+		if reversed {
+			return domain.ErrAlreadyReversed
+		}
+	}
+
+	// Payouts have no purchase
+	var purchaseID *uuid.UUID
+	if t.PurchaseID != uuid.Nil {
+		purchaseID = &t.PurchaseID
+	}
+
+	query := `
+		INSERT INTO ledger_transactions (id, type, purchase_id, merchant_id, reversal_of, settlement_id, posted_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	if _, err := tx.ExecContext(ctx, query, t.ID, t.Type, purchaseID, t.MerchantID, t.ReversalOf, t.SettlementID, t.PostedAt); err != nil {
+		return fmt.Errorf("failed to insert ledger transaction: %w", err)
+	}
+
+	entryQuery := `
+		INSERT INTO ledger_entries (id, transaction_id, line_no, account, merchant_id, amount, currency, settlement_id, posted_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	for i, entry := range t.Entries {
+		_, err := tx.ExecContext(ctx, entryQuery,
+			entry.ID,
+			entry.TransactionID,
+			i+1,
+			entry.Account,
+			entry.MerchantID,
+			entry.Amount.Decimal(),
+			entry.Amount.Currency,
+			entry.SettlementID,
+			entry.PostedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert ledger entry: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetTransaction retrieves a ledger transaction with its entries
+func (r *LedgerRepository) GetTransaction(ctx context.Context, id uuid.UUID) (*domain.LedgerTransaction, error) {
+	transactions, err := queryTransactions(ctx, r.db.DB, "t.id = ?", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(transactions) == 0 {
+		return nil, domain.ErrLedgerTransactionNotFound
+	}
+
+	return transactions[0], nil
+}
+
+// GetTransactionsByPurchaseID retrieves the ledger transactions of a purchase in posting order
+func (r *LedgerRepository) GetTransactionsByPurchaseID(ctx context.Context, purchaseID uuid.UUID) ([]*domain.LedgerTransaction, error) {
+	return queryTransactions(ctx, r.db.DB, "t.purchase_id = ?", purchaseID)
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// queryTransactions loads the transactions matching condition with their entries
+func queryTransactions(ctx context.Context, q querier, condition string, args ...interface{}) ([]*domain.LedgerTransaction, error) {
+	query := `
+		SELECT t.id, t.type, t.purchase_id, t.merchant_id, t.reversal_of, t.settlement_id, t.posted_at,
+			e.id, e.account, e.merchant_id, e.amount, e.currency, e.settlement_id, e.posted_at
+		FROM ledger_transactions t
+		JOIN ledger_entries e ON e.transaction_id = t.id
+		WHERE ` + condition + `
+		ORDER BY t.posted_at, t.id, e.line_no`
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ledger transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var transactions []*domain.LedgerTransaction
+	var current *domain.LedgerTransaction
+	for rows.Next() {
+		var t domain.LedgerTransaction
+		var entry domain.LedgerEntry
+		var purchaseID uuid.NullUUID
+		var amount moneyColumns
+		err := rows.Scan(
+			&t.ID,
+			&t.Type,
+			&purchaseID,
+			&t.MerchantID,
+			&t.ReversalOf,
+			&t.SettlementID,
+			&t.PostedAt,
+			&entry.ID,
+			&entry.Account,
+			&entry.MerchantID,
+			&amount.amount,
+			&amount.currency,
+			&entry.SettlementID,
+			&entry.PostedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ledger entry: %w", err)
+		}
+		if entry.Amount, err = amount.money(); err != nil {
+			return nil, err
+		}
+		t.PurchaseID = purchaseID.UUID
+
+		if current == nil || current.ID != t.ID {
+			current = &t
+			transactions = append(transactions, current)
+		}
+		entry.TransactionID = current.ID
+		current.Entries = append(current.Entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ledger entries: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// GetCommissionRules retrieves the commission rules that may apply to a sale by the merchant in the category
+func (r *LedgerRepository) GetCommissionRules(ctx context.Context, merchantID int64, categoryID *int64) ([]*domain.CommissionRule, error) {
+	query := `
+		SELECT id, merchant_id, category_id, rate_bps
+		FROM commission_rules
+		WHERE (merchant_id IS NULL OR merchant_id = ?)
+			AND (category_id IS NULL OR category_id = ?)`
+
+	rows, err := r.db.DB.QueryContext(ctx, query, merchantID, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query commission rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*domain.CommissionRule
+	for rows.Next() {
+		var rule domain.CommissionRule
+		if err := rows.Scan(&rule.ID, &rule.MerchantID, &rule.CategoryID, &rule.RateBasisPoints); err != nil {
+			return nil, fmt.Errorf("failed to scan commission rule: %w", err)
+		}
+		rules = append(rules, &rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating commission rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// GetStatementLines retrieves the payable entries of a merchant posted in [from, to)
+// with the gross price and commission of their purchase
+func (r *LedgerRepository) GetStatementLines(ctx context.Context, merchantID int64, from, to time.Time) ([]*domain.StatementLine, error) {
+	// The purchase transaction of each line's purchase split its price, including any platform
+	// funded discount, into commission and held amount
+	const purchaseLeg = `
+		SELECT SUM(pe.amount) FROM ledger_entries pe
+		JOIN ledger_transactions pt ON pe.transaction_id = pt.id
+		WHERE pt.purchase_id = t.purchase_id AND pt.type = 'purchase'`
+
+	query := `
+		SELECT e.id, e.transaction_id, t.type, t.purchase_id, vp.voucher_id,
+			e.amount, e.currency, e.settlement_id, e.posted_at,
+			COALESCE((` + purchaseLeg + ` AND pe.account IN (?, ?)), 0) AS gross,
+			COALESCE(-(` + purchaseLeg + ` AND pe.account = ?), 0) AS commission
+		FROM ledger_entries e
+		JOIN ledger_transactions t ON e.transaction_id = t.id
+		JOIN voucher_purchases vp ON vp.id = t.purchase_id
+		WHERE e.account = ? AND e.merchant_id = ? AND e.posted_at >= ? AND e.posted_at < ?
+		ORDER BY e.posted_at, e.id`
+
+	rows, err := r.db.DB.QueryContext(ctx, query,
+		domain.AccountBuyerPayments,
+		domain.AccountPlatformPromotions,
+		domain.AccountPlatformCommission,
+		domain.AccountMerchantPayable,
+		merchantID,
+		from,
+		to,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query statement lines: %w", err)
+	}
+	defer rows.Close()
+
+	lines := make([]*domain.StatementLine, 0)
+	for rows.Next() {
+		var line domain.StatementLine
+		var amount moneyColumns
+		var gross, commission string
+		err := rows.Scan(
+			&line.EntryID,
+			&line.TransactionID,
+			&line.Type,
+			&line.PurchaseID,
+			&line.VoucherID,
+			&amount.amount,
+			&amount.currency,
+			&line.SettlementID,
+			&line.PostedAt,
+			&gross,
+			&commission,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan statement line: %w", err)
+		}
+
+		// Payable is a credit balance, so what the merchant is owed is the negated entry
+		if line.Net, err = amount.money(); err != nil {
+			return nil, err
+		}
+		line.Net = line.Net.Neg()
+
+		grossColumns := moneyColumns{amount: gross, currency: amount.currency}
+		if line.Gross, err = grossColumns.money(); err != nil {
+			return nil, err
+		}
+		commissionColumns := moneyColumns{amount: commission, currency: amount.currency}
+		if line.Commission, err = commissionColumns.money(); err != nil {
+			return nil, err
+		}
+		// Lines taking money back from the merchant take back the whole sale
+		if line.Net.IsNegative() {
+			line.Gross, line.Commission = line.Gross.Neg(), line.Commission.Neg()
+		}
+
+		lines = append(lines, &line)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating statement lines: %w", err)
+	}
+
+	return lines, nil
+}
+
+// GetPayableMovement sums the payable entries of a merchant posted in [from, to) per currency,
+// as amounts owed to the merchant
+func (r *LedgerRepository) GetPayableMovement(ctx context.Context, merchantID int64, from, to time.Time) ([]domain.Money, error) {
+	// Payouts settle the balance rather than move what the merchant earned in the period
+	query := `
+		SELECT -SUM(e.amount), e.currency
+		FROM ledger_entries e
+		JOIN ledger_transactions t ON e.transaction_id = t.id
+		WHERE e.account = ? AND e.merchant_id = ? AND e.posted_at >= ? AND e.posted_at < ? AND t.type <> ?
+		GROUP BY e.currency
+		ORDER BY e.currency`
+
+	rows, err := r.db.DB.QueryContext(ctx, query, domain.AccountMerchantPayable, merchantID, from, to, domain.LedgerPayout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query payable movement: %w", err)
+	}
+	defer rows.Close()
+
+	var movement []domain.Money
+	for rows.Next() {
+		var amount moneyColumns
+		if err := rows.Scan(&amount.amount, &amount.currency); err != nil {
+			return nil, fmt.Errorf("failed to scan payable movement: %w", err)
+		}
+		m, err := amount.money()
+		if err != nil {
+			return nil, err
+		}
+		movement = append(movement, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating payable movement: %w", err)
+	}
+
+	return movement, nil
+}
+
+// SettlePayables records one settlement per currency for the unsettled payable entries of a
+// merchant posted in [from, to), marks those entries as settled by it and posts its payout
+func (r *LedgerRepository) SettlePayables(ctx context.Context, merchantID int64, from, to, settledAt time.Time) ([]*domain.Settlement, error) {
+	tx, err := r.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	const unsettled = "account = ? AND merchant_id = ? AND posted_at >= ? AND posted_at < ? AND settlement_id IS NULL"
+
+	// Lock the entries so a concurrent settlement cannot pay them out twice
+	rows, err := tx.QueryContext(ctx, `
+		SELECT -SUM(amount), currency
+		FROM ledger_entries
+		WHERE `+unsettled+`
+		GROUP BY currency
+		ORDER BY currency
+		FOR UPDATE`,
+		domain.AccountMerchantPayable, merchantID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unsettled payables: %w", err)
+	}
+
+	settlements := make([]*domain.Settlement, 0)
+	for rows.Next() {
+		var amount moneyColumns
+		if err := rows.Scan(&amount.amount, &amount.currency); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan unsettled payables: %w", err)
+		}
+		m, err := amount.money()
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		settlements = append(settlements, &domain.Settlement{
+			ID:         uuid.New(),
+			MerchantID: merchantID,
+			Amount:     m,
+			PeriodFrom: from,
+			PeriodTo:   to,
+			CreatedAt:  settledAt,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("error iterating unsettled payables: %w", err)
+	}
+	rows.Close()
+
+	for _, settlement := range settlements {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO settlements (id, merchant_id, amount, currency, period_from, period_to, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			settlement.ID,
+			settlement.MerchantID,
+			settlement.Amount.Decimal(),
+			settlement.Amount.Currency,
+			settlement.PeriodFrom,
+			settlement.PeriodTo,
+			settlement.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert settlement: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE ledger_entries
+			SET settlement_id = ?
+			WHERE `+unsettled+` AND currency = ?`,
+			settlement.ID, domain.AccountMerchantPayable, merchantID, from, to, settlement.Amount.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("failed to settle ledger entries: %w", err)
+		}
+
+		payout, err := domain.NewPayoutTransaction(settlement)
+		if err != nil {
+			return nil, err
+		}
+		if err := insertLedgerTransaction(ctx, tx, payout); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit settlement: %w", err)
+	}
+
+	return settlements, nil
+}
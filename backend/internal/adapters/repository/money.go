@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"fmt"
+
+	"4SaleBackendSkeleton/internal/domain"
+)
+
+// amountType is the SQL type of stored amounts; parameters are cast to it so
+// comparisons stay exact instead of falling back to floating point
+const amountType = "DECIMAL(15,3)"
+
+// moneyColumns receives a DECIMAL amount column and its currency column
+type moneyColumns struct {
+	amount   string
+	currency string
+}
+
+// money returns the scanned amount as a domain.Money
+func (c *moneyColumns) money() (domain.Money, error) {
+	m, err := domain.ParseMoney(c.amount, c.currency)
+	if err != nil {
+		return domain.Money{}, fmt.Errorf("failed to parse amount %q %s: %w", c.amount, c.currency, err)
+	}
+	return m, nil
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"4SaleBackendSkeleton/internal/domain"
+	"4SaleBackendSkeleton/internal/infrastructure/database"
+	"github.com/google/uuid"
+)
+
+// NotificationRepository implements the notification repository interface
+type NotificationRepository struct {
+	db *database.PostgresDB
+}
+
+// NewNotificationRepository creates a new notification repository
+func NewNotificationRepository(db *database.PostgresDB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// GetPreferences retrieves a user's notification preferences
+func (r *NotificationRepository) GetPreferences(ctx context.Context, userID int64) (*domain.NotificationPreferences, error) {
+	var prefs domain.NotificationPreferences
+	var phone, emailAddress sql.NullString
+	err := r.db.DB.QueryRowContext(ctx, `
+		SELECT user_id, push, sms, email, language, phone, email_address, updated_at
+		FROM notification_preferences
+		WHERE user_id = ?`, userID).Scan(
+		&prefs.UserID,
+		&prefs.Push,
+		&prefs.SMS,
+		&prefs.Email,
+		&prefs.Language,
+		&phone,
+		&emailAddress,
+		&prefs.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrPreferencesNotFound
+		}
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+
+	if phone.Valid {
+		prefs.Phone = &phone.String
+	}
+	if emailAddress.Valid {
+		prefs.EmailAddress = &emailAddress.String
+	}
+
+	return &prefs, nil
+}
+
+// SavePreferences creates or replaces a user's notification preferences
+func (r *NotificationRepository) SavePreferences(ctx context.Context, prefs *domain.NotificationPreferences) error {
+	_, err := r.db.DB.ExecContext(ctx, `
+		INSERT INTO notification_preferences (user_id, push, sms, email, language, phone, email_address, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			push = VALUES(push),
+			sms = VALUES(sms),
+			email = VALUES(email),
+			language = VALUES(language),
+			phone = VALUES(phone),
+			email_address = VALUES(email_address),
+			updated_at = VALUES(updated_at)`,
+		prefs.UserID,
+		prefs.Push,
+		prefs.SMS,
+		prefs.Email,
+		prefs.Language,
+		prefs.Phone,
+		prefs.EmailAddress,
+		prefs.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return nil
+}
+
+// ListExpiringPurchases lists active purchases whose voucher expires in [from, to) and that
+// have no expiry reminder recorded, soonest first
+func (r *NotificationRepository) ListExpiringPurchases(ctx context.Context, from, to time.Time, limit int) ([]*domain.ExpiringPurchase, error) {
+	rows, err := r.db.DB.QueryContext(ctx, `
+		SELECT vp.id, vp.buyer_id, v.title, v.title_ar, v.expires_at
+		FROM voucher_purchases vp
+		JOIN vouchers v ON v.id = vp.voucher_id
+		LEFT JOIN notification_reminders nr ON nr.purchase_id = vp.id AND nr.kind = ?
+		WHERE vp.status = ? AND v.expires_at >= ? AND v.expires_at < ? AND nr.purchase_id IS NULL
+		ORDER BY v.expires_at, vp.id
+		LIMIT ?`,
+		domain.NotificationExpiring, domain.StatusActive, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expiring purchases: %w", err)
+	}
+	defer rows.Close()
+
+	var purchases []*domain.ExpiringPurchase
+	for rows.Next() {
+		var purchase domain.ExpiringPurchase
+		var titleAR sql.NullString
+		if err := rows.Scan(&purchase.PurchaseID, &purchase.BuyerID, &purchase.VoucherTitle, &titleAR, &purchase.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("failed to scan expiring purchase: %w", err)
+		}
+		if titleAR.Valid {
+			purchase.VoucherTitleAR = &titleAR.String
+		}
+		purchases = append(purchases, &purchase)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list expiring purchases: %w", err)
+	}
+
+	return purchases, nil
+}
+
+// ClaimReminder records a reminder for a purchase. It returns false when the reminder
+// was already recorded, so each purchase is reminded once even with several job runners.
+func (r *NotificationRepository) ClaimReminder(ctx context.Context, purchaseID uuid.UUID, kind string, at time.Time) (bool, error) {
+	result, err := r.db.DB.ExecContext(ctx, `
+		INSERT IGNORE INTO notification_reminders (purchase_id, kind, sent_at)
+		VALUES (?, ?, ?)`,
+		purchaseID, kind, at)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim reminder: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get claimed reminder: %w", err)
+	}
+	return rows > 0, nil
+}
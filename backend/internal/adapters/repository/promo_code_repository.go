@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"4SaleBackendSkeleton/internal/domain"
+	"4SaleBackendSkeleton/internal/infrastructure/database"
+)
+
+// promoCodeColumns lists the promo_codes columns read by scanPromoCode
+const promoCodeColumns = `id, code, discount_type, percent_basis_points, amount, currency, funded_by, merchant_id,
+		max_uses, max_uses_per_user, uses, starts_at, ends_at, created_at`
+
+// PromoCodeRepository implements the promo code repository interface
+type PromoCodeRepository struct {
+	db *database.PostgresDB
+}
+
+// NewPromoCodeRepository creates a new promo code repository
+func NewPromoCodeRepository(db *database.PostgresDB) *PromoCodeRepository {
+	return &PromoCodeRepository{db: db}
+}
+
+// CreatePromoCode stores a new promo code and sets its ID.
+// It returns ErrPromoCodeExists when the code is taken.
+func (r *PromoCodeRepository) CreatePromoCode(ctx context.Context, promo *domain.PromoCode) error {
+	var amount, currency sql.NullString
+	if promo.Amount != nil {
+		amount = sql.NullString{String: promo.Amount.Decimal(), Valid: true}
+		currency = sql.NullString{String: promo.Amount.Currency, Valid: true}
+	}
+
+	result, err := r.db.DB.ExecContext(ctx, `
+		INSERT IGNORE INTO promo_codes (code, discount_type, percent_basis_points, amount, currency, funded_by,
+			merchant_id, max_uses, max_uses_per_user, uses, starts_at, ends_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 0, ?, ?, ?)`,
+		promo.Code,
+		promo.DiscountType,
+		promo.PercentBasisPoints,
+		amount,
+		currency,
+		promo.FundedBy,
+		promo.MerchantID,
+		promo.MaxUses,
+		promo.MaxUsesPerUser,
+		promo.StartsAt,
+		promo.EndsAt,
+		promo.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create promo code: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get created promo code: %w", err)
+	}
+	if rows == 0 {
+		return domain.ErrPromoCodeExists
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get promo code ID: %w", err)
+	}
+	promo.ID = id
+
+	return nil
+}
+
+// GetPromoCodeByCode retrieves a promo code by its normalized code
+func (r *PromoCodeRepository) GetPromoCodeByCode(ctx context.Context, code string) (*domain.PromoCode, error) {
+	query := `SELECT ` + promoCodeColumns + ` FROM promo_codes WHERE code = ?`
+
+	promo, err := scanPromoCode(r.db.DB.QueryRowContext(ctx, query, code))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrPromoNotFound
+		}
+		return nil, fmt.Errorf("failed to get promo code: %w", err)
+	}
+
+	return promo, nil
+}
+
+// CountUserRedemptions returns how many times a user has used a promo code
+func (r *PromoCodeRepository) CountUserRedemptions(ctx context.Context, promoCodeID, userID int64) (int64, error) {
+	return countUserRedemptions(ctx, r.db.DB, promoCodeID, userID)
+}
+
+// queryRower is satisfied by both *sql.DB and *sql.Tx
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// countUserRedemptions counts a user's redemptions of a promo code
+func countUserRedemptions(ctx context.Context, q queryRower, promoCodeID, userID int64) (int64, error) {
+	var count int64
+	err := q.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM promo_redemptions WHERE promo_code_id = ? AND user_id = ?`,
+		promoCodeID, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count promo redemptions: %w", err)
+	}
+	return count, nil
+}
+
+// insertPromoRedemption records a promo code use within tx. The code's row is locked
+// while its limits are checked so concurrent purchases cannot use it past them.
+func insertPromoRedemption(ctx context.Context, tx *sql.Tx, redemption *domain.PromoRedemption) error {
+	promo, err := scanPromoCode(tx.QueryRowContext(ctx, `
+		SELECT `+promoCodeColumns+` FROM promo_codes WHERE id = ? FOR UPDATE`, redemption.PromoCodeID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.ErrPromoNotFound
+		}
+		return fmt.Errorf("failed to lock promo code: %w", err)
+	}
+
+	userUses, err := countUserRedemptions(ctx, tx, redemption.PromoCodeID, redemption.UserID)
+	if err != nil {
+		return err
+	}
+	if err := promo.CheckUses(userUses); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO promo_redemptions (id, promo_code_id, purchase_id, user_id, discount, currency, funded_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		redemption.ID,
+		redemption.PromoCodeID,
+		redemption.PurchaseID,
+		redemption.UserID,
+		redemption.Discount.Decimal(),
+		redemption.Discount.Currency,
+		redemption.FundedBy,
+		redemption.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create promo redemption: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE promo_codes SET uses = uses + 1 WHERE id = ?`, redemption.PromoCodeID)
+	if err != nil {
+		return fmt.Errorf("failed to count promo code use: %w", err)
+	}
+
+	return nil
+}
+
+// scanPromoCode reads a row selected with promoCodeColumns
+func scanPromoCode(row rowScanner) (*domain.PromoCode, error) {
+	var promo domain.PromoCode
+	var amount, currency sql.NullString
+	var merchantID, maxUses, maxUsesPerUser sql.NullInt64
+	var startsAt, endsAt sql.NullTime
+	err := row.Scan(
+		&promo.ID,
+		&promo.Code,
+		&promo.DiscountType,
+		&promo.PercentBasisPoints,
+		&amount,
+		&currency,
+		&promo.FundedBy,
+		&merchantID,
+		&maxUses,
+		&maxUsesPerUser,
+		&promo.Uses,
+		&startsAt,
+		&endsAt,
+		&promo.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if amount.Valid && currency.Valid {
+		columns := moneyColumns{amount: amount.String, currency: currency.String}
+		m, err := columns.money()
+		if err != nil {
+			return nil, err
+		}
+		promo.Amount = &m
+	}
+	if merchantID.Valid {
+		promo.MerchantID = &merchantID.Int64
+	}
+	if maxUses.Valid {
+		promo.MaxUses = &maxUses.Int64
+	}
+	if maxUsesPerUser.Valid {
+		promo.MaxUsesPerUser = &maxUsesPerUser.Int64
+	}
+	if startsAt.Valid {
+		promo.StartsAt = &startsAt.Time
+	}
+	if endsAt.Valid {
+		promo.EndsAt = &endsAt.Time
+	}
+
+	return &promo, nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"4SaleBackendSkeleton/internal/domain"
+	"4SaleBackendSkeleton/internal/infrastructure/database"
+)
+
+// RedemptionRepository implements the merchant redemption history repository interface
+type RedemptionRepository struct {
+	db *database.PostgresDB
+}
+
+// NewRedemptionRepository creates a new redemption repository
+func NewRedemptionRepository(db *database.PostgresDB) *RedemptionRepository {
+	return &RedemptionRepository{db: db}
+}
+
+// ListRedemptions returns one page of the redemptions of a merchant's vouchers, newest first
+func (r *RedemptionRepository) ListRedemptions(ctx context.Context, q *domain.RedemptionQuery) (*domain.RedemptionPage, error) {
+	conditions := []string{"v.user_id = ?", "vp.status = 'redeemed'"}
+	args := []interface{}{q.MerchantID}
+	if q.BranchID != nil {
+		conditions = append(conditions, "vp.redeemed_branch_id = ?")
+		args = append(args, *q.BranchID)
+	}
+	if q.From != nil {
+		conditions = append(conditions, "vp.redeemed_at >= ?")
+		args = append(args, *q.From)
+	}
+	if q.To != nil {
+		conditions = append(conditions, "vp.redeemed_at < ?")
+		args = append(args, *q.To)
+	}
+	if q.After != nil {
+		key, err := q.After.TimeKey()
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, "(vp.redeemed_at < ? OR (vp.redeemed_at = ? AND vp.id < ?))")
+		args = append(args, key, key, q.After.ID)
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	args = append(args, q.Limit+1)
+
+	rows, err := r.db.DB.QueryContext(ctx, `
+		SELECT vp.id, vp.voucher_id, v.title, v.user_id, vp.redeemed_branch_id, vp.redeemed_by,
+			vp.price, vp.currency, vp.redeemed_at
+		FROM voucher_purchases vp
+		JOIN vouchers v ON vp.voucher_id = v.id
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY vp.redeemed_at DESC, vp.id DESC
+		LIMIT ?`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list redemptions: %w", err)
+	}
+	defer rows.Close()
+
+	redemptions := make([]*domain.Redemption, 0, q.Limit)
+	for rows.Next() {
+		var redemption domain.Redemption
+		var price moneyColumns
+		err := rows.Scan(
+			&redemption.PurchaseID,
+			&redemption.VoucherID,
+			&redemption.VoucherTitle,
+			&redemption.MerchantID,
+			&redemption.BranchID,
+			&redemption.StaffID,
+			&price.amount,
+			&price.currency,
+			&redemption.RedeemedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan redemption: %w", err)
+		}
+		if redemption.Amount, err = price.money(); err != nil {
+			return nil, err
+		}
+		redemptions = append(redemptions, &redemption)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list redemptions: %w", err)
+	}
+
+	page := &domain.RedemptionPage{Redemptions: redemptions}
+	if len(redemptions) > q.Limit {
+		page.Redemptions = redemptions[:q.Limit]
+		last := page.Redemptions[q.Limit-1]
+		page.Next = domain.NewTimeCursor(domain.SortNewest, last.RedeemedAt, last.PurchaseID)
+	}
+
+	return page, nil
+}
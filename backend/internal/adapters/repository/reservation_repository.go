@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"4SaleBackendSkeleton/internal/domain"
+	"4SaleBackendSkeleton/internal/infrastructure/database"
+	"github.com/google/uuid"
+)
+
+// reservationColumns are the voucher_reservations columns read by scanReservation, in order
+const reservationColumns = `id, voucher_id, buyer_id, status, purchase_id, created_at, expires_at, resolved_at`
+
+// ReservationRepository implements the voucher reservation repository interface
+type ReservationRepository struct {
+	db *database.PostgresDB
+}
+
+// NewReservationRepository creates a new reservation repository
+func NewReservationRepository(db *database.PostgresDB) *ReservationRepository {
+	return &ReservationRepository{db: db}
+}
+
+// CreateReservation places a hold on a voucher. The voucher row is locked so two holds cannot
+// race in; when the buyer already holds the voucher their existing reservation is returned.
+func (r *ReservationRepository) CreateReservation(ctx context.Context, reservation *domain.Reservation) (*domain.Reservation, error) {
+	tx, err := r.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var voucherID uuid.UUID
+	err = tx.QueryRowContext(ctx, `SELECT id FROM vouchers WHERE id = ? FOR UPDATE`, reservation.VoucherID).Scan(&voucherID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrVoucherNotFound
+		}
+		return nil, fmt.Errorf("failed to lock voucher: %w", err)
+	}
+
+	existing, err := getActiveReservation(ctx, tx, reservation.VoucherID, reservation.CreatedAt)
+	if err != nil && err != domain.ErrReservationNotFound {
+		return nil, err
+	}
+	if existing != nil {
+		if existing.BuyerID != reservation.BuyerID {
+			return nil, domain.ErrVoucherReserved
+		}
+		return existing, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO voucher_reservations (id, voucher_id, buyer_id, status, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		reservation.ID, reservation.VoucherID, reservation.BuyerID, reservation.Status, reservation.CreatedAt, reservation.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reservation: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit reservation: %w", err)
+	}
+
+	return reservation, nil
+}
+
+// GetReservation retrieves a reservation by its ID
+func (r *ReservationRepository) GetReservation(ctx context.Context, id uuid.UUID) (*domain.Reservation, error) {
+	query := `
+		SELECT ` + reservationColumns + `
+		FROM voucher_reservations
+		WHERE id = ?`
+
+	reservation, err := scanReservation(r.db.DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrReservationNotFound
+		}
+		return nil, fmt.Errorf("failed to get reservation: %w", err)
+	}
+
+	return reservation, nil
+}
+
+// GetActiveReservation retrieves the hold on a voucher that is still in force at now.
+// It returns ErrReservationNotFound when the voucher is not held.
+func (r *ReservationRepository) GetActiveReservation(ctx context.Context, voucherID uuid.UUID, now time.Time) (*domain.Reservation, error) {
+	return getActiveReservation(ctx, r.db.DB, voucherID, now)
+}
+
+// getActiveReservation retrieves the hold on a voucher in force at now
+func getActiveReservation(ctx context.Context, q queryRower, voucherID uuid.UUID, now time.Time) (*domain.Reservation, error) {
+	reservation, err := scanReservation(q.QueryRowContext(ctx, `
+		SELECT `+reservationColumns+`
+		FROM voucher_reservations
+		WHERE voucher_id = ? AND status = ? AND expires_at > ?
+		ORDER BY created_at DESC
+		LIMIT 1`, voucherID, domain.ReservationHeld, now))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrReservationNotFound
+		}
+		return nil, fmt.Errorf("failed to get active reservation: %w", err)
+	}
+
+	return reservation, nil
+}
+
+// ResolveReservation ends a hold that is still in force with the given status, recording the
+// purchase it was confirmed into, if any. It returns ErrReservationNotActive when the hold has
+// already ended or expired.
+func (r *ReservationRepository) ResolveReservation(ctx context.Context, id uuid.UUID, status string, purchaseID *uuid.UUID, at time.Time) error {
+	return resolveReservation(ctx, r.db.DB, id, status, purchaseID, at)
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// resolveReservation ends a hold that is still in force at at
+func resolveReservation(ctx context.Context, e execer, id uuid.UUID, status string, purchaseID *uuid.UUID, at time.Time) error {
+	result, err := e.ExecContext(ctx, `
+		UPDATE voucher_reservations
+		SET status = ?, purchase_id = ?, resolved_at = ?
+		WHERE id = ? AND status = ? AND expires_at > ?`,
+		status, purchaseID, at, id, domain.ReservationHeld, at)
+	if err != nil {
+		return fmt.Errorf("failed to resolve reservation: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrReservationNotActive
+	}
+
+	return nil
+}
+
+// ReleaseExpired marks the holds that expired by now as expired and returns how many there were
+func (r *ReservationRepository) ReleaseExpired(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.db.DB.ExecContext(ctx, `
+		UPDATE voucher_reservations
+		SET status = ?, resolved_at = expires_at
+		WHERE status = ? AND expires_at <= ?`,
+		domain.ReservationExpired, domain.ReservationHeld, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to release expired reservations: %w", err)
+	}
+
+	released, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return released, nil
+}
+
+// scanReservation scans a row selected with reservationColumns
+func scanReservation(row rowScanner) (*domain.Reservation, error) {
+	var reservation domain.Reservation
+	var purchaseID uuid.NullUUID
+	var resolvedAt sql.NullTime
+	err := row.Scan(
+		&reservation.ID,
+		&reservation.VoucherID,
+		&reservation.BuyerID,
+		&reservation.Status,
+		&purchaseID,
+		&reservation.CreatedAt,
+		&reservation.ExpiresAt,
+		&resolvedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if purchaseID.Valid {
+		reservation.PurchaseID = &purchaseID.UUID
+	}
+	if resolvedAt.Valid {
+		reservation.ResolvedAt = &resolvedAt.Time
+	}
+
+	return &reservation, nil
+}
@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"4SaleBackendSkeleton/internal/domain"
+	"4SaleBackendSkeleton/internal/infrastructure/database"
+	"github.com/google/uuid"
+)
+
+// transferColumns are the purchase_transfers columns read by scanTransfer, in order
+const transferColumns = `id, purchase_id, from_buyer_id, to_user_id, to_phone, token_hash, status, accepted_by,
+		created_at, expires_at, resolved_at`
+
+// TransferRepository implements the purchase transfer repository interface
+type TransferRepository struct {
+	db *database.PostgresDB
+}
+
+// NewTransferRepository creates a new transfer repository
+func NewTransferRepository(db *database.PostgresDB) *TransferRepository {
+	return &TransferRepository{db: db}
+}
+
+// CreateTransfer stores a transfer; the purchase row is locked so two open transfers cannot race in
+func (r *TransferRepository) CreateTransfer(ctx context.Context, t *domain.PurchaseTransfer) error {
+	tx, err := r.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var buyerID int64
+	var status string
+	err = tx.QueryRowContext(ctx, `SELECT buyer_id, status FROM voucher_purchases WHERE id = ? FOR UPDATE`, t.PurchaseID).Scan(&buyerID, &status)
+	if err == sql.ErrNoRows || (err == nil && buyerID != t.FromBuyerID) {
+		return domain.ErrPurchaseNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock voucher purchase: %w", err)
+	}
+	if err := domain.CheckTransferable(status); err != nil {
+		return err
+	}
+
+	var open int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM purchase_transfers
+		WHERE purchase_id = ? AND status = ? AND expires_at > ?`,
+		t.PurchaseID, domain.TransferPending, t.CreatedAt).Scan(&open)
+	if err != nil {
+		return fmt.Errorf("failed to check pending transfers: %w", err)
+	}
+	if open > 0 {
+		return domain.ErrTransferPending
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO purchase_transfers (id, purchase_id, from_buyer_id, to_user_id, to_phone, token_hash, status, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.ID, t.PurchaseID, t.FromBuyerID, t.ToUserID, t.ToPhone, t.TokenHash, t.Status, t.CreatedAt, t.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create transfer: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transfer: %w", err)
+	}
+
+	return nil
+}
+
+// GetTransfer retrieves a transfer by its ID
+func (r *TransferRepository) GetTransfer(ctx context.Context, id uuid.UUID) (*domain.PurchaseTransfer, error) {
+	query := `
+		SELECT ` + transferColumns + `
+		FROM purchase_transfers
+		WHERE id = ?`
+
+	transfer, err := scanTransfer(r.db.DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrTransferNotFound
+		}
+		return nil, fmt.Errorf("failed to get transfer: %w", err)
+	}
+
+	return transfer, nil
+}
+
+// GetTransfersByPurchaseID retrieves every transfer of a purchase, oldest first
+func (r *TransferRepository) GetTransfersByPurchaseID(ctx context.Context, purchaseID uuid.UUID) ([]*domain.PurchaseTransfer, error) {
+	query := `
+		SELECT ` + transferColumns + `
+		FROM purchase_transfers
+		WHERE purchase_id = ?
+		ORDER BY created_at, id`
+
+	rows, err := r.db.DB.QueryContext(ctx, query, purchaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transfers: %w", err)
+	}
+	defer rows.Close()
+
+	transfers := make([]*domain.PurchaseTransfer, 0)
+	for rows.Next() {
+		transfer, err := scanTransfer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transfer: %w", err)
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transfers: %w", err)
+	}
+
+	return transfers, nil
+}
+
+// AcceptTransfer moves a purchase to the recipient and replaces its QR code in one transaction
+func (r *TransferRepository) AcceptTransfer(ctx context.Context, transferID uuid.UUID, recipientID int64, qrCode, qrPayload string, at time.Time) (*domain.PurchaseTransfer, error) {
+	tx, err := r.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	transfer, err := scanTransfer(tx.QueryRowContext(ctx, `
+		SELECT `+transferColumns+`
+		FROM purchase_transfers
+		WHERE id = ?
+		FOR UPDATE`, transferID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrTransferNotFound
+		}
+		return nil, fmt.Errorf("failed to lock transfer: %w", err)
+	}
+	if !transfer.IsOpen(at) {
+		return nil, domain.ErrTransferNotPending
+	}
+
+	var buyerID int64
+	var status string
+	err = tx.QueryRowContext(ctx, `SELECT buyer_id, status FROM voucher_purchases WHERE id = ? FOR UPDATE`, transfer.PurchaseID).Scan(&buyerID, &status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock voucher purchase: %w", err)
+	}
+	if buyerID != transfer.FromBuyerID {
+		return nil, domain.ErrTransferNotPending
+	}
+	if err := domain.CheckTransferable(status); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE voucher_purchases SET buyer_id = ?, qr_code = ?, qr_payload = ? WHERE id = ?`, recipientID, qrCode, qrPayload, transfer.PurchaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer voucher purchase: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE purchase_transfers
+		SET status = ?, accepted_by = ?, resolved_at = ?
+		WHERE id = ?`, domain.TransferAccepted, recipientID, at, transferID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to accept transfer: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transfer: %w", err)
+	}
+
+	transfer.Status = domain.TransferAccepted
+	transfer.AcceptedBy = &recipientID
+	transfer.ResolvedAt = &at
+	return transfer, nil
+}
+
+// CancelTransfer cancels a transfer that is still open
+func (r *TransferRepository) CancelTransfer(ctx context.Context, transferID uuid.UUID, at time.Time) error {
+	result, err := r.db.DB.ExecContext(ctx, `
+		UPDATE purchase_transfers
+		SET status = ?, resolved_at = ?
+		WHERE id = ? AND status = ? AND expires_at > ?`,
+		domain.TransferCancelled, at, transferID, domain.TransferPending, at)
+	if err != nil {
+		return fmt.Errorf("failed to cancel transfer: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrTransferNotPending
+	}
+
+	return nil
+}
+
+// scanTransfer scans a row selected with transferColumns
+func scanTransfer(row rowScanner) (*domain.PurchaseTransfer, error) {
+	var transfer domain.PurchaseTransfer
+	var toUserID, acceptedBy sql.NullInt64
+	var toPhone sql.NullString
+	var resolvedAt sql.NullTime
+	err := row.Scan(
+		&transfer.ID,
+		&transfer.PurchaseID,
+		&transfer.FromBuyerID,
+		&toUserID,
+		&toPhone,
+		&transfer.TokenHash,
+		&transfer.Status,
+		&acceptedBy,
+		&transfer.CreatedAt,
+		&transfer.ExpiresAt,
+		&resolvedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if toUserID.Valid {
+		transfer.ToUserID = &toUserID.Int64
+	}
+	if toPhone.Valid {
+		transfer.ToPhone = &toPhone.String
+	}
+	if acceptedBy.Valid {
+		transfer.AcceptedBy = &acceptedBy.Int64
+	}
+	if resolvedAt.Valid {
+		transfer.ResolvedAt = &resolvedAt.Time
+	}
+
+	return &transfer, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"4SaleBackendSkeleton/internal/domain"
@@ -16,68 +17,230 @@ type VoucherPurchaseRepositorySQL struct {
 	db *database.PostgresDB
 }
 
+// purchaseColumns lists the voucher_purchases columns read by scanPurchase
+const purchaseColumns = `id, voucher_id, buyer_id, qr_code, qr_payload, status, redeemed_at, refunded_at, price, currency,
+		reporting_price, reporting_currency, exchange_rate, rate_as_of, created_at, discount, promo_code`
+
 // NewVoucherPurchaseRepositorySQL creates a new voucher purchase repository
 func NewVoucherPurchaseRepositorySQL(db *database.PostgresDB) *VoucherPurchaseRepositorySQL {
 	return &VoucherPurchaseRepositorySQL{db: db}
 }
 
-// CreatePurchase creates a new voucher purchase in the database
-func (r *VoucherPurchaseRepositorySQL) CreatePurchase(ctx context.Context, purchase *domain.VoucherPurchase) error {
+// CreatePurchase creates a new voucher purchase in the database together with its
+// ledger posting and promo code redemption, if any. The voucher row is locked while it is
+// checked not to be held for another buyer, and the buyer's reservation, given or found
+// active on the voucher, is confirmed in the same transaction. A voucher without a code pool sells once; one with a
+// pool sells while it has unassigned codes, and the pool code given must be the next of them.
+func (r *VoucherPurchaseRepositorySQL) CreatePurchase(ctx context.Context, purchase *domain.VoucherPurchase, posting *domain.LedgerTransaction, redemption *domain.PromoRedemption, reservationID *uuid.UUID, code *string) error {
+	tx, err := r.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var voucherID uuid.UUID
+	err = tx.QueryRowContext(ctx, `SELECT id FROM vouchers WHERE id = ? FOR UPDATE`, purchase.VoucherID).Scan(&voucherID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.ErrVoucherNotFound
+		}
+		return fmt.Errorf("failed to lock voucher: %w", err)
+	}
+
+	if code == nil {
+		var sold bool
+		err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM voucher_purchases WHERE voucher_id = ?)`, purchase.VoucherID).Scan(&sold)
+		if err != nil {
+			return fmt.Errorf("failed to check existing purchase: %w", err)
+		}
+		if sold {
+			return domain.ErrAlreadyPurchased
+		}
+	}
+
+	hold, err := getActiveReservation(ctx, tx, purchase.VoucherID, purchase.CreatedAt)
+	if err != nil && err != domain.ErrReservationNotFound {
+		return err
+	}
+	if hold != nil && hold.BuyerID != purchase.BuyerID {
+		return domain.ErrVoucherReserved
+	}
+	// A buyer purchasing directly while holding the voucher confirms their own hold, so it
+	// does not keep holding a voucher that has been sold
+	if hold != nil && reservationID == nil {
+		reservationID = &hold.ID
+	}
+
 	query := `
-		INSERT INTO voucher_purchases (id, voucher_id, buyer_id, qr_code, status, redeemed_at, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+		INSERT INTO voucher_purchases (id, voucher_id, buyer_id, qr_code, qr_payload, status, redeemed_at, price, currency,
+			reporting_price, reporting_currency, exchange_rate, rate_as_of, created_at, discount, promo_code)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	// The exchange-rate snapshot is optional; purchases recorded without one leave it NULL
+	var reportingPrice, reportingCurrency, exchangeRate sql.NullString
+	var rateAsOf sql.NullTime
+	if purchase.ReportingPrice != nil && purchase.ExchangeRate != nil {
+		reportingPrice = sql.NullString{String: purchase.ReportingPrice.Decimal(), Valid: true}
+		reportingCurrency = sql.NullString{String: purchase.ReportingPrice.Currency, Valid: true}
+		exchangeRate = sql.NullString{String: purchase.ExchangeRate.Decimal(), Valid: true}
+		rateAsOf = sql.NullTime{Time: purchase.ExchangeRate.AsOf, Valid: true}
+	}
+	var discount sql.NullString
+	if purchase.Discount != nil {
+		discount = sql.NullString{String: purchase.Discount.Decimal(), Valid: true}
+	}
 
-	_, err := r.db.DB.ExecContext(ctx, query,
+	_, err = tx.ExecContext(ctx, query,
 		purchase.ID,
 		purchase.VoucherID,
 		purchase.BuyerID,
 		purchase.QRCode,
+		purchase.QRPayload,
 		purchase.Status,
 		purchase.RedeemedAt,
+		purchase.Price.Decimal(),
+		purchase.Price.Currency,
+		reportingPrice,
+		reportingCurrency,
+		exchangeRate,
+		rateAsOf,
 		purchase.CreatedAt,
+		discount,
+		purchase.PromoCode,
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to create voucher purchase: %w", err)
 	}
 
+	if code != nil {
+		if err := claimCode(ctx, tx, purchase.VoucherID, *code, purchase.ID, purchase.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	if reservationID != nil {
+		err := resolveReservation(ctx, tx, *reservationID, domain.ReservationConfirmed, &purchase.ID, purchase.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	if redemption != nil {
+		if err := insertPromoRedemption(ctx, tx, redemption); err != nil {
+			return err
+		}
+	}
+
+	return commitWithPosting(ctx, tx, posting)
+}
+
+// commitWithPosting stores the ledger posting, if any, within tx and commits
+func commitWithPosting(ctx context.Context, tx *sql.Tx, posting *domain.LedgerTransaction) error {
+	if posting != nil {
+		if err := insertLedgerTransaction(ctx, tx, posting); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit voucher purchase: %w", err)
+	}
+
 	return nil
 }
 
-// GetPurchaseByVoucherID retrieves a purchase by voucher ID
+// GetPurchaseByVoucherID retrieves a purchase by voucher ID; a voucher sold from a code pool
+// has several, and any one of them is returned
 func (r *VoucherPurchaseRepositorySQL) GetPurchaseByVoucherID(ctx context.Context, voucherID uuid.UUID) (*domain.VoucherPurchase, error) {
 	query := `
-		SELECT id, voucher_id, buyer_id, qr_code, status, redeemed_at, created_at
+		SELECT ` + purchaseColumns + `
 		FROM voucher_purchases
-		WHERE voucher_id = $1`
+		WHERE voucher_id = ?`
 
-	var purchase domain.VoucherPurchase
-	err := r.db.DB.QueryRowContext(ctx, query, voucherID).Scan(
-		&purchase.ID,
-		&purchase.VoucherID,
-		&purchase.BuyerID,
-		&purchase.QRCode,
-		&purchase.Status,
-		&purchase.RedeemedAt,
-		&purchase.CreatedAt,
-	)
+	purchase, err := scanPurchase(r.db.DB.QueryRowContext(ctx, query, voucherID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrPurchaseNotFound
+		}
+		return nil, fmt.Errorf("failed to get voucher purchase: %w", err)
+	}
+
+	return purchase, nil
+}
+
+// GetPurchaseByQRPayload retrieves the purchase of a voucher whose current QR code encodes payload
+func (r *VoucherPurchaseRepositorySQL) GetPurchaseByQRPayload(ctx context.Context, voucherID uuid.UUID, payload string) (*domain.VoucherPurchase, error) {
+	query := `
+		SELECT ` + purchaseColumns + `
+		FROM voucher_purchases
+		WHERE voucher_id = ? AND qr_payload = ?`
 
+	purchase, err := scanPurchase(r.db.DB.QueryRowContext(ctx, query, voucherID, payload))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("voucher purchase not found")
+			return nil, domain.ErrPurchaseNotFound
 		}
 		return nil, fmt.Errorf("failed to get voucher purchase: %w", err)
 	}
 
-	return &purchase, nil
+	return purchase, nil
+}
+
+// GetPurchasesByVoucherID retrieves all purchases of a voucher, oldest first
+func (r *VoucherPurchaseRepositorySQL) GetPurchasesByVoucherID(ctx context.Context, voucherID uuid.UUID) ([]*domain.VoucherPurchase, error) {
+	query := `
+		SELECT ` + purchaseColumns + `
+		FROM voucher_purchases
+		WHERE voucher_id = ?
+		ORDER BY created_at`
+
+	rows, err := r.db.DB.QueryContext(ctx, query, voucherID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query voucher purchases: %w", err)
+	}
+	defer rows.Close()
+
+	var purchases []*domain.VoucherPurchase
+	for rows.Next() {
+		purchase, err := scanPurchase(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan voucher purchase: %w", err)
+		}
+		purchases = append(purchases, purchase)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating voucher purchases: %w", err)
+	}
+
+	return purchases, nil
+}
+
+// GetPurchaseByID retrieves a purchase by its ID
+func (r *VoucherPurchaseRepositorySQL) GetPurchaseByID(ctx context.Context, id uuid.UUID) (*domain.VoucherPurchase, error) {
+	query := `
+		SELECT ` + purchaseColumns + `
+		FROM voucher_purchases
+		WHERE id = ?`
+
+	purchase, err := scanPurchase(r.db.DB.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrPurchaseNotFound
+		}
+		return nil, fmt.Errorf("failed to get voucher purchase: %w", err)
+	}
+
+	return purchase, nil
 }
 
 // GetPurchasesByBuyerID retrieves all purchases for a specific buyer
 func (r *VoucherPurchaseRepositorySQL) GetPurchasesByBuyerID(ctx context.Context, buyerID int64) ([]*domain.VoucherPurchase, error) {
 	query := `
-		SELECT id, voucher_id, buyer_id, qr_code, status, redeemed_at, created_at
+		SELECT ` + purchaseColumns + `
 		FROM voucher_purchases
-		WHERE buyer_id = $1
+		WHERE buyer_id = ?
 		ORDER BY created_at DESC`
 
 	rows, err := r.db.DB.QueryContext(ctx, query, buyerID)
@@ -88,20 +251,11 @@ func (r *VoucherPurchaseRepositorySQL) GetPurchasesByBuyerID(ctx context.Context
 
 	var purchases []*domain.VoucherPurchase
 	for rows.Next() {
-		var purchase domain.VoucherPurchase
-		err := rows.Scan(
-			&purchase.ID,
-			&purchase.VoucherID,
-			&purchase.BuyerID,
-			&purchase.QRCode,
-			&purchase.Status,
-			&purchase.RedeemedAt,
-			&purchase.CreatedAt,
-		)
+		purchase, err := scanPurchase(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan voucher purchase: %w", err)
 		}
-		purchases = append(purchases, &purchase)
+		purchases = append(purchases, purchase)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -111,14 +265,111 @@ func (r *VoucherPurchaseRepositorySQL) GetPurchasesByBuyerID(ctx context.Context
 	return purchases, nil
 }
 
-// UpdatePurchaseStatus updates the status and redeemed_at of a voucher purchase
-func (r *VoucherPurchaseRepositorySQL) UpdatePurchaseStatus(ctx context.Context, voucherID uuid.UUID, status string, redeemedAt *time.Time) error {
+// scanPurchase reads a row selected with purchaseColumns
+func scanPurchase(row rowScanner) (*domain.VoucherPurchase, error) {
+	var purchase domain.VoucherPurchase
+	var price moneyColumns
+	var reportingPrice, reportingCurrency, exchangeRate sql.NullString
+	var rateAsOf sql.NullTime
+	var discount sql.NullString
+	err := row.Scan(
+		&purchase.ID,
+		&purchase.VoucherID,
+		&purchase.BuyerID,
+		&purchase.QRCode,
+		&purchase.QRPayload,
+		&purchase.Status,
+		&purchase.RedeemedAt,
+		&purchase.RefundedAt,
+		&price.amount,
+		&price.currency,
+		&reportingPrice,
+		&reportingCurrency,
+		&exchangeRate,
+		&rateAsOf,
+		&purchase.CreatedAt,
+		&discount,
+		&purchase.PromoCode,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if purchase.Price, err = price.money(); err != nil {
+		return nil, err
+	}
+	if discount.Valid {
+		columns := moneyColumns{amount: discount.String, currency: purchase.Price.Currency}
+		amount, err := columns.money()
+		if err != nil {
+			return nil, err
+		}
+		purchase.Discount = &amount
+	}
+
+	if reportingPrice.Valid && reportingCurrency.Valid && exchangeRate.Valid && rateAsOf.Valid {
+		reporting := moneyColumns{amount: reportingPrice.String, currency: reportingCurrency.String}
+		converted, err := reporting.money()
+		if err != nil {
+			return nil, err
+		}
+		rate, err := domain.ParseRate(exchangeRate.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse exchange rate %q: %w", exchangeRate.String, err)
+		}
+		purchase.ReportingPrice = &converted
+		purchase.ExchangeRate = &domain.ExchangeRate{
+			From: purchase.Price.Currency,
+			To:   converted.Currency,
+			Rate: rate,
+			AsOf: rateAsOf.Time,
+		}
+	}
+
+	return &purchase, nil
+}
+
+// RedeemPurchase marks the active purchase of a voucher as redeemed, recording the branch and
+// cashier that redeemed it, and releases the amount held for it to the merchant. The purchase
+// row is locked so the held balance released is the one read, and a purchase already redeemed
+// or refunded fails with ErrAlreadyRedeemed or ErrPurchaseRefunded. The scanned QR data must
+// still be the purchase's current code, which a transfer replaces.
+func (r *VoucherPurchaseRepositorySQL) RedeemPurchase(ctx context.Context, redemption *domain.Redemption, scanned string) error {
+	tx, err := r.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var status string
+	var payload *string
+	err = tx.QueryRowContext(ctx, `SELECT status, qr_payload FROM voucher_purchases WHERE id = ? FOR UPDATE`, redemption.PurchaseID).Scan(&status, &payload)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.ErrPurchaseNotFound
+		}
+		return fmt.Errorf("failed to lock voucher purchase: %w", err)
+	}
+	if !domain.QRPayloadMatches(payload, scanned) {
+		return domain.ErrQRCodeMismatch
+	}
+	if err := inactivePurchaseError(status); err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE voucher_purchases
-		SET status = $1, redeemed_at = $2
-		WHERE voucher_id = $3`
+		SET status = ?, redeemed_at = ?, redeemed_branch_id = ?, redeemed_by = ?
+		WHERE id = ? AND status = ?`
 
-	result, err := r.db.DB.ExecContext(ctx, query, status, redeemedAt, voucherID)
+	result, err := tx.ExecContext(ctx, query,
+		domain.StatusRedeemed,
+		redemption.RedeemedAt,
+		redemption.BranchID,
+		redemption.StaffID,
+		redemption.PurchaseID,
+		domain.StatusActive,
+	)
 	if err != nil {
 		return fmt.Errorf("failed to update voucher purchase status: %w", err)
 	}
@@ -129,53 +380,214 @@ func (r *VoucherPurchaseRepositorySQL) UpdatePurchaseStatus(ctx context.Context,
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("voucher purchase not found")
+		return domain.ErrAlreadyRedeemed
 	}
 
+	posting, err := redemptionPosting(ctx, tx, redemption)
+	if err != nil {
+		return err
+	}
+
+	return commitWithPosting(ctx, tx, posting)
+}
+
+// inactivePurchaseError returns the error for redeeming a purchase in status, or nil while it is active
+func inactivePurchaseError(status string) error {
+	switch status {
+	case domain.StatusRedeemed:
+		return domain.ErrAlreadyRedeemed
+	case domain.StatusRefunded:
+		return domain.ErrPurchaseRefunded
+	}
 	return nil
 }
 
-// GetUserVouchers retrieves all vouchers purchased by a user with detailed information
-func (r *VoucherPurchaseRepositorySQL) GetUserVouchers(ctx context.Context, userID int64) ([]*domain.UserVoucherResponse, error) {
+// redemptionPosting releases the amount held for a redeemed purchase to the merchant, reading
+// the held balance within tx. Purchases recorded before the ledger hold nothing and get no posting.
+func redemptionPosting(ctx context.Context, tx *sql.Tx, redemption *domain.Redemption) (*domain.LedgerTransaction, error) {
+	transactions, err := queryTransactions(ctx, tx, "t.purchase_id = ?", redemption.PurchaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, found, err := domain.AccountBalance(transactions, domain.AccountMerchantHeld)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read held balance: %w", err)
+	}
+	if !found || balance.IsZero() {
+		return nil, nil
+	}
+
+	// The held account carries a credit balance, so the amount held is its negation
+	posting, err := domain.NewRedemptionTransaction(redemption.PurchaseID, redemption.MerchantID, balance.Neg(), redemption.RedeemedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build redemption posting: %w", err)
+	}
+	return posting, nil
+}
+
+// RefundPurchase marks an unredeemed purchase as refunded together with its ledger posting, if any
+func (r *VoucherPurchaseRepositorySQL) RefundPurchase(ctx context.Context, id uuid.UUID, refundedAt time.Time, posting *domain.LedgerTransaction) error {
+	tx, err := r.db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE voucher_purchases
+		SET status = ?, refunded_at = ?
+		WHERE id = ? AND status = ?`
+
+	result, err := tx.ExecContext(ctx, query, domain.StatusRefunded, refundedAt, id, domain.StatusActive)
+	if err != nil {
+		return fmt.Errorf("failed to refund voucher purchase: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrPurchaseNotFound
+	}
+
+	return commitWithPosting(ctx, tx, posting)
+}
+
+// GetUserVouchers retrieves one page of the vouchers purchased by a user with detailed information
+func (r *VoucherPurchaseRepositorySQL) GetUserVouchers(ctx context.Context, q *domain.UserVoucherQuery) (*domain.UserVoucherPage, error) {
+	// Expiry is derived at read time, so an active purchase of an expired voucher reads as expired
+	const notExpired = "(v.expires_at IS NULL OR v.expires_at > ?)"
+
+	args := []interface{}{q.Now, q.Now, q.BuyerID}
+	conditions := []string{"vp.buyer_id = ?"}
+
+	if len(q.Statuses) > 0 {
+		statusConditions := make([]string, 0, len(q.Statuses))
+		for _, status := range q.Statuses {
+			switch status {
+			case domain.StatusActive:
+				statusConditions = append(statusConditions, "(vp.status = 'active' AND "+notExpired+")")
+				args = append(args, q.Now)
+			case domain.StatusExpired:
+				statusConditions = append(statusConditions, "(vp.status = 'active' AND v.expires_at <= ?)")
+				args = append(args, q.Now)
+			default:
+				statusConditions = append(statusConditions, "vp.status = ?")
+				args = append(args, status)
+			}
+		}
+		conditions = append(conditions, "("+strings.Join(statusConditions, " OR ")+")")
+	}
+
+	if len(q.CategoryIDs) > 0 {
+		placeholders, categoryArgs := int64Placeholders(q.CategoryIDs)
+		conditions = append(conditions, "v.category_id IN ("+placeholders+")")
+		args = append(args, categoryArgs...)
+	}
+	if q.Tag != "" {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM voucher_tags vt WHERE vt.voucher_id = v.id AND vt.tag = ?)")
+		args = append(args, q.Tag)
+	}
+
+	if q.PurchasedFrom != nil {
+		conditions = append(conditions, "vp.created_at >= ?")
+		args = append(args, *q.PurchasedFrom)
+	}
+	if q.PurchasedTo != nil {
+		conditions = append(conditions, "vp.created_at < ?")
+		args = append(args, *q.PurchasedTo)
+	}
+
+	sortColumn, direction, comparison := "vp.created_at", "DESC", "<"
+	switch q.Sort {
+	case domain.SortOldest:
+		direction, comparison = "ASC", ">"
+	case domain.SortPriceAsc:
+		sortColumn, direction, comparison = "v.price", "ASC", ">"
+	case domain.SortPriceDesc:
+		sortColumn = "v.price"
+	}
+
+	if q.After != nil {
+		var key interface{}
+		var err error
+		placeholder := "?"
+		if sortColumn == "v.price" {
+			key, err = q.After.AmountKey()
+			placeholder = "CAST(? AS " + amountType + ")"
+		} else {
+			key, err = q.After.TimeKey()
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		conditions = append(conditions, fmt.Sprintf("(%[1]s %[2]s %[3]s OR (%[1]s = %[3]s AND vp.id %[2]s ?))", sortColumn, comparison, placeholder))
+		args = append(args, key, key, q.After.ID)
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	args = append(args, q.Limit+1)
+
 	query := `
 		SELECT 
 			vp.id,
+			v.id,
 			v.title,
 			v.description,
-			vp.status,
+			v.title_ar,
+			v.description_ar,
+			CASE WHEN vp.status = 'active' AND NOT ` + notExpired + ` THEN 'expired' ELSE vp.status END as status,
 			v.photo_url,
-			CASE WHEN vp.status = 'active' THEN vp.qr_code ELSE NULL END as qr_code,
+			CASE WHEN vp.status = 'active' AND ` + notExpired + ` THEN vp.qr_code ELSE NULL END as qr_code,
 			v.price,
+			v.currency,
+			v.category_id,
+			v.expires_at,
 			vp.created_at,
 			vp.redeemed_at
 		FROM voucher_purchases vp
 		JOIN vouchers v ON vp.voucher_id = v.id
-		WHERE vp.buyer_id = $1
-		ORDER BY vp.created_at DESC`
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY ` + sortColumn + ` ` + direction + `, vp.id ` + direction + `
+		LIMIT ?`
 
-	rows, err := r.db.DB.QueryContext(ctx, query, userID)
+	rows, err := r.db.DB.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query user vouchers: %w", err)
 	}
 	defer rows.Close()
 
-	var userVouchers []*domain.UserVoucherResponse
+	userVouchers := make([]*domain.UserVoucherResponse, 0, q.Limit)
 	for rows.Next() {
 		var voucher domain.UserVoucherResponse
+		var price moneyColumns
 		err := rows.Scan(
 			&voucher.ID,
+			&voucher.VoucherID,
 			&voucher.Title,
 			&voucher.Description,
+			&voucher.TitleAR,
+			&voucher.DescriptionAR,
 			&voucher.Status,
 			&voucher.PhotoURL,
 			&voucher.QRCode,
-			&voucher.Price,
+			&price.amount,
+			&price.currency,
+			&voucher.CategoryID,
+			&voucher.ExpiresAt,
 			&voucher.PurchasedAt,
 			&voucher.RedeemedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan user voucher: %w", err)
 		}
+		if voucher.Price, err = price.money(); err != nil {
+			return nil, err
+		}
 		userVouchers = append(userVouchers, &voucher)
 	}
 
@@ -183,5 +595,31 @@ func (r *VoucherPurchaseRepositorySQL) GetUserVouchers(ctx context.Context, user
 		return nil, fmt.Errorf("error iterating user vouchers: %w", err)
 	}
 
-	return userVouchers, nil
+	page := &domain.UserVoucherPage{Vouchers: userVouchers}
+	if len(userVouchers) > q.Limit {
+		page.Vouchers = userVouchers[:q.Limit]
+	}
+
+	voucherIDs := make([]uuid.UUID, len(page.Vouchers))
+	for i, voucher := range page.Vouchers {
+		voucherIDs[i] = voucher.VoucherID
+	}
+	tags, err := loadVoucherTags(ctx, r.db.DB, voucherIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, voucher := range page.Vouchers {
+		voucher.Tags = tags[voucher.VoucherID]
+	}
+
+	if len(userVouchers) > q.Limit {
+		last := page.Vouchers[q.Limit-1]
+		if sortColumn == "v.price" {
+			page.Next = domain.NewAmountCursor(q.Sort, last.Price, last.ID)
+		} else {
+			page.Next = domain.NewTimeCursor(q.Sort, last.PurchasedAt, last.ID)
+		}
+	}
+
+	return page, nil
 }
\ No newline at end of file
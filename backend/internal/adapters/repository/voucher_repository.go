@@ -4,12 +4,21 @@ import (
         "context"
         "database/sql"
         "fmt"
+        "time"
 
         "4SaleBackendSkeleton/internal/domain"
         "4SaleBackendSkeleton/internal/infrastructure/database"
         "github.com/google/uuid"
 )
 
+// voucherColumns lists the voucher columns in the order scanVoucher expects
+const voucherColumns = `id, adv_id, user_id, title, description, price, currency, photo_url, status, version, expires_at, created_at, updated_at, deleted_at, category_id, title_ar, description_ar`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+        Scan(dest ...interface{}) error
+}
+
 // VoucherRepository implements the voucher repository interface
 type VoucherRepository struct {
         db *database.PostgresDB
@@ -20,20 +29,53 @@ func NewVoucherRepository(db *database.PostgresDB) *VoucherRepository {
         return &VoucherRepository{db: db}
 }
 
-// CreateVoucher creates a new voucher in the database
+// CreateVoucher creates a new voucher and its tags in the database
 func (r *VoucherRepository) CreateVoucher(ctx context.Context, voucher *domain.Voucher) error {
+        return r.CreateVouchers(ctx, []*domain.Voucher{voucher})
+}
+
+// CreateVouchers creates vouchers and their tags in a single transaction; either all are stored or none
+func (r *VoucherRepository) CreateVouchers(ctx context.Context, vouchers []*domain.Voucher) error {
+        tx, err := r.db.DB.BeginTx(ctx, nil)
+        if err != nil {
+                return fmt.Errorf("failed to begin transaction: %w", err)
+        }
+        defer tx.Rollback()
+
+        for _, voucher := range vouchers {
+                if err := insertVoucher(ctx, tx, voucher); err != nil {
+                        return err
+                }
+        }
+
+        if err := tx.Commit(); err != nil {
+                return fmt.Errorf("failed to commit voucher: %w", err)
+        }
+
+        return nil
+}
+
+// insertVoucher inserts a voucher and its tags within tx
+func insertVoucher(ctx context.Context, tx *sql.Tx, voucher *domain.Voucher) error {
         query := `
-                INSERT INTO vouchers (id, adv_id, user_id, title, description, price, photo_url, created_at)
-                VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+                INSERT INTO vouchers (id, adv_id, user_id, title, title_ar, description, description_ar, price, currency, photo_url, category_id, status, version, expires_at, created_at)
+                VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-        _, err := r.db.DB.ExecContext(ctx, query,
+        _, err := tx.ExecContext(ctx, query,
                 voucher.ID,
                 voucher.AdvID,
                 voucher.UserID,
                 voucher.Title,
+                voucher.TitleAR,
                 voucher.Description,
-                voucher.Price,
+                voucher.DescriptionAR,
+                voucher.Price.Decimal(),
+                voucher.Price.Currency,
                 voucher.PhotoURL,
+                voucher.CategoryID,
+                voucher.Status,
+                voucher.Version,
+                voucher.ExpiresAt,
                 voucher.CreatedAt,
         )
 
@@ -41,44 +83,37 @@ func (r *VoucherRepository) CreateVoucher(ctx context.Context, voucher *domain.V
                 return fmt.Errorf("failed to create voucher: %w", err)
         }
 
-        return nil
+        return insertVoucherTags(ctx, tx, voucher.ID, voucher.Tags)
 }
 
 // GetVoucherByID retrieves a voucher by its ID
 func (r *VoucherRepository) GetVoucherByID(ctx context.Context, id uuid.UUID) (*domain.Voucher, error) {
         query := `
-                SELECT id, adv_id, user_id, title, description, price, photo_url, created_at
+                SELECT ` + voucherColumns + `
                 FROM vouchers
-                WHERE id = $1`
-
-        var voucher domain.Voucher
-        err := r.db.DB.QueryRowContext(ctx, query, id).Scan(
-                &voucher.ID,
-                &voucher.AdvID,
-                &voucher.UserID,
-                &voucher.Title,
-                &voucher.Description,
-                &voucher.Price,
-                &voucher.PhotoURL,
-                &voucher.CreatedAt,
-        )
+                WHERE id = ?`
 
+        voucher, err := scanVoucher(r.db.DB.QueryRowContext(ctx, query, id))
         if err != nil {
                 if err == sql.ErrNoRows {
-                        return nil, fmt.Errorf("voucher not found")
+                        return nil, domain.ErrVoucherNotFound
                 }
                 return nil, fmt.Errorf("failed to get voucher: %w", err)
         }
 
-        return &voucher, nil
+        if err := r.attachTags(ctx, voucher); err != nil {
+                return nil, err
+        }
+
+        return voucher, nil
 }
 
 // GetVouchersByUserID retrieves all vouchers for a specific user
 func (r *VoucherRepository) GetVouchersByUserID(ctx context.Context, userID int64) ([]*domain.Voucher, error) {
         query := `
-                SELECT id, adv_id, user_id, title, description, price, photo_url, created_at
+                SELECT ` + voucherColumns + `
                 FROM vouchers
-                WHERE user_id = $1
+                WHERE user_id = ? AND deleted_at IS NULL
                 ORDER BY created_at DESC`
 
         rows, err := r.db.DB.QueryContext(ctx, query, userID)
@@ -89,26 +124,476 @@ func (r *VoucherRepository) GetVouchersByUserID(ctx context.Context, userID int6
 
         var vouchers []*domain.Voucher
         for rows.Next() {
-                var voucher domain.Voucher
-                err := rows.Scan(
-                        &voucher.ID,
-                        &voucher.AdvID,
-                        &voucher.UserID,
-                        &voucher.Title,
-                        &voucher.Description,
-                        &voucher.Price,
-                        &voucher.PhotoURL,
-                        &voucher.CreatedAt,
-                )
+                voucher, err := scanVoucher(rows)
                 if err != nil {
                         return nil, fmt.Errorf("failed to scan voucher: %w", err)
                 }
-                vouchers = append(vouchers, &voucher)
+                vouchers = append(vouchers, voucher)
         }
 
         if err := rows.Err(); err != nil {
                 return nil, fmt.Errorf("error iterating vouchers: %w", err)
         }
 
+        if err := r.attachTags(ctx, vouchers...); err != nil {
+                return nil, err
+        }
+
         return vouchers, nil
+}
+
+// GetMerchantVouchers retrieves a merchant's vouchers with their sales figures, excluding deleted ones
+func (r *VoucherRepository) GetMerchantVouchers(ctx context.Context, userID int64) ([]*domain.MerchantVoucher, error) {
+        query := `
+                SELECT ` + prefixedVoucherColumns + `,
+                        COUNT(vp.id) as sales_count,
+                        COUNT(vp.redeemed_at) as redemption_count
+                FROM vouchers v
+                LEFT JOIN voucher_purchases vp ON vp.voucher_id = v.id
+                WHERE v.user_id = ? AND v.deleted_at IS NULL
+                GROUP BY v.id
+                ORDER BY v.created_at DESC`
+
+        rows, err := r.db.DB.QueryContext(ctx, query, userID)
+        if err != nil {
+                return nil, fmt.Errorf("failed to query merchant vouchers: %w", err)
+        }
+        defer rows.Close()
+
+        vouchers := make([]*domain.MerchantVoucher, 0)
+        for rows.Next() {
+                voucher, err := scanMerchantVoucher(rows)
+                if err != nil {
+                        return nil, fmt.Errorf("failed to scan merchant voucher: %w", err)
+                }
+                vouchers = append(vouchers, voucher)
+        }
+
+        if err := rows.Err(); err != nil {
+                return nil, fmt.Errorf("error iterating merchant vouchers: %w", err)
+        }
+
+        if err := r.attachMerchantTags(ctx, vouchers); err != nil {
+                return nil, err
+        }
+
+        return vouchers, nil
+}
+
+// GetMerchantVoucherByID retrieves a non-deleted voucher with its sales figures
+func (r *VoucherRepository) GetMerchantVoucherByID(ctx context.Context, id uuid.UUID) (*domain.MerchantVoucher, error) {
+        query := `
+                SELECT ` + prefixedVoucherColumns + `,
+                        COUNT(vp.id) as sales_count,
+                        COUNT(vp.redeemed_at) as redemption_count
+                FROM vouchers v
+                LEFT JOIN voucher_purchases vp ON vp.voucher_id = v.id
+                WHERE v.id = ? AND v.deleted_at IS NULL
+                GROUP BY v.id`
+
+        voucher, err := scanMerchantVoucher(r.db.DB.QueryRowContext(ctx, query, id))
+        if err != nil {
+                if err == sql.ErrNoRows {
+                        return nil, domain.ErrVoucherNotFound
+                }
+                return nil, fmt.Errorf("failed to get merchant voucher: %w", err)
+        }
+
+        if err := r.attachTags(ctx, &voucher.Voucher); err != nil {
+                return nil, err
+        }
+
+        return voucher, nil
+}
+
+// UpdateVoucher saves the editable fields of a voucher together with the change history
+// if it is still at expectedVersion and has not been sold, bumping its version
+func (r *VoucherRepository) UpdateVoucher(ctx context.Context, voucher *domain.Voucher, expectedVersion int, changes []*domain.VoucherChange) error {
+        tx, err := r.db.DB.BeginTx(ctx, nil)
+        if err != nil {
+                return fmt.Errorf("failed to begin transaction: %w", err)
+        }
+        defer tx.Rollback()
+
+        query := `
+                UPDATE vouchers
+                SET title = ?, title_ar = ?, description = ?, description_ar = ?, price = ?, currency = ?, photo_url = ?, version = version + 1, updated_at = ?
+                WHERE id = ? AND version = ? AND deleted_at IS NULL
+                        AND NOT EXISTS (SELECT 1 FROM voucher_purchases vp WHERE vp.voucher_id = vouchers.id)`
+
+        result, err := tx.ExecContext(ctx, query,
+                voucher.Title,
+                voucher.TitleAR,
+                voucher.Description,
+                voucher.DescriptionAR,
+                voucher.Price.Decimal(),
+                voucher.Price.Currency,
+                voucher.PhotoURL,
+                voucher.UpdatedAt,
+                voucher.ID,
+                expectedVersion,
+        )
+        if err != nil {
+                return fmt.Errorf("failed to update voucher: %w", err)
+        }
+
+        rowsAffected, err := result.RowsAffected()
+        if err != nil {
+                return fmt.Errorf("failed to get rows affected: %w", err)
+        }
+
+        if rowsAffected == 0 {
+                return r.updateConflict(ctx, voucher.ID, expectedVersion)
+        }
+
+        if err := recordVoucherChanges(ctx, tx, changes); err != nil {
+                return err
+        }
+
+        if err := tx.Commit(); err != nil {
+                return fmt.Errorf("failed to commit voucher update: %w", err)
+        }
+
+        voucher.Version = expectedVersion + 1
+        return nil
+}
+
+// UpdateVoucherStatus sets the sale status of a non-deleted voucher together with the change history
+func (r *VoucherRepository) UpdateVoucherStatus(ctx context.Context, id uuid.UUID, status string, updatedAt time.Time, changes []*domain.VoucherChange) error {
+        tx, err := r.db.DB.BeginTx(ctx, nil)
+        if err != nil {
+                return fmt.Errorf("failed to begin transaction: %w", err)
+        }
+        defer tx.Rollback()
+
+        query := `
+                UPDATE vouchers
+                SET status = ?, version = version + 1, updated_at = ?
+                WHERE id = ? AND deleted_at IS NULL`
+
+        result, err := tx.ExecContext(ctx, query, status, updatedAt, id)
+        if err != nil {
+                return fmt.Errorf("failed to update voucher status: %w", err)
+        }
+
+        rowsAffected, err := result.RowsAffected()
+        if err != nil {
+                return fmt.Errorf("failed to get rows affected: %w", err)
+        }
+
+        if rowsAffected == 0 {
+                return domain.ErrVoucherNotFound
+        }
+
+        if err := recordVoucherChanges(ctx, tx, changes); err != nil {
+                return err
+        }
+
+        if err := tx.Commit(); err != nil {
+                return fmt.Errorf("failed to commit voucher status: %w", err)
+        }
+
+        return nil
+}
+
+// DeleteVoucher soft-deletes a voucher together with the change history, so existing
+// purchases keep their details
+func (r *VoucherRepository) DeleteVoucher(ctx context.Context, id uuid.UUID, deletedAt time.Time, changes []*domain.VoucherChange) error {
+        tx, err := r.db.DB.BeginTx(ctx, nil)
+        if err != nil {
+                return fmt.Errorf("failed to begin transaction: %w", err)
+        }
+        defer tx.Rollback()
+
+        query := `
+                UPDATE vouchers
+                SET deleted_at = ?, updated_at = ?
+                WHERE id = ? AND deleted_at IS NULL`
+
+        result, err := tx.ExecContext(ctx, query, deletedAt, deletedAt, id)
+        if err != nil {
+                return fmt.Errorf("failed to delete voucher: %w", err)
+        }
+
+        rowsAffected, err := result.RowsAffected()
+        if err != nil {
+                return fmt.Errorf("failed to get rows affected: %w", err)
+        }
+
+        if rowsAffected == 0 {
+                return domain.ErrVoucherNotFound
+        }
+
+        if err := recordVoucherChanges(ctx, tx, changes); err != nil {
+                return err
+        }
+
+        if err := tx.Commit(); err != nil {
+                return fmt.Errorf("failed to commit voucher deletion: %w", err)
+        }
+
+        return nil
+}
+
+// GetVoucherByAdvID retrieves the most recent voucher created for a 4Sale ad, including deleted ones
+func (r *VoucherRepository) GetVoucherByAdvID(ctx context.Context, advID int64) (*domain.Voucher, error) {
+        query := `
+                SELECT ` + voucherColumns + `
+                FROM vouchers
+                WHERE adv_id = ?
+                ORDER BY created_at DESC
+                LIMIT 1`
+
+        voucher, err := scanVoucher(r.db.DB.QueryRowContext(ctx, query, advID))
+        if err != nil {
+                if err == sql.ErrNoRows {
+                        return nil, domain.ErrVoucherNotFound
+                }
+                return nil, fmt.Errorf("failed to get voucher by adv_id: %w", err)
+        }
+
+        if err := r.attachTags(ctx, voucher); err != nil {
+                return nil, err
+        }
+
+        return voucher, nil
+}
+
+// ApplyVoucherChanges saves the listing fields and deletion time of a voucher together with
+// the change history, bumping its version. Only a non-deleted voucher still at expectedVersion
+// is written, so changes made since it was read are never overwritten.
+func (r *VoucherRepository) ApplyVoucherChanges(ctx context.Context, voucher *domain.Voucher, expectedVersion int, changes []*domain.VoucherChange) error {
+        tx, err := r.db.DB.BeginTx(ctx, nil)
+        if err != nil {
+                return fmt.Errorf("failed to begin transaction: %w", err)
+        }
+        defer tx.Rollback()
+
+        query := `
+                UPDATE vouchers
+                SET title = ?, title_ar = ?, description = ?, description_ar = ?, price = ?, currency = ?, photo_url = ?, deleted_at = ?, version = version + 1, updated_at = ?
+                WHERE id = ? AND version = ? AND deleted_at IS NULL`
+
+        result, err := tx.ExecContext(ctx, query,
+                voucher.Title,
+                voucher.TitleAR,
+                voucher.Description,
+                voucher.DescriptionAR,
+                voucher.Price.Decimal(),
+                voucher.Price.Currency,
+                voucher.PhotoURL,
+                voucher.DeletedAt,
+                voucher.UpdatedAt,
+                voucher.ID,
+                expectedVersion,
+        )
+        if err != nil {
+                return fmt.Errorf("failed to update voucher: %w", err)
+        }
+
+        rowsAffected, err := result.RowsAffected()
+        if err != nil {
+                return fmt.Errorf("failed to get rows affected: %w", err)
+        }
+
+        if rowsAffected == 0 {
+                var exists bool
+                err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM vouchers WHERE id = ?)`, voucher.ID).Scan(&exists)
+                if err != nil {
+                        return fmt.Errorf("failed to check voucher: %w", err)
+                }
+                if !exists {
+                        return domain.ErrVoucherNotFound
+                }
+                return domain.ErrVersionConflict
+        }
+
+        if err := recordVoucherChanges(ctx, tx, changes); err != nil {
+                return err
+        }
+
+        if err := tx.Commit(); err != nil {
+                return fmt.Errorf("failed to commit voucher changes: %w", err)
+        }
+
+        voucher.Version = expectedVersion + 1
+        return nil
+}
+
+// GetVoucherChanges retrieves the change history of a voucher, oldest first
+func (r *VoucherRepository) GetVoucherChanges(ctx context.Context, voucherID uuid.UUID) ([]*domain.VoucherChange, error) {
+        query := `
+                SELECT id, voucher_id, source, field, old_value, new_value, changed_at
+                FROM voucher_changes
+                WHERE voucher_id = ?
+                ORDER BY changed_at ASC`
+
+        rows, err := r.db.DB.QueryContext(ctx, query, voucherID)
+        if err != nil {
+                return nil, fmt.Errorf("failed to query voucher changes: %w", err)
+        }
+        defer rows.Close()
+
+        changes := make([]*domain.VoucherChange, 0)
+        for rows.Next() {
+                var change domain.VoucherChange
+                err := rows.Scan(
+                        &change.ID,
+                        &change.VoucherID,
+                        &change.Source,
+                        &change.Field,
+                        &change.OldValue,
+                        &change.NewValue,
+                        &change.ChangedAt,
+                )
+                if err != nil {
+                        return nil, fmt.Errorf("failed to scan voucher change: %w", err)
+                }
+                changes = append(changes, &change)
+        }
+
+        if err := rows.Err(); err != nil {
+                return nil, fmt.Errorf("error iterating voucher changes: %w", err)
+        }
+
+        return changes, nil
+}
+
+// recordVoucherChanges adds entries to the change history of vouchers
+func recordVoucherChanges(ctx context.Context, e execer, changes []*domain.VoucherChange) error {
+        query := `
+                INSERT INTO voucher_changes (id, voucher_id, source, field, old_value, new_value, changed_at)
+                VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+        for _, change := range changes {
+                _, err := e.ExecContext(ctx, query,
+                        change.ID,
+                        change.VoucherID,
+                        change.Source,
+                        change.Field,
+                        change.OldValue,
+                        change.NewValue,
+                        change.ChangedAt,
+                )
+                if err != nil {
+                        return fmt.Errorf("failed to record voucher change: %w", err)
+                }
+        }
+
+        return nil
+}
+
+// updateConflict works out why a guarded voucher update matched no rows
+func (r *VoucherRepository) updateConflict(ctx context.Context, id uuid.UUID, expectedVersion int) error {
+        current, err := r.GetMerchantVoucherByID(ctx, id)
+        if err != nil {
+                return err
+        }
+
+        if current.SalesCount > 0 {
+                return domain.ErrVoucherSold
+        }
+        if current.Version != expectedVersion {
+                return domain.ErrVersionConflict
+        }
+
+        return fmt.Errorf("failed to update voucher %s", id)
+}
+
+// attachTags loads the tags of the vouchers
+func (r *VoucherRepository) attachTags(ctx context.Context, vouchers ...*domain.Voucher) error {
+        ids := make([]uuid.UUID, len(vouchers))
+        for i, voucher := range vouchers {
+                ids[i] = voucher.ID
+        }
+
+        tags, err := loadVoucherTags(ctx, r.db.DB, ids)
+        if err != nil {
+                return err
+        }
+
+        for _, voucher := range vouchers {
+                voucher.Tags = tags[voucher.ID]
+        }
+        return nil
+}
+
+// attachMerchantTags loads the tags of the merchant vouchers
+func (r *VoucherRepository) attachMerchantTags(ctx context.Context, vouchers []*domain.MerchantVoucher) error {
+        embedded := make([]*domain.Voucher, len(vouchers))
+        for i, voucher := range vouchers {
+                embedded[i] = &voucher.Voucher
+        }
+        return r.attachTags(ctx, embedded...)
+}
+
+// prefixedVoucherColumns lists voucherColumns qualified with the v alias
+const prefixedVoucherColumns = `v.id, v.adv_id, v.user_id, v.title, v.description, v.price, v.currency, v.photo_url, v.status, v.version, v.expires_at, v.created_at, v.updated_at, v.deleted_at, v.category_id, v.title_ar, v.description_ar`
+
+// scanVoucher scans a row selected with voucherColumns
+func scanVoucher(row rowScanner) (*domain.Voucher, error) {
+        var voucher domain.Voucher
+        var price moneyColumns
+        err := row.Scan(
+                &voucher.ID,
+                &voucher.AdvID,
+                &voucher.UserID,
+                &voucher.Title,
+                &voucher.Description,
+                &price.amount,
+                &price.currency,
+                &voucher.PhotoURL,
+                &voucher.Status,
+                &voucher.Version,
+                &voucher.ExpiresAt,
+                &voucher.CreatedAt,
+                &voucher.UpdatedAt,
+                &voucher.DeletedAt,
+                &voucher.CategoryID,
+                &voucher.TitleAR,
+                &voucher.DescriptionAR,
+        )
+        if err != nil {
+                return nil, err
+        }
+        if voucher.Price, err = price.money(); err != nil {
+                return nil, err
+        }
+
+        return &voucher, nil
+}
+
+// scanMerchantVoucher scans a row selected with prefixedVoucherColumns followed by the sales figures
+func scanMerchantVoucher(row rowScanner) (*domain.MerchantVoucher, error) {
+        var voucher domain.MerchantVoucher
+        var price moneyColumns
+        err := row.Scan(
+                &voucher.ID,
+                &voucher.AdvID,
+                &voucher.UserID,
+                &voucher.Title,
+                &voucher.Description,
+                &price.amount,
+                &price.currency,
+                &voucher.PhotoURL,
+                &voucher.Status,
+                &voucher.Version,
+                &voucher.ExpiresAt,
+                &voucher.CreatedAt,
+                &voucher.UpdatedAt,
+                &voucher.DeletedAt,
+                &voucher.CategoryID,
+                &voucher.TitleAR,
+                &voucher.DescriptionAR,
+                &voucher.SalesCount,
+                &voucher.RedemptionCount,
+        )
+        if err != nil {
+                return nil, err
+        }
+        if voucher.Price, err = price.money(); err != nil {
+                return nil, err
+        }
+
+        return &voucher, nil
 }
\ No newline at end of file
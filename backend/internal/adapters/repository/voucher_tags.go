@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// insertVoucherTags stores the tags of a voucher within tx
+func insertVoucherTags(ctx context.Context, tx *sql.Tx, voucherID uuid.UUID, tags []string) error {
+	query := `INSERT INTO voucher_tags (voucher_id, tag) VALUES (?, ?)`
+
+	for _, tag := range tags {
+		if _, err := tx.ExecContext(ctx, query, voucherID, tag); err != nil {
+			return fmt.Errorf("failed to insert voucher tag: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// loadVoucherTags returns the tags of the given vouchers keyed by voucher ID.
+// Every requested voucher has an entry, empty if it has no tags.
+func loadVoucherTags(ctx context.Context, db *sql.DB, voucherIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	tags := make(map[uuid.UUID][]string, len(voucherIDs))
+	if len(voucherIDs) == 0 {
+		return tags, nil
+	}
+
+	placeholders := make([]string, len(voucherIDs))
+	args := make([]interface{}, len(voucherIDs))
+	for i, id := range voucherIDs {
+		tags[id] = []string{}
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	query := `
+		SELECT voucher_id, tag
+		FROM voucher_tags
+		WHERE voucher_id IN (` + strings.Join(placeholders, ", ") + `)
+		ORDER BY tag`
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query voucher tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var voucherID uuid.UUID
+		var tag string
+		if err := rows.Scan(&voucherID, &tag); err != nil {
+			return nil, fmt.Errorf("failed to scan voucher tag: %w", err)
+		}
+		tags[voucherID] = append(tags[voucherID], tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating voucher tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// int64Placeholders returns a placeholder list and its arguments for an IN clause
+func int64Placeholders(values []int64) (string, []interface{}) {
+	placeholders := make([]string, len(values))
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		placeholders[i] = "?"
+		args[i] = value
+	}
+	return strings.Join(placeholders, ", "), args
+}
@@ -2,6 +2,7 @@ package app
 
 import (
         "context"
+        "database/sql"
         "fmt"
         "net/http"
         "os"
@@ -11,11 +12,20 @@ import (
 
         "4SaleBackendSkeleton/internal/adapters/handlers"
         "4SaleBackendSkeleton/internal/adapters/repository"
+        "4SaleBackendSkeleton/internal/application/jobs"
         "4SaleBackendSkeleton/internal/application/services"
+        "4SaleBackendSkeleton/internal/domain"
+        "4SaleBackendSkeleton/internal/infrastructure/alerts"
+        "4SaleBackendSkeleton/internal/infrastructure/auth"
         "4SaleBackendSkeleton/internal/infrastructure/config"
         "4SaleBackendSkeleton/internal/infrastructure/database"
         "4SaleBackendSkeleton/internal/infrastructure/logger"
+        "4SaleBackendSkeleton/internal/infrastructure/notify"
         "4SaleBackendSkeleton/internal/infrastructure/qr"
+        "4SaleBackendSkeleton/internal/infrastructure/rates"
+        "4SaleBackendSkeleton/internal/infrastructure/realtime"
+        "4SaleBackendSkeleton/internal/ports"
+        "github.com/gorilla/mux"
         "github.com/rs/zerolog"
 )
 
@@ -25,6 +35,10 @@ type App struct {
         db     *database.PostgresDB
         server *http.Server
         logger zerolog.Logger
+        // stopJobs cancels the background jobs started by Routes
+        stopJobs context.CancelFunc
+        // eventHub feeds the real-time event streams, which are ended on Shutdown
+        eventHub *realtime.Hub
 }
 
 // NewApp creates a new application instance
@@ -51,32 +65,40 @@ func NewApp() (*App, error) {
         }, nil
 }
 
-// Run starts the application
-func (a *App) Run() error {
-        // Initialize dependencies
-        qrGenerator := qr.NewQRGenerator()
-
-        // Initialize repositories
-        voucherRepo := repository.NewVoucherRepository(a.db)
-        voucherPurchaseRepo := repository.NewVoucherPurchaseRepositorySQL(a.db)
+// NewAppWithDB creates an application instance on a database connection opened by the caller
+func NewAppWithDB(db *sql.DB) (*App, error) {
+        cfg, err := config.Load()
+        if err != nil {
+                return nil, fmt.Errorf("failed to load config: %w", err)
+        }
 
-        // Initialize services
-        voucherService := services.NewVoucherService(voucherRepo, voucherPurchaseRepo, qrGenerator)
+        return &App{
+                config: cfg,
+                db:     &database.PostgresDB{DB: db},
+                logger: logger.NewLogger(),
+        }, nil
+}
 
-        // Initialize handlers
-        voucherHandler := handlers.NewVoucherHandler(voucherService, a.logger)
+// Run starts the application
+func (a *App) Run() error {
+        // Initialize session tokens
+        tokenIssuer, err := a.newTokenIssuer()
+        if err != nil {
+                return fmt.Errorf("failed to initialize session tokens: %w", err)
+        }
 
-        // Initialize router
-        router := handlers.NewRouter(voucherHandler, a.logger)
-        routes := router.SetupRoutes()
+        routes, err := a.Routes(tokenIssuer)
+        if err != nil {
+                return err
+        }
 
         // Create HTTP server
         a.server = &http.Server{
-                Addr:         fmt.Sprintf("%s:%s", a.config.Server.Host, a.config.Server.Port),
-                Handler:      routes,
-                ReadTimeout:  15 * time.Second,
-                WriteTimeout: 15 * time.Second,
-                IdleTimeout:  60 * time.Second,
+                Addr:        fmt.Sprintf("%s:%s", a.config.Server.Host, a.config.Server.Port),
+                Handler:     routes,
+                ReadTimeout: 15 * time.Second,
+                // No WriteTimeout: event streams stay open for as long as clients listen
+                IdleTimeout: 60 * time.Second,
         }
 
         // Start server in a goroutine
@@ -104,11 +126,259 @@ func (a *App) Run() error {
         return a.Shutdown()
 }
 
+// Routes wires the services and returns the HTTP routes of the voucher system, whose
+// sessions are validated by tokenIssuer. It starts the background jobs.
+func (a *App) Routes(tokenIssuer *auth.TokenIssuer) (*mux.Router, error) {
+        // Initialize dependencies
+        qrGenerator := qr.NewQRGenerator()
+
+        // Initialize repositories
+        voucherRepo := repository.NewVoucherRepository(a.db)
+        voucherPurchaseRepo := repository.NewVoucherPurchaseRepositorySQL(a.db)
+        catalogueRepo := repository.NewCatalogueRepository(a.db)
+        categoryRepo := repository.NewCategoryRepository(a.db)
+        ledgerRepo := repository.NewLedgerRepository(a.db)
+        analyticsRepo := repository.NewAnalyticsRepository(a.db)
+        exportRepo := repository.NewExportRepository(a.db)
+        codePoolRepo := repository.NewCodePoolRepository(a.db)
+        transferRepo := repository.NewTransferRepository(a.db)
+        promoCodeRepo := repository.NewPromoCodeRepository(a.db)
+        reservationRepo := repository.NewReservationRepository(a.db)
+        fraudRepo := repository.NewFraudRepository(a.db)
+        auditRepo := repository.NewAuditRepository(a.db)
+        notificationRepo := repository.NewNotificationRepository(a.db)
+        redemptionRepo := repository.NewRedemptionRepository(a.db)
+
+        // Initialize services
+        refundPolicy := domain.RefundPolicy(a.config.Listing.DeletionRefundPolicy)
+        if !refundPolicy.IsValid() {
+                return nil, fmt.Errorf("invalid LISTING_DELETION_REFUND_POLICY %q", refundPolicy)
+        }
+        if !domain.IsSupportedCurrency(a.config.Rates.ReportingCurrency) {
+                return nil, fmt.Errorf("unsupported REPORTING_CURRENCY %q", a.config.Rates.ReportingCurrency)
+        }
+        rateProvider, err := rates.NewStaticProvider(a.config.Rates.File)
+        if err != nil {
+                return nil, fmt.Errorf("failed to load exchange rates: %w", err)
+        }
+        if a.config.CodePool.LowThreshold < 0 {
+                return nil, fmt.Errorf("invalid CODE_POOL_LOW_THRESHOLD %d", a.config.CodePool.LowThreshold)
+        }
+        codePoolLowThreshold := int64(a.config.CodePool.LowThreshold)
+        alerter := alerts.NewLogAlerter(a.logger)
+        if a.config.Checkout.HoldTTL <= 0 {
+                return nil, fmt.Errorf("invalid RESERVATION_HOLD_TTL %s", a.config.Checkout.HoldTTL)
+        }
+        fraudRules, err := a.fraudRules()
+        if err != nil {
+                return nil, err
+        }
+        fraudService := services.NewFraudService(fraudRepo, fraudRules)
+        auditService := services.NewAuditService(auditRepo, a.config.Audit.StaffUserIDs, a.logger)
+        notificationChannels, err := a.notificationChannels()
+        if err != nil {
+                return nil, err
+        }
+        if a.config.Notify.ReminderLead <= 0 {
+                return nil, fmt.Errorf("invalid NOTIFY_EXPIRY_REMINDER_LEAD %s", a.config.Notify.ReminderLead)
+        }
+        notificationService := services.NewNotificationService(notificationRepo, auditService, notificationChannels, a.config.Notify.ReminderLead, a.logger)
+        if a.config.Realtime.HistorySize <= 0 {
+                return nil, fmt.Errorf("invalid REALTIME_HISTORY_SIZE %d", a.config.Realtime.HistorySize)
+        }
+        if a.config.Realtime.Retention <= 0 {
+                return nil, fmt.Errorf("invalid REALTIME_RETENTION %s", a.config.Realtime.Retention)
+        }
+        eventHub := realtime.NewHub(a.config.Realtime.HistorySize, a.config.Realtime.Retention)
+        a.eventHub = eventHub
+        voucherService := services.NewVoucherService(voucherRepo, voucherPurchaseRepo, categoryRepo, ledgerRepo, codePoolRepo, promoCodeRepo, reservationRepo, qrGenerator, rateProvider, alerter, fraudService, auditService, notificationService, eventHub, refundPolicy, a.config.Rates.ReportingCurrency, codePoolLowThreshold, a.config.Checkout.HoldTTL)
+        merchantVoucherService := services.NewMerchantVoucherService(voucherRepo, codePoolRepo, auditService, codePoolLowThreshold)
+        if a.config.Import.BatchSize <= 0 {
+                return nil, fmt.Errorf("invalid IMPORT_BATCH_SIZE %d", a.config.Import.BatchSize)
+        }
+        voucherImportService := services.NewVoucherImportService(voucherRepo, categoryRepo, auditService, a.config.Import.BatchSize, a.logger)
+        catalogueService := services.NewCatalogueService(catalogueRepo, categoryRepo)
+        categoryService := services.NewCategoryService(categoryRepo)
+        ledgerService := services.NewLedgerService(ledgerRepo, auditService)
+        analyticsService := services.NewAnalyticsService(analyticsRepo, a.config.Analytics.RollupEnabled)
+        exportService := services.NewExportService(exportRepo, a.config.Export.StaffUserIDs)
+        redemptionService := services.NewRedemptionService(redemptionRepo)
+        if a.config.Transfer.TTL <= 0 {
+                return nil, fmt.Errorf("invalid TRANSFER_TTL %s", a.config.Transfer.TTL)
+        }
+        promoService := services.NewPromoService(promoCodeRepo, auditService)
+        transferService := services.NewTransferService(voucherRepo, voucherPurchaseRepo, transferRepo, codePoolRepo, qrGenerator, auditService, a.config.Transfer.TTL)
+
+        // Initialize handlers
+        voucherHandler := handlers.NewVoucherHandler(voucherService, a.logger)
+        merchantVoucherHandler := handlers.NewMerchantVoucherHandler(merchantVoucherService, a.logger)
+        voucherImportHandler := handlers.NewVoucherImportHandler(voucherImportService, a.logger)
+        catalogueHandler := handlers.NewCatalogueHandler(catalogueService, a.logger)
+        categoryHandler := handlers.NewCategoryHandler(categoryService, a.logger)
+        ledgerHandler := handlers.NewLedgerHandler(ledgerService, a.logger)
+        analyticsHandler := handlers.NewAnalyticsHandler(analyticsService, a.logger)
+        exportHandler := handlers.NewExportHandler(exportService, a.logger)
+        transferHandler := handlers.NewTransferHandler(transferService, a.logger)
+        promoHandler := handlers.NewPromoHandler(promoService, a.logger)
+        auditHandler := handlers.NewAuditHandler(auditService, a.logger)
+        notificationHandler := handlers.NewNotificationHandler(notificationService, a.logger)
+        if a.config.Realtime.Heartbeat <= 0 {
+                return nil, fmt.Errorf("invalid REALTIME_HEARTBEAT_INTERVAL %s", a.config.Realtime.Heartbeat)
+        }
+        eventHandler := handlers.NewEventHandler(eventHub, a.config.Realtime.Heartbeat, a.logger)
+        redemptionHandler := handlers.NewRedemptionHandler(redemptionService, a.logger)
+
+        // Money-moving webhooks are refused until the marketplace's signing secret is configured
+        webhookSecret := []byte(a.config.Webhook.SigningSecret)
+        if len(webhookSecret) == 0 {
+                a.logger.Warn().Msg("WEBHOOK_SIGNING_SECRET not set, signed webhooks will be refused")
+        }
+
+        // Initialize router
+        router := handlers.NewRouter(voucherHandler, merchantVoucherHandler, voucherImportHandler, catalogueHandler, categoryHandler, ledgerHandler, analyticsHandler, exportHandler, transferHandler, promoHandler, auditHandler, notificationHandler, eventHandler, redemptionHandler, tokenIssuer, webhookSecret, a.logger)
+
+        // Start background jobs
+        if err := a.startJobs(analyticsRepo, reservationRepo, notificationService, eventHub); err != nil {
+                return nil, err
+        }
+
+        return router.SetupRoutes(), nil
+}
+
+// newTokenIssuer creates the issuer that validates session tokens locally
+func (a *App) newTokenIssuer() (*auth.TokenIssuer, error) {
+        signingKey := []byte(a.config.Auth.SigningKey)
+        if len(signingKey) == 0 {
+                a.logger.Warn().Msg("SESSION_SIGNING_KEY not set, using a random key; sessions will not survive restarts")
+
+                var err error
+                signingKey, err = auth.GenerateSigningKey()
+                if err != nil {
+                        return nil, err
+                }
+        }
+
+        return auth.NewTokenIssuer(auth.TokenConfig{
+                SigningKey: signingKey,
+                Issuer:     a.config.Auth.Issuer,
+                AccessTTL:  a.config.Auth.AccessTTL,
+                RefreshTTL: a.config.Auth.RefreshTTL,
+        }, auth.NewMemorySessionStore())
+}
+
+// fraudRules builds the fraud rules from the configuration
+func (a *App) fraudRules() (domain.FraudRules, error) {
+        cfg := a.config.Fraud
+        actions := []struct {
+                env    string
+                action string
+        }{
+                {"FRAUD_PURCHASE_VELOCITY_ACTION", cfg.PurchaseVelocityAction},
+                {"FRAUD_SELF_REDEMPTION_ACTION", cfg.SelfRedemptionAction},
+                {"FRAUD_QUICK_REDEMPTION_ACTION", cfg.QuickRedemptionAction},
+                {"FRAUD_FAILED_LOOKUPS_ACTION", cfg.FailedLookupsAction},
+        }
+        for _, c := range actions {
+                if !domain.IsFraudAction(c.action) {
+                        return domain.FraudRules{}, fmt.Errorf("invalid %s %q", c.env, c.action)
+                }
+        }
+        if cfg.PurchaseVelocityMax < 0 {
+                return domain.FraudRules{}, fmt.Errorf("invalid FRAUD_PURCHASE_VELOCITY_MAX %d", cfg.PurchaseVelocityMax)
+        }
+        if cfg.FailedLookupsMax < 0 {
+                return domain.FraudRules{}, fmt.Errorf("invalid FRAUD_FAILED_LOOKUPS_MAX %d", cfg.FailedLookupsMax)
+        }
+
+        return domain.FraudRules{
+                PurchaseVelocityMax:    int64(cfg.PurchaseVelocityMax),
+                PurchaseVelocityWindow: cfg.PurchaseVelocityWindow,
+                PurchaseVelocityAction: cfg.PurchaseVelocityAction,
+                SelfRedemptionAction:   cfg.SelfRedemptionAction,
+                QuickRedemptionWindow:  cfg.QuickRedemptionWindow,
+                QuickRedemptionAction:  cfg.QuickRedemptionAction,
+                FailedLookupsMax:       int64(cfg.FailedLookupsMax),
+                FailedLookupsWindow:    cfg.FailedLookupsWindow,
+                FailedLookupsAction:    cfg.FailedLookupsAction,
+        }, nil
+}
+
+// notificationChannels creates the notifier of each channel. Channels without a gateway
+// or SMTP host configured are logged, to NOTIFY_LOG_FILE when it is set.
+func (a *App) notificationChannels() (map[string]ports.Notifier, error) {
+        cfg := a.config.Notify
+        logNotifier := notify.NewLogNotifier(a.logger)
+        if cfg.LogFile != "" {
+                file, err := os.OpenFile(cfg.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+                if err != nil {
+                        return nil, fmt.Errorf("failed to open NOTIFY_LOG_FILE: %w", err)
+                }
+                logNotifier = notify.NewLogNotifier(zerolog.New(file).With().Timestamp().Logger())
+        }
+
+        channels := map[string]ports.Notifier{
+                domain.ChannelPush:  logNotifier,
+                domain.ChannelSMS:   logNotifier,
+                domain.ChannelEmail: logNotifier,
+        }
+        if cfg.PushURL != "" {
+                channels[domain.ChannelPush] = notify.NewPushNotifier(cfg.PushURL, cfg.PushAPIKey)
+        }
+        if cfg.SMSURL != "" {
+                channels[domain.ChannelSMS] = notify.NewSMSNotifier(cfg.SMSURL, cfg.SMSAPIKey)
+        }
+        if cfg.SMTPHost != "" {
+                channels[domain.ChannelEmail] = notify.NewEmailNotifier(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.EmailFrom)
+        }
+
+        return channels, nil
+}
+
+// startJobs starts the enabled background jobs; they stop on Shutdown
+func (a *App) startJobs(analyticsRepo *repository.AnalyticsRepository, reservationRepo *repository.ReservationRepository, notificationService *services.NotificationService, eventHub *realtime.Hub) error {
+        ctx, cancel := context.WithCancel(context.Background())
+        a.stopJobs = cancel
+
+        if a.config.Analytics.RollupEnabled {
+                if a.config.Analytics.RollupInterval <= 0 {
+                        return fmt.Errorf("invalid ANALYTICS_ROLLUP_INTERVAL %s", a.config.Analytics.RollupInterval)
+                }
+                rollup := jobs.NewAnalyticsRollup(analyticsRepo, a.logger)
+                go jobs.RunEvery(ctx, "analytics_rollup", a.config.Analytics.RollupInterval, a.logger, rollup.Refresh)
+        }
+
+        if a.config.Checkout.ReleaseInterval <= 0 {
+                return fmt.Errorf("invalid RESERVATION_RELEASE_INTERVAL %s", a.config.Checkout.ReleaseInterval)
+        }
+        release := jobs.NewReservationRelease(reservationRepo, a.logger)
+        go jobs.RunEvery(ctx, "reservation_release", a.config.Checkout.ReleaseInterval, a.logger, release.Release)
+
+        if a.config.Notify.ReminderInterval <= 0 {
+                return fmt.Errorf("invalid NOTIFY_EXPIRY_REMINDER_INTERVAL %s", a.config.Notify.ReminderInterval)
+        }
+        reminder := jobs.NewExpiryReminder(notificationService)
+        go jobs.RunEvery(ctx, "expiry_reminder", a.config.Notify.ReminderInterval, a.logger, reminder.Remind)
+
+        go jobs.RunEvery(ctx, "event_hub_sweep", a.config.Realtime.Retention, a.logger, eventHub.Sweep)
+
+        return nil
+}
+
 // Shutdown gracefully shuts down the application
 func (a *App) Shutdown() error {
         ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
         defer cancel()
 
+        // Stop background jobs
+        if a.stopJobs != nil {
+                a.stopJobs()
+        }
+
+        // End event streams so the server does not wait on them; clients reconnect elsewhere
+        if a.eventHub != nil {
+                a.eventHub.Close()
+        }
+
         // Shutdown HTTP server
         if err := a.server.Shutdown(ctx); err != nil {
                 a.logger.Error().Err(err).Msg("Server forced to shutdown")
@@ -0,0 +1,58 @@
+package dto
+
+// ErrorCode is a stable machine-readable error identifier
+type ErrorCode string
+
+// Error codes returned in ErrorDetail.Code
+const (
+	ErrorCodeInvalidRequest     ErrorCode = "INVALID_REQUEST"
+	ErrorCodeValidationFailed   ErrorCode = "VALIDATION_FAILED"
+	ErrorCodeInvalidCursor      ErrorCode = "INVALID_CURSOR"
+	ErrorCodeVoucherNotFound    ErrorCode = "VOUCHER_NOT_FOUND"
+	ErrorCodePurchaseNotFound   ErrorCode = "PURCHASE_NOT_FOUND"
+	ErrorCodeAlreadyPurchased   ErrorCode = "ALREADY_PURCHASED"
+	ErrorCodeAlreadyRedeemed    ErrorCode = "ALREADY_REDEEMED"
+	ErrorCodePurchaseRefunded   ErrorCode = "PURCHASE_REFUNDED"
+	ErrorCodeQRCodeMismatch     ErrorCode = "QR_CODE_MISMATCH"
+	ErrorCodeVoucherExpired     ErrorCode = "VOUCHER_EXPIRED"
+	ErrorCodeVoucherUnavailable ErrorCode = "VOUCHER_UNAVAILABLE"
+	ErrorCodeVoucherSold        ErrorCode = "VOUCHER_ALREADY_SOLD"
+	ErrorCodeVersionConflict    ErrorCode = "VERSION_CONFLICT"
+	ErrorCodeRateUnavailable    ErrorCode = "EXCHANGE_RATE_UNAVAILABLE"
+	ErrorCodeLedgerTxNotFound   ErrorCode = "LEDGER_TRANSACTION_NOT_FOUND"
+	ErrorCodeAlreadyReversed    ErrorCode = "ALREADY_REVERSED"
+	ErrorCodeNotRefunded        ErrorCode = "PURCHASE_NOT_REFUNDED"
+	ErrorCodePayoutReversal     ErrorCode = "PAYOUT_NOT_REVERSIBLE"
+	ErrorCodeCodePoolEmpty      ErrorCode = "CODE_POOL_EMPTY"
+	ErrorCodeCodeTaken          ErrorCode = "CODE_TAKEN"
+	ErrorCodeTransferNotFound   ErrorCode = "TRANSFER_NOT_FOUND"
+	ErrorCodeTransferNotPending ErrorCode = "TRANSFER_NOT_PENDING"
+	ErrorCodeTransferPending    ErrorCode = "TRANSFER_PENDING"
+	ErrorCodeTransferPooled     ErrorCode = "TRANSFER_POOLED_CODE"
+	ErrorCodePromoNotFound      ErrorCode = "PROMO_CODE_NOT_FOUND"
+	ErrorCodePromoNotApplicable ErrorCode = "PROMO_CODE_NOT_APPLICABLE"
+	ErrorCodePromoExhausted     ErrorCode = "PROMO_CODE_EXHAUSTED"
+	ErrorCodePromoCodeExists    ErrorCode = "PROMO_CODE_EXISTS"
+	ErrorCodeReservationMissing ErrorCode = "RESERVATION_NOT_FOUND"
+	ErrorCodeReservationEnded   ErrorCode = "RESERVATION_NOT_ACTIVE"
+	ErrorCodeVoucherReserved    ErrorCode = "VOUCHER_RESERVED"
+	ErrorCodeOperationBlocked   ErrorCode = "OPERATION_BLOCKED"
+	ErrorCodeOperationDelayed   ErrorCode = "OPERATION_DELAYED"
+	ErrorCodeUnauthorized       ErrorCode = "UNAUTHORIZED"
+	ErrorCodeForbidden          ErrorCode = "FORBIDDEN"
+	ErrorCodeRateLimited        ErrorCode = "RATE_LIMITED"
+	ErrorCodeMethodNotAllowed   ErrorCode = "METHOD_NOT_ALLOWED"
+	ErrorCodeUpstreamFailure    ErrorCode = "UPSTREAM_FAILURE"
+	ErrorCodeInternal           ErrorCode = "INTERNAL_ERROR"
+)
+
+// NewErrorResponse creates an error envelope
+func NewErrorResponse(code ErrorCode, message string) ErrorResponse {
+	return ErrorResponse{
+		Success: false,
+		Error: ErrorDetail{
+			Code:    code,
+			Message: message,
+		},
+	}
+}
@@ -1,42 +1,296 @@
 package dto
 
 import (
+	"encoding/json"
 	"time"
 
+	"4SaleBackendSkeleton/internal/domain"
 	"github.com/google/uuid"
 )
 
 // CreateVoucherRequest represents the webhook payload for voucher creation
 type CreateVoucherRequest struct {
-	AdvID       int64   `json:"adv_id" validate:"required"`
-	UserID      int64   `json:"user_id" validate:"required"`
-	Title       string  `json:"title" validate:"required"`
-	Description *string `json:"description"`
-	Price       float64 `json:"price" validate:"required,min=0"`
-	Photo       *string `json:"photo"`
+	AdvID         int64       `json:"adv_id" validate:"required,min=1"`
+	UserID        int64       `json:"user_id" validate:"required,min=1"`
+	Title         string      `json:"title" validate:"required,max=255"`
+	TitleAR       *string     `json:"title_ar" validate:"omitempty,max=255"`
+	Description   *string     `json:"description" validate:"omitempty,max=2000"`
+	DescriptionAR *string     `json:"description_ar" validate:"omitempty,max=2000"`
+	Price         json.Number `json:"price" validate:"required,max=32"`
+	Currency      string      `json:"currency" validate:"omitempty,max=3"`
+	Photo         *string     `json:"photo" validate:"omitempty,max=2048,url"`
+	ExpiresAt     *time.Time  `json:"expires_at"`
+	CategoryID    *int64      `json:"category_id" validate:"omitempty,min=1"`
+	Tags          []string    `json:"tags" validate:"omitempty,max=20"`
+}
+
+// ImportVouchersRequest represents the options of a bulk voucher import.
+// MerchantID, when set, owns every imported voucher and rows may not name another user_id.
+type ImportVouchersRequest struct {
+	Format     string `json:"format" validate:"required,oneof=csv json"`
+	DryRun     bool   `json:"dry_run"`
+	MerchantID *int64 `json:"merchant_id" validate:"omitempty,min=1"`
 }
 
 // PurchaseVoucherRequest represents the webhook payload for voucher purchase
 type PurchaseVoucherRequest struct {
 	VoucherID uuid.UUID `json:"voucher_id" validate:"required"`
-	BuyerID   int64     `json:"buyer_id" validate:"required"`
+	BuyerID   int64     `json:"buyer_id" validate:"required,min=1"`
+	PromoCode string    `json:"promo_code" validate:"omitempty,max=32"`
+}
+
+// ReserveVoucherRequest represents the webhook payload holding a voucher for a buyer during checkout
+type ReserveVoucherRequest struct {
+	VoucherID uuid.UUID `json:"voucher_id" validate:"required"`
+	BuyerID   int64     `json:"buyer_id" validate:"required,min=1"`
+}
+
+// ConfirmReservationRequest represents the webhook payload purchasing a reserved voucher once
+// payment is confirmed
+type ConfirmReservationRequest struct {
+	ReservationID uuid.UUID `json:"reservation_id" validate:"required"`
+	PromoCode     string    `json:"promo_code" validate:"omitempty,max=32"`
+}
+
+// ReleaseReservationRequest represents the webhook payload ending a hold when checkout is abandoned
+type ReleaseReservationRequest struct {
+	ReservationID uuid.UUID `json:"reservation_id" validate:"required"`
+}
+
+// CreatePromoCodeRequest represents the webhook payload for promo code creation.
+// Percentage codes take PercentBasisPoints, fixed codes Amount in Currency;
+// merchant funded codes must name the merchant whose vouchers they apply to.
+type CreatePromoCodeRequest struct {
+	Code               string      `json:"code" validate:"required,max=32"`
+	DiscountType       string      `json:"discount_type" validate:"required,oneof=percentage fixed"`
+	PercentBasisPoints int64       `json:"percent_basis_points" validate:"omitempty,min=1,max=10000"`
+	Amount             json.Number `json:"amount" validate:"omitempty,max=32"`
+	Currency           string      `json:"currency" validate:"omitempty,max=3"`
+	FundedBy           string      `json:"funded_by" validate:"required,oneof=merchant platform"`
+	MerchantID         *int64      `json:"merchant_id" validate:"omitempty,min=1"`
+	MaxUses            *int64      `json:"max_uses" validate:"omitempty,min=1"`
+	MaxUsesPerUser     *int64      `json:"max_uses_per_user" validate:"omitempty,min=1"`
+	StartsAt           *time.Time  `json:"starts_at"`
+	EndsAt             *time.Time  `json:"ends_at"`
+}
+
+// CreateTransferRequest represents a buyer's offer to give a purchase to another user,
+// identified by exactly one of ToUserID and ToPhone
+type CreateTransferRequest struct {
+	ToUserID *int64  `json:"to_user_id" validate:"omitempty,min=1"`
+	ToPhone  *string `json:"to_phone" validate:"omitempty,max=20"`
+}
+
+// AcceptTransferRequest represents a recipient accepting a transfer.
+// ClaimToken is required for transfers addressed to a phone number.
+type AcceptTransferRequest struct {
+	ClaimToken string `json:"claim_token" validate:"omitempty,max=64"`
 }
 
 // RedeemVoucherRequest represents the webhook payload for voucher redemption
 type RedeemVoucherRequest struct {
 	VoucherID  uuid.UUID `json:"voucher_id" validate:"required"`
 	RedeemedAt time.Time `json:"redeemed_at" validate:"required"`
+	// QRCode is the data read from the scanned QR code, which must be the purchase's current one
+	QRCode string `json:"qr_code" validate:"required,max=255"`
+	// DeviceID identifies the scanning device for the fraud rules
+	DeviceID string `json:"device_id" validate:"omitempty,max=128"`
+	// BranchID and StaffID identify the merchant branch and cashier that scanned the voucher
+	BranchID *int64 `json:"branch_id" validate:"omitempty,min=1"`
+	StaffID  *int64 `json:"staff_id" validate:"omitempty,min=1"`
+}
+
+// UpdateListingRequest represents the webhook payload for an edited 4Sale ad.
+// Only the fields present in the payload are changed.
+type UpdateListingRequest struct {
+	AdvID         int64        `json:"adv_id" validate:"required,min=1"`
+	Title         *string      `json:"title" validate:"omitempty,min=1,max=255"`
+	TitleAR       *string      `json:"title_ar" validate:"omitempty,max=255"`
+	Description   *string      `json:"description" validate:"omitempty,max=2000"`
+	DescriptionAR *string      `json:"description_ar" validate:"omitempty,max=2000"`
+	Price         *json.Number `json:"price" validate:"omitempty,max=32"`
+	Photo         *string      `json:"photo" validate:"omitempty,max=2048,url"`
+}
+
+// DeleteListingRequest represents the webhook payload for a removed 4Sale ad
+type DeleteListingRequest struct {
+	AdvID int64 `json:"adv_id" validate:"required,min=1"`
+}
+
+// UploadVoucherCodesRequest represents the codes a merchant adds to a voucher's code pool
+type UploadVoucherCodesRequest struct {
+	Codes []string `json:"codes" validate:"required,min=1,max=10000"`
 }
 
-// ErrorResponse represents an error response
+// UpdateVoucherRequest represents a merchant's partial update of an unsold voucher.
+// Version must match the voucher's current version.
+type UpdateVoucherRequest struct {
+	Title         *string      `json:"title" validate:"omitempty,min=1,max=255"`
+	TitleAR       *string      `json:"title_ar" validate:"omitempty,max=255"`
+	Description   *string      `json:"description" validate:"omitempty,max=2000"`
+	DescriptionAR *string      `json:"description_ar" validate:"omitempty,max=2000"`
+	Price         *json.Number `json:"price" validate:"omitempty,max=32"`
+	Photo         *string      `json:"photo" validate:"omitempty,max=2048,url"`
+	Version       int          `json:"version" validate:"required,min=1"`
+}
+
+// UserVoucherListRequest represents the query parameters of a user's voucher list.
+// Status is a comma separated list; From and To are inclusive YYYY-MM-DD dates.
+type UserVoucherListRequest struct {
+	UserID int64  `json:"user_id" validate:"required,min=1"`
+	Status string `json:"status" validate:"omitempty,max=100"`
+	From   string `json:"from" validate:"omitempty,max=10"`
+	To     string `json:"to" validate:"omitempty,max=10"`
+	// Category matches the category and its descendants
+	Category *int64 `json:"category" validate:"omitempty,min=1"`
+	Tag      string `json:"tag" validate:"omitempty,max=50"`
+	Sort     string `json:"sort" validate:"omitempty,oneof=newest oldest price_asc price_desc"`
+	Limit    int    `json:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor   string `json:"cursor" validate:"omitempty,max=512"`
+	// Language selects the title and description variant
+	Language string `json:"lang" validate:"omitempty,oneof=en ar"`
+}
+
+// UserVoucherListResponse is one page of a user's vouchers
+type UserVoucherListResponse struct {
+	Vouchers []*domain.UserVoucherResponse
+	Meta     PageMeta
+}
+
+// CatalogueRequest represents the query parameters of the public catalogue
+type CatalogueRequest struct {
+	Query    string `json:"q" validate:"omitempty,max=200"`
+	MinPrice string `json:"min_price" validate:"omitempty,max=32"`
+	MaxPrice string `json:"max_price" validate:"omitempty,max=32"`
+	// Currency restricts the listing to one currency; price bounds are read in it
+	Currency   string `json:"currency" validate:"omitempty,max=3"`
+	MerchantID *int64 `json:"merchant_id" validate:"omitempty,min=1"`
+	// Category matches the category and its descendants
+	Category     *int64 `json:"category" validate:"omitempty,min=1"`
+	Tag          string `json:"tag" validate:"omitempty,max=50"`
+	Availability string `json:"availability" validate:"omitempty,oneof=available sold_out all"`
+	Sort         string `json:"sort" validate:"omitempty,oneof=relevance newest price_asc price_desc popularity"`
+	Limit        int    `json:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor       string `json:"cursor" validate:"omitempty,max=512"`
+	// Language selects the title and description variant
+	Language string `json:"lang" validate:"omitempty,oneof=en ar"`
+}
+
+// CatalogueResponse is one page of the public catalogue
+type CatalogueResponse struct {
+	Vouchers []*domain.CatalogueVoucher
+	Meta     PageMeta
+}
+
+// StatementRequest represents a merchant payout statement for a period, or the payout
+// webhook payload settling it. From and To are inclusive YYYY-MM-DD dates.
+type StatementRequest struct {
+	MerchantID int64  `json:"merchant_id" validate:"required,min=1"`
+	From       string `json:"from" validate:"required,max=10"`
+	To         string `json:"to" validate:"required,max=10"`
+}
+
+// MerchantAnalyticsRequest represents the query parameters of a merchant's analytics.
+// From and To are inclusive YYYY-MM-DD dates; Top is the number of top vouchers listed.
+type MerchantAnalyticsRequest struct {
+	MerchantID int64  `json:"merchant_id" validate:"required,min=1"`
+	From       string `json:"from" validate:"required,max=10"`
+	To         string `json:"to" validate:"required,max=10"`
+	Top        int    `json:"top" validate:"omitempty,min=1,max=50"`
+}
+
+// ExportRequest represents the query parameters of a purchase or redemption export.
+// From and To are inclusive YYYY-MM-DD dates; RequesterID is the session user asking for it.
+type ExportRequest struct {
+	Kind        string `json:"kind" validate:"required,oneof=purchases redemptions"`
+	MerchantID  *int64 `json:"merchant_id" validate:"omitempty,min=1"`
+	From        string `json:"from" validate:"required,max=10"`
+	To          string `json:"to" validate:"required,max=10"`
+	RequesterID int64  `json:"-" validate:"required,min=1"`
+}
+
+// ReverseLedgerTransactionRequest represents the webhook payload correcting a posted ledger transaction
+type ReverseLedgerTransactionRequest struct {
+	TransactionID uuid.UUID `json:"transaction_id" validate:"required"`
+}
+
+// UpdateNotificationPreferencesRequest represents a user's notification settings; fields
+// left out keep their current value. Empty phone and email_address clear them.
+type UpdateNotificationPreferencesRequest struct {
+	Push         *bool   `json:"push"`
+	SMS          *bool   `json:"sms"`
+	Email        *bool   `json:"email"`
+	Language     *string `json:"language" validate:"omitempty,oneof=en ar"`
+	Phone        *string `json:"phone" validate:"omitempty,max=20"`
+	EmailAddress *string `json:"email_address" validate:"omitempty,max=254"`
+}
+
+// AuditEventListRequest represents the query parameters of the audit log search.
+// From and To are inclusive YYYY-MM-DD dates; RequesterID is the session user asking for it.
+type AuditEventListRequest struct {
+	ActorID     *int64 `json:"actor_id" validate:"omitempty,min=1"`
+	Action      string `json:"action" validate:"omitempty,max=50"`
+	TargetType  string `json:"target_type" validate:"omitempty,max=50"`
+	TargetID    string `json:"target_id" validate:"omitempty,max=64"`
+	From        string `json:"from" validate:"omitempty,max=10"`
+	To          string `json:"to" validate:"omitempty,max=10"`
+	Limit       int    `json:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor      string `json:"cursor" validate:"omitempty,max=32"`
+	RequesterID int64  `json:"-" validate:"required,min=1"`
+}
+
+// RedemptionListRequest represents the query of a merchant's redemption history
+type RedemptionListRequest struct {
+	MerchantID int64  `json:"merchant_id" validate:"required,min=1"`
+	BranchID   *int64 `json:"branch_id" validate:"omitempty,min=1"`
+	From       string `json:"from" validate:"omitempty,max=10"`
+	To         string `json:"to" validate:"omitempty,max=10"`
+	Limit      int    `json:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor     string `json:"cursor" validate:"omitempty,max=512"`
+}
+
+// RedemptionListResponse is one page of a merchant's redemptions, newest first
+type RedemptionListResponse struct {
+	Redemptions []*domain.Redemption
+	Meta        PageMeta
+}
+
+// AuditEventListResponse is one page of audit events, newest first
+type AuditEventListResponse struct {
+	Events []*domain.AuditEvent
+	Meta   AuditPageMeta
+}
+
+// AuditPageMeta describes a page of audit events. ChainIntact is false when an event's
+// hash no longer matches it, or, on unfiltered pages, when an event is missing between two.
+type AuditPageMeta struct {
+	PageMeta
+	ChainIntact bool `json:"chain_intact"`
+}
+
+// PageMeta describes a page of a cursor paginated list; NextCursor is null on the last page
+type PageMeta struct {
+	Limit      int     `json:"limit"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+// ErrorResponse represents the error envelope shared by every endpoint
 type ErrorResponse struct {
-	Error   string `json:"error"`
-	Message string `json:"message"`
-	Code    int    `json:"code"`
+	Success bool        `json:"success"`
+	Error   ErrorDetail `json:"error"`
+}
+
+// ErrorDetail describes an error with a stable machine-readable code
+type ErrorDetail struct {
+	Code    ErrorCode           `json:"code"`
+	Message string              `json:"message"`
+	Details []domain.FieldError `json:"details,omitempty"`
 }
 
 // SuccessResponse represents a success response
 type SuccessResponse struct {
+	Success bool        `json:"success"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
+	Meta    interface{} `json:"meta,omitempty"`
 }
\ No newline at end of file
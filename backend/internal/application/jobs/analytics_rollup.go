@@ -0,0 +1,43 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"4SaleBackendSkeleton/internal/ports"
+	"github.com/rs/zerolog"
+)
+
+// AnalyticsRollup keeps the merchant daily sales rollup up to date
+type AnalyticsRollup struct {
+	analyticsRepo ports.AnalyticsRepository
+	logger        zerolog.Logger
+	// since is the database time of the last successful refresh; zero until the first one
+	since time.Time
+}
+
+// NewAnalyticsRollup creates a new analytics rollup job
+func NewAnalyticsRollup(analyticsRepo ports.AnalyticsRepository, logger zerolog.Logger) *AnalyticsRollup {
+	return &AnalyticsRollup{
+		analyticsRepo: analyticsRepo,
+		logger:        logger,
+	}
+}
+
+// Refresh recomputes the rollup days with purchases changed since the last refresh.
+// The first refresh after start rebuilds the whole rollup.
+func (j *AnalyticsRollup) Refresh(ctx context.Context) error {
+	startedAt, err := j.analyticsRepo.RefreshDailySales(ctx, j.since)
+	if err != nil {
+		return fmt.Errorf("failed to refresh analytics rollup: %w", err)
+	}
+
+	j.logger.Debug().
+		Time("since", j.since).
+		Time("refreshed_at", startedAt).
+		Msg("Analytics rollup refreshed")
+
+	j.since = startedAt
+	return nil
+}
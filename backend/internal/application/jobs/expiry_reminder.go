@@ -0,0 +1,26 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"4SaleBackendSkeleton/internal/ports"
+)
+
+// ExpiryReminder reminds buyers of vouchers that are about to expire unused
+type ExpiryReminder struct {
+	sender ports.ExpiryReminderSender
+}
+
+// NewExpiryReminder creates a new expiry reminder job
+func NewExpiryReminder(sender ports.ExpiryReminderSender) *ExpiryReminder {
+	return &ExpiryReminder{sender: sender}
+}
+
+// Remind sends the reminders that are due
+func (j *ExpiryReminder) Remind(ctx context.Context) error {
+	if err := j.sender.SendExpiryReminders(ctx); err != nil {
+		return fmt.Errorf("failed to send expiry reminders: %w", err)
+	}
+	return nil
+}
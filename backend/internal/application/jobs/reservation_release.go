@@ -0,0 +1,38 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"4SaleBackendSkeleton/internal/ports"
+	"github.com/rs/zerolog"
+)
+
+// ReservationRelease marks checkout holds that ran out as expired
+type ReservationRelease struct {
+	reservationRepo ports.ReservationRepository
+	logger          zerolog.Logger
+}
+
+// NewReservationRelease creates a new reservation release job
+func NewReservationRelease(reservationRepo ports.ReservationRepository, logger zerolog.Logger) *ReservationRelease {
+	return &ReservationRelease{
+		reservationRepo: reservationRepo,
+		logger:          logger,
+	}
+}
+
+// Release expires every hold past its expiry. Expired holds already stop blocking sales;
+// releasing them keeps their status accurate for reads and reports.
+func (j *ReservationRelease) Release(ctx context.Context) error {
+	released, err := j.reservationRepo.ReleaseExpired(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to release expired reservations: %w", err)
+	}
+
+	if released > 0 {
+		j.logger.Info().Int64("released", released).Msg("Expired reservations released")
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Job is one run of a background job
+type Job func(ctx context.Context) error
+
+// RunEvery runs job immediately and then every interval until ctx is cancelled.
+// Failed runs are logged and retried on the next tick.
+func RunEvery(ctx context.Context, name string, interval time.Duration, logger zerolog.Logger, job Job) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job(ctx); err != nil && ctx.Err() == nil {
+			logger.Error().Err(err).Str("job", name).Msg("Background job failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+        "context"
+        "fmt"
+        "strconv"
+        "time"
+
+        "4SaleBackendSkeleton/internal/application/dto"
+        "4SaleBackendSkeleton/internal/application/validation"
+        "4SaleBackendSkeleton/internal/domain"
+        "4SaleBackendSkeleton/internal/ports"
+)
+
+// maxAnalyticsDays bounds the date range of an analytics request, and so its time series
+const maxAnalyticsDays = 366
+
+// defaultTopVouchers is the number of top vouchers listed when a request sets none
+const defaultTopVouchers = 10
+
+// AnalyticsService implements merchant sales and redemption analytics
+type AnalyticsService struct {
+        analyticsRepo ports.AnalyticsRepository
+        // useRollup reads daily sales from the rollup table instead of aggregating purchases
+        useRollup bool
+}
+
+// NewAnalyticsService creates a new analytics service
+func NewAnalyticsService(analyticsRepo ports.AnalyticsRepository, useRollup bool) *AnalyticsService {
+        return &AnalyticsService{
+                analyticsRepo: analyticsRepo,
+                useRollup:     useRollup,
+        }
+}
+
+// GetMerchantAnalytics summarizes the sales of a merchant's vouchers bought in a date range
+func (s *AnalyticsService) GetMerchantAnalytics(ctx context.Context, req *dto.MerchantAnalyticsRequest) (*domain.MerchantAnalytics, error) {
+        // Validate request
+        if err := validation.Validate(req); err != nil {
+                return nil, err
+        }
+
+        from, to, err := parsePeriod(req.From, req.To)
+        if err != nil {
+                return nil, err
+        }
+        if to.Sub(from) > maxAnalyticsDays*24*time.Hour {
+                days := strconv.Itoa(maxAnalyticsDays)
+                return nil, domain.NewValidationError(domain.FieldError{Field: "to", Code: "max", Message: "to must be at most " + days + " days after from", Param: days})
+        }
+
+        top := req.Top
+        if top == 0 {
+                top = defaultTopVouchers
+        }
+
+        source := domain.AnalyticsSourceLive
+        var sales []*domain.DailySales
+        if s.useRollup {
+                source = domain.AnalyticsSourceRollup
+                sales, err = s.analyticsRepo.GetRollupDailySales(ctx, req.MerchantID, from, to)
+        } else {
+                sales, err = s.analyticsRepo.GetDailySales(ctx, req.MerchantID, from, to)
+        }
+        if err != nil {
+                return nil, fmt.Errorf("failed to get daily sales: %w", err)
+        }
+
+        topVouchers, err := s.analyticsRepo.GetTopVouchers(ctx, req.MerchantID, from, to, top)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get top vouchers: %w", err)
+        }
+
+        return domain.NewMerchantAnalytics(req.MerchantID, from, to, source, sales, topVouchers), nil
+}
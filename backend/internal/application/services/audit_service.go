@@ -0,0 +1,192 @@
+package services
+
+import (
+        "context"
+        "encoding/json"
+        "strconv"
+        "time"
+
+        "4SaleBackendSkeleton/internal/application/dto"
+        "4SaleBackendSkeleton/internal/application/validation"
+        "4SaleBackendSkeleton/internal/domain"
+        "4SaleBackendSkeleton/internal/ports"
+        "github.com/rs/zerolog"
+)
+
+// AuditService records state-changing operations in the audit log and searches it
+type AuditService struct {
+        auditRepo ports.AuditRepository
+        // staff are the support users allowed to search the audit log
+        staff  map[int64]bool
+        logger zerolog.Logger
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(auditRepo ports.AuditRepository, staffUserIDs []int64, logger zerolog.Logger) *AuditService {
+        staff := make(map[int64]bool, len(staffUserIDs))
+        for _, id := range staffUserIDs {
+                staff[id] = true
+        }
+
+        return &AuditService{
+                auditRepo: auditRepo,
+                staff:     staff,
+                logger:    logger,
+        }
+}
+
+// Record appends event to the audit log with the request it came from. The session user
+// is the actor when there is one; otherwise the user named by the event, acting through
+// the platform's webhooks, or the system for work done outside a request.
+func (s *AuditService) Record(ctx context.Context, event *domain.AuditEvent) {
+        event.ActorType = domain.AuditActorSystem
+        if source, ok := domain.AuditSourceFromContext(ctx); ok {
+                event.RequestID = source.RequestID
+                event.IP = source.IP
+                event.ActorType = source.ActorType
+                if source.ActorID != nil {
+                        event.ActorID = source.ActorID
+                }
+        }
+        // The log stores timestamps to the microsecond, so the hash is taken over the same value
+        event.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+
+        if err := s.auditRepo.AppendEvent(ctx, event); err != nil {
+                s.logger.Error().
+                        Err(err).
+                        Str("action", event.Action).
+                        Str("target_type", event.TargetType).
+                        Str("target_id", event.TargetID).
+                        Str("request_id", event.RequestID).
+                        Msg("Failed to record audit event")
+        }
+}
+
+// ListAuditEvents searches the audit log for a staff user, newest first
+func (s *AuditService) ListAuditEvents(ctx context.Context, req *dto.AuditEventListRequest) (*dto.AuditEventListResponse, error) {
+        // Validate request
+        if err := validation.Validate(req); err != nil {
+                return nil, err
+        }
+        if !s.staff[req.RequesterID] {
+                return nil, domain.ErrForbidden
+        }
+
+        filter := &domain.AuditFilter{
+                ActorID:    req.ActorID,
+                Action:     req.Action,
+                TargetType: req.TargetType,
+                TargetID:   req.TargetID,
+                Limit:      req.Limit,
+        }
+        if filter.Limit == 0 {
+                filter.Limit = defaultPageSize
+        }
+
+        var fieldErrors []domain.FieldError
+        if req.From != "" {
+                from, err := time.Parse(dateLayout, req.From)
+                if err != nil {
+                        fieldErrors = append(fieldErrors, domain.FieldError{Field: "from", Code: "date", Message: "from must be a date in YYYY-MM-DD format"})
+                } else {
+                        filter.From = &from
+                }
+        }
+        if req.To != "" {
+                to, err := time.Parse(dateLayout, req.To)
+                if err != nil {
+                        fieldErrors = append(fieldErrors, domain.FieldError{Field: "to", Code: "date", Message: "to must be a date in YYYY-MM-DD format"})
+                } else {
+                        // The end date is inclusive, so the range ends at the start of the next day
+                        end := to.AddDate(0, 0, 1)
+                        filter.To = &end
+                }
+        }
+        if req.Cursor != "" {
+                beforeID, err := strconv.ParseInt(req.Cursor, 10, 64)
+                if err != nil || beforeID < 1 {
+                        fieldErrors = append(fieldErrors, domain.FieldError{Field: "cursor", Code: "cursor", Message: "cursor is invalid"})
+                } else {
+                        filter.BeforeID = &beforeID
+                }
+        }
+        if len(fieldErrors) > 0 {
+                return nil, domain.NewValidationError(fieldErrors...)
+        }
+
+        // One extra event tells whether there is a next page
+        limit := filter.Limit
+        filter.Limit++
+        events, err := s.auditRepo.ListEvents(ctx, filter)
+        if err != nil {
+                return nil, err
+        }
+
+        meta := dto.AuditPageMeta{PageMeta: dto.PageMeta{Limit: limit}, ChainIntact: true}
+        if len(events) > limit {
+                events = events[:limit]
+                next := strconv.FormatInt(events[limit-1].ID, 10)
+                meta.NextCursor = &next
+        }
+
+        // Unfiltered pages hold consecutive events, so each must link to the one before it
+        consecutive := filter.ActorID == nil && filter.Action == "" && filter.TargetType == "" && filter.TargetID == "" &&
+                filter.From == nil && filter.To == nil
+        for i, event := range events {
+                if !event.Intact() {
+                        meta.ChainIntact = false
+                }
+                if consecutive && i+1 < len(events) && event.PrevHash != events[i+1].Hash {
+                        meta.ChainIntact = false
+                }
+        }
+
+        return &dto.AuditEventListResponse{Events: events, Meta: meta}, nil
+}
+
+// newAuditEvent creates an audit event for an operation on a target; before and after
+// are the target's state around it, or nil when it did not exist
+func newAuditEvent(action, targetType, targetID string, before, after interface{}) *domain.AuditEvent {
+        return &domain.AuditEvent{
+                Action:     action,
+                TargetType: targetType,
+                TargetID:   targetID,
+                Before:     auditState(before),
+                After:      auditState(after),
+        }
+}
+
+// auditState encodes a target's state for the audit log
+func auditState(state interface{}) json.RawMessage {
+        if state == nil {
+                return nil
+        }
+        b, err := json.Marshal(state)
+        if err != nil {
+                return nil
+        }
+        return b
+}
+
+// auditPurchase returns a copy of purchase without its QR code, which is a bearer credential
+func auditPurchase(purchase *domain.VoucherPurchase) *domain.VoucherPurchase {
+        if purchase == nil {
+                return nil
+        }
+        clean := *purchase
+        clean.QRCode = ""
+        return &clean
+}
+
+// auditPreferences returns a copy of prefs without the phone number and email address,
+// which could never be erased from the append-only log; it only records whether they are set
+func auditPreferences(prefs *domain.NotificationPreferences) map[string]interface{} {
+        return map[string]interface{}{
+                "push":              prefs.Push,
+                "sms":               prefs.SMS,
+                "email":             prefs.Email,
+                "language":          prefs.Language,
+                "has_phone":         prefs.Phone != nil,
+                "has_email_address": prefs.EmailAddress != nil,
+        }
+}
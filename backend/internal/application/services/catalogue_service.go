@@ -0,0 +1,163 @@
+package services
+
+import (
+        "context"
+        "fmt"
+        "strings"
+        "time"
+        "unicode"
+
+        "4SaleBackendSkeleton/internal/application/dto"
+        "4SaleBackendSkeleton/internal/application/validation"
+        "4SaleBackendSkeleton/internal/domain"
+        "4SaleBackendSkeleton/internal/ports"
+)
+
+// CatalogueService implements browsing and searching the public voucher catalogue
+type CatalogueService struct {
+        catalogueRepo ports.CatalogueRepository
+        categoryRepo  ports.CategoryRepository
+}
+
+// NewCatalogueService creates a new catalogue service
+func NewCatalogueService(catalogueRepo ports.CatalogueRepository, categoryRepo ports.CategoryRepository) *CatalogueService {
+        return &CatalogueService{
+                catalogueRepo: catalogueRepo,
+                categoryRepo:  categoryRepo,
+        }
+}
+
+// Search retrieves one page of catalogue vouchers matching the request
+func (s *CatalogueService) Search(ctx context.Context, req *dto.CatalogueRequest) (*dto.CatalogueResponse, error) {
+        // Validate request
+        if err := validation.Validate(req); err != nil {
+                return nil, err
+        }
+
+        query, err := newCatalogueQuery(req, time.Now())
+        if err != nil {
+                return nil, err
+        }
+        if query.CategoryIDs, err = categorySubtree(ctx, s.categoryRepo, req.Category, "category"); err != nil {
+                return nil, err
+        }
+
+        page, err := s.catalogueRepo.SearchCatalogue(ctx, query)
+        if err != nil {
+                return nil, fmt.Errorf("failed to search catalogue: %w", err)
+        }
+
+        for _, voucher := range page.Vouchers {
+                voucher.Localize(query.Language)
+        }
+
+        response := &dto.CatalogueResponse{
+                Vouchers: page.Vouchers,
+                Meta:     dto.PageMeta{Limit: query.Limit},
+        }
+        if page.Next != nil {
+                nextCursor := page.Next.Encode()
+                response.Meta.NextCursor = &nextCursor
+        }
+
+        return response, nil
+}
+
+// newCatalogueQuery turns a validated catalogue request into a repository query
+func newCatalogueQuery(req *dto.CatalogueRequest, now time.Time) (*domain.CatalogueQuery, error) {
+        query := &domain.CatalogueQuery{
+                Search:       booleanSearch(req.Query),
+                MerchantID:   req.MerchantID,
+                Tag:          normalizeTag(req.Tag),
+                Availability: req.Availability,
+                Sort:         req.Sort,
+                Limit:        req.Limit,
+                Language:     req.Language,
+                Now:          now,
+        }
+        if query.Language == "" {
+                query.Language = domain.DefaultLanguage
+        }
+        if query.Availability == "" {
+                query.Availability = domain.AvailabilityAvailable
+        }
+        if query.Sort == "" {
+                query.Sort = domain.SortNewest
+                if query.Search != "" {
+                        query.Sort = domain.SortRelevance
+                }
+        }
+        if query.Limit == 0 {
+                query.Limit = defaultPageSize
+        }
+
+        var fieldErrors []domain.FieldError
+
+        if query.Sort == domain.SortRelevance && query.Search == "" {
+                fieldErrors = append(fieldErrors, domain.FieldError{Field: "sort", Code: "relevance", Message: "sort by relevance requires a search query"})
+        }
+        // Prices in different currencies cannot be compared, so price bounds
+        // restrict the listing to one currency
+        if req.Currency != "" || req.MinPrice != "" || req.MaxPrice != "" {
+                currency, fieldErr := parseCurrency("currency", req.Currency)
+                if fieldErr != nil {
+                        fieldErrors = append(fieldErrors, *fieldErr)
+                }
+                query.Currency = currency
+        }
+        if req.MinPrice != "" && query.Currency != "" {
+                minPrice, fieldErr := parseAmount("min_price", req.MinPrice, query.Currency)
+                if fieldErr != nil {
+                        fieldErrors = append(fieldErrors, *fieldErr)
+                } else {
+                        query.MinPrice = &minPrice
+                }
+        }
+        if req.MaxPrice != "" && query.Currency != "" {
+                maxPrice, fieldErr := parseAmount("max_price", req.MaxPrice, query.Currency)
+                if fieldErr != nil {
+                        fieldErrors = append(fieldErrors, *fieldErr)
+                } else {
+                        query.MaxPrice = &maxPrice
+                }
+        }
+        if query.MinPrice != nil && query.MaxPrice != nil && query.MinPrice.Amount > query.MaxPrice.Amount {
+                fieldErrors = append(fieldErrors, domain.FieldError{Field: "max_price", Code: "range", Message: "max_price must not be less than min_price", Param: "min_price"})
+        }
+
+        if req.Cursor != "" {
+                cursor, err := decodeCursor(req.Cursor, query.Sort)
+                if err != nil {
+                        fieldErrors = append(fieldErrors, domain.FieldError{Field: "cursor", Code: "cursor", Message: "cursor is invalid or was issued for a different sort"})
+                } else {
+                        query.After = cursor
+                }
+        }
+
+        if len(fieldErrors) > 0 {
+                return nil, domain.NewValidationError(fieldErrors...)
+        }
+        return query, nil
+}
+
+// booleanSearch turns free text into a MySQL boolean mode expression requiring every word.
+// Operator characters are dropped so user input cannot change the meaning of the query,
+// and Arabic diacritics and tatweel are dropped since listings are rarely written with them.
+func booleanSearch(text string) string {
+        text = strings.Map(func(r rune) rune {
+                if r == '\u0640' || (r >= '\u064B' && r <= '\u0652') {
+                        return -1
+                }
+                return r
+        }, text)
+
+        words := strings.FieldsFunc(text, func(r rune) bool {
+                return unicode.IsSpace(r) || strings.ContainsRune(`+-<>()~*"@`, r)
+        })
+
+        terms := make([]string, 0, len(words))
+        for _, word := range words {
+                terms = append(terms, `+"`+word+`"`)
+        }
+        return strings.Join(terms, " ")
+}
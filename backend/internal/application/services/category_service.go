@@ -0,0 +1,78 @@
+package services
+
+import (
+        "context"
+        "fmt"
+        "strconv"
+        "strings"
+        "unicode/utf8"
+
+        "4SaleBackendSkeleton/internal/domain"
+        "4SaleBackendSkeleton/internal/ports"
+)
+
+// maxTagLength is the longest tag accepted, in characters
+const maxTagLength = 50
+
+// CategoryService implements reading the voucher category tree
+type CategoryService struct {
+        categoryRepo ports.CategoryRepository
+}
+
+// NewCategoryService creates a new category service
+func NewCategoryService(categoryRepo ports.CategoryRepository) *CategoryService {
+        return &CategoryService{
+                categoryRepo: categoryRepo,
+        }
+}
+
+// GetCategoryTree retrieves the categories as a tree
+func (s *CategoryService) GetCategoryTree(ctx context.Context) ([]*domain.Category, error) {
+        categories, err := s.categoryRepo.GetCategories(ctx)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get categories: %w", err)
+        }
+
+        return domain.BuildCategoryTree(categories), nil
+}
+
+// categorySubtree returns the category and its descendants for a category filter.
+// A nil id means no filter; an unknown id is reported as a validation error on field.
+func categorySubtree(ctx context.Context, categoryRepo ports.CategoryRepository, id *int64, field string) ([]int64, error) {
+        if id == nil {
+                return nil, nil
+        }
+
+        categories, err := categoryRepo.GetCategories(ctx)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get categories: %w", err)
+        }
+
+        ids := domain.CategorySubtree(categories, *id)
+        if ids == nil {
+                return nil, domain.NewValidationError(domain.FieldError{Field: field, Code: "exists", Message: field + " does not exist"})
+        }
+        return ids, nil
+}
+
+// normalizeTag trims and lowercases a tag and collapses inner whitespace
+func normalizeTag(tag string) string {
+        return strings.ToLower(strings.Join(strings.Fields(tag), " "))
+}
+
+// normalizeTags normalizes and de-duplicates tags, keeping their order
+func normalizeTags(tags []string) ([]string, error) {
+        normalized := make([]string, 0, len(tags))
+        seen := make(map[string]bool, len(tags))
+        for _, tag := range tags {
+                tag = normalizeTag(tag)
+                if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+                        return nil, domain.NewValidationError(domain.FieldError{Field: "tags", Code: "tag", Message: fmt.Sprintf("tags must be between 1 and %d characters", maxTagLength), Param: strconv.Itoa(maxTagLength)})
+                }
+                if !seen[tag] {
+                        seen[tag] = true
+                        normalized = append(normalized, tag)
+                }
+        }
+        return normalized, nil
+}
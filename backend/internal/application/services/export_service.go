@@ -0,0 +1,69 @@
+package services
+
+import (
+        "context"
+        "strconv"
+        "time"
+
+        "4SaleBackendSkeleton/internal/application/dto"
+        "4SaleBackendSkeleton/internal/application/validation"
+        "4SaleBackendSkeleton/internal/domain"
+        "4SaleBackendSkeleton/internal/ports"
+)
+
+// maxExportDays bounds the date range of a single export
+const maxExportDays = 366
+
+// ExportService implements purchase and redemption exports
+type ExportService struct {
+        exportRepo ports.ExportRepository
+        // staff are the finance and support users allowed to export any merchant, or all of them
+        staff map[int64]bool
+}
+
+// NewExportService creates a new export service
+func NewExportService(exportRepo ports.ExportRepository, staffUserIDs []int64) *ExportService {
+        staff := make(map[int64]bool, len(staffUserIDs))
+        for _, id := range staffUserIDs {
+                staff[id] = true
+        }
+
+        return &ExportService{
+                exportRepo: exportRepo,
+                staff:      staff,
+        }
+}
+
+// ExportPurchases streams the purchases or redemptions of a date range to fn.
+// Merchants may only export their own vouchers; staff may export any merchant, or every
+// merchant when none is given. Request errors are returned before fn is first called.
+func (s *ExportService) ExportPurchases(ctx context.Context, req *dto.ExportRequest, fn func(*domain.PurchaseExportRow) error) error {
+        // Validate request
+        if err := validation.Validate(req); err != nil {
+                return err
+        }
+
+        from, to, err := parsePeriod(req.From, req.To)
+        if err != nil {
+                return err
+        }
+        if to.Sub(from) > maxExportDays*24*time.Hour {
+                days := strconv.Itoa(maxExportDays)
+                return domain.NewValidationError(domain.FieldError{Field: "to", Code: "max", Message: "to must be at most " + days + " days after from", Param: days})
+        }
+
+        merchantID := req.MerchantID
+        if !s.staff[req.RequesterID] {
+                if merchantID != nil && *merchantID != req.RequesterID {
+                        return domain.ErrForbidden
+                }
+                merchantID = &req.RequesterID
+        }
+
+        return s.exportRepo.StreamPurchases(ctx, &domain.ExportFilter{
+                Kind:       req.Kind,
+                MerchantID: merchantID,
+                From:       from,
+                To:         to,
+        }, fn)
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+        "context"
+        "fmt"
+        "time"
+
+        "4SaleBackendSkeleton/internal/domain"
+        "4SaleBackendSkeleton/internal/ports"
+        "github.com/google/uuid"
+)
+
+// FraudService evaluates the fraud rules on purchases and redemptions
+type FraudService struct {
+        fraudRepo ports.FraudRepository
+        rules     domain.FraudRules
+}
+
+// NewFraudService creates a new fraud service
+func NewFraudService(fraudRepo ports.FraudRepository, rules domain.FraudRules) *FraudService {
+        return &FraudService{
+                fraudRepo: fraudRepo,
+                rules:     rules,
+        }
+}
+
+// Check gathers the history the rules need, evaluates them on the operation and records
+// every decision other than allow for review
+func (s *FraudService) Check(ctx context.Context, signals *domain.FraudSignals) (*domain.FraudDecision, error) {
+        switch signals.Operation {
+        case domain.FraudOperationPurchase:
+                if s.rules.PurchaseVelocityAction != domain.FraudActionAllow && s.rules.PurchaseVelocityMax > 0 {
+                        count, err := s.fraudRepo.CountPurchasesSince(ctx, signals.BuyerID, signals.At.Add(-s.rules.PurchaseVelocityWindow))
+                        if err != nil {
+                                return nil, err
+                        }
+                        signals.RecentPurchases = count
+                }
+        case domain.FraudOperationRedemption:
+                if s.rules.FailedLookupsAction != domain.FraudActionAllow && s.rules.FailedLookupsMax > 0 && signals.DeviceID != "" {
+                        count, err := s.fraudRepo.CountFailedLookupsSince(ctx, signals.DeviceID, signals.At.Add(-s.rules.FailedLookupsWindow))
+                        if err != nil {
+                                return nil, err
+                        }
+                        signals.FailedLookups = count
+                }
+        }
+
+        decision := s.rules.Evaluate(signals)
+        if decision.Action != domain.FraudActionAllow {
+                if err := s.fraudRepo.CreateDecision(ctx, decision); err != nil {
+                        return nil, fmt.Errorf("failed to record fraud decision: %w", err)
+                }
+        }
+
+        return decision, nil
+}
+
+// RecordFailedLookup counts a redemption from a device that matched no purchase
+func (s *FraudService) RecordFailedLookup(ctx context.Context, deviceID string, voucherID uuid.UUID, at time.Time) error {
+        return s.fraudRepo.RecordFailedLookup(ctx, deviceID, voucherID, at)
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+        "context"
+        "fmt"
+        "strconv"
+        "time"
+
+        "4SaleBackendSkeleton/internal/application/dto"
+        "4SaleBackendSkeleton/internal/application/validation"
+        "4SaleBackendSkeleton/internal/domain"
+        "4SaleBackendSkeleton/internal/ports"
+)
+
+// LedgerService implements merchant payout statements and ledger corrections
+type LedgerService struct {
+        ledgerRepo ports.LedgerRepository
+        auditor    ports.AuditRecorder
+}
+
+// NewLedgerService creates a new ledger service
+func NewLedgerService(ledgerRepo ports.LedgerRepository, auditor ports.AuditRecorder) *LedgerService {
+        return &LedgerService{
+                ledgerRepo: ledgerRepo,
+                auditor:    auditor,
+        }
+}
+
+// GetStatement builds the payout statement of a merchant for a period
+func (s *LedgerService) GetStatement(ctx context.Context, req *dto.StatementRequest) (*domain.PayoutStatement, error) {
+        // Validate request
+        if err := validation.Validate(req); err != nil {
+                return nil, err
+        }
+
+        from, to, err := parsePeriod(req.From, req.To)
+        if err != nil {
+                return nil, err
+        }
+
+        return s.statement(ctx, req.MerchantID, from, to)
+}
+
+// SettleStatement marks the unsettled payable entries of a merchant for a period as paid out
+// and returns the statement with the settlements recorded
+func (s *LedgerService) SettleStatement(ctx context.Context, req *dto.StatementRequest) (*domain.PayoutStatement, error) {
+        // Validate request
+        if err := validation.Validate(req); err != nil {
+                return nil, err
+        }
+
+        from, to, err := parsePeriod(req.From, req.To)
+        if err != nil {
+                return nil, err
+        }
+
+        settlements, err := s.ledgerRepo.SettlePayables(ctx, req.MerchantID, from, to, time.Now())
+        if err != nil {
+                return nil, fmt.Errorf("failed to settle payables: %w", err)
+        }
+        if len(settlements) > 0 {
+                s.auditor.Record(ctx, newAuditEvent(domain.AuditPayoutSettled, domain.AuditTargetMerchant, strconv.FormatInt(req.MerchantID, 10), nil, settlements))
+        }
+
+        statement, err := s.statement(ctx, req.MerchantID, from, to)
+        if err != nil {
+                return nil, err
+        }
+        statement.Settlements = settlements
+
+        return statement, nil
+}
+
+// ReverseTransaction posts the opposite of a ledger transaction of a refunded purchase; each
+// transaction can be reversed once
+func (s *LedgerService) ReverseTransaction(ctx context.Context, req *dto.ReverseLedgerTransactionRequest) (*domain.LedgerTransaction, error) {
+        // Validate request
+        if err := validation.Validate(req); err != nil {
+                return nil, err
+        }
+
+        original, err := s.ledgerRepo.GetTransaction(ctx, req.TransactionID)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get ledger transaction: %w", err)
+        }
+
+        reversal, err := domain.NewReversalTransaction(original, domain.LedgerReversal, time.Now())
+        if err != nil {
+                return nil, fmt.Errorf("failed to build reversal: %w", err)
+        }
+
+        if err := s.ledgerRepo.PostTransaction(ctx, reversal); err != nil {
+                return nil, fmt.Errorf("failed to post reversal: %w", err)
+        }
+
+        s.auditor.Record(ctx, newAuditEvent(domain.AuditLedgerReversed, domain.AuditTargetLedger, original.ID.String(), original, reversal))
+
+        return reversal, nil
+}
+
+// statement reads the payable lines of a merchant in [from, to) and reconciles them with the ledger
+func (s *LedgerService) statement(ctx context.Context, merchantID int64, from, to time.Time) (*domain.PayoutStatement, error) {
+        lines, err := s.ledgerRepo.GetStatementLines(ctx, merchantID, from, to)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get statement lines: %w", err)
+        }
+
+        movement, err := s.ledgerRepo.GetPayableMovement(ctx, merchantID, from, to)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get payable movement: %w", err)
+        }
+
+        return domain.NewPayoutStatement(merchantID, from, to, lines, movement), nil
+}
+
+// parsePeriod parses an inclusive YYYY-MM-DD date range into the half-open range [from, to)
+func parsePeriod(fromDate, toDate string) (time.Time, time.Time, error) {
+        var fieldErrors []domain.FieldError
+
+        from, err := time.Parse(dateLayout, fromDate)
+        if err != nil {
+                fieldErrors = append(fieldErrors, domain.FieldError{Field: "from", Code: "date", Message: "from must be a date in YYYY-MM-DD format"})
+        }
+        to, err := time.Parse(dateLayout, toDate)
+        if err != nil {
+                fieldErrors = append(fieldErrors, domain.FieldError{Field: "to", Code: "date", Message: "to must be a date in YYYY-MM-DD format"})
+        }
+        if len(fieldErrors) == 0 && to.Before(from) {
+                fieldErrors = append(fieldErrors, domain.FieldError{Field: "to", Code: "range", Message: "to must not be before from", Param: "from"})
+        }
+
+        if len(fieldErrors) > 0 {
+                return time.Time{}, time.Time{}, domain.NewValidationError(fieldErrors...)
+        }
+
+        // The end date is inclusive, so the range ends at the start of the next day
+        return from, to.AddDate(0, 0, 1), nil
+}
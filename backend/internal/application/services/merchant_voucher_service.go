@@ -0,0 +1,287 @@
+package services
+
+import (
+        "context"
+        "fmt"
+        "strconv"
+        "strings"
+        "time"
+        "unicode/utf8"
+
+        "4SaleBackendSkeleton/internal/application/dto"
+        "4SaleBackendSkeleton/internal/application/validation"
+        "4SaleBackendSkeleton/internal/domain"
+        "4SaleBackendSkeleton/internal/ports"
+        "github.com/google/uuid"
+)
+
+// MerchantVoucherService implements voucher management for the owning merchant
+type MerchantVoucherService struct {
+        voucherRepo  ports.VoucherRepository
+        codePoolRepo ports.CodePoolRepository
+        auditor      ports.AuditRecorder
+        // codePoolLowThreshold is the number of unassigned codes at which a pool is reported low
+        codePoolLowThreshold int64
+}
+
+// NewMerchantVoucherService creates a new merchant voucher service
+func NewMerchantVoucherService(voucherRepo ports.VoucherRepository, codePoolRepo ports.CodePoolRepository, auditor ports.AuditRecorder, codePoolLowThreshold int64) *MerchantVoucherService {
+        return &MerchantVoucherService{
+                voucherRepo:          voucherRepo,
+                codePoolRepo:         codePoolRepo,
+                auditor:              auditor,
+                codePoolLowThreshold: codePoolLowThreshold,
+        }
+}
+
+// ListVouchers retrieves the merchant's vouchers with their sales figures
+func (s *MerchantVoucherService) ListVouchers(ctx context.Context, merchantID int64) ([]*domain.MerchantVoucher, error) {
+        vouchers, err := s.voucherRepo.GetMerchantVouchers(ctx, merchantID)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get merchant vouchers: %w", err)
+        }
+
+        return vouchers, nil
+}
+
+// GetVoucher retrieves one of the merchant's vouchers
+func (s *MerchantVoucherService) GetVoucher(ctx context.Context, merchantID int64, voucherID uuid.UUID) (*domain.MerchantVoucher, error) {
+        voucher, err := s.voucherRepo.GetMerchantVoucherByID(ctx, voucherID)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get voucher: %w", err)
+        }
+
+        // Vouchers of other merchants are reported as missing so their IDs are not disclosed
+        if voucher.UserID != merchantID {
+                return nil, domain.ErrVoucherNotFound
+        }
+
+        return voucher, nil
+}
+
+// UpdateVoucher applies a partial update to an unsold voucher
+func (s *MerchantVoucherService) UpdateVoucher(ctx context.Context, merchantID int64, voucherID uuid.UUID, req *dto.UpdateVoucherRequest) (*domain.MerchantVoucher, error) {
+        if err := validation.Validate(req); err != nil {
+                return nil, err
+        }
+
+        voucher, err := s.GetVoucher(ctx, merchantID, voucherID)
+        if err != nil {
+                return nil, err
+        }
+
+        if voucher.SalesCount > 0 {
+                return nil, domain.ErrVoucherSold
+        }
+        if voucher.Version != req.Version {
+                return nil, domain.ErrVersionConflict
+        }
+
+        now := time.Now()
+        var changes []*domain.VoucherChange
+        if req.Title != nil && voucher.Title != *req.Title {
+                changes = append(changes, newMerchantChange(voucher.ID, "title", &voucher.Title, req.Title, now))
+                voucher.Title = *req.Title
+        }
+        if req.TitleAR != nil && !sameString(voucher.TitleAR, req.TitleAR) {
+                changes = append(changes, newMerchantChange(voucher.ID, "title_ar", voucher.TitleAR, req.TitleAR, now))
+                voucher.TitleAR = req.TitleAR
+        }
+        if req.Description != nil && !sameString(voucher.Description, req.Description) {
+                changes = append(changes, newMerchantChange(voucher.ID, "description", voucher.Description, req.Description, now))
+                voucher.Description = req.Description
+        }
+        if req.DescriptionAR != nil && !sameString(voucher.DescriptionAR, req.DescriptionAR) {
+                changes = append(changes, newMerchantChange(voucher.ID, "description_ar", voucher.DescriptionAR, req.DescriptionAR, now))
+                voucher.DescriptionAR = req.DescriptionAR
+        }
+        if req.Price != nil {
+                price, fieldErr := parsePrice("price", req.Price.String(), voucher.Price.Currency)
+                if fieldErr != nil {
+                        return nil, domain.NewValidationError(*fieldErr)
+                }
+                if price != voucher.Price {
+                        oldPrice, newPrice := voucher.Price.Decimal(), price.Decimal()
+                        changes = append(changes, newMerchantChange(voucher.ID, "price", &oldPrice, &newPrice, now))
+                        voucher.Price = price
+                }
+        }
+        if req.Photo != nil && !sameString(voucher.PhotoURL, req.Photo) {
+                changes = append(changes, newMerchantChange(voucher.ID, "photo_url", voucher.PhotoURL, req.Photo, now))
+                voucher.PhotoURL = req.Photo
+        }
+
+        voucher.UpdatedAt = &now
+
+        if err := s.voucherRepo.UpdateVoucher(ctx, &voucher.Voucher, req.Version, changes); err != nil {
+                return nil, fmt.Errorf("failed to update voucher: %w", err)
+        }
+
+        if len(changes) > 0 {
+                before, after := changeStates(changes)
+                event := newAuditEvent(domain.AuditVoucherUpdated, domain.AuditTargetVoucher, voucherID.String(), before, after)
+                event.ActorID = &merchantID
+                s.auditor.Record(ctx, event)
+        }
+
+        return voucher, nil
+}
+
+// SetVoucherStatus pauses or resumes sales of a voucher
+func (s *MerchantVoucherService) SetVoucherStatus(ctx context.Context, merchantID int64, voucherID uuid.UUID, status string) (*domain.MerchantVoucher, error) {
+        if status != domain.VoucherStatusActive && status != domain.VoucherStatusPaused {
+                return nil, domain.NewValidationError(domain.FieldError{Field: "status", Code: "oneof", Message: "status must be one of: active, paused", Param: "active, paused"})
+        }
+
+        voucher, err := s.GetVoucher(ctx, merchantID, voucherID)
+        if err != nil {
+                return nil, err
+        }
+
+        if voucher.Status == status {
+                return voucher, nil
+        }
+
+        now := time.Now()
+        changes := []*domain.VoucherChange{newMerchantChange(voucherID, "status", &voucher.Status, &status, now)}
+        if err := s.voucherRepo.UpdateVoucherStatus(ctx, voucherID, status, now, changes); err != nil {
+                return nil, fmt.Errorf("failed to update voucher status: %w", err)
+        }
+
+        action := domain.AuditVoucherResumed
+        if status == domain.VoucherStatusPaused {
+                action = domain.AuditVoucherPaused
+        }
+        event := newAuditEvent(action, domain.AuditTargetVoucher, voucherID.String(), map[string]string{"status": voucher.Status}, map[string]string{"status": status})
+        event.ActorID = &merchantID
+        s.auditor.Record(ctx, event)
+
+        voucher.Status = status
+        voucher.Version++
+        voucher.UpdatedAt = &now
+
+        return voucher, nil
+}
+
+// GetVoucherHistory retrieves the changes made to one of the merchant's vouchers by listing
+// events and by the merchant
+func (s *MerchantVoucherService) GetVoucherHistory(ctx context.Context, merchantID int64, voucherID uuid.UUID) ([]*domain.VoucherChange, error) {
+        if _, err := s.GetVoucher(ctx, merchantID, voucherID); err != nil {
+                return nil, err
+        }
+
+        changes, err := s.voucherRepo.GetVoucherChanges(ctx, voucherID)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get voucher history: %w", err)
+        }
+
+        return changes, nil
+}
+
+// DeleteVoucher withdraws a voucher from sale; existing purchases stay redeemable
+func (s *MerchantVoucherService) DeleteVoucher(ctx context.Context, merchantID int64, voucherID uuid.UUID) error {
+        voucher, err := s.GetVoucher(ctx, merchantID, voucherID)
+        if err != nil {
+                return err
+        }
+
+        now := time.Now()
+        deletedAt := now.UTC().Format(time.RFC3339)
+        changes := []*domain.VoucherChange{newMerchantChange(voucherID, "deleted_at", nil, &deletedAt, now)}
+        if err := s.voucherRepo.DeleteVoucher(ctx, voucherID, now, changes); err != nil {
+                return fmt.Errorf("failed to delete voucher: %w", err)
+        }
+
+        event := newAuditEvent(domain.AuditVoucherDeleted, domain.AuditTargetVoucher, voucherID.String(), voucher, map[string]string{"deleted_at": deletedAt})
+        event.ActorID = &merchantID
+        s.auditor.Record(ctx, event)
+
+        return nil
+}
+
+// UploadCodes adds the merchant's own codes to a voucher's pool; once a voucher has codes,
+// each purchase is handed the next unassigned one and sales stop when none is left
+func (s *MerchantVoucherService) UploadCodes(ctx context.Context, merchantID int64, voucherID uuid.UUID, req *dto.UploadVoucherCodesRequest) (*domain.CodeUploadResult, error) {
+        if err := validation.Validate(req); err != nil {
+                return nil, err
+        }
+
+        codes, duplicates, err := normalizeCodes(req.Codes)
+        if err != nil {
+                return nil, err
+        }
+
+        if _, err := s.GetVoucher(ctx, merchantID, voucherID); err != nil {
+                return nil, err
+        }
+
+        now := time.Now()
+        change := newMerchantChange(voucherID, "codes_added", nil, nil, now)
+        added, err := s.codePoolRepo.AddCodes(ctx, voucherID, codes, now, change)
+        if err != nil {
+                return nil, fmt.Errorf("failed to add voucher codes: %w", err)
+        }
+
+        pool, err := s.codePool(ctx, voucherID)
+        if err != nil {
+                return nil, err
+        }
+
+        result := &domain.CodeUploadResult{
+                Added:      added,
+                Duplicates: duplicates + len(codes) - added,
+                Pool:       pool,
+        }
+
+        // The codes themselves are redeemable by buyers, so only the counts are recorded
+        if added > 0 {
+                event := newAuditEvent(domain.AuditVoucherCodesUploaded, domain.AuditTargetVoucher, voucherID.String(), nil, result)
+                event.ActorID = &merchantID
+                s.auditor.Record(ctx, event)
+        }
+
+        return result, nil
+}
+
+// GetCodePool reports how many codes of one of the merchant's vouchers are left
+func (s *MerchantVoucherService) GetCodePool(ctx context.Context, merchantID int64, voucherID uuid.UUID) (*domain.CodePoolStatus, error) {
+        if _, err := s.GetVoucher(ctx, merchantID, voucherID); err != nil {
+                return nil, err
+        }
+
+        return s.codePool(ctx, voucherID)
+}
+
+// codePool reads the status of a voucher's code pool
+func (s *MerchantVoucherService) codePool(ctx context.Context, voucherID uuid.UUID) (*domain.CodePoolStatus, error) {
+        total, available, err := s.codePoolRepo.GetPoolCounts(ctx, voucherID)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get voucher code pool: %w", err)
+        }
+
+        return domain.NewCodePoolStatus(voucherID, total, available, s.codePoolLowThreshold), nil
+}
+
+// newMerchantChange creates a history entry for a field changed by the merchant
+func newMerchantChange(voucherID uuid.UUID, field string, oldValue, newValue *string, changedAt time.Time) *domain.VoucherChange {
+        return newVoucherChange(domain.ChangeSourceMerchant, voucherID, field, oldValue, newValue, changedAt)
+}
+
+// normalizeCodes trims the uploaded codes and drops repeats, returning how many were dropped
+func normalizeCodes(codes []string) ([]string, int, error) {
+        normalized := make([]string, 0, len(codes))
+        seen := make(map[string]bool, len(codes))
+        for _, code := range codes {
+                code = strings.TrimSpace(code)
+                if code == "" || utf8.RuneCountInString(code) > domain.MaxVoucherCodeLength {
+                        limit := strconv.Itoa(domain.MaxVoucherCodeLength)
+                        return nil, 0, domain.NewValidationError(domain.FieldError{Field: "codes", Code: "code", Message: "codes must be between 1 and " + limit + " characters", Param: limit})
+                }
+                if !seen[code] {
+                        seen[code] = true
+                        normalized = append(normalized, code)
+                }
+        }
+
+        return normalized, len(codes) - len(normalized), nil
+}
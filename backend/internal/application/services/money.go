@@ -0,0 +1,49 @@
+package services
+
+import (
+        "strconv"
+        "strings"
+
+        "4SaleBackendSkeleton/internal/domain"
+)
+
+// parseAmount parses a client supplied decimal amount in currency. Malformed amounts,
+// amounts with more decimal places than the currency has and negative amounts are
+// reported as a field error.
+func parseAmount(field, value, currency string) (domain.Money, *domain.FieldError) {
+        amount, err := domain.ParseMoney(value, currency)
+        if err != nil {
+                exponent, _ := domain.CurrencyExponent(currency)
+                places := strconv.Itoa(exponent)
+                return domain.Money{}, &domain.FieldError{Field: field, Code: "amount", Message: field + " must be a decimal amount with at most " + places + " decimal places", Param: places}
+        }
+        if amount.IsNegative() {
+                return domain.Money{}, &domain.FieldError{Field: field, Code: "min", Message: field + " must be at least 0", Param: "0"}
+        }
+        return amount, nil
+}
+
+// parseCurrency parses a client supplied ISO 4217 currency code, defaulting to
+// domain.DefaultCurrency when none is given
+func parseCurrency(field, value string) (string, *domain.FieldError) {
+        if value == "" {
+                return domain.DefaultCurrency, nil
+        }
+        if !domain.IsSupportedCurrency(value) {
+                options := strings.Join(domain.SupportedCurrencies(), ",")
+                return "", &domain.FieldError{Field: field, Code: "oneof", Message: field + " must be one of " + options, Param: options}
+        }
+        return value, nil
+}
+
+// parsePrice parses a client supplied price, which must be greater than zero
+func parsePrice(field, value, currency string) (domain.Money, *domain.FieldError) {
+        price, fieldErr := parseAmount(field, value, currency)
+        if fieldErr != nil {
+                return domain.Money{}, fieldErr
+        }
+        if !price.IsPositive() {
+                return domain.Money{}, &domain.FieldError{Field: field, Code: "gt", Message: field + " must be greater than 0", Param: "0"}
+        }
+        return price, nil
+}
\ No newline at end of file
@@ -0,0 +1,235 @@
+package services
+
+import (
+        "context"
+        "errors"
+        "fmt"
+        "net/mail"
+        "strconv"
+        "strings"
+        "time"
+
+        "4SaleBackendSkeleton/internal/application/dto"
+        "4SaleBackendSkeleton/internal/application/validation"
+        "4SaleBackendSkeleton/internal/domain"
+        "4SaleBackendSkeleton/internal/ports"
+        "github.com/rs/zerolog"
+)
+
+// notifyTimeout bounds the background delivery of one purchase notification
+const notifyTimeout = 30 * time.Second
+
+// reminderBatchSize is the number of expiring purchases reminded per query
+const reminderBatchSize = 100
+
+// NotificationService sends buyers notifications on the channels they chose
+type NotificationService struct {
+        notificationRepo ports.NotificationRepository
+        auditor          ports.AuditRecorder
+        // channels are the notifiers by channel; channels without one are not delivered
+        channels map[string]ports.Notifier
+        // reminderLead is how long before a voucher expires its buyer is reminded
+        reminderLead time.Duration
+        logger       zerolog.Logger
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(notificationRepo ports.NotificationRepository, auditor ports.AuditRecorder, channels map[string]ports.Notifier, reminderLead time.Duration, logger zerolog.Logger) *NotificationService {
+        return &NotificationService{
+                notificationRepo: notificationRepo,
+                auditor:          auditor,
+                channels:         channels,
+                reminderLead:     reminderLead,
+                logger:           logger,
+        }
+}
+
+// PurchaseConfirmed notifies the buyer of a purchase in the background
+func (s *NotificationService) PurchaseConfirmed(ctx context.Context, purchase *domain.VoucherPurchase, voucher *domain.Voucher) {
+        s.notifyInBackground(domain.NotificationPurchased, purchase, voucher)
+}
+
+// VoucherRedeemed notifies the buyer of a redemption in the background
+func (s *NotificationService) VoucherRedeemed(ctx context.Context, purchase *domain.VoucherPurchase, voucher *domain.Voucher) {
+        s.notifyInBackground(domain.NotificationRedeemed, purchase, voucher)
+}
+
+// notifyInBackground delivers a purchase notification without holding up the request,
+// which may end before delivery does
+func (s *NotificationService) notifyInBackground(kind string, purchase *domain.VoucherPurchase, voucher *domain.Voucher) {
+        params := &domain.NotificationParams{
+                PurchaseID:     purchase.ID,
+                VoucherTitle:   voucher.Title,
+                VoucherTitleAR: voucher.TitleAR,
+                ExpiresAt:      voucher.ExpiresAt,
+        }
+        buyerID := purchase.BuyerID
+
+        go func() {
+                ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+                defer cancel()
+                s.notify(ctx, kind, buyerID, params)
+        }()
+}
+
+// SendExpiryReminders reminds the buyers of active purchases whose voucher expires within
+// the reminder lead. Each purchase is reminded once.
+func (s *NotificationService) SendExpiryReminders(ctx context.Context) error {
+        now := time.Now()
+        for {
+                purchases, err := s.notificationRepo.ListExpiringPurchases(ctx, now, now.Add(s.reminderLead), reminderBatchSize)
+                if err != nil {
+                        return err
+                }
+
+                for _, purchase := range purchases {
+                        // Claimed reminders leave the listing, so the next query returns the following batch
+                        claimed, err := s.notificationRepo.ClaimReminder(ctx, purchase.PurchaseID, domain.NotificationExpiring, now)
+                        if err != nil {
+                                return err
+                        }
+                        if !claimed {
+                                continue
+                        }
+
+                        expiresAt := purchase.ExpiresAt
+                        s.notify(ctx, domain.NotificationExpiring, purchase.BuyerID, &domain.NotificationParams{
+                                PurchaseID:     purchase.PurchaseID,
+                                VoucherTitle:   purchase.VoucherTitle,
+                                VoucherTitleAR: purchase.VoucherTitleAR,
+                                ExpiresAt:      &expiresAt,
+                        })
+                }
+
+                if len(purchases) < reminderBatchSize {
+                        return nil
+                }
+        }
+}
+
+// notify renders a notification in the user's language and delivers it on each channel
+// they enabled. Failures are logged per channel so one channel does not stop the others.
+func (s *NotificationService) notify(ctx context.Context, kind string, userID int64, params *domain.NotificationParams) {
+        prefs, err := s.preferences(ctx, userID)
+        if err != nil {
+                s.logger.Error().Err(err).Int64("user_id", userID).Str("kind", kind).Msg("Failed to get notification preferences")
+                return
+        }
+
+        for channel, recipient := range prefs.Recipients() {
+                notifier, ok := s.channels[channel]
+                if !ok {
+                        continue
+                }
+
+                notification := domain.NewNotification(kind, channel, recipient, prefs, params)
+                if err := notifier.Notify(ctx, notification); err != nil {
+                        s.logger.Error().
+                                Err(err).
+                                Int64("user_id", userID).
+                                Str("kind", kind).
+                                Str("channel", channel).
+                                Str("purchase_id", params.PurchaseID.String()).
+                                Msg("Failed to send notification")
+                }
+        }
+}
+
+// GetPreferences retrieves a user's notification preferences, or the defaults when none were saved
+func (s *NotificationService) GetPreferences(ctx context.Context, userID int64) (*domain.NotificationPreferences, error) {
+        return s.preferences(ctx, userID)
+}
+
+// UpdatePreferences changes a user's notification preferences. SMS and email can only be
+// enabled once the user has a phone number or email address to deliver to.
+func (s *NotificationService) UpdatePreferences(ctx context.Context, userID int64, req *dto.UpdateNotificationPreferencesRequest) (*domain.NotificationPreferences, error) {
+        // Validate request
+        if err := validation.Validate(req); err != nil {
+                return nil, err
+        }
+
+        prefs, err := s.preferences(ctx, userID)
+        if err != nil {
+                return nil, err
+        }
+        before := auditPreferences(prefs)
+
+        var fieldErrors []domain.FieldError
+        if req.Phone != nil {
+                prefs.Phone = nil
+                if *req.Phone != "" {
+                        phone, ok := normalizePhone(*req.Phone)
+                        if !ok {
+                                fieldErrors = append(fieldErrors, domain.FieldError{Field: "phone", Code: "phone", Message: "phone must be a phone number of 8 to 15 digits"})
+                        }
+                        prefs.Phone = &phone
+                }
+        }
+        if req.EmailAddress != nil {
+                prefs.EmailAddress = nil
+                if *req.EmailAddress != "" {
+                        address, ok := normalizeEmailAddress(*req.EmailAddress)
+                        if !ok {
+                                fieldErrors = append(fieldErrors, domain.FieldError{Field: "email_address", Code: "email", Message: "email_address must be an email address"})
+                        }
+                        prefs.EmailAddress = &address
+                }
+        }
+        if req.Push != nil {
+                prefs.Push = *req.Push
+        }
+        if req.SMS != nil {
+                prefs.SMS = *req.SMS
+        }
+        if req.Email != nil {
+                prefs.Email = *req.Email
+        }
+        if req.Language != nil {
+                prefs.Language = *req.Language
+        }
+
+        if prefs.SMS && prefs.Phone == nil {
+                fieldErrors = append(fieldErrors, domain.FieldError{Field: "phone", Code: "required", Message: "phone is required"})
+        }
+        if prefs.Email && prefs.EmailAddress == nil {
+                fieldErrors = append(fieldErrors, domain.FieldError{Field: "email_address", Code: "required", Message: "email_address is required"})
+        }
+        if len(fieldErrors) > 0 {
+                return nil, domain.NewValidationError(fieldErrors...)
+        }
+
+        prefs.UpdatedAt = time.Now()
+        if err := s.notificationRepo.SavePreferences(ctx, prefs); err != nil {
+                return nil, err
+        }
+
+        event := newAuditEvent(domain.AuditNotificationPreferencesUpdated, domain.AuditTargetNotificationPreferences, strconv.FormatInt(userID, 10), before, auditPreferences(prefs))
+        event.ActorID = &userID
+        s.auditor.Record(ctx, event)
+
+        return prefs, nil
+}
+
+// preferences retrieves a user's saved preferences, falling back to the defaults
+func (s *NotificationService) preferences(ctx context.Context, userID int64) (*domain.NotificationPreferences, error) {
+        prefs, err := s.notificationRepo.GetPreferences(ctx, userID)
+        if errors.Is(err, domain.ErrPreferencesNotFound) {
+                return domain.DefaultNotificationPreferences(userID), nil
+        }
+        if err != nil {
+                return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+        }
+        return prefs, nil
+}
+
+// normalizeEmailAddress checks that value is a bare email address and lowercases its domain
+func normalizeEmailAddress(value string) (string, bool) {
+        value = strings.TrimSpace(value)
+        address, err := mail.ParseAddress(value)
+        if err != nil || address.Address != value {
+                return "", false
+        }
+
+        at := strings.LastIndex(value, "@")
+        return value[:at] + strings.ToLower(value[at:]), true
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+        "strings"
+        "time"
+
+        "4SaleBackendSkeleton/internal/application/dto"
+        "4SaleBackendSkeleton/internal/domain"
+)
+
+// defaultPageSize is the page size used when a list request sets no limit
+const defaultPageSize = 20
+
+// dateLayout is the layout of date filters in list requests
+const dateLayout = "2006-01-02"
+
+// newUserVoucherQuery turns a validated list request into a repository query
+func newUserVoucherQuery(req *dto.UserVoucherListRequest, now time.Time) (*domain.UserVoucherQuery, error) {
+        query := &domain.UserVoucherQuery{
+                BuyerID:  req.UserID,
+                Tag:      normalizeTag(req.Tag),
+                Sort:     req.Sort,
+                Limit:    req.Limit,
+                Language: req.Language,
+                Now:      now,
+        }
+        if query.Language == "" {
+                query.Language = domain.DefaultLanguage
+        }
+        if query.Sort == "" {
+                query.Sort = domain.SortNewest
+        }
+        if query.Limit == 0 {
+                query.Limit = defaultPageSize
+        }
+
+        var fieldErrors []domain.FieldError
+
+        if req.Status != "" {
+                statuses, ok := parseStatuses(req.Status)
+                if !ok {
+                        fieldErrors = append(fieldErrors, domain.FieldError{Field: "status", Code: "oneof", Message: "status must be a comma separated list of: active, redeemed, expired, refunded", Param: "active, redeemed, expired, refunded"})
+                }
+                query.Statuses = statuses
+        }
+
+        if req.From != "" {
+                from, err := time.Parse(dateLayout, req.From)
+                if err != nil {
+                        fieldErrors = append(fieldErrors, domain.FieldError{Field: "from", Code: "date", Message: "from must be a date in YYYY-MM-DD format"})
+                } else {
+                        query.PurchasedFrom = &from
+                }
+        }
+        if req.To != "" {
+                to, err := time.Parse(dateLayout, req.To)
+                if err != nil {
+                        fieldErrors = append(fieldErrors, domain.FieldError{Field: "to", Code: "date", Message: "to must be a date in YYYY-MM-DD format"})
+                } else {
+                        // The end date is inclusive, so the range ends at the start of the next day
+                        end := to.AddDate(0, 0, 1)
+                        query.PurchasedTo = &end
+                }
+        }
+        if query.PurchasedFrom != nil && query.PurchasedTo != nil && !query.PurchasedFrom.Before(*query.PurchasedTo) {
+                fieldErrors = append(fieldErrors, domain.FieldError{Field: "to", Code: "range", Message: "to must not be before from", Param: "from"})
+        }
+
+        if req.Cursor != "" {
+                cursor, err := decodeCursor(req.Cursor, query.Sort)
+                if err != nil {
+                        fieldErrors = append(fieldErrors, domain.FieldError{Field: "cursor", Code: "cursor", Message: "cursor is invalid or was issued for a different sort"})
+                } else {
+                        query.After = cursor
+                }
+        }
+
+        if len(fieldErrors) > 0 {
+                return nil, domain.NewValidationError(fieldErrors...)
+        }
+        return query, nil
+}
+
+// parseStatuses parses a comma separated status filter, dropping duplicates
+func parseStatuses(value string) ([]string, bool) {
+        var statuses []string
+        seen := make(map[string]bool)
+        for _, status := range strings.Split(value, ",") {
+                status = strings.TrimSpace(status)
+                switch status {
+                case domain.StatusActive, domain.StatusRedeemed, domain.StatusExpired, domain.StatusRefunded:
+                default:
+                        return nil, false
+                }
+                if !seen[status] {
+                        seen[status] = true
+                        statuses = append(statuses, status)
+                }
+        }
+        return statuses, true
+}
+
+// decodeCursor decodes a client cursor and checks that it belongs to the requested sort
+// and carries the kind of key that sort expects
+func decodeCursor(encoded, sort string) (*domain.PageCursor, error) {
+        cursor, err := domain.DecodePageCursor(encoded)
+        if err != nil {
+                return nil, err
+        }
+        if cursor.Sort != sort {
+                return nil, domain.ErrInvalidCursor
+        }
+
+        switch sort {
+        case domain.SortNewest, domain.SortOldest:
+                _, err = cursor.TimeKey()
+        case domain.SortPriceAsc, domain.SortPriceDesc:
+                _, err = cursor.AmountKey()
+        case domain.SortPopularity:
+                _, err = cursor.CountKey()
+        case domain.SortRelevance:
+                _, err = cursor.DecimalKey(domain.RelevanceScale)
+        default:
+                err = domain.ErrInvalidCursor
+        }
+        if err != nil {
+                return nil, err
+        }
+
+        return cursor, nil
+}
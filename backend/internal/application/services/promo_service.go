@@ -0,0 +1,99 @@
+package services
+
+import (
+        "context"
+        "fmt"
+        "strconv"
+        "time"
+
+        "4SaleBackendSkeleton/internal/application/dto"
+        "4SaleBackendSkeleton/internal/application/validation"
+        "4SaleBackendSkeleton/internal/domain"
+        "4SaleBackendSkeleton/internal/ports"
+)
+
+// PromoService implements promo code management
+type PromoService struct {
+        promoCodeRepo ports.PromoCodeRepository
+        auditor       ports.AuditRecorder
+}
+
+// NewPromoService creates a new promo service
+func NewPromoService(promoCodeRepo ports.PromoCodeRepository, auditor ports.AuditRecorder) *PromoService {
+        return &PromoService{
+                promoCodeRepo: promoCodeRepo,
+                auditor:       auditor,
+        }
+}
+
+// CreatePromoCode creates a new promo code
+func (s *PromoService) CreatePromoCode(ctx context.Context, req *dto.CreatePromoCodeRequest) (*domain.PromoCode, error) {
+        // Validate request
+        if err := validation.Validate(req); err != nil {
+                return nil, err
+        }
+
+        code := domain.NormalizePromoCode(req.Code)
+        if code == "" || !isPromoCode(code) {
+                return nil, domain.NewValidationError(domain.FieldError{Field: "code", Code: "charset", Message: "code must only contain letters, digits, dashes and underscores"})
+        }
+
+        promo := &domain.PromoCode{
+                Code:           code,
+                DiscountType:   req.DiscountType,
+                FundedBy:       req.FundedBy,
+                MerchantID:     req.MerchantID,
+                MaxUses:        req.MaxUses,
+                MaxUsesPerUser: req.MaxUsesPerUser,
+                StartsAt:       req.StartsAt,
+                EndsAt:         req.EndsAt,
+                CreatedAt:      time.Now(),
+        }
+
+        switch req.DiscountType {
+        case domain.DiscountPercentage:
+                if req.PercentBasisPoints == 0 {
+                        return nil, domain.NewValidationError(domain.FieldError{Field: "percent_basis_points", Code: "required", Message: "percent_basis_points is required for percentage codes"})
+                }
+                promo.PercentBasisPoints = req.PercentBasisPoints
+        case domain.DiscountFixed:
+                if req.Amount == "" {
+                        return nil, domain.NewValidationError(domain.FieldError{Field: "amount", Code: "required", Message: "amount is required for fixed codes"})
+                }
+                currency, fieldErr := parseCurrency("currency", req.Currency)
+                if fieldErr != nil {
+                        return nil, domain.NewValidationError(*fieldErr)
+                }
+                amount, fieldErr := parsePrice("amount", req.Amount.String(), currency)
+                if fieldErr != nil {
+                        return nil, domain.NewValidationError(*fieldErr)
+                }
+                promo.Amount = &amount
+        }
+
+        // A merchant can only fund discounts on its own vouchers
+        if req.FundedBy == domain.FundedByMerchant && req.MerchantID == nil {
+                return nil, domain.NewValidationError(domain.FieldError{Field: "merchant_id", Code: "required", Message: "merchant_id is required for merchant funded codes"})
+        }
+        if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+                return nil, domain.NewValidationError(domain.FieldError{Field: "ends_at", Code: "after", Message: "ends_at must be after starts_at", Param: "starts_at"})
+        }
+
+        if err := s.promoCodeRepo.CreatePromoCode(ctx, promo); err != nil {
+                return nil, fmt.Errorf("failed to create promo code: %w", err)
+        }
+
+        s.auditor.Record(ctx, newAuditEvent(domain.AuditPromoCodeCreated, domain.AuditTargetPromoCode, strconv.FormatInt(promo.ID, 10), nil, promo))
+
+        return promo, nil
+}
+
+// isPromoCode reports whether a normalized code holds only the characters codes are made of
+func isPromoCode(code string) bool {
+        for _, r := range code {
+                if !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') && r != '-' && r != '_' {
+                        return false
+                }
+        }
+        return true
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+        "context"
+        "fmt"
+        "time"
+
+        "4SaleBackendSkeleton/internal/application/dto"
+        "4SaleBackendSkeleton/internal/application/validation"
+        "4SaleBackendSkeleton/internal/domain"
+        "4SaleBackendSkeleton/internal/ports"
+)
+
+// RedemptionService implements the redemption history of merchants
+type RedemptionService struct {
+        redemptionRepo ports.RedemptionRepository
+}
+
+// NewRedemptionService creates a new redemption service
+func NewRedemptionService(redemptionRepo ports.RedemptionRepository) *RedemptionService {
+        return &RedemptionService{redemptionRepo: redemptionRepo}
+}
+
+// ListRedemptions lists the redemptions of a merchant's vouchers across its branches, newest first
+func (s *RedemptionService) ListRedemptions(ctx context.Context, req *dto.RedemptionListRequest) (*dto.RedemptionListResponse, error) {
+        // Validate request
+        if err := validation.Validate(req); err != nil {
+                return nil, err
+        }
+
+        query := &domain.RedemptionQuery{
+                MerchantID: req.MerchantID,
+                BranchID:   req.BranchID,
+                Limit:      req.Limit,
+        }
+        if query.Limit == 0 {
+                query.Limit = defaultPageSize
+        }
+
+        var fieldErrors []domain.FieldError
+        if req.From != "" {
+                from, err := time.Parse(dateLayout, req.From)
+                if err != nil {
+                        fieldErrors = append(fieldErrors, domain.FieldError{Field: "from", Code: "date", Message: "from must be a date in YYYY-MM-DD format"})
+                } else {
+                        query.From = &from
+                }
+        }
+        if req.To != "" {
+                to, err := time.Parse(dateLayout, req.To)
+                if err != nil {
+                        fieldErrors = append(fieldErrors, domain.FieldError{Field: "to", Code: "date", Message: "to must be a date in YYYY-MM-DD format"})
+                } else {
+                        // The end date is inclusive, so the range ends at the start of the next day
+                        end := to.AddDate(0, 0, 1)
+                        query.To = &end
+                }
+        }
+        if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+                fieldErrors = append(fieldErrors, domain.FieldError{Field: "to", Code: "range", Message: "to must not be before from", Param: "from"})
+        }
+        if req.Cursor != "" {
+                cursor, err := decodeCursor(req.Cursor, domain.SortNewest)
+                if err != nil {
+                        fieldErrors = append(fieldErrors, domain.FieldError{Field: "cursor", Code: "cursor", Message: "cursor is invalid"})
+                } else {
+                        query.After = cursor
+                }
+        }
+        if len(fieldErrors) > 0 {
+                return nil, domain.NewValidationError(fieldErrors...)
+        }
+
+        page, err := s.redemptionRepo.ListRedemptions(ctx, query)
+        if err != nil {
+                return nil, fmt.Errorf("failed to list redemptions: %w", err)
+        }
+
+        response := &dto.RedemptionListResponse{
+                Redemptions: page.Redemptions,
+                Meta:        dto.PageMeta{Limit: query.Limit},
+        }
+        if page.Next != nil {
+                nextCursor := page.Next.Encode()
+                response.Meta.NextCursor = &nextCursor
+        }
+
+        return response, nil
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+        "context"
+        "crypto/rand"
+        "crypto/sha256"
+        "crypto/subtle"
+        "encoding/base64"
+        "encoding/hex"
+        "fmt"
+        "strings"
+        "time"
+
+        "4SaleBackendSkeleton/internal/application/dto"
+        "4SaleBackendSkeleton/internal/application/validation"
+        "4SaleBackendSkeleton/internal/domain"
+        "4SaleBackendSkeleton/internal/ports"
+        "github.com/google/uuid"
+)
+
+// TransferService implements gifting a purchased voucher to another user
+type TransferService struct {
+        voucherRepo         ports.VoucherRepository
+        voucherPurchaseRepo ports.VoucherPurchaseRepository
+        transferRepo        ports.TransferRepository
+        codePoolRepo        ports.CodePoolRepository
+        qrGenerator         ports.QRCodeGenerator
+        auditor             ports.AuditRecorder
+        // ttl is how long a transfer can be accepted for
+        ttl time.Duration
+}
+
+// NewTransferService creates a new transfer service
+func NewTransferService(
+        voucherRepo ports.VoucherRepository,
+        voucherPurchaseRepo ports.VoucherPurchaseRepository,
+        transferRepo ports.TransferRepository,
+        codePoolRepo ports.CodePoolRepository,
+        qrGenerator ports.QRCodeGenerator,
+        auditor ports.AuditRecorder,
+        ttl time.Duration,
+) *TransferService {
+        return &TransferService{
+                voucherRepo:         voucherRepo,
+                voucherPurchaseRepo: voucherPurchaseRepo,
+                transferRepo:        transferRepo,
+                codePoolRepo:        codePoolRepo,
+                qrGenerator:         qrGenerator,
+                auditor:             auditor,
+                ttl:                 ttl,
+        }
+}
+
+// CreateTransfer offers one of the user's active purchases to another user. The returned
+// transfer carries the claim token a phone recipient needs to accept it.
+func (s *TransferService) CreateTransfer(ctx context.Context, userID int64, purchaseID uuid.UUID, req *dto.CreateTransferRequest) (*domain.PurchaseTransfer, error) {
+        // Validate request
+        if err := validation.Validate(req); err != nil {
+                return nil, err
+        }
+        toPhone, err := transferRecipient(userID, req)
+        if err != nil {
+                return nil, err
+        }
+
+        now := time.Now()
+        if _, err := s.transferablePurchase(ctx, userID, purchaseID, now); err != nil {
+                return nil, err
+        }
+
+        token, tokenHash, err := newClaimToken()
+        if err != nil {
+                return nil, err
+        }
+
+        transfer := &domain.PurchaseTransfer{
+                ID:          uuid.New(),
+                PurchaseID:  purchaseID,
+                FromBuyerID: userID,
+                ToUserID:    req.ToUserID,
+                ToPhone:     toPhone,
+                Status:      domain.TransferPending,
+                CreatedAt:   now,
+                ExpiresAt:   now.Add(s.ttl),
+                TokenHash:   tokenHash,
+        }
+
+        if err := s.transferRepo.CreateTransfer(ctx, transfer); err != nil {
+                return nil, fmt.Errorf("failed to create transfer: %w", err)
+        }
+        s.auditor.Record(ctx, newAuditEvent(domain.AuditTransferCreated, domain.AuditTargetTransfer, transfer.ID.String(), nil, transfer))
+
+        transfer.ClaimToken = token
+        return transfer, nil
+}
+
+// AcceptTransfer makes the user the owner of a transferred purchase and issues it a new QR code,
+// which invalidates the sender's. Transfers to a user ID can only be accepted by that user;
+// transfers to a phone number by whoever presents the claim token.
+func (s *TransferService) AcceptTransfer(ctx context.Context, userID int64, transferID uuid.UUID, req *dto.AcceptTransferRequest) (*domain.PurchaseTransfer, error) {
+        // Validate request
+        if err := validation.Validate(req); err != nil {
+                return nil, err
+        }
+
+        transfer, err := s.transferRepo.GetTransfer(ctx, transferID)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get transfer: %w", err)
+        }
+
+        // Transfers the user may not accept are reported as missing so their IDs are not disclosed
+        if userID == transfer.FromBuyerID || !canClaim(transfer, userID, req.ClaimToken) {
+                return nil, domain.ErrTransferNotFound
+        }
+
+        now := time.Now()
+        if !transfer.IsOpen(now) {
+                return nil, domain.ErrTransferNotPending
+        }
+        purchase, err := s.transferablePurchase(ctx, transfer.FromBuyerID, transfer.PurchaseID, now)
+        if err != nil {
+                return nil, err
+        }
+
+        // A fresh payload makes the sender's QR code stop redeeming the voucher
+        qrPayload := domain.NewQRPayload(purchase.VoucherID, userID)
+        qrCode, err := s.qrGenerator.EncodeQRCode(qrPayload)
+        if err != nil {
+                return nil, fmt.Errorf("failed to generate QR code: %w", err)
+        }
+
+        accepted, err := s.transferRepo.AcceptTransfer(ctx, transferID, userID, qrCode, qrPayload, now)
+        if err != nil {
+                return nil, fmt.Errorf("failed to accept transfer: %w", err)
+        }
+        s.auditor.Record(ctx, newAuditEvent(domain.AuditTransferAccepted, domain.AuditTargetTransfer, transferID.String(), transfer, accepted))
+
+        return accepted, nil
+}
+
+// CancelTransfer withdraws a transfer the user sent that has not been accepted yet
+func (s *TransferService) CancelTransfer(ctx context.Context, userID int64, transferID uuid.UUID) error {
+        transfer, err := s.transferRepo.GetTransfer(ctx, transferID)
+        if err != nil {
+                return fmt.Errorf("failed to get transfer: %w", err)
+        }
+        if transfer.FromBuyerID != userID {
+                return domain.ErrTransferNotFound
+        }
+
+        now := time.Now()
+        if err := s.transferRepo.CancelTransfer(ctx, transferID, now); err != nil {
+                return fmt.Errorf("failed to cancel transfer: %w", err)
+        }
+
+        cancelled := *transfer
+        cancelled.Status = domain.TransferCancelled
+        cancelled.ResolvedAt = &now
+        s.auditor.Record(ctx, newAuditEvent(domain.AuditTransferCancelled, domain.AuditTargetTransfer, transferID.String(), transfer, &cancelled))
+
+        return nil
+}
+
+// GetTransferHistory lists the transfers of a purchase the user currently owns, oldest first
+func (s *TransferService) GetTransferHistory(ctx context.Context, userID int64, purchaseID uuid.UUID) ([]*domain.PurchaseTransfer, error) {
+        purchase, err := s.voucherPurchaseRepo.GetPurchaseByID(ctx, purchaseID)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get voucher purchase: %w", err)
+        }
+        if purchase.BuyerID != userID {
+                return nil, domain.ErrPurchaseNotFound
+        }
+
+        transfers, err := s.transferRepo.GetTransfersByPurchaseID(ctx, purchaseID)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get transfers: %w", err)
+        }
+
+        now := time.Now()
+        for _, transfer := range transfers {
+                transfer.ExpireAt(now)
+        }
+
+        return transfers, nil
+}
+
+// transferablePurchase retrieves a purchase owned by buyerID that is neither redeemed,
+// refunded, holding a merchant's pool code nor for an expired voucher
+func (s *TransferService) transferablePurchase(ctx context.Context, buyerID int64, purchaseID uuid.UUID, now time.Time) (*domain.VoucherPurchase, error) {
+        purchase, err := s.voucherPurchaseRepo.GetPurchaseByID(ctx, purchaseID)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get voucher purchase: %w", err)
+        }
+        if purchase.BuyerID != buyerID {
+                return nil, domain.ErrPurchaseNotFound
+        }
+        if err := domain.CheckTransferable(purchase.Status); err != nil {
+                return nil, err
+        }
+
+        voucher, err := s.voucherRepo.GetVoucherByID(ctx, purchase.VoucherID)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get voucher: %w", err)
+        }
+        if voucher.IsExpired(now) {
+                return nil, domain.ErrVoucherExpired
+        }
+
+        _, pooled, err := s.codePoolRepo.GetAssignedCode(ctx, purchase.ID)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get voucher code: %w", err)
+        }
+        if pooled {
+                return nil, domain.ErrTransferPooledCode
+        }
+
+        return purchase, nil
+}
+
+// transferRecipient checks that exactly one recipient is given and that it is not the sender,
+// and returns the normalized phone number, if any
+func transferRecipient(userID int64, req *dto.CreateTransferRequest) (*string, error) {
+        switch {
+        case req.ToUserID == nil && req.ToPhone == nil:
+                return nil, domain.NewValidationError(domain.FieldError{Field: "to_user_id", Code: "required", Message: "to_user_id or to_phone is required"})
+        case req.ToUserID != nil && req.ToPhone != nil:
+                return nil, domain.NewValidationError(domain.FieldError{Field: "to_phone", Code: "exclusive", Message: "to_phone cannot be combined with to_user_id", Param: "to_user_id"})
+        case req.ToUserID != nil:
+                if *req.ToUserID == userID {
+                        return nil, domain.NewValidationError(domain.FieldError{Field: "to_user_id", Code: "self", Message: "to_user_id cannot be yourself"})
+                }
+                return nil, nil
+        }
+
+        phone, ok := normalizePhone(*req.ToPhone)
+        if !ok {
+                return nil, domain.NewValidationError(domain.FieldError{Field: "to_phone", Code: "phone", Message: "to_phone must be a phone number of 8 to 15 digits"})
+        }
+        return &phone, nil
+}
+
+// normalizePhone strips spaces, dashes and parentheses from a phone number, keeping a leading +
+func normalizePhone(phone string) (string, bool) {
+        phone = strings.TrimSpace(phone)
+        prefix := ""
+        if strings.HasPrefix(phone, "+") {
+                prefix, phone = "+", phone[1:]
+        }
+
+        digits := strings.Map(func(r rune) rune {
+                switch {
+                case r >= '0' && r <= '9':
+                        return r
+                case r == ' ' || r == '-' || r == '(' || r == ')':
+                        return -1
+                default:
+                        return 'x'
+                }
+        }, phone)
+
+        if strings.Contains(digits, "x") || len(digits) < 8 || len(digits) > 15 {
+                return "", false
+        }
+        return prefix + digits, true
+}
+
+// newClaimToken returns a random claim token and the hash stored in its place
+func newClaimToken() (string, string, error) {
+        b := make([]byte, 24)
+        if _, err := rand.Read(b); err != nil {
+                return "", "", fmt.Errorf("failed to generate claim token: %w", err)
+        }
+        token := base64.RawURLEncoding.EncodeToString(b)
+        return token, hashClaimToken(token), nil
+}
+
+// hashClaimToken returns the hex SHA-256 of a claim token
+func hashClaimToken(token string) string {
+        sum := sha256.Sum256([]byte(token))
+        return hex.EncodeToString(sum[:])
+}
+
+// canClaim reports whether the user may accept the transfer: its addressee when it names a
+// user ID, or the holder of the claim token when it names a phone number
+func canClaim(transfer *domain.PurchaseTransfer, userID int64, claimToken string) bool {
+        if transfer.ToUserID != nil {
+                return *transfer.ToUserID == userID
+        }
+        if claimToken == "" {
+                return false
+        }
+        return subtle.ConstantTimeCompare([]byte(hashClaimToken(claimToken)), []byte(transfer.TokenHash)) == 1
+}
@@ -0,0 +1,294 @@
+package services
+
+import (
+        "context"
+        "encoding/csv"
+        "encoding/json"
+        "errors"
+        "io"
+        "strconv"
+        "strings"
+        "time"
+
+        "4SaleBackendSkeleton/internal/application/dto"
+        "4SaleBackendSkeleton/internal/application/validation"
+        "4SaleBackendSkeleton/internal/domain"
+        "4SaleBackendSkeleton/internal/ports"
+        "github.com/rs/zerolog"
+)
+
+// maxImportRows bounds the number of rows in one import file
+const maxImportRows = 5000
+
+// importTagSeparator separates the tags of a CSV row
+const importTagSeparator = "|"
+
+// importRow is one decoded row of an import file; fieldErrors holds the cells that could not be decoded
+type importRow struct {
+        req         dto.CreateVoucherRequest
+        fieldErrors []domain.FieldError
+}
+
+// VoucherImportService implements bulk voucher imports
+type VoucherImportService struct {
+        voucherRepo  ports.VoucherRepository
+        categoryRepo ports.CategoryRepository
+        auditor      ports.AuditRecorder
+        batchSize    int
+        logger       zerolog.Logger
+}
+
+// NewVoucherImportService creates a new voucher import service storing batchSize vouchers per transaction
+func NewVoucherImportService(voucherRepo ports.VoucherRepository, categoryRepo ports.CategoryRepository, auditor ports.AuditRecorder, batchSize int, logger zerolog.Logger) *VoucherImportService {
+        return &VoucherImportService{
+                voucherRepo:  voucherRepo,
+                categoryRepo: categoryRepo,
+                auditor:      auditor,
+                batchSize:    batchSize,
+                logger:       logger,
+        }
+}
+
+// ImportVouchers validates every row of a CSV or JSON voucher file and, unless the request
+// is a dry run, stores the valid rows in batches. Invalid rows are reported without stopping
+// the import; a batch that fails to store is reported row by row and the next batch still runs.
+func (s *VoucherImportService) ImportVouchers(ctx context.Context, req *dto.ImportVouchersRequest, data io.Reader) (*domain.VoucherImportReport, error) {
+        // Validate request
+        if err := validation.Validate(req); err != nil {
+                return nil, err
+        }
+
+        var rows []importRow
+        var err error
+        if req.Format == "csv" {
+                rows, err = decodeCSVImport(data)
+        } else {
+                rows, err = decodeJSONImport(data)
+        }
+        if err != nil {
+                return nil, err
+        }
+
+        report := domain.NewVoucherImportReport(req.DryRun)
+        report.Total = len(rows)
+
+        // Validate every row before storing any, so a dry run reports exactly what an import would do
+        now := time.Now()
+        var vouchers []*domain.Voucher
+        var voucherRows []int
+        for i := range rows {
+                row := &rows[i]
+                if req.MerchantID != nil {
+                        if row.req.UserID != 0 && row.req.UserID != *req.MerchantID {
+                                row.fieldErrors = append(row.fieldErrors, domain.FieldError{Field: "user_id", Code: "merchant", Message: "user_id must be empty or the importing merchant"})
+                        }
+                        row.req.UserID = *req.MerchantID
+                }
+                if len(row.fieldErrors) > 0 {
+                        report.Reject(i+1, row.fieldErrors...)
+                        continue
+                }
+
+                voucher, err := newVoucher(ctx, s.categoryRepo, &row.req, now)
+                if err != nil {
+                        var validationErr *domain.ValidationError
+                        if !errors.As(err, &validationErr) {
+                                return nil, err
+                        }
+                        report.Reject(i+1, validationErr.Fields...)
+                        continue
+                }
+                vouchers = append(vouchers, voucher)
+                voucherRows = append(voucherRows, i+1)
+        }
+        report.Valid = len(vouchers)
+
+        if req.DryRun {
+                return report, nil
+        }
+
+        for start := 0; start < len(vouchers); start += s.batchSize {
+                end := start + s.batchSize
+                if end > len(vouchers) {
+                        end = len(vouchers)
+                }
+
+                if err := s.voucherRepo.CreateVouchers(ctx, vouchers[start:end]); err != nil {
+                        if ctx.Err() != nil {
+                                return nil, ctx.Err()
+                        }
+                        s.logger.Error().Err(err).Int("first_row", voucherRows[start]).Int("rows", end-start).Msg("Failed to store voucher import batch")
+                        for _, row := range voucherRows[start:end] {
+                                report.Reject(row, domain.FieldError{Field: "row", Code: "stored", Message: "row could not be stored"})
+                        }
+                        continue
+                }
+
+                for i := start; i < end; i++ {
+                        report.Vouchers = append(report.Vouchers, domain.ImportedVoucher{Row: voucherRows[i], VoucherID: vouchers[i].ID})
+
+                        event := newAuditEvent(domain.AuditVoucherImported, domain.AuditTargetVoucher, vouchers[i].ID.String(), nil, vouchers[i])
+                        event.ActorID = &vouchers[i].UserID
+                        s.auditor.Record(ctx, event)
+                }
+                report.Imported += end - start
+        }
+
+        return report, nil
+}
+
+// tooManyImportRows is returned when a file has more than maxImportRows rows
+func tooManyImportRows() error {
+        limit := strconv.Itoa(maxImportRows)
+        return domain.NewValidationError(domain.FieldError{Field: "rows", Code: "max", Message: "rows must be at most " + limit, Param: limit})
+}
+
+// decodeJSONImport decodes a JSON array of voucher objects shaped like the voucher-created webhook payload
+func decodeJSONImport(data io.Reader) ([]importRow, error) {
+        decoder := json.NewDecoder(data)
+        invalidFile := domain.NewValidationError(domain.FieldError{Field: "file", Code: "format", Message: "file must be a JSON array of vouchers"})
+
+        if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+                return nil, invalidFile
+        }
+
+        var rows []importRow
+        for decoder.More() {
+                if len(rows) == maxImportRows {
+                        return nil, tooManyImportRows()
+                }
+
+                var row importRow
+                if err := decoder.Decode(&row.req); err != nil {
+                        var typeErr *json.UnmarshalTypeError
+                        if !errors.As(err, &typeErr) {
+                                return nil, invalidFile
+                        }
+                        // The value was read in full, so the next row can still be decoded
+                        row.fieldErrors = []domain.FieldError{{Field: typeErr.Field, Code: "type", Message: typeErr.Field + " has the wrong type"}}
+                }
+                rows = append(rows, row)
+        }
+
+        if _, err := decoder.Token(); err != nil {
+                return nil, invalidFile
+        }
+
+        return rows, nil
+}
+
+// decodeCSVImport decodes a CSV file whose header names the voucher-created webhook fields.
+// Tags are separated by "|" and expires_at is an RFC 3339 timestamp.
+func decodeCSVImport(data io.Reader) ([]importRow, error) {
+        reader := csv.NewReader(data)
+        reader.TrimLeadingSpace = true
+
+        header, err := reader.Read()
+        if err != nil {
+                return nil, domain.NewValidationError(domain.FieldError{Field: "file", Code: "format", Message: "file must be a CSV file with a header row"})
+        }
+        for i, column := range header {
+                header[i] = strings.ToLower(strings.TrimSpace(column))
+                if !isImportColumn(header[i]) {
+                        return nil, domain.NewValidationError(domain.FieldError{Field: "file", Code: "column", Message: "unknown column " + column})
+                }
+        }
+        reader.FieldsPerRecord = len(header)
+
+        var rows []importRow
+        for {
+                record, err := reader.Read()
+                if err == io.EOF {
+                        break
+                }
+                if len(rows) == maxImportRows {
+                        return nil, tooManyImportRows()
+                }
+
+                var row importRow
+                if err != nil {
+                        var parseErr *csv.ParseError
+                        if !errors.As(err, &parseErr) || !errors.Is(parseErr.Err, csv.ErrFieldCount) {
+                                return nil, domain.NewValidationError(domain.FieldError{Field: "file", Code: "format", Message: "file is not valid CSV: " + err.Error()})
+                        }
+                        row.fieldErrors = []domain.FieldError{{Field: "row", Code: "columns", Message: "row must have one value per header column"}}
+                        rows = append(rows, row)
+                        continue
+                }
+
+                for i, value := range record {
+                        if fieldErr := setImportColumn(&row.req, header[i], strings.TrimSpace(value)); fieldErr != nil {
+                                row.fieldErrors = append(row.fieldErrors, *fieldErr)
+                        }
+                }
+                rows = append(rows, row)
+        }
+
+        return rows, nil
+}
+
+// importColumns are the CSV columns of a voucher import
+var importColumns = []string{"adv_id", "user_id", "title", "title_ar", "description", "description_ar", "price", "currency", "photo", "expires_at", "category_id", "tags"}
+
+// isImportColumn reports whether column is an import CSV column
+func isImportColumn(column string) bool {
+        for _, c := range importColumns {
+                if c == column {
+                        return true
+                }
+        }
+        return false
+}
+
+// setImportColumn sets the request field of a CSV column; empty values leave it unset
+func setImportColumn(req *dto.CreateVoucherRequest, column, value string) *domain.FieldError {
+        if value == "" {
+                return nil
+        }
+
+        optional := func() *string { return &value }
+        integer := func(target *int64) *domain.FieldError {
+                n, err := strconv.ParseInt(value, 10, 64)
+                if err != nil {
+                        return &domain.FieldError{Field: column, Code: "type", Message: column + " must be an integer"}
+                }
+                *target = n
+                return nil
+        }
+
+        switch column {
+        case "adv_id":
+                return integer(&req.AdvID)
+        case "user_id":
+                return integer(&req.UserID)
+        case "title":
+                req.Title = value
+        case "title_ar":
+                req.TitleAR = optional()
+        case "description":
+                req.Description = optional()
+        case "description_ar":
+                req.DescriptionAR = optional()
+        case "price":
+                req.Price = json.Number(value)
+        case "currency":
+                req.Currency = value
+        case "photo":
+                req.Photo = optional()
+        case "expires_at":
+                expiresAt, err := time.Parse(time.RFC3339, value)
+                if err != nil {
+                        return &domain.FieldError{Field: column, Code: "type", Message: column + " must be an RFC 3339 timestamp"}
+                }
+                req.ExpiresAt = &expiresAt
+        case "category_id":
+                var categoryID int64
+                if fieldErr := integer(&categoryID); fieldErr != nil {
+                        return fieldErr
+                }
+                req.CategoryID = &categoryID
+        case "tags":
+                req.Tags = strings.Split(value, importTagSeparator)
+        }
+        return nil
+}
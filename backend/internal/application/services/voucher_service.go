@@ -2,156 +2,906 @@ package services
 
 import (
         "context"
+        "errors"
         "fmt"
         "time"
 
         "4SaleBackendSkeleton/internal/application/dto"
+        "4SaleBackendSkeleton/internal/application/validation"
         "4SaleBackendSkeleton/internal/domain"
         "4SaleBackendSkeleton/internal/ports"
         "github.com/google/uuid"
 )
 
+// listingUpdateAttempts bounds how often a listing event is re-applied after racing a merchant edit
+const listingUpdateAttempts = 3
+
 // VoucherService implements the voucher business logic
 type VoucherService struct {
         voucherRepo         ports.VoucherRepository
         voucherPurchaseRepo ports.VoucherPurchaseRepository
+        categoryRepo        ports.CategoryRepository
+        ledgerRepo          ports.LedgerRepository
+        codePoolRepo        ports.CodePoolRepository
+        promoCodeRepo       ports.PromoCodeRepository
+        reservationRepo     ports.ReservationRepository
         qrGenerator         ports.QRCodeGenerator
+        rateProvider        ports.RateProvider
+        codePoolAlerter     ports.CodePoolAlerter
+        fraudChecker        ports.FraudChecker
+        auditor             ports.AuditRecorder
+        notifier            ports.PurchaseNotifier
+        events              ports.EventPublisher
+        refundPolicy        domain.RefundPolicy
+        // reportingCurrency is the currency purchases are snapshotted into for reports
+        reportingCurrency string
+        // codePoolLowThreshold is the number of unassigned codes at which a pool is reported low
+        codePoolLowThreshold int64
+        // reservationHold is how long a reservation holds its voucher
+        reservationHold time.Duration
 }
 
 // NewVoucherService creates a new voucher service
 func NewVoucherService(
         voucherRepo ports.VoucherRepository,
         voucherPurchaseRepo ports.VoucherPurchaseRepository,
+        categoryRepo ports.CategoryRepository,
+        ledgerRepo ports.LedgerRepository,
+        codePoolRepo ports.CodePoolRepository,
+        promoCodeRepo ports.PromoCodeRepository,
+        reservationRepo ports.ReservationRepository,
         qrGenerator ports.QRCodeGenerator,
+        rateProvider ports.RateProvider,
+        codePoolAlerter ports.CodePoolAlerter,
+        fraudChecker ports.FraudChecker,
+        auditor ports.AuditRecorder,
+        notifier ports.PurchaseNotifier,
+        events ports.EventPublisher,
+        refundPolicy domain.RefundPolicy,
+        reportingCurrency string,
+        codePoolLowThreshold int64,
+        reservationHold time.Duration,
 ) *VoucherService {
         return &VoucherService{
-                voucherRepo:         voucherRepo,
-                voucherPurchaseRepo: voucherPurchaseRepo,
-                qrGenerator:         qrGenerator,
+                voucherRepo:          voucherRepo,
+                voucherPurchaseRepo:  voucherPurchaseRepo,
+                categoryRepo:         categoryRepo,
+                ledgerRepo:           ledgerRepo,
+                codePoolRepo:         codePoolRepo,
+                promoCodeRepo:        promoCodeRepo,
+                reservationRepo:      reservationRepo,
+                qrGenerator:          qrGenerator,
+                rateProvider:         rateProvider,
+                codePoolAlerter:      codePoolAlerter,
+                fraudChecker:         fraudChecker,
+                auditor:              auditor,
+                notifier:             notifier,
+                events:               events,
+                refundPolicy:         refundPolicy,
+                reportingCurrency:    reportingCurrency,
+                codePoolLowThreshold: codePoolLowThreshold,
+                reservationHold:      reservationHold,
         }
 }
 
 // CreateVoucher creates a new voucher
 func (s *VoucherService) CreateVoucher(ctx context.Context, req *dto.CreateVoucherRequest) (*domain.Voucher, error) {
-        // Validate request
-        if req.AdvID <= 0 {
-                return nil, fmt.Errorf("invalid adv_id")
+        voucher, err := newVoucher(ctx, s.categoryRepo, req, time.Now())
+        if err != nil {
+                return nil, err
+        }
+
+        // Save to repository
+        if err := s.voucherRepo.CreateVoucher(ctx, voucher); err != nil {
+                return nil, fmt.Errorf("failed to create voucher: %w", err)
         }
-        if req.UserID <= 0 {
-                return nil, fmt.Errorf("invalid user_id")
+
+        event := newAuditEvent(domain.AuditVoucherCreated, domain.AuditTargetVoucher, voucher.ID.String(), nil, voucher)
+        event.ActorID = &voucher.UserID
+        s.auditor.Record(ctx, event)
+
+        return voucher, nil
+}
+
+// newVoucher validates a create request and builds the active voucher it describes
+func newVoucher(ctx context.Context, categoryRepo ports.CategoryRepository, req *dto.CreateVoucherRequest, now time.Time) (*domain.Voucher, error) {
+        // Validate request
+        if err := validation.Validate(req); err != nil {
+                return nil, err
         }
-        if req.Title == "" {
-                return nil, fmt.Errorf("title is required")
+
+        currency, fieldErr := parseCurrency("currency", req.Currency)
+        if fieldErr != nil {
+                return nil, domain.NewValidationError(*fieldErr)
         }
-        if req.Price < 0 {
-                return nil, fmt.Errorf("price must be non-negative")
+        price, fieldErr := parsePrice("price", req.Price.String(), currency)
+        if fieldErr != nil {
+                return nil, domain.NewValidationError(*fieldErr)
         }
 
-        // Create voucher entity
-        voucher := &domain.Voucher{
-                ID:          uuid.New(),
-                AdvID:       req.AdvID,
-                UserID:      req.UserID,
-                Title:       req.Title,
-                Description: req.Description,
-                Price:       req.Price,
-                PhotoURL:    req.Photo,
-                CreatedAt:   time.Now(),
+        if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+                return nil, domain.NewValidationError(domain.FieldError{Field: "expires_at", Code: "future", Message: "expires_at must be in the future"})
         }
 
-        // Save to repository
-        if err := s.voucherRepo.CreateVoucher(ctx, voucher); err != nil {
-                return nil, fmt.Errorf("failed to create voucher: %w", err)
+        tags, err := normalizeTags(req.Tags)
+        if err != nil {
+                return nil, err
+        }
+        if _, err := categorySubtree(ctx, categoryRepo, req.CategoryID, "category_id"); err != nil {
+                return nil, err
         }
 
-        return voucher, nil
+        return &domain.Voucher{
+                ID:            uuid.New(),
+                AdvID:         req.AdvID,
+                UserID:        req.UserID,
+                Title:         req.Title,
+                TitleAR:       req.TitleAR,
+                Description:   req.Description,
+                DescriptionAR: req.DescriptionAR,
+                Price:         price,
+                PhotoURL:      req.Photo,
+                CategoryID:    req.CategoryID,
+                Tags:          tags,
+                Status:        domain.VoucherStatusActive,
+                Version:       1,
+                ExpiresAt:     req.ExpiresAt,
+                CreatedAt:     now,
+        }, nil
 }
 
 // PurchaseVoucher creates a new voucher purchase
 func (s *VoucherService) PurchaseVoucher(ctx context.Context, req *dto.PurchaseVoucherRequest) (*domain.VoucherPurchase, error) {
         // Validate request
-        if req.VoucherID == uuid.Nil {
-                return nil, fmt.Errorf("invalid voucher_id")
+        if err := validation.Validate(req); err != nil {
+                return nil, err
+        }
+
+        return s.purchaseVoucher(ctx, req, nil)
+}
+
+// purchaseVoucher sells a voucher to the buyer, confirming their reservation if one is given
+func (s *VoucherService) purchaseVoucher(ctx context.Context, req *dto.PurchaseVoucherRequest, reservationID *uuid.UUID) (*domain.VoucherPurchase, error) {
+        now := time.Now()
+        voucher, err := s.sellableVoucher(ctx, req.VoucherID, now)
+        if err != nil {
+                return nil, err
+        }
+
+        // A voucher held for another buyer's checkout cannot be sold until the hold ends; both
+        // checks are repeated under the voucher's lock as the purchase is recorded
+        hold, err := s.reservationRepo.GetActiveReservation(ctx, req.VoucherID, now)
+        if err != nil && !errors.Is(err, domain.ErrReservationNotFound) {
+                return nil, fmt.Errorf("failed to check reservation: %w", err)
+        }
+        if hold != nil && hold.BuyerID != req.BuyerID {
+                return nil, domain.ErrVoucherReserved
+        }
+
+        if err := s.checkFraud(ctx, &domain.FraudSignals{
+                Operation:  domain.FraudOperationPurchase,
+                BuyerID:    req.BuyerID,
+                MerchantID: voucher.UserID,
+                VoucherID:  voucher.ID,
+                At:         now,
+        }); err != nil {
+                return nil, err
+        }
+
+        // The buyer pays the voucher's price less the promo code's discount, if any
+        promo, err := s.applicablePromoCode(ctx, req, voucher, now)
+        if err != nil {
+                return nil, err
         }
-        if req.BuyerID <= 0 {
-                return nil, fmt.Errorf("invalid buyer_id")
+        price := voucher.Price
+        var discount *domain.Money
+        if promo != nil {
+                amount, err := promo.Discount(voucher.Price)
+                if err != nil {
+                        return nil, err
+                }
+                if price, err = voucher.Price.Sub(amount); err != nil {
+                        return nil, fmt.Errorf("failed to apply promo code: %w", err)
+                }
+                discount = &amount
         }
 
-        // Check if voucher exists
-        _, err := s.voucherRepo.GetVoucherByID(ctx, req.VoucherID)
+        // Snapshot the rate so reports read the purchase in one currency even after rates move
+        rate, err := s.rateProvider.Rate(ctx, price.Currency, s.reportingCurrency)
         if err != nil {
-                return nil, fmt.Errorf("voucher not found: %w", err)
+                return nil, fmt.Errorf("failed to get exchange rate: %w", err)
+        }
+        reportingPrice, err := rate.Convert(price)
+        if err != nil {
+                return nil, fmt.Errorf("failed to convert price: %w", err)
         }
 
-        // Check if voucher is already purchased
-        existingPurchase, err := s.voucherPurchaseRepo.GetPurchaseByVoucherID(ctx, req.VoucherID)
-        if err == nil && existingPurchase != nil {
-                return nil, fmt.Errorf("voucher already purchased")
+        // Vouchers with a code pool hand out the merchant's next code; the QR encodes it as is
+        // and the code is claimed as the purchase is recorded
+        purchaseID := uuid.New()
+        code, pooled, err := s.codePoolRepo.NextCode(ctx, req.VoucherID)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get voucher code: %w", err)
+        }
+        var poolCode *string
+        if pooled {
+                poolCode = &code
         }
 
-        // Generate QR code
-        qrData := fmt.Sprintf("voucher:%s:buyer:%d", req.VoucherID.String(), req.BuyerID)
-        qrCode, err := s.qrGenerator.GenerateQRCode(qrData)
+        // Generate QR code; its data is kept to check the code scanned on redemption
+        qrPayload := domain.NewQRPayload(req.VoucherID, req.BuyerID)
+        if pooled {
+                qrPayload = code
+        }
+        qrCode, err := s.qrGenerator.EncodeQRCode(qrPayload)
         if err != nil {
                 return nil, fmt.Errorf("failed to generate QR code: %w", err)
         }
 
         // Create purchase entity
         purchase := &domain.VoucherPurchase{
-                ID:         uuid.New(),
-                VoucherID:  req.VoucherID,
-                BuyerID:    req.BuyerID,
-                QRCode:     qrCode,
-                Status:     domain.StatusActive,
-                RedeemedAt: nil,
-                CreatedAt:  time.Now(),
+                ID:             purchaseID,
+                VoucherID:      req.VoucherID,
+                BuyerID:        req.BuyerID,
+                QRCode:         qrCode,
+                QRPayload:      &qrPayload,
+                Status:         domain.StatusActive,
+                RedeemedAt:     nil,
+                Price:          price,
+                Discount:       discount,
+                ReportingPrice: &reportingPrice,
+                ExchangeRate:   rate,
+                CreatedAt:      now,
         }
 
-        // Save to repository
-        if err := s.voucherPurchaseRepo.CreatePurchase(ctx, purchase); err != nil {
+        var redemption *domain.PromoRedemption
+        if promo != nil {
+                purchase.PromoCode = &promo.Code
+                redemption = &domain.PromoRedemption{
+                        ID:          uuid.New(),
+                        PromoCodeID: promo.ID,
+                        PurchaseID:  purchaseID,
+                        UserID:      req.BuyerID,
+                        Discount:    *discount,
+                        FundedBy:    promo.FundedBy,
+                        CreatedAt:   now,
+                }
+        }
+
+        posting, err := s.purchasePosting(ctx, voucher, purchase, redemption)
+        if err != nil {
+                return nil, err
+        }
+
+        // Save to repository; the promo code's limits are enforced again as its use is counted
+        if err := s.voucherPurchaseRepo.CreatePurchase(ctx, purchase, posting, redemption, reservationID, poolCode); err != nil {
                 return nil, fmt.Errorf("failed to create voucher purchase: %w", err)
         }
 
+        event := newAuditEvent(domain.AuditVoucherPurchased, domain.AuditTargetPurchase, purchaseID.String(), nil, auditPurchase(purchase))
+        event.ActorID = &req.BuyerID
+        s.auditor.Record(ctx, event)
+        s.notifier.PurchaseConfirmed(ctx, purchase, voucher)
+        s.publishPurchase(domain.EventPurchaseCreated, purchase)
+
+        if pooled {
+                s.checkCodePool(ctx, voucher)
+        }
+
+        return purchase, nil
+}
+
+// sellableVoucher retrieves a voucher that is on sale, unexpired and not yet purchased. A voucher
+// with a code pool is sold once per code, so it stays sellable while it has unassigned codes.
+func (s *VoucherService) sellableVoucher(ctx context.Context, voucherID uuid.UUID, now time.Time) (*domain.Voucher, error) {
+        // Check if voucher exists and is on sale
+        voucher, err := s.voucherRepo.GetVoucherByID(ctx, voucherID)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get voucher: %w", err)
+        }
+        if !voucher.IsAvailable() {
+                return nil, domain.ErrVoucherUnavailable
+        }
+        if voucher.IsExpired(now) {
+                return nil, domain.ErrVoucherExpired
+        }
+
+        total, available, err := s.codePoolRepo.GetPoolCounts(ctx, voucherID)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get voucher code pool: %w", err)
+        }
+        if total > 0 {
+                if available == 0 {
+                        return nil, domain.ErrCodePoolEmpty
+                }
+                return voucher, nil
+        }
+
+        // Check if voucher is already purchased
+        existingPurchase, err := s.voucherPurchaseRepo.GetPurchaseByVoucherID(ctx, voucherID)
+        if err != nil && !errors.Is(err, domain.ErrPurchaseNotFound) {
+                return nil, fmt.Errorf("failed to check existing purchase: %w", err)
+        }
+        if existingPurchase != nil {
+                return nil, domain.ErrAlreadyPurchased
+        }
+
+        return voucher, nil
+}
+
+// ReserveVoucher holds a voucher for a buyer while the marketplace takes payment. Reserving
+// a voucher the buyer already holds returns the existing reservation.
+func (s *VoucherService) ReserveVoucher(ctx context.Context, req *dto.ReserveVoucherRequest) (*domain.Reservation, error) {
+        // Validate request
+        if err := validation.Validate(req); err != nil {
+                return nil, err
+        }
+
+        now := time.Now()
+        if _, err := s.sellableVoucher(ctx, req.VoucherID, now); err != nil {
+                return nil, err
+        }
+
+        reservation := &domain.Reservation{
+                ID:        uuid.New(),
+                VoucherID: req.VoucherID,
+                BuyerID:   req.BuyerID,
+                Status:    domain.ReservationHeld,
+                CreatedAt: now,
+                ExpiresAt: now.Add(s.reservationHold),
+        }
+
+        held, err := s.reservationRepo.CreateReservation(ctx, reservation)
+        if err != nil {
+                return nil, fmt.Errorf("failed to create reservation: %w", err)
+        }
+
+        // Reserving again returns the buyer's existing hold, which was recorded when it was made
+        if held.ID == reservation.ID {
+                event := newAuditEvent(domain.AuditReservationCreated, domain.AuditTargetReservation, held.ID.String(), nil, held)
+                event.ActorID = &held.BuyerID
+                s.auditor.Record(ctx, event)
+        }
+
+        return held, nil
+}
+
+// ConfirmReservation purchases a reserved voucher for the buyer holding it
+func (s *VoucherService) ConfirmReservation(ctx context.Context, req *dto.ConfirmReservationRequest) (*domain.VoucherPurchase, error) {
+        // Validate request
+        if err := validation.Validate(req); err != nil {
+                return nil, err
+        }
+
+        reservation, err := s.reservationRepo.GetReservation(ctx, req.ReservationID)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get reservation: %w", err)
+        }
+        if !reservation.IsHeld(time.Now()) {
+                return nil, domain.ErrReservationNotActive
+        }
+
+        // The hold is confirmed together with the purchase, so a hold that lapses meanwhile fails both
+        purchase, err := s.purchaseVoucher(ctx, &dto.PurchaseVoucherRequest{
+                VoucherID: reservation.VoucherID,
+                BuyerID:   reservation.BuyerID,
+                PromoCode: req.PromoCode,
+        }, &reservation.ID)
+        if err != nil {
+                return nil, err
+        }
+
+        confirmed := *reservation
+        confirmed.Status = domain.ReservationConfirmed
+        confirmed.PurchaseID = &purchase.ID
+        confirmed.ResolvedAt = &purchase.CreatedAt
+        event := newAuditEvent(domain.AuditReservationConfirmed, domain.AuditTargetReservation, reservation.ID.String(), reservation, &confirmed)
+        event.ActorID = &reservation.BuyerID
+        s.auditor.Record(ctx, event)
+
         return purchase, nil
 }
 
+// ReleaseReservation ends a hold early, when the buyer abandons checkout or payment fails
+func (s *VoucherService) ReleaseReservation(ctx context.Context, req *dto.ReleaseReservationRequest) error {
+        // Validate request
+        if err := validation.Validate(req); err != nil {
+                return err
+        }
+
+        reservation, err := s.reservationRepo.GetReservation(ctx, req.ReservationID)
+        if err != nil {
+                return fmt.Errorf("failed to get reservation: %w", err)
+        }
+
+        now := time.Now()
+        if err := s.reservationRepo.ResolveReservation(ctx, req.ReservationID, domain.ReservationReleased, nil, now); err != nil {
+                return fmt.Errorf("failed to release reservation: %w", err)
+        }
+
+        released := *reservation
+        released.Status = domain.ReservationReleased
+        released.ResolvedAt = &now
+        event := newAuditEvent(domain.AuditReservationReleased, domain.AuditTargetReservation, reservation.ID.String(), reservation, &released)
+        event.ActorID = &reservation.BuyerID
+        s.auditor.Record(ctx, event)
+
+        return nil
+}
+
+// applicablePromoCode returns the promo code of a purchase request after checking that it
+// applies to voucher and that the buyer has not used it up, or nil when none was given
+func (s *VoucherService) applicablePromoCode(ctx context.Context, req *dto.PurchaseVoucherRequest, voucher *domain.Voucher, now time.Time) (*domain.PromoCode, error) {
+        code := domain.NormalizePromoCode(req.PromoCode)
+        if code == "" {
+                return nil, nil
+        }
+
+        promo, err := s.promoCodeRepo.GetPromoCodeByCode(ctx, code)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get promo code: %w", err)
+        }
+        if err := promo.CheckApplies(voucher, now); err != nil {
+                return nil, err
+        }
+
+        userUses, err := s.promoCodeRepo.CountUserRedemptions(ctx, promo.ID, req.BuyerID)
+        if err != nil {
+                return nil, fmt.Errorf("failed to count promo code uses: %w", err)
+        }
+        if err := promo.CheckUses(userUses); err != nil {
+                return nil, err
+        }
+
+        return promo, nil
+}
+
+// checkCodePool alerts the merchant when the voucher's code pool has run low
+func (s *VoucherService) checkCodePool(ctx context.Context, voucher *domain.Voucher) {
+        total, available, err := s.codePoolRepo.GetPoolCounts(ctx, voucher.ID)
+        if err != nil {
+                return
+        }
+
+        status := domain.NewCodePoolStatus(voucher.ID, total, available, s.codePoolLowThreshold)
+        if status.Low {
+                s.codePoolAlerter.CodePoolLow(ctx, voucher.UserID, status)
+        }
+}
+
 // RedeemVoucher redeems a voucher purchase
 func (s *VoucherService) RedeemVoucher(ctx context.Context, req *dto.RedeemVoucherRequest) error {
         // Validate request
-        if req.VoucherID == uuid.Nil {
-                return fmt.Errorf("invalid voucher_id")
+        if err := validation.Validate(req); err != nil {
+                return err
         }
 
-        // Check if purchase exists
-        purchase, err := s.voucherPurchaseRepo.GetPurchaseByVoucherID(ctx, req.VoucherID)
+        // Fraud signals and expiry use server time; the redemption time comes from the scanning device
+        now := time.Now()
+
+        // Find the purchase by its scanned code, since a voucher sold from a code pool has one per
+        // code; purchases recorded before their QR data was kept are found by voucher
+        purchase, err := s.voucherPurchaseRepo.GetPurchaseByQRPayload(ctx, req.VoucherID, req.QRCode)
+        if errors.Is(err, domain.ErrPurchaseNotFound) {
+                purchase, err = s.voucherPurchaseRepo.GetPurchaseByVoucherID(ctx, req.VoucherID)
+        }
         if err != nil {
-                return fmt.Errorf("voucher purchase not found: %w", err)
+                // Scanning codes that were never sold counts against the device
+                if errors.Is(err, domain.ErrPurchaseNotFound) && req.DeviceID != "" {
+                        _ = s.fraudChecker.RecordFailedLookup(ctx, req.DeviceID, req.VoucherID, now)
+                }
+                return fmt.Errorf("failed to get voucher purchase: %w", err)
+        }
+
+        // A QR code replaced by a transfer no longer redeems the voucher
+        if !domain.QRPayloadMatches(purchase.QRPayload, req.QRCode) {
+                if req.DeviceID != "" {
+                        _ = s.fraudChecker.RecordFailedLookup(ctx, req.DeviceID, req.VoucherID, now)
+                }
+                return domain.ErrQRCodeMismatch
         }
 
-        // Check if already redeemed
+        // Check if already redeemed or refunded
         if purchase.Status == domain.StatusRedeemed {
-                return fmt.Errorf("voucher already redeemed")
+                return domain.ErrAlreadyRedeemed
+        }
+        if purchase.Status == domain.StatusRefunded {
+                return domain.ErrPurchaseRefunded
+        }
+
+        // Deleted vouchers stay redeemable, expired ones do not; expiry is checked against server
+        // time so a backdated redemption time cannot redeem an expired voucher
+        voucher, err := s.voucherRepo.GetVoucherByID(ctx, req.VoucherID)
+        if err != nil {
+                return fmt.Errorf("failed to get voucher: %w", err)
+        }
+        if voucher.IsExpired(now) {
+                return domain.ErrVoucherExpired
+        }
+
+        purchasedAt := purchase.CreatedAt
+        if err := s.checkFraud(ctx, &domain.FraudSignals{
+                Operation:   domain.FraudOperationRedemption,
+                BuyerID:     purchase.BuyerID,
+                MerchantID:  voucher.UserID,
+                VoucherID:   voucher.ID,
+                PurchaseID:  &purchase.ID,
+                DeviceID:    req.DeviceID,
+                At:          now,
+                PurchasedAt: &purchasedAt,
+        }); err != nil {
+                return err
         }
 
-        // Update status to redeemed
+        // Update status to redeemed; the purchase is checked again under its lock, so a concurrent
+        // redemption or refund of it fails here
         redeemedAt := req.RedeemedAt
-        if err := s.voucherPurchaseRepo.UpdatePurchaseStatus(ctx, req.VoucherID, domain.StatusRedeemed, &redeemedAt); err != nil {
+        redemption := &domain.Redemption{
+                PurchaseID:   purchase.ID,
+                VoucherID:    voucher.ID,
+                VoucherTitle: voucher.Title,
+                MerchantID:   voucher.UserID,
+                BranchID:     req.BranchID,
+                StaffID:      req.StaffID,
+                Amount:       purchase.Price,
+                RedeemedAt:   redeemedAt,
+        }
+        if err := s.voucherPurchaseRepo.RedeemPurchase(ctx, redemption, req.QRCode); err != nil {
                 return fmt.Errorf("failed to redeem voucher: %w", err)
         }
 
+        redeemed := auditPurchase(purchase)
+        redeemed.Status = domain.StatusRedeemed
+        redeemed.RedeemedAt = &redeemedAt
+        event := newAuditEvent(domain.AuditVoucherRedeemed, domain.AuditTargetPurchase, purchase.ID.String(), auditPurchase(purchase), redeemed)
+        event.ActorID = &voucher.UserID
+        s.auditor.Record(ctx, event)
+        s.notifier.VoucherRedeemed(ctx, purchase, voucher)
+        s.publishPurchase(domain.EventPurchaseRedeemed, redeemed)
+        s.events.Publish(domain.MerchantTopic(voucher.UserID), domain.EventRedemption, redemption)
+
         return nil
 }
 
-// GetUserVouchers retrieves all vouchers purchased by a user
-func (s *VoucherService) GetUserVouchers(ctx context.Context, userID int64) ([]*domain.UserVoucherResponse, error) {
-        if userID <= 0 {
-                return nil, fmt.Errorf("invalid user_id")
+// publishPurchase pushes a purchase event to the buyer's open screens
+func (s *VoucherService) publishPurchase(eventType string, purchase *domain.VoucherPurchase) {
+        s.events.Publish(domain.UserTopic(purchase.BuyerID), eventType, &domain.PurchaseEvent{
+                PurchaseID: purchase.ID,
+                VoucherID:  purchase.VoucherID,
+                Status:     purchase.Status,
+                CreatedAt:  purchase.CreatedAt,
+                RedeemedAt: purchase.RedeemedAt,
+        })
+}
+
+// checkFraud runs the fraud rules on an operation. Blocked and delayed operations are
+// refused; flagged ones go ahead and are left for review.
+func (s *VoucherService) checkFraud(ctx context.Context, signals *domain.FraudSignals) error {
+        decision, err := s.fraudChecker.Check(ctx, signals)
+        if err != nil {
+                return fmt.Errorf("failed to check fraud rules: %w", err)
+        }
+
+        switch decision.Action {
+        case domain.FraudActionBlock:
+                return domain.ErrFraudBlocked
+        case domain.FraudActionDelay:
+                return domain.ErrFraudDelayed
+        }
+        return nil
+}
+
+// UpdateVoucherFromListing applies the changes of an edited 4Sale ad to its voucher and records them
+func (s *VoucherService) UpdateVoucherFromListing(ctx context.Context, req *dto.UpdateListingRequest) (*domain.Voucher, error) {
+        // Validate request
+        if err := validation.Validate(req); err != nil {
+                return nil, err
+        }
+
+        // A merchant edit between reading and writing the voucher bumps its version; the
+        // event is then diffed again against the fresh voucher
+        for attempt := 1; ; attempt++ {
+                voucher, err := s.applyListingUpdate(ctx, req)
+                if errors.Is(err, domain.ErrVersionConflict) && attempt < listingUpdateAttempts {
+                        continue
+                }
+                return voucher, err
+        }
+}
+
+// applyListingUpdate diffs the edited ad against the current voucher and saves the changes
+func (s *VoucherService) applyListingUpdate(ctx context.Context, req *dto.UpdateListingRequest) (*domain.Voucher, error) {
+        voucher, err := s.voucherRepo.GetVoucherByAdvID(ctx, req.AdvID)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get voucher: %w", err)
+        }
+        if voucher.DeletedAt != nil {
+                return nil, domain.ErrVoucherNotFound
+        }
+
+        now := time.Now()
+        var changes []*domain.VoucherChange
+
+        if req.Title != nil && *req.Title != voucher.Title {
+                changes = append(changes, newListingChange(voucher.ID, "title", &voucher.Title, req.Title, now))
+                voucher.Title = *req.Title
+        }
+        if req.TitleAR != nil && !sameString(voucher.TitleAR, req.TitleAR) {
+                changes = append(changes, newListingChange(voucher.ID, "title_ar", voucher.TitleAR, req.TitleAR, now))
+                voucher.TitleAR = req.TitleAR
+        }
+        if req.Description != nil && !sameString(voucher.Description, req.Description) {
+                changes = append(changes, newListingChange(voucher.ID, "description", voucher.Description, req.Description, now))
+                voucher.Description = req.Description
+        }
+        if req.DescriptionAR != nil && !sameString(voucher.DescriptionAR, req.DescriptionAR) {
+                changes = append(changes, newListingChange(voucher.ID, "description_ar", voucher.DescriptionAR, req.DescriptionAR, now))
+                voucher.DescriptionAR = req.DescriptionAR
+        }
+        if req.Price != nil {
+                price, fieldErr := parsePrice("price", req.Price.String(), voucher.Price.Currency)
+                if fieldErr != nil {
+                        return nil, domain.NewValidationError(*fieldErr)
+                }
+                if price != voucher.Price {
+                        oldPrice, newPrice := voucher.Price.Decimal(), price.Decimal()
+                        changes = append(changes, newListingChange(voucher.ID, "price", &oldPrice, &newPrice, now))
+                        voucher.Price = price
+                }
+        }
+        if req.Photo != nil && !sameString(voucher.PhotoURL, req.Photo) {
+                changes = append(changes, newListingChange(voucher.ID, "photo_url", voucher.PhotoURL, req.Photo, now))
+                voucher.PhotoURL = req.Photo
+        }
+
+        // Redelivered or no-op events leave the voucher and its version untouched
+        if len(changes) == 0 {
+                return voucher, nil
+        }
+
+        voucher.UpdatedAt = &now
+        if err := s.voucherRepo.ApplyVoucherChanges(ctx, voucher, voucher.Version, changes); err != nil {
+                return nil, fmt.Errorf("failed to apply listing changes: %w", err)
+        }
+
+        before, after := changeStates(changes)
+        event := newAuditEvent(domain.AuditListingUpdated, domain.AuditTargetVoucher, voucher.ID.String(), before, after)
+        event.ActorID = &voucher.UserID
+        s.auditor.Record(ctx, event)
+
+        return voucher, nil
+}
+
+// changeStates returns the old and new values of the changed fields by field name
+func changeStates(changes []*domain.VoucherChange) (map[string]*string, map[string]*string) {
+        before := make(map[string]*string, len(changes))
+        after := make(map[string]*string, len(changes))
+        for _, change := range changes {
+                before[change.Field] = change.OldValue
+                after[change.Field] = change.NewValue
+        }
+        return before, after
+}
+
+// DeleteVoucherFromListing withdraws the voucher of a removed 4Sale ad from sale.
+// Existing purchases stay redeemable unless the refund policy refunds unredeemed ones.
+func (s *VoucherService) DeleteVoucherFromListing(ctx context.Context, req *dto.DeleteListingRequest) (*domain.VoucherWithdrawal, error) {
+        // Validate request
+        if err := validation.Validate(req); err != nil {
+                return nil, err
+        }
+
+        // A concurrent edit between reading and deleting the voucher is retried like listing updates
+        var voucher *domain.Voucher
+        var withdrawal *domain.VoucherWithdrawal
+        for attempt := 1; ; attempt++ {
+                var err error
+                voucher, withdrawal, err = s.withdrawVoucher(ctx, req.AdvID)
+                if errors.Is(err, domain.ErrVersionConflict) && attempt < listingUpdateAttempts {
+                        continue
+                }
+                if err != nil {
+                        return nil, err
+                }
+                break
         }
 
-        vouchers, err := s.voucherPurchaseRepo.GetUserVouchers(ctx, userID)
+        if s.refundPolicy == domain.RefundPolicyUnredeemed {
+                refunded, err := s.refundPurchases(ctx, voucher.ID)
+                if err != nil {
+                        return nil, err
+                }
+                withdrawal.PurchaseRefunded = refunded
+        }
+
+        // Redelivered events are recorded only when they completed a refund the first delivery missed
+        if !withdrawal.AlreadyWithdrawn || withdrawal.PurchaseRefunded {
+                event := newAuditEvent(domain.AuditListingDeleted, domain.AuditTargetVoucher, voucher.ID.String(), nil, withdrawal)
+                event.ActorID = &voucher.UserID
+                s.auditor.Record(ctx, event)
+        }
+
+        return withdrawal, nil
+}
+
+// withdrawVoucher marks the voucher of a removed ad deleted, unless it already is
+func (s *VoucherService) withdrawVoucher(ctx context.Context, advID int64) (*domain.Voucher, *domain.VoucherWithdrawal, error) {
+        voucher, err := s.voucherRepo.GetVoucherByAdvID(ctx, advID)
+        if err != nil {
+                return nil, nil, fmt.Errorf("failed to get voucher: %w", err)
+        }
+
+        withdrawal := &domain.VoucherWithdrawal{VoucherID: voucher.ID}
+        if voucher.DeletedAt != nil {
+                // Redelivered event; still apply the refund policy in case the first delivery failed half way
+                withdrawal.DeletedAt = *voucher.DeletedAt
+                withdrawal.AlreadyWithdrawn = true
+                return voucher, withdrawal, nil
+        }
+
+        now := time.Now()
+        deletedAt := now.UTC().Format(time.RFC3339)
+        changes := []*domain.VoucherChange{newListingChange(voucher.ID, "deleted_at", nil, &deletedAt, now)}
+
+        voucher.DeletedAt = &now
+        voucher.UpdatedAt = &now
+        if err := s.voucherRepo.ApplyVoucherChanges(ctx, voucher, voucher.Version, changes); err != nil {
+                return nil, nil, fmt.Errorf("failed to withdraw voucher: %w", err)
+        }
+        withdrawal.DeletedAt = now
+
+        return voucher, withdrawal, nil
+}
+
+// GetUserVouchers retrieves one page of the vouchers purchased by a user
+func (s *VoucherService) GetUserVouchers(ctx context.Context, req *dto.UserVoucherListRequest) (*dto.UserVoucherListResponse, error) {
+        // Validate request
+        if err := validation.Validate(req); err != nil {
+                return nil, err
+        }
+
+        query, err := newUserVoucherQuery(req, time.Now())
+        if err != nil {
+                return nil, err
+        }
+        if query.CategoryIDs, err = categorySubtree(ctx, s.categoryRepo, req.Category, "category"); err != nil {
+                return nil, err
+        }
+
+        page, err := s.voucherPurchaseRepo.GetUserVouchers(ctx, query)
         if err != nil {
                 return nil, fmt.Errorf("failed to get user vouchers: %w", err)
         }
 
-        return vouchers, nil
+        for _, voucher := range page.Vouchers {
+                voucher.Localize(query.Language)
+        }
+
+        response := &dto.UserVoucherListResponse{
+                Vouchers: page.Vouchers,
+                Meta:     dto.PageMeta{Limit: query.Limit},
+        }
+        if page.Next != nil {
+                nextCursor := page.Next.Encode()
+                response.Meta.NextCursor = &nextCursor
+        }
+
+        return response, nil
+}
+
+// refundPurchases refunds the unredeemed purchases of a voucher and reverses their ledger postings.
+// It reports false if the voucher was not sold or all its purchases were already redeemed or refunded.
+func (s *VoucherService) refundPurchases(ctx context.Context, voucherID uuid.UUID) (bool, error) {
+        purchases, err := s.voucherPurchaseRepo.GetPurchasesByVoucherID(ctx, voucherID)
+        if err != nil {
+                return false, fmt.Errorf("failed to get voucher purchases: %w", err)
+        }
+
+        refunded := false
+        for _, purchase := range purchases {
+                if purchase.Status != domain.StatusActive {
+                        continue
+                }
+
+                now := time.Now()
+                posting, err := s.refundPosting(ctx, purchase, now)
+                if err != nil {
+                        return refunded, err
+                }
+
+                err = s.voucherPurchaseRepo.RefundPurchase(ctx, purchase.ID, now, posting)
+                switch {
+                case err == nil:
+                        refunded = true
+                case errors.Is(err, domain.ErrPurchaseNotFound):
+                        // Redeemed or refunded since it was read
+                default:
+                        return refunded, fmt.Errorf("failed to refund voucher purchase: %w", err)
+                }
+        }
+
+        return refunded, nil
+}
+
+// purchasePosting splits the price of a purchase into the commission of the most specific
+// matching rule and the amount held for the merchant; without a matching rule no commission is charged.
+// A platform funded discount is paid in by the platform, so the merchant is settled on the full price.
+func (s *VoucherService) purchasePosting(ctx context.Context, voucher *domain.Voucher, purchase *domain.VoucherPurchase, redemption *domain.PromoRedemption) (*domain.LedgerTransaction, error) {
+        rules, err := s.ledgerRepo.GetCommissionRules(ctx, voucher.UserID, voucher.CategoryID)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get commission rules: %w", err)
+        }
+
+        gross := purchase.Price
+        platformDiscount := domain.NewMoney(0, purchase.Price.Currency)
+        if redemption != nil && redemption.FundedBy == domain.FundedByPlatform {
+                gross = voucher.Price
+                platformDiscount = redemption.Discount
+        }
+
+        commission := domain.NewMoney(0, purchase.Price.Currency)
+        if rule := domain.MatchCommissionRule(rules, voucher.UserID, voucher.CategoryID); rule != nil {
+                commission = gross.Percent(rule.RateBasisPoints)
+        }
+
+        posting, err := domain.NewPurchaseTransaction(purchase, voucher.UserID, commission, platformDiscount)
+        if err != nil {
+                return nil, fmt.Errorf("failed to build purchase posting: %w", err)
+        }
+        return posting, nil
+}
+
+// refundPosting reverses the purchase posting of a purchase. Purchases recorded before the
+// ledger, or whose posting was already reversed, get no posting.
+func (s *VoucherService) refundPosting(ctx context.Context, purchase *domain.VoucherPurchase, refundedAt time.Time) (*domain.LedgerTransaction, error) {
+        transactions, err := s.ledgerRepo.GetTransactionsByPurchaseID(ctx, purchase.ID)
+        if err != nil {
+                return nil, fmt.Errorf("failed to get ledger transactions: %w", err)
+        }
+
+        original := domain.FindLedgerTransaction(transactions, domain.LedgerPurchase)
+        if original == nil || domain.IsReversed(transactions, original.ID) {
+                return nil, nil
+        }
+
+        posting, err := domain.NewReversalTransaction(original, domain.LedgerRefund, refundedAt)
+        if err != nil {
+                return nil, fmt.Errorf("failed to build refund posting: %w", err)
+        }
+        return posting, nil
+}
+
+// newListingChange creates a history entry for a field changed by a listing event
+func newListingChange(voucherID uuid.UUID, field string, oldValue, newValue *string, changedAt time.Time) *domain.VoucherChange {
+        return newVoucherChange(domain.ChangeSourceListing, voucherID, field, oldValue, newValue, changedAt)
+}
+
+// newVoucherChange creates a history entry for a field changed from source
+func newVoucherChange(source string, voucherID uuid.UUID, field string, oldValue, newValue *string, changedAt time.Time) *domain.VoucherChange {
+        return &domain.VoucherChange{
+                ID:        uuid.New(),
+                VoucherID: voucherID,
+                Source:    source,
+                Field:     field,
+                OldValue:  copyString(oldValue),
+                NewValue:  copyString(newValue),
+                ChangedAt: changedAt,
+        }
+}
+
+// copyString returns a pointer to a copy of *s, so later changes to s are not recorded
+func copyString(s *string) *string {
+        if s == nil {
+                return nil
+        }
+        c := *s
+        return &c
+}
+
+// sameString reports whether two optional strings are equal
+func sameString(a, b *string) bool {
+        if a == nil || b == nil {
+                return a == b
+        }
+        return *a == *b
 }
\ No newline at end of file
@@ -0,0 +1,185 @@
+package services
+
+import (
+        "context"
+        "errors"
+        "testing"
+        "time"
+
+        "4SaleBackendSkeleton/internal/application/dto"
+        "4SaleBackendSkeleton/internal/domain"
+        "4SaleBackendSkeleton/internal/ports"
+        "github.com/google/uuid"
+)
+
+// fakeVoucherRepo serves a single voucher
+type fakeVoucherRepo struct {
+        ports.VoucherRepository
+        voucher *domain.Voucher
+}
+
+func (r *fakeVoucherRepo) GetVoucherByID(ctx context.Context, id uuid.UUID) (*domain.Voucher, error) {
+        if id != r.voucher.ID {
+                return nil, domain.ErrVoucherNotFound
+        }
+        return r.voucher, nil
+}
+
+// fakeCodePool keeps a voucher's codes in upload order with the purchase each is assigned to
+type fakeCodePool struct {
+        ports.CodePoolRepository
+        codes    []string
+        assigned map[string]uuid.UUID
+}
+
+func (p *fakeCodePool) NextCode(ctx context.Context, voucherID uuid.UUID) (string, bool, error) {
+        if len(p.codes) == 0 {
+                return "", false, nil
+        }
+        for _, code := range p.codes {
+                if _, ok := p.assigned[code]; !ok {
+                        return code, true, nil
+                }
+        }
+        return "", true, domain.ErrCodePoolEmpty
+}
+
+func (p *fakeCodePool) GetPoolCounts(ctx context.Context, voucherID uuid.UUID) (int64, int64, error) {
+        total := int64(len(p.codes))
+        return total, total - int64(len(p.assigned)), nil
+}
+
+// fakePurchaseRepo records purchases the way the SQL repository checks them
+type fakePurchaseRepo struct {
+        ports.VoucherPurchaseRepository
+        pool      *fakeCodePool
+        purchases []*domain.VoucherPurchase
+}
+
+func (r *fakePurchaseRepo) CreatePurchase(ctx context.Context, purchase *domain.VoucherPurchase, posting *domain.LedgerTransaction, redemption *domain.PromoRedemption, reservationID *uuid.UUID, code *string) error {
+        if code == nil {
+                if len(r.purchases) > 0 {
+                        return domain.ErrAlreadyPurchased
+                }
+        } else {
+                next, _, err := r.pool.NextCode(ctx, purchase.VoucherID)
+                if err != nil {
+                        return err
+                }
+                if next != *code {
+                        return domain.ErrCodeTaken
+                }
+                r.pool.assigned[next] = purchase.ID
+        }
+        r.purchases = append(r.purchases, purchase)
+        return nil
+}
+
+func (r *fakePurchaseRepo) GetPurchaseByVoucherID(ctx context.Context, voucherID uuid.UUID) (*domain.VoucherPurchase, error) {
+        if len(r.purchases) == 0 {
+                return nil, domain.ErrPurchaseNotFound
+        }
+        return r.purchases[0], nil
+}
+
+type fakeReservationRepo struct {
+        ports.ReservationRepository
+}
+
+func (fakeReservationRepo) GetActiveReservation(ctx context.Context, voucherID uuid.UUID, now time.Time) (*domain.Reservation, error) {
+        return nil, domain.ErrReservationNotFound
+}
+
+type fakeLedgerRepo struct {
+        ports.LedgerRepository
+}
+
+func (fakeLedgerRepo) GetCommissionRules(ctx context.Context, merchantID int64, categoryID *int64) ([]*domain.CommissionRule, error) {
+        return nil, nil
+}
+
+// fakeCollaborators allows every operation and ignores what it is told
+type fakeCollaborators struct{}
+
+func (fakeCollaborators) EncodeQRCode(data string) (string, error) { return "qr:" + data, nil }
+func (fakeCollaborators) Rate(ctx context.Context, from, to string) (*domain.ExchangeRate, error) {
+        return domain.IdentityRate(from, time.Now()), nil
+}
+func (fakeCollaborators) CodePoolLow(ctx context.Context, merchantID int64, status *domain.CodePoolStatus) {
+}
+func (fakeCollaborators) Check(ctx context.Context, signals *domain.FraudSignals) (*domain.FraudDecision, error) {
+        return &domain.FraudDecision{Action: domain.FraudActionAllow}, nil
+}
+func (fakeCollaborators) RecordFailedLookup(ctx context.Context, deviceID string, voucherID uuid.UUID, at time.Time) error {
+        return nil
+}
+func (fakeCollaborators) Record(ctx context.Context, event *domain.AuditEvent) {}
+func (fakeCollaborators) PurchaseConfirmed(ctx context.Context, purchase *domain.VoucherPurchase, voucher *domain.Voucher) {
+}
+func (fakeCollaborators) VoucherRedeemed(ctx context.Context, purchase *domain.VoucherPurchase, voucher *domain.Voucher) {
+}
+func (fakeCollaborators) Publish(topic, eventType string, data interface{}) {}
+
+// newTestVoucherService returns a service selling one voucher from a pool of codes, or
+// without a pool when codes is empty
+func newTestVoucherService(codes []string) (*VoucherService, *domain.Voucher, *fakePurchaseRepo) {
+        voucher := &domain.Voucher{
+                ID:     uuid.New(),
+                UserID: 7,
+                Title:  "Coffee",
+                Price:  domain.NewMoney(2500, "KWD"),
+                Status: domain.VoucherStatusActive,
+        }
+        pool := &fakeCodePool{codes: codes, assigned: map[string]uuid.UUID{}}
+        purchases := &fakePurchaseRepo{pool: pool}
+        var collaborators fakeCollaborators
+
+        service := NewVoucherService(&fakeVoucherRepo{voucher: voucher}, purchases, nil, fakeLedgerRepo{}, pool, nil, fakeReservationRepo{},
+                collaborators, collaborators, collaborators, collaborators, collaborators, collaborators, collaborators,
+                domain.RefundPolicyNone, "KWD", 0, time.Minute)
+        return service, voucher, purchases
+}
+
+func TestPurchaseVoucherFromCodePool(t *testing.T) {
+        tests := []struct {
+                name  string
+                codes []string
+                // want are the errors of consecutive purchases by different buyers
+                want []error
+        }{
+                {name: "voucher without a pool sells once", want: []error{nil, domain.ErrAlreadyPurchased}},
+                {name: "pool sells each code once", codes: []string{"CODE-1", "CODE-2"}, want: []error{nil, nil, domain.ErrCodePoolEmpty}},
+                {name: "pool of one code", codes: []string{"CODE-1"}, want: []error{nil, domain.ErrCodePoolEmpty}},
+        }
+
+        for _, tt := range tests {
+                t.Run(tt.name, func(t *testing.T) {
+                        service, voucher, repo := newTestVoucherService(tt.codes)
+
+                        for i, want := range tt.want {
+                                buyerID := int64(100 + i)
+                                purchase, err := service.PurchaseVoucher(context.Background(), &dto.PurchaseVoucherRequest{VoucherID: voucher.ID, BuyerID: buyerID})
+                                if !errors.Is(err, want) {
+                                        t.Fatalf("purchase %d error = %v, want %v", i+1, err, want)
+                                }
+                                if want != nil {
+                                        continue
+                                }
+                                if purchase.BuyerID != buyerID || purchase.Status != domain.StatusActive {
+                                        t.Errorf("purchase %d = %+v, want an active purchase of buyer %d", i+1, purchase, buyerID)
+                                }
+                                if len(tt.codes) > 0 && (purchase.QRPayload == nil || *purchase.QRPayload != tt.codes[i]) {
+                                        t.Errorf("purchase %d QR payload = %v, want code %s", i+1, purchase.QRPayload, tt.codes[i])
+                                }
+                        }
+
+                        sold := len(tt.codes)
+                        if sold == 0 {
+                                sold = 1
+                        }
+                        if len(repo.purchases) != sold {
+                                t.Errorf("recorded %d purchases, want %d", len(repo.purchases), sold)
+                        }
+                })
+        }
+}
@@ -0,0 +1,192 @@
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"4SaleBackendSkeleton/internal/domain"
+)
+
+// Supported rules in `validate` struct tags:
+//
+//	required   value must not be the zero value (non-nil for pointers)
+//	omitempty  skip the remaining rules when the value is empty
+//	min=N      numbers >= N, strings and slices at least N characters/items
+//	max=N      numbers <= N, strings and slices at most N characters/items
+//	gt=N       numbers > N
+//	url        absolute http(s) URL
+//	oneof=a b  value is one of the space separated options
+const tagName = "validate"
+
+// Validate checks every tagged field of the struct v points to and returns a
+// *domain.ValidationError listing all invalid fields, or nil if v is valid
+func Validate(v interface{}) error {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return domain.NewValidationError(domain.FieldError{Field: "body", Code: "required", Message: "request body is required"})
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("validation: expected struct, got %s", value.Kind())
+	}
+
+	var fieldErrors []domain.FieldError
+	valueType := value.Type()
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		tag := field.Tag.Get(tagName)
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		if fieldErr := validateField(fieldName(field), value.Field(i), strings.Split(tag, ",")); fieldErr != nil {
+			fieldErrors = append(fieldErrors, *fieldErr)
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return domain.NewValidationError(fieldErrors...)
+	}
+	return nil
+}
+
+// validateField applies the rules in order and reports the first one that fails
+func validateField(name string, value reflect.Value, rules []string) *domain.FieldError {
+	for _, rule := range rules {
+		ruleName, param := rule, ""
+		if i := strings.Index(rule, "="); i >= 0 {
+			ruleName, param = rule[:i], rule[i+1:]
+		}
+
+		switch ruleName {
+		case "required":
+			if value.IsZero() {
+				return newFieldError(name, ruleName, param, "is required")
+			}
+		case "omitempty":
+			if value.IsZero() {
+				return nil
+			}
+		}
+
+		// Remaining rules apply to the pointed-to value
+		target := value
+		if target.Kind() == reflect.Ptr {
+			if target.IsNil() {
+				continue
+			}
+			target = target.Elem()
+		}
+
+		switch ruleName {
+		case "required", "omitempty":
+		case "min":
+			if measure(target) < parseParam(param) {
+				return newFieldError(name, ruleName, param, "must be at least "+param+unit(target))
+			}
+		case "max":
+			if measure(target) > parseParam(param) {
+				return newFieldError(name, ruleName, param, "must be at most "+param+unit(target))
+			}
+		case "gt":
+			if measure(target) <= parseParam(param) {
+				return newFieldError(name, ruleName, param, "must be greater than "+param)
+			}
+		case "url":
+			if !isURL(target.String()) {
+				return newFieldError(name, ruleName, param, "must be a valid http or https URL")
+			}
+		case "oneof":
+			if !isOneOf(fmt.Sprint(target.Interface()), strings.Fields(param)) {
+				options := strings.Join(strings.Fields(param), ", ")
+				return newFieldError(name, ruleName, options, "must be one of: "+options)
+			}
+		default:
+			panic(fmt.Sprintf("validation: unknown rule %q on field %s", ruleName, name))
+		}
+	}
+
+	return nil
+}
+
+// measure returns the numeric value of numbers and the length of strings and slices
+func measure(value reflect.Value) float64 {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		return value.Float()
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String()))
+	case reflect.Slice, reflect.Map:
+		return float64(value.Len())
+	default:
+		return 0
+	}
+}
+
+// unit returns the unit suffix of min/max messages
+func unit(value reflect.Value) string {
+	switch value.Kind() {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Map:
+		return " items"
+	default:
+		return ""
+	}
+}
+
+// parseParam parses a numeric rule parameter; malformed tags are programming errors
+func parseParam(param string) float64 {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		panic(fmt.Sprintf("validation: invalid rule parameter %q", param))
+	}
+	return n
+}
+
+// isURL reports whether s is an absolute http or https URL
+func isURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// isOneOf reports whether s is in options
+func isOneOf(s string, options []string) bool {
+	for _, option := range options {
+		if s == option {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldName returns the JSON name of a struct field
+func fieldName(field reflect.StructField) string {
+	if name := strings.Split(field.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+		return name
+	}
+	return field.Name
+}
+
+// newFieldError creates a field error with a readable message
+func newFieldError(field, code, param, message string) *domain.FieldError {
+	return &domain.FieldError{
+		Field:   field,
+		Code:    code,
+		Message: field + " " + message,
+		Param:   param,
+	}
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Analytics sources
+const (
+	// AnalyticsSourceLive means the figures were aggregated from voucher_purchases
+	AnalyticsSourceLive = "live"
+	// AnalyticsSourceRollup means the figures were read from the daily rollup table
+	AnalyticsSourceRollup = "rollup"
+)
+
+// DailySales aggregates the purchases a merchant made in one currency on one day.
+// Refunds, redemptions and revenue belong to the day the voucher was bought.
+type DailySales struct {
+	Day           time.Time
+	Currency      string
+	Sold          int64
+	Refunded      int64
+	Redeemed      int64
+	RedeemSeconds int64
+	// Revenue sums the prices of the purchases that were not refunded
+	Revenue Money
+}
+
+// TopVoucher is one of a merchant's best selling vouchers
+type TopVoucher struct {
+	VoucherID uuid.UUID `json:"voucher_id"`
+	Title     string    `json:"title"`
+	Sold      int64     `json:"sold"`
+	Redeemed  int64     `json:"redeemed"`
+	Revenue   Money     `json:"revenue"`
+}
+
+// AnalyticsDay is one point of the daily time series
+type AnalyticsDay struct {
+	Date     string  `json:"date"`
+	Sold     int64   `json:"sold"`
+	Refunded int64   `json:"refunded"`
+	Redeemed int64   `json:"redeemed"`
+	Revenue  []Money `json:"revenue"`
+}
+
+// MerchantAnalytics summarizes a merchant's sales of vouchers bought in [From, To].
+// RedemptionRate is the share of sold, unrefunded vouchers that were redeemed.
+type MerchantAnalytics struct {
+	MerchantID                 int64           `json:"merchant_id"`
+	From                       string          `json:"from"`
+	To                         string          `json:"to"`
+	Source                     string          `json:"source"`
+	Sold                       int64           `json:"sold"`
+	Refunded                   int64           `json:"refunded"`
+	Redeemed                   int64           `json:"redeemed"`
+	Revenue                    []Money         `json:"revenue"`
+	RedemptionRate             float64         `json:"redemption_rate"`
+	AverageTimeToRedeemSeconds *int64          `json:"average_time_to_redeem_seconds"`
+	Daily                      []*AnalyticsDay `json:"daily"`
+	TopVouchers                []*TopVoucher   `json:"top_vouchers"`
+}
+
+// NewMerchantAnalytics totals the daily sales of [from, to) into a summary with one
+// time series point per day, including days without sales
+func NewMerchantAnalytics(merchantID int64, from, to time.Time, source string, sales []*DailySales, top []*TopVoucher) *MerchantAnalytics {
+	const dayLayout = "2006-01-02"
+
+	analytics := &MerchantAnalytics{
+		MerchantID:  merchantID,
+		From:        from.Format(dayLayout),
+		To:          to.AddDate(0, 0, -1).Format(dayLayout),
+		Source:      source,
+		Revenue:     make([]Money, 0),
+		Daily:       make([]*AnalyticsDay, 0),
+		TopVouchers: top,
+	}
+	if analytics.TopVouchers == nil {
+		analytics.TopVouchers = make([]*TopVoucher, 0)
+	}
+
+	days := make(map[string]*AnalyticsDay)
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		point := &AnalyticsDay{Date: day.Format(dayLayout), Revenue: make([]Money, 0)}
+		days[point.Date] = point
+		analytics.Daily = append(analytics.Daily, point)
+	}
+
+	var redeemSeconds int64
+	revenue := make(map[string]int)
+	for _, s := range sales {
+		analytics.Sold += s.Sold
+		analytics.Refunded += s.Refunded
+		analytics.Redeemed += s.Redeemed
+		redeemSeconds += s.RedeemSeconds
+
+		if i, ok := revenue[s.Currency]; ok {
+			analytics.Revenue[i].Amount += s.Revenue.Amount
+		} else {
+			revenue[s.Currency] = len(analytics.Revenue)
+			analytics.Revenue = append(analytics.Revenue, s.Revenue)
+		}
+
+		if point, ok := days[s.Day.Format(dayLayout)]; ok {
+			point.Sold += s.Sold
+			point.Refunded += s.Refunded
+			point.Redeemed += s.Redeemed
+			point.Revenue = append(point.Revenue, s.Revenue)
+		}
+	}
+
+	if kept := analytics.Sold - analytics.Refunded; kept > 0 {
+		analytics.RedemptionRate = float64(analytics.Redeemed) / float64(kept)
+	}
+	if analytics.Redeemed > 0 {
+		average := redeemSeconds / analytics.Redeemed
+		analytics.AverageTimeToRedeemSeconds = &average
+	}
+
+	return analytics
+}
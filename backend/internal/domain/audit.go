@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Audit actions recorded for state-changing operations
+const (
+	AuditVoucherCreated                 = "voucher.created"
+	AuditVoucherImported                = "voucher.imported"
+	AuditVoucherUpdated                 = "voucher.updated"
+	AuditVoucherPaused                  = "voucher.paused"
+	AuditVoucherResumed                 = "voucher.resumed"
+	AuditVoucherDeleted                 = "voucher.deleted"
+	AuditVoucherCodesUploaded           = "voucher.codes_uploaded"
+	AuditVoucherPurchased               = "voucher.purchased"
+	AuditVoucherRedeemed                = "voucher.redeemed"
+	AuditReservationCreated             = "reservation.created"
+	AuditReservationConfirmed           = "reservation.confirmed"
+	AuditReservationReleased            = "reservation.released"
+	AuditLedgerReversed                 = "ledger.reversed"
+	AuditPayoutSettled                  = "payout.settled"
+	AuditTransferCreated                = "transfer.created"
+	AuditTransferAccepted               = "transfer.accepted"
+	AuditTransferCancelled              = "transfer.cancelled"
+	AuditPromoCodeCreated               = "promo_code.created"
+	AuditListingUpdated                 = "listing.updated"
+	AuditListingDeleted                 = "listing.deleted"
+	AuditNotificationPreferencesUpdated = "notification_preferences.updated"
+)
+
+// Audit target types
+const (
+	AuditTargetVoucher                 = "voucher"
+	AuditTargetPurchase                = "purchase"
+	AuditTargetReservation             = "reservation"
+	AuditTargetLedger                  = "ledger_transaction"
+	AuditTargetMerchant                = "merchant"
+	AuditTargetTransfer                = "transfer"
+	AuditTargetPromoCode               = "promo_code"
+	AuditTargetNotificationPreferences = "notification_preferences"
+)
+
+// Audit actor types. Webhook calls act for the 4Sale platform, session calls for
+// the signed-in user, and background jobs for the system.
+const (
+	AuditActorPlatform = "platform"
+	AuditActorUser     = "user"
+	AuditActorSystem   = "system"
+)
+
+// AuditEvent is one entry of the append-only audit log. Each event's hash covers its
+// fields and the previous event's hash, so editing or removing an event breaks the chain.
+type AuditEvent struct {
+	ID        int64  `json:"id"`
+	ActorType string `json:"actor_type"`
+	// ActorID is the session user, or the user named by a webhook payload
+	ActorID    *int64 `json:"actor_id"`
+	Action     string `json:"action"`
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+	// Before and After are the JSON state of the target around the operation
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	RequestID string          `json:"request_id"`
+	IP        string          `json:"ip"`
+	CreatedAt time.Time       `json:"created_at"`
+	PrevHash  string          `json:"prev_hash"`
+	Hash      string          `json:"hash"`
+}
+
+// Chain links the event after the event with hash prevHash and sets its hash
+func (e *AuditEvent) Chain(prevHash string) {
+	e.PrevHash = prevHash
+	e.Hash = e.computeHash()
+}
+
+// Intact reports whether the event's fields still match its hash
+func (e *AuditEvent) Intact() bool {
+	return e.Hash == e.computeHash()
+}
+
+// computeHash hashes the event's fields in a fixed order, excluding its own hash
+func (e *AuditEvent) computeHash() string {
+	actorID := ""
+	if e.ActorID != nil {
+		actorID = strconv.FormatInt(*e.ActorID, 10)
+	}
+	fields, _ := json.Marshal([]string{
+		e.PrevHash,
+		e.ActorType,
+		actorID,
+		e.Action,
+		e.TargetType,
+		e.TargetID,
+		string(e.Before),
+		string(e.After),
+		e.RequestID,
+		e.IP,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	sum := sha256.Sum256(fields)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditFilter selects audit events, newest first
+type AuditFilter struct {
+	ActorID    *int64
+	Action     string
+	TargetType string
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+	// BeforeID pages past the events already returned
+	BeforeID *int64
+	Limit    int
+}
+
+// AuditSource describes the request behind an operation: who made it and from where
+type AuditSource struct {
+	RequestID string
+	IP        string
+	ActorType string
+	ActorID   *int64
+}
+
+// auditSourceContextKey is the context key for the audit source of a request
+type auditSourceContextKey struct{}
+
+// ContextWithAuditSource returns a copy of ctx carrying source
+func ContextWithAuditSource(ctx context.Context, source AuditSource) context.Context {
+	return context.WithValue(ctx, auditSourceContextKey{}, source)
+}
+
+// AuditSourceFromContext returns the audit source stored in ctx, if any
+func AuditSourceFromContext(ctx context.Context) (AuditSource, bool) {
+	source, ok := ctx.Value(auditSourceContextKey{}).(AuditSource)
+	return source, ok
+}
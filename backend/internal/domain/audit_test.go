@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// newTestAuditEvent returns a chained event as the audit log would store it
+func newTestAuditEvent(prevHash string) *AuditEvent {
+	actorID := int64(42)
+	event := &AuditEvent{
+		ActorType:  AuditActorUser,
+		ActorID:    &actorID,
+		Action:     AuditVoucherUpdated,
+		TargetType: AuditTargetVoucher,
+		TargetID:   "6f1c1b0e-8f8e-4c1e-9a57-4a51c1b0e8f8",
+		Before:     json.RawMessage(`{"title":"Old"}`),
+		After:      json.RawMessage(`{"title":"New"}`),
+		RequestID:  "req-1",
+		IP:         "203.0.113.7",
+		CreatedAt:  time.Date(2026, 10, 19, 12, 0, 0, 123456000, time.UTC),
+	}
+	event.Chain(prevHash)
+	return event
+}
+
+func TestAuditEventChain(t *testing.T) {
+	first := newTestAuditEvent("")
+	second := newTestAuditEvent(first.Hash)
+
+	if first.Hash == "" || len(first.Hash) != 64 {
+		t.Fatalf("Chain() hash = %q, want a hex SHA-256", first.Hash)
+	}
+	if second.PrevHash != first.Hash {
+		t.Errorf("Chain() prev hash = %q, want %q", second.PrevHash, first.Hash)
+	}
+	if second.Hash == first.Hash {
+		t.Errorf("Chain() gave events with different predecessors the same hash")
+	}
+	if again := newTestAuditEvent(""); again.Hash != first.Hash {
+		t.Errorf("Chain() hash = %q for the same fields, want %q", again.Hash, first.Hash)
+	}
+}
+
+func TestAuditEventIntact(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(e *AuditEvent)
+		want   bool
+	}{
+		{name: "untouched", tamper: func(e *AuditEvent) {}, want: true},
+		{name: "same time in another zone", tamper: func(e *AuditEvent) { e.CreatedAt = e.CreatedAt.In(time.FixedZone("AST", 3*60*60)) }, want: true},
+		{name: "prev hash", tamper: func(e *AuditEvent) { e.PrevHash = "0000" }},
+		{name: "actor type", tamper: func(e *AuditEvent) { e.ActorType = AuditActorSystem }},
+		{name: "actor removed", tamper: func(e *AuditEvent) { e.ActorID = nil }},
+		{name: "actor changed", tamper: func(e *AuditEvent) { other := int64(43); e.ActorID = &other }},
+		{name: "action", tamper: func(e *AuditEvent) { e.Action = AuditVoucherDeleted }},
+		{name: "target type", tamper: func(e *AuditEvent) { e.TargetType = AuditTargetPurchase }},
+		{name: "target id", tamper: func(e *AuditEvent) { e.TargetID = "other" }},
+		{name: "before state", tamper: func(e *AuditEvent) { e.Before = json.RawMessage(`{"title":"Forged"}`) }},
+		{name: "after state", tamper: func(e *AuditEvent) { e.After = nil }},
+		{name: "request id", tamper: func(e *AuditEvent) { e.RequestID = "req-2" }},
+		{name: "ip", tamper: func(e *AuditEvent) { e.IP = "198.51.100.1" }},
+		{name: "created at", tamper: func(e *AuditEvent) { e.CreatedAt = e.CreatedAt.Add(time.Microsecond) }},
+		{name: "hash", tamper: func(e *AuditEvent) { e.Hash = "0000" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := newTestAuditEvent("")
+			tt.tamper(event)
+			if got := event.Intact(); got != tt.want {
+				t.Errorf("Intact() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditSourceContext(t *testing.T) {
+	if _, ok := AuditSourceFromContext(context.Background()); ok {
+		t.Errorf("AuditSourceFromContext() found a source in an empty context")
+	}
+
+	actorID := int64(42)
+	source := AuditSource{RequestID: "req-1", IP: "203.0.113.7", ActorType: AuditActorUser, ActorID: &actorID}
+	got, ok := AuditSourceFromContext(ContextWithAuditSource(context.Background(), source))
+	if !ok || got != source {
+		t.Errorf("AuditSourceFromContext() = %+v, %v, want %+v", got, ok, source)
+	}
+}
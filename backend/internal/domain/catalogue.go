@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Catalogue sort orders in addition to the shared newest, price_asc and price_desc
+const (
+	SortRelevance  = "relevance"
+	SortPopularity = "popularity"
+)
+
+// RelevanceScale is the number of decimal places full-text relevance scores are sorted
+// and paged by
+const RelevanceScale = 6
+
+// Catalogue availability filters
+const (
+	AvailabilityAvailable = "available"
+	AvailabilitySoldOut   = "sold_out"
+	AvailabilityAll       = "all"
+)
+
+// CatalogueQuery selects one page of the public voucher catalogue
+type CatalogueQuery struct {
+	// Search is a MySQL boolean mode full-text expression; empty disables search
+	Search   string
+	MinPrice *Money
+	MaxPrice *Money
+	// Currency restricts the listing to one currency; empty lists all
+	Currency   string
+	MerchantID *int64
+	// CategoryIDs holds the requested category and its descendants
+	CategoryIDs  []int64
+	Tag          string
+	Availability string
+	Sort         string
+	Limit        int
+	After        *PageCursor
+	Language     string
+	// Now is the reference time for excluding expired vouchers
+	Now time.Time
+}
+
+// CatalogueVoucher is a voucher as listed in the public catalogue.
+// Title and Description hold the variant for Language after Localize.
+type CatalogueVoucher struct {
+	ID            uuid.UUID  `json:"id"`
+	AdvID         int64      `json:"adv_id"`
+	MerchantID    int64      `json:"merchant_id"`
+	Language      string     `json:"language"`
+	Title         string     `json:"title"`
+	Description   *string    `json:"description"`
+	TitleAR       *string    `json:"-"`
+	DescriptionAR *string    `json:"-"`
+	Price         Money      `json:"price"`
+	PhotoURL      *string    `json:"photo_url"`
+	CategoryID    *int64     `json:"category_id"`
+	Tags          []string   `json:"tags"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	Available     bool       `json:"available"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// Localize picks the title and description for lang, falling back to English
+func (v *CatalogueVoucher) Localize(lang string) {
+	v.Language = lang
+	v.Title = LocalizedText(lang, v.Title, v.TitleAR)
+	v.Description = LocalizedOptionalText(lang, v.Description, v.DescriptionAR)
+}
+
+// CataloguePage is one page of the catalogue; Next is nil on the last page
+type CataloguePage struct {
+	Vouchers []*CatalogueVoucher
+	Next     *PageCursor
+}
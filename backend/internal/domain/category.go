@@ -0,0 +1,58 @@
+package domain
+
+// Category is a node of the voucher category tree with Arabic and English names
+type Category struct {
+	ID        int64       `json:"id"`
+	ParentID  *int64      `json:"parent_id"`
+	Slug      string      `json:"slug"`
+	NameEN    string      `json:"name_en"`
+	NameAR    string      `json:"name_ar"`
+	SortOrder int         `json:"sort_order"`
+	Children  []*Category `json:"children,omitempty"`
+}
+
+// BuildCategoryTree links a flat list of categories into trees and returns the roots.
+// Categories whose parent is missing are treated as roots.
+func BuildCategoryTree(categories []*Category) []*Category {
+	byID := make(map[int64]*Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+
+	roots := make([]*Category, 0)
+	for _, category := range categories {
+		if category.ParentID != nil {
+			if parent, ok := byID[*category.ParentID]; ok {
+				parent.Children = append(parent.Children, category)
+				continue
+			}
+		}
+		roots = append(roots, category)
+	}
+
+	return roots
+}
+
+// CategorySubtree returns the ID of the category and all its descendants,
+// or nil if the category is not in the list
+func CategorySubtree(categories []*Category, id int64) []int64 {
+	children := make(map[int64][]int64)
+	found := false
+	for _, category := range categories {
+		if category.ID == id {
+			found = true
+		}
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+	if !found {
+		return nil
+	}
+
+	ids := []int64{id}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrCodePoolEmpty is returned when a voucher sold from a code pool has no unassigned code left
+	ErrCodePoolEmpty = errors.New("voucher code pool is empty")
+	// ErrCodeTaken is returned when the code read for a purchase was assigned elsewhere before it was claimed
+	ErrCodeTaken = errors.New("voucher code was assigned to another purchase")
+)
+
+// MaxVoucherCodeLength is the longest code a merchant can upload
+const MaxVoucherCodeLength = 64
+
+// CodePoolStatus reports how many of a voucher's codes are still unassigned.
+// Low is set once Available drops to LowThreshold.
+type CodePoolStatus struct {
+	VoucherID    uuid.UUID `json:"voucher_id"`
+	Total        int64     `json:"total"`
+	Assigned     int64     `json:"assigned"`
+	Available    int64     `json:"available"`
+	LowThreshold int64     `json:"low_threshold"`
+	Low          bool      `json:"low"`
+}
+
+// NewCodePoolStatus creates the status of a pool holding total codes of which available are unassigned
+func NewCodePoolStatus(voucherID uuid.UUID, total, available, lowThreshold int64) *CodePoolStatus {
+	return &CodePoolStatus{
+		VoucherID:    voucherID,
+		Total:        total,
+		Assigned:     total - available,
+		Available:    available,
+		LowThreshold: lowThreshold,
+		Low:          total > 0 && available <= lowThreshold,
+	}
+}
+
+// CodeUploadResult reports the outcome of a code upload; codes already in the pool are skipped
+type CodeUploadResult struct {
+	Added      int             `json:"added"`
+	Duplicates int             `json:"duplicates"`
+	Pool       *CodePoolStatus `json:"pool"`
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+// Domain errors returned by services and repositories
+var (
+	ErrVoucherNotFound    = errors.New("voucher not found")
+	ErrPurchaseNotFound   = errors.New("voucher purchase not found")
+	ErrAlreadyPurchased   = errors.New("voucher already purchased")
+	ErrAlreadyRedeemed    = errors.New("voucher already redeemed")
+	ErrPurchaseRefunded   = errors.New("voucher purchase was refunded")
+	ErrQRCodeMismatch     = errors.New("QR code is not the voucher's current code")
+	ErrVoucherExpired     = errors.New("voucher has expired")
+	ErrInvalidCursor      = errors.New("invalid cursor")
+	ErrVoucherUnavailable = errors.New("voucher is not available for sale")
+	ErrVoucherSold        = errors.New("voucher cannot be changed after it has been sold")
+	ErrVersionConflict    = errors.New("voucher was modified by another request")
+	ErrValidation         = errors.New("validation failed")
+	ErrForbidden          = errors.New("access denied")
+)
+
+// FieldError describes why a single request field is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Param is the rule parameter, e.g. the limit of min and max, used to translate Message
+	Param string `json:"-"`
+}
+
+// ValidationError carries every invalid field of a request.
+// It matches ErrValidation with errors.Is.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// NewValidationError creates a validation error for the given fields
+func NewValidationError(fields ...FieldError) *ValidationError {
+	return &ValidationError{Fields: fields}
+}
+
+// Error returns a summary of the invalid fields
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Field+": "+field.Message)
+	}
+	return ErrValidation.Error() + ": " + strings.Join(messages, "; ")
+}
+
+// Is reports whether target is ErrValidation
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrRateUnavailable is returned when no exchange rate is known between two currencies
+var ErrRateUnavailable = errors.New("exchange rate unavailable")
+
+// RateScale is the number of decimal places exchange rates are kept with
+const RateScale = 8
+
+// rateUnit is the integer value of a rate of exactly 1
+const rateUnit = 100000000
+
+// ExchangeRate is the value of one unit of From in To, as known at AsOf.
+// Rate is a fixed-point number with RateScale decimal places.
+type ExchangeRate struct {
+	From string
+	To   string
+	Rate int64
+	AsOf time.Time
+}
+
+// ParseRate parses a positive decimal exchange rate such as "0.30712500"
+func ParseRate(value string) (int64, error) {
+	rate, err := parseDecimal(value, RateScale)
+	if err != nil || rate <= 0 {
+		return 0, ErrInvalidAmount
+	}
+	return rate, nil
+}
+
+// IdentityRate is the rate of a currency to itself
+func IdentityRate(currency string, asOf time.Time) *ExchangeRate {
+	return &ExchangeRate{From: currency, To: currency, Rate: rateUnit, AsOf: asOf}
+}
+
+// CrossRate derives the rate from one currency to another from their rates to a common base
+func CrossRate(from, to *ExchangeRate) (*ExchangeRate, error) {
+	if from.To != to.To {
+		return nil, ErrCurrencyMismatch
+	}
+
+	asOf := from.AsOf
+	if to.AsOf.Before(asOf) {
+		asOf = to.AsOf
+	}
+	rate := divideRounded(new(big.Int).Mul(big.NewInt(from.Rate), big.NewInt(rateUnit)), big.NewInt(to.Rate))
+	if !rate.IsInt64() || rate.Sign() <= 0 {
+		return nil, ErrRateUnavailable
+	}
+	return &ExchangeRate{From: from.From, To: to.From, Rate: rate.Int64(), AsOf: asOf}, nil
+}
+
+// Decimal formats the rate with RateScale decimal places
+func (r *ExchangeRate) Decimal() string {
+	digits := strconv.FormatInt(r.Rate, 10)
+	if len(digits) <= RateScale {
+		digits = strings.Repeat("0", RateScale-len(digits)+1) + digits
+	}
+	return digits[:len(digits)-RateScale] + "." + digits[len(digits)-RateScale:]
+}
+
+// Convert returns m in the rate's target currency, rounded half away from zero to its minor unit
+func (r *ExchangeRate) Convert(m Money) (Money, error) {
+	if m.Currency != r.From {
+		return Money{}, ErrCurrencyMismatch
+	}
+	fromExponent, ok := CurrencyExponent(r.From)
+	if !ok {
+		return Money{}, ErrUnsupportedCurrency
+	}
+	toExponent, ok := CurrencyExponent(r.To)
+	if !ok {
+		return Money{}, ErrUnsupportedCurrency
+	}
+
+	// amount * rate / 10^RateScale, rescaled from the source to the target minor unit
+	numerator := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(r.Rate))
+	numerator.Mul(numerator, pow10(toExponent))
+	denominator := new(big.Int).Mul(big.NewInt(rateUnit), pow10(fromExponent))
+
+	converted := divideRounded(numerator, denominator)
+	if !converted.IsInt64() {
+		return Money{}, ErrInvalidAmount
+	}
+	return Money{Amount: converted.Int64(), Currency: r.To}, nil
+}
+
+// MarshalJSON encodes the rate as a decimal string
+func (r *ExchangeRate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		From string    `json:"from"`
+		To   string    `json:"to"`
+		Rate string    `json:"rate"`
+		AsOf time.Time `json:"as_of"`
+	}{r.From, r.To, r.Decimal(), r.AsOf})
+}
+
+// pow10 returns 10^n
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// divideRounded returns numerator / denominator rounded half away from zero;
+// denominator must be positive
+func divideRounded(numerator, denominator *big.Int) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	twice := new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2))
+	if twice.Cmp(denominator) >= 0 {
+		if numerator.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return quotient
+}
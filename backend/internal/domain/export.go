@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// Export kinds
+const (
+	// ExportPurchases lists the purchases made in the export period
+	ExportPurchases = "purchases"
+	// ExportRedemptions lists the purchases redeemed in the export period
+	ExportRedemptions = "redemptions"
+)
+
+// ExportFilter selects the rows of a purchase or redemption export in [From, To).
+// A nil MerchantID exports every merchant.
+type ExportFilter struct {
+	Kind       string
+	MerchantID *int64
+	From       time.Time
+	To         time.Time
+}
+
+// PurchaseExportRow is one exported purchase with the voucher and merchant it belongs to
+type PurchaseExportRow struct {
+	Purchase     *VoucherPurchase
+	VoucherTitle string
+	MerchantID   int64
+	AdvID        int64
+}
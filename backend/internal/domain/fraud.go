@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Fraud errors
+var (
+	ErrFraudBlocked = errors.New("operation blocked by fraud rules")
+	ErrFraudDelayed = errors.New("operation delayed by fraud rules")
+)
+
+// Fraud actions, from least to most severe. A rule with FraudActionAllow is disabled;
+// flagged operations go ahead and are recorded for review.
+const (
+	FraudActionAllow = "allow"
+	FraudActionFlag  = "flag"
+	FraudActionDelay = "delay"
+	FraudActionBlock = "block"
+)
+
+// fraudSeverity orders the fraud actions
+var fraudSeverity = map[string]int{
+	FraudActionAllow: 0,
+	FraudActionFlag:  1,
+	FraudActionDelay: 2,
+	FraudActionBlock: 3,
+}
+
+// IsFraudAction reports whether action is a known fraud action
+func IsFraudAction(action string) bool {
+	_, ok := fraudSeverity[action]
+	return ok
+}
+
+// Operations the fraud rules are evaluated on
+const (
+	FraudOperationPurchase   = "purchase"
+	FraudOperationRedemption = "redemption"
+)
+
+// Fraud rule names, as recorded on decisions
+const (
+	FraudRulePurchaseVelocity = "purchase_velocity"
+	FraudRuleSelfRedemption   = "self_redemption"
+	FraudRuleQuickRedemption  = "quick_redemption"
+	FraudRuleFailedLookups    = "failed_lookups"
+)
+
+// FraudSignals are the facts about an operation that the fraud rules are evaluated on
+type FraudSignals struct {
+	Operation  string
+	BuyerID    int64
+	MerchantID int64
+	VoucherID  uuid.UUID
+	PurchaseID *uuid.UUID
+	DeviceID   string
+	At         time.Time
+	// PurchasedAt is when the voucher being redeemed was bought
+	PurchasedAt *time.Time
+	// RecentPurchases is the number of the buyer's purchases within the velocity window
+	RecentPurchases int64
+	// FailedLookups is the number of failed QR lookups from DeviceID within the lookup window
+	FailedLookups int64
+}
+
+// FraudRules configures the fraud rules and the action each takes when it matches
+type FraudRules struct {
+	// PurchaseVelocityMax purchases by one buyer within PurchaseVelocityWindow match
+	PurchaseVelocityMax    int64
+	PurchaseVelocityWindow time.Duration
+	PurchaseVelocityAction string
+	// SelfRedemptionAction is taken when a merchant redeems a voucher they bought themselves
+	SelfRedemptionAction string
+	// QuickRedemptionWindow matches redemptions this soon after the purchase
+	QuickRedemptionWindow time.Duration
+	QuickRedemptionAction string
+	// FailedLookupsMax failed QR lookups from one device within FailedLookupsWindow match
+	FailedLookupsMax    int64
+	FailedLookupsWindow time.Duration
+	FailedLookupsAction string
+}
+
+// Evaluate returns the decision of the rules on an operation: the most severe action of
+// the rules that matched, or FraudActionAllow when none did
+func (r *FraudRules) Evaluate(signals *FraudSignals) *FraudDecision {
+	decision := &FraudDecision{
+		ID:         uuid.New(),
+		Operation:  signals.Operation,
+		Action:     FraudActionAllow,
+		Rules:      []string{},
+		BuyerID:    signals.BuyerID,
+		MerchantID: signals.MerchantID,
+		VoucherID:  signals.VoucherID,
+		PurchaseID: signals.PurchaseID,
+		CreatedAt:  signals.At,
+	}
+	if signals.DeviceID != "" {
+		deviceID := signals.DeviceID
+		decision.DeviceID = &deviceID
+	}
+
+	switch signals.Operation {
+	case FraudOperationPurchase:
+		if r.PurchaseVelocityMax > 0 && signals.RecentPurchases >= r.PurchaseVelocityMax {
+			decision.match(FraudRulePurchaseVelocity, r.PurchaseVelocityAction)
+		}
+	case FraudOperationRedemption:
+		if signals.BuyerID == signals.MerchantID {
+			decision.match(FraudRuleSelfRedemption, r.SelfRedemptionAction)
+		}
+		if signals.PurchasedAt != nil && signals.At.Sub(*signals.PurchasedAt) < r.QuickRedemptionWindow {
+			decision.match(FraudRuleQuickRedemption, r.QuickRedemptionAction)
+		}
+		if r.FailedLookupsMax > 0 && signals.FailedLookups >= r.FailedLookupsMax {
+			decision.match(FraudRuleFailedLookups, r.FailedLookupsAction)
+		}
+	}
+
+	return decision
+}
+
+// FraudDecision records the outcome of the fraud rules on one operation
+type FraudDecision struct {
+	ID        uuid.UUID `json:"id"`
+	Operation string    `json:"operation"`
+	Action    string    `json:"action"`
+	// Rules lists the rules that matched
+	Rules      []string   `json:"rules"`
+	BuyerID    int64      `json:"buyer_id"`
+	MerchantID int64      `json:"merchant_id"`
+	VoucherID  uuid.UUID  `json:"voucher_id"`
+	PurchaseID *uuid.UUID `json:"purchase_id,omitempty"`
+	DeviceID   *string    `json:"device_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// match records a rule that matched with its action; disabled rules are ignored
+func (d *FraudDecision) match(rule, action string) {
+	if action == FraudActionAllow || !IsFraudAction(action) {
+		return
+	}
+	d.Rules = append(d.Rules, rule)
+	if fraudSeverity[action] > fraudSeverity[d.Action] {
+		d.Action = action
+	}
+}
@@ -0,0 +1,31 @@
+package domain
+
+// Supported content languages
+const (
+	LanguageEnglish = "en"
+	LanguageArabic  = "ar"
+)
+
+// DefaultLanguage is served when no supported language is requested
+const DefaultLanguage = LanguageEnglish
+
+// IsSupportedLanguage reports whether content is available in lang
+func IsSupportedLanguage(lang string) bool {
+	return lang == LanguageEnglish || lang == LanguageArabic
+}
+
+// LocalizedText returns the Arabic text for Arabic requests when it is set, and the English text otherwise
+func LocalizedText(lang, english string, arabic *string) string {
+	if lang == LanguageArabic && arabic != nil && *arabic != "" {
+		return *arabic
+	}
+	return english
+}
+
+// LocalizedOptionalText is LocalizedText for optional fields
+func LocalizedOptionalText(lang string, english, arabic *string) *string {
+	if lang == LanguageArabic && arabic != nil && *arabic != "" {
+		return arabic
+	}
+	return english
+}
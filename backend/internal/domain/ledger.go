@@ -0,0 +1,347 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ledger errors
+var (
+	ErrLedgerTransactionNotFound = errors.New("ledger transaction not found")
+	ErrAlreadyReversed           = errors.New("ledger transaction already reversed")
+	ErrUnbalancedTransaction     = errors.New("ledger transaction does not balance")
+	ErrPurchaseNotRefunded       = errors.New("purchase of the ledger transaction is not refunded")
+	ErrPayoutNotReversible       = errors.New("payout transactions cannot be reversed")
+)
+
+// Ledger accounts. Debits are positive amounts and credits negative, so the
+// entries of every transaction sum to zero in each currency.
+const (
+	// AccountBuyerPayments holds the money collected from buyers
+	AccountBuyerPayments = "buyer_payments"
+	// AccountPlatformCommission holds the commission earned by the platform
+	AccountPlatformCommission = "platform_commission"
+	// AccountPlatformPromotions holds the discounts the platform funded on merchants' sales
+	AccountPlatformPromotions = "platform_promotions"
+	// AccountMerchantHeld holds what a merchant will be owed once the voucher is redeemed
+	AccountMerchantHeld = "merchant_held"
+	// AccountMerchantPayable holds what a merchant is owed and has not been paid yet
+	AccountMerchantPayable = "merchant_payable"
+	// AccountPayoutCash holds the money paid out to merchants from the platform's bank account
+	AccountPayoutCash = "payout_cash"
+)
+
+// Ledger transaction types
+const (
+	LedgerPurchase   = "purchase"
+	LedgerRedemption = "redemption"
+	LedgerRefund     = "refund"
+	LedgerReversal   = "reversal"
+	LedgerPayout     = "payout"
+)
+
+// LedgerEntry is one leg of a ledger transaction.
+// Amount is positive for a debit and negative for a credit.
+type LedgerEntry struct {
+	ID            uuid.UUID  `json:"id"`
+	TransactionID uuid.UUID  `json:"transaction_id"`
+	Account       string     `json:"account"`
+	MerchantID    int64      `json:"merchant_id"`
+	Amount        Money      `json:"amount"`
+	SettlementID  *uuid.UUID `json:"settlement_id,omitempty"`
+	PostedAt      time.Time  `json:"posted_at"`
+}
+
+// LedgerTransaction is a balanced set of entries posted for one purchase event, or for
+// one settlement paid out to a merchant; payouts have no purchase and a nil PurchaseID
+type LedgerTransaction struct {
+	ID           uuid.UUID      `json:"id"`
+	Type         string         `json:"type"`
+	PurchaseID   uuid.UUID      `json:"purchase_id"`
+	MerchantID   int64          `json:"merchant_id"`
+	ReversalOf   *uuid.UUID     `json:"reversal_of,omitempty"`
+	SettlementID *uuid.UUID     `json:"settlement_id,omitempty"`
+	Entries      []*LedgerEntry `json:"entries"`
+	PostedAt     time.Time      `json:"posted_at"`
+}
+
+// newLedgerTransaction creates an empty transaction of the given type
+func newLedgerTransaction(txType string, purchaseID uuid.UUID, merchantID int64, postedAt time.Time) *LedgerTransaction {
+	return &LedgerTransaction{
+		ID:         uuid.New(),
+		Type:       txType,
+		PurchaseID: purchaseID,
+		MerchantID: merchantID,
+		PostedAt:   postedAt,
+	}
+}
+
+// add appends an entry to the transaction
+func (t *LedgerTransaction) add(account string, amount Money) {
+	t.Entries = append(t.Entries, &LedgerEntry{
+		ID:            uuid.New(),
+		TransactionID: t.ID,
+		Account:       account,
+		MerchantID:    t.MerchantID,
+		Amount:        amount,
+		PostedAt:      t.PostedAt,
+	})
+}
+
+// Validate checks that the transaction has entries and that they sum to zero in each currency
+func (t *LedgerTransaction) Validate() error {
+	if len(t.Entries) == 0 {
+		return ErrUnbalancedTransaction
+	}
+	sums := make(map[string]int64)
+	for _, entry := range t.Entries {
+		sums[entry.Amount.Currency] += entry.Amount.Amount
+	}
+	for _, sum := range sums {
+		if sum != 0 {
+			return ErrUnbalancedTransaction
+		}
+	}
+	return nil
+}
+
+// NewPurchaseTransaction records a buyer paying for a voucher: the price paid plus any
+// platform funded discount is split into the platform commission and the amount held
+// for the merchant until redemption
+func NewPurchaseTransaction(purchase *VoucherPurchase, merchantID int64, commission, platformDiscount Money) (*LedgerTransaction, error) {
+	gross, err := purchase.Price.Add(platformDiscount)
+	if err != nil {
+		return nil, err
+	}
+	held, err := gross.Sub(commission)
+	if err != nil {
+		return nil, err
+	}
+
+	t := newLedgerTransaction(LedgerPurchase, purchase.ID, merchantID, purchase.CreatedAt)
+	t.add(AccountBuyerPayments, purchase.Price)
+	if !platformDiscount.IsZero() {
+		t.add(AccountPlatformPromotions, platformDiscount)
+	}
+	t.add(AccountPlatformCommission, commission.Neg())
+	t.add(AccountMerchantHeld, held.Neg())
+	return t, t.Validate()
+}
+
+// NewRedemptionTransaction releases the amount held for a purchase to the merchant's payable balance
+func NewRedemptionTransaction(purchaseID uuid.UUID, merchantID int64, held Money, postedAt time.Time) (*LedgerTransaction, error) {
+	t := newLedgerTransaction(LedgerRedemption, purchaseID, merchantID, postedAt)
+	t.add(AccountMerchantHeld, held)
+	t.add(AccountMerchantPayable, held.Neg())
+	return t, t.Validate()
+}
+
+// NewPayoutTransaction pays a settlement out of the merchant's payable balance. Its payable
+// entry is settled by the settlement itself so later settlements do not pick it up.
+func NewPayoutTransaction(settlement *Settlement) (*LedgerTransaction, error) {
+	t := newLedgerTransaction(LedgerPayout, uuid.Nil, settlement.MerchantID, settlement.CreatedAt)
+	settlementID := settlement.ID
+	t.SettlementID = &settlementID
+	t.add(AccountMerchantPayable, settlement.Amount)
+	t.add(AccountPayoutCash, settlement.Amount.Neg())
+	t.Entries[0].SettlementID = &settlementID
+	return t, t.Validate()
+}
+
+// NewReversalTransaction posts the opposite of every entry of original. Refunds use it
+// with LedgerRefund to undo a purchase; corrections use LedgerReversal. Payouts cannot
+// be reversed.
+func NewReversalTransaction(original *LedgerTransaction, txType string, postedAt time.Time) (*LedgerTransaction, error) {
+	if original.Type == LedgerPayout {
+		return nil, ErrPayoutNotReversible
+	}
+	t := newLedgerTransaction(txType, original.PurchaseID, original.MerchantID, postedAt)
+	reversalOf := original.ID
+	t.ReversalOf = &reversalOf
+	for _, entry := range original.Entries {
+		t.add(entry.Account, entry.Amount.Neg())
+	}
+	return t, t.Validate()
+}
+
+// AccountBalance sums the entries of account across transactions.
+// It returns false when the account has no entries.
+func AccountBalance(transactions []*LedgerTransaction, account string) (Money, bool, error) {
+	var balance Money
+	found := false
+	for _, t := range transactions {
+		for _, entry := range t.Entries {
+			if entry.Account != account {
+				continue
+			}
+			if !found {
+				balance, found = entry.Amount, true
+				continue
+			}
+			sum, err := balance.Add(entry.Amount)
+			if err != nil {
+				return Money{}, false, err
+			}
+			balance = sum
+		}
+	}
+	return balance, found, nil
+}
+
+// FindLedgerTransaction returns the first transaction of txType, or nil if there is none
+func FindLedgerTransaction(transactions []*LedgerTransaction, txType string) *LedgerTransaction {
+	for _, t := range transactions {
+		if t.Type == txType {
+			return t
+		}
+	}
+	return nil
+}
+
+// IsReversed reports whether any of the transactions reverses the transaction with the given ID
+func IsReversed(transactions []*LedgerTransaction, id uuid.UUID) bool {
+	for _, t := range transactions {
+		if t.ReversalOf != nil && *t.ReversalOf == id {
+			return true
+		}
+	}
+	return false
+}
+
+// CommissionRule sets the commission charged on sales of a merchant, of a category, or of
+// a merchant within a category. Rules without a merchant or category apply to all of them.
+type CommissionRule struct {
+	ID              int64  `json:"id"`
+	MerchantID      *int64 `json:"merchant_id"`
+	CategoryID      *int64 `json:"category_id"`
+	RateBasisPoints int64  `json:"rate_bps"`
+}
+
+// specificity ranks a rule: merchant and category, then merchant, then category, then global.
+// It returns -1 if the rule does not apply to the sale.
+func (r *CommissionRule) specificity(merchantID int64, categoryID *int64) int {
+	rank := 0
+	if r.MerchantID != nil {
+		if *r.MerchantID != merchantID {
+			return -1
+		}
+		rank += 2
+	}
+	if r.CategoryID != nil {
+		if categoryID == nil || *r.CategoryID != *categoryID {
+			return -1
+		}
+		rank++
+	}
+	return rank
+}
+
+// MatchCommissionRule returns the most specific rule that applies to a sale, or nil if none does
+func MatchCommissionRule(rules []*CommissionRule, merchantID int64, categoryID *int64) *CommissionRule {
+	var best *CommissionRule
+	bestRank := -1
+	for _, rule := range rules {
+		if rank := rule.specificity(merchantID, categoryID); rank > bestRank {
+			best, bestRank = rule, rank
+		}
+	}
+	return best
+}
+
+// Settlement records a payout of a merchant's payable balance in one currency for a period
+type Settlement struct {
+	ID         uuid.UUID `json:"id"`
+	MerchantID int64     `json:"merchant_id"`
+	Amount     Money     `json:"amount"`
+	PeriodFrom time.Time `json:"period_from"`
+	PeriodTo   time.Time `json:"period_to"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// StatementLine is one movement of a merchant's payable balance.
+// Net is what the merchant is owed for it; Gross and Commission come from the purchase.
+type StatementLine struct {
+	EntryID       uuid.UUID  `json:"entry_id"`
+	TransactionID uuid.UUID  `json:"transaction_id"`
+	Type          string     `json:"type"`
+	PurchaseID    uuid.UUID  `json:"purchase_id"`
+	VoucherID     uuid.UUID  `json:"voucher_id"`
+	Gross         Money      `json:"gross"`
+	Commission    Money      `json:"commission"`
+	Net           Money      `json:"net"`
+	SettlementID  *uuid.UUID `json:"settlement_id"`
+	PostedAt      time.Time  `json:"posted_at"`
+}
+
+// StatementTotal sums the lines of a statement in one currency. LedgerNet is the movement
+// of the payable account read independently from the ledger, which Net must equal.
+type StatementTotal struct {
+	Currency   string `json:"currency"`
+	Gross      Money  `json:"gross"`
+	Commission Money  `json:"commission"`
+	Net        Money  `json:"net"`
+	Settled    Money  `json:"settled"`
+	Unsettled  Money  `json:"unsettled"`
+	LedgerNet  Money  `json:"ledger_net"`
+}
+
+// PayoutStatement lists the payable movements of a merchant over a period.
+// To is exclusive.
+type PayoutStatement struct {
+	MerchantID  int64             `json:"merchant_id"`
+	From        time.Time         `json:"from"`
+	To          time.Time         `json:"to"`
+	Lines       []*StatementLine  `json:"lines"`
+	Totals      []*StatementTotal `json:"totals"`
+	Reconciled  bool              `json:"reconciled"`
+	Settlements []*Settlement     `json:"settlements,omitempty"`
+}
+
+// NewPayoutStatement totals the lines per currency and reconciles them against the
+// payable movements read from the ledger
+func NewPayoutStatement(merchantID int64, from, to time.Time, lines []*StatementLine, ledgerNet []Money) *PayoutStatement {
+	statement := &PayoutStatement{
+		MerchantID: merchantID,
+		From:       from,
+		To:         to,
+		Lines:      lines,
+		Totals:     make([]*StatementTotal, 0),
+		Reconciled: true,
+	}
+
+	totals := make(map[string]*StatementTotal)
+	total := func(currency string) *StatementTotal {
+		if t, ok := totals[currency]; ok {
+			return t
+		}
+		zero := NewMoney(0, currency)
+		t := &StatementTotal{Currency: currency, Gross: zero, Commission: zero, Net: zero, Settled: zero, Unsettled: zero, LedgerNet: zero}
+		totals[currency] = t
+		statement.Totals = append(statement.Totals, t)
+		return t
+	}
+
+	for _, line := range lines {
+		t := total(line.Net.Currency)
+		t.Gross.Amount += line.Gross.Amount
+		t.Commission.Amount += line.Commission.Amount
+		t.Net.Amount += line.Net.Amount
+		if line.SettlementID != nil {
+			t.Settled.Amount += line.Net.Amount
+		} else {
+			t.Unsettled.Amount += line.Net.Amount
+		}
+	}
+	for _, net := range ledgerNet {
+		total(net.Currency).LedgerNet = net
+	}
+
+	for _, t := range statement.Totals {
+		if t.Net != t.LedgerNet {
+			statement.Reconciled = false
+		}
+	}
+	return statement
+}
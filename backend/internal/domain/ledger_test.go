@@ -0,0 +1,232 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestLedgerTransactionValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		amounts []Money
+		wantErr error
+	}{
+		{name: "balanced", amounts: []Money{NewMoney(1000, "KWD"), NewMoney(-100, "KWD"), NewMoney(-900, "KWD")}},
+		{name: "balanced in each currency", amounts: []Money{NewMoney(1000, "KWD"), NewMoney(-1000, "KWD"), NewMoney(500, "USD"), NewMoney(-500, "USD")}},
+		{name: "no entries", amounts: nil, wantErr: ErrUnbalancedTransaction},
+		{name: "unbalanced", amounts: []Money{NewMoney(1000, "KWD"), NewMoney(-999, "KWD")}, wantErr: ErrUnbalancedTransaction},
+		{name: "balanced only across currencies", amounts: []Money{NewMoney(1000, "KWD"), NewMoney(-1000, "USD")}, wantErr: ErrUnbalancedTransaction},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := newLedgerTransaction(LedgerPurchase, uuid.New(), 1, time.Now())
+			for _, amount := range tt.amounts {
+				tx.add(AccountBuyerPayments, amount)
+			}
+			if err := tx.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewPurchaseTransaction(t *testing.T) {
+	tests := []struct {
+		name             string
+		price            Money
+		commission       Money
+		platformDiscount Money
+		want             map[string]int64
+		wantErr          error
+	}{
+		{
+			name:             "without discount",
+			price:            NewMoney(10000, "KWD"),
+			commission:       NewMoney(1000, "KWD"),
+			platformDiscount: NewMoney(0, "KWD"),
+			want: map[string]int64{
+				AccountBuyerPayments:      10000,
+				AccountPlatformCommission: -1000,
+				AccountMerchantHeld:       -9000,
+			},
+		},
+		{
+			name:             "platform funded discount",
+			price:            NewMoney(8000, "KWD"),
+			commission:       NewMoney(1000, "KWD"),
+			platformDiscount: NewMoney(2000, "KWD"),
+			want: map[string]int64{
+				AccountBuyerPayments:      8000,
+				AccountPlatformPromotions: 2000,
+				AccountPlatformCommission: -1000,
+				AccountMerchantHeld:       -9000,
+			},
+		},
+		{
+			name:             "mixed currencies",
+			price:            NewMoney(10000, "KWD"),
+			commission:       NewMoney(1000, "USD"),
+			platformDiscount: NewMoney(0, "KWD"),
+			wantErr:          ErrCurrencyMismatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			purchase := &VoucherPurchase{ID: uuid.New(), Price: tt.price, CreatedAt: time.Now()}
+			tx, err := NewPurchaseTransaction(purchase, 42, tt.commission, tt.platformDiscount)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("NewPurchaseTransaction() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewPurchaseTransaction() unexpected error: %v", err)
+			}
+
+			if tx.Type != LedgerPurchase || tx.PurchaseID != purchase.ID || tx.MerchantID != 42 {
+				t.Errorf("NewPurchaseTransaction() = %+v, want a purchase transaction of merchant 42", tx)
+			}
+			if len(tx.Entries) != len(tt.want) {
+				t.Fatalf("NewPurchaseTransaction() has %d entries, want %d", len(tx.Entries), len(tt.want))
+			}
+			for _, entry := range tx.Entries {
+				if want, ok := tt.want[entry.Account]; !ok || entry.Amount.Amount != want {
+					t.Errorf("entry %s = %d, want %d", entry.Account, entry.Amount.Amount, want)
+				}
+				if entry.TransactionID != tx.ID || entry.MerchantID != 42 {
+					t.Errorf("entry %s is not linked to its transaction", entry.Account)
+				}
+			}
+		})
+	}
+}
+
+func TestNewReversalTransaction(t *testing.T) {
+	purchase := &VoucherPurchase{ID: uuid.New(), Price: NewMoney(10000, "KWD"), CreatedAt: time.Now()}
+	original, err := NewPurchaseTransaction(purchase, 42, NewMoney(1000, "KWD"), NewMoney(0, "KWD"))
+	if err != nil {
+		t.Fatalf("NewPurchaseTransaction() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		txType string
+	}{
+		{name: "refund", txType: LedgerRefund},
+		{name: "correction", txType: LedgerReversal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reversal, err := NewReversalTransaction(original, tt.txType, time.Now())
+			if err != nil {
+				t.Fatalf("NewReversalTransaction() unexpected error: %v", err)
+			}
+
+			if reversal.Type != tt.txType || reversal.ReversalOf == nil || *reversal.ReversalOf != original.ID {
+				t.Errorf("NewReversalTransaction() = %+v, want a %s of %s", reversal, tt.txType, original.ID)
+			}
+			if reversal.PurchaseID != original.PurchaseID || reversal.MerchantID != original.MerchantID {
+				t.Errorf("NewReversalTransaction() does not keep the purchase and merchant of the original")
+			}
+			if len(reversal.Entries) != len(original.Entries) {
+				t.Fatalf("NewReversalTransaction() has %d entries, want %d", len(reversal.Entries), len(original.Entries))
+			}
+			for i, entry := range reversal.Entries {
+				if entry.Account != original.Entries[i].Account || entry.Amount != original.Entries[i].Amount.Neg() {
+					t.Errorf("entry %d = %s %s, want %s %s", i, entry.Account, entry.Amount, original.Entries[i].Account, original.Entries[i].Amount.Neg())
+				}
+			}
+
+			transactions := []*LedgerTransaction{original, reversal}
+			if !IsReversed(transactions, original.ID) {
+				t.Errorf("IsReversed() = false, want true")
+			}
+			balance, found, err := AccountBalance(transactions, AccountMerchantHeld)
+			if err != nil || !found || !balance.IsZero() {
+				t.Errorf("AccountBalance() = %s, %v, %v, want a zero balance", balance, found, err)
+			}
+		})
+	}
+}
+
+func TestNewPayoutTransaction(t *testing.T) {
+	settlement := &Settlement{ID: uuid.New(), MerchantID: 42, Amount: NewMoney(9000, "KWD"), CreatedAt: time.Now()}
+
+	payout, err := NewPayoutTransaction(settlement)
+	if err != nil {
+		t.Fatalf("NewPayoutTransaction() unexpected error: %v", err)
+	}
+	if payout.Type != LedgerPayout || payout.PurchaseID != uuid.Nil || payout.SettlementID == nil || *payout.SettlementID != settlement.ID {
+		t.Errorf("NewPayoutTransaction() = %+v, want a payout of settlement %s", payout, settlement.ID)
+	}
+
+	tests := []struct {
+		account string
+		want    int64
+		settled bool
+	}{
+		{account: AccountMerchantPayable, want: 9000, settled: true},
+		{account: AccountPayoutCash, want: -9000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.account, func(t *testing.T) {
+			balance, found, err := AccountBalance([]*LedgerTransaction{payout}, tt.account)
+			if err != nil || !found || balance.Amount != tt.want {
+				t.Errorf("AccountBalance() = %s, %v, %v, want %d", balance, found, err, tt.want)
+			}
+			for _, entry := range payout.Entries {
+				if entry.Account == tt.account && (entry.SettlementID != nil) != tt.settled {
+					t.Errorf("entry %s settlement = %v, want settled %v", entry.Account, entry.SettlementID, tt.settled)
+				}
+			}
+		})
+	}
+
+	if _, err := NewReversalTransaction(payout, LedgerReversal, time.Now()); err != ErrPayoutNotReversible {
+		t.Errorf("NewReversalTransaction() of a payout error = %v, want %v", err, ErrPayoutNotReversible)
+	}
+}
+
+func TestAccountBalance(t *testing.T) {
+	purchaseID := uuid.New()
+	held := func(amounts ...Money) *LedgerTransaction {
+		tx := newLedgerTransaction(LedgerPurchase, purchaseID, 42, time.Now())
+		for _, amount := range amounts {
+			tx.add(AccountMerchantHeld, amount)
+		}
+		return tx
+	}
+
+	tests := []struct {
+		name         string
+		transactions []*LedgerTransaction
+		want         Money
+		wantFound    bool
+		wantErr      error
+	}{
+		{name: "no transactions"},
+		{name: "one entry", transactions: []*LedgerTransaction{held(NewMoney(-9000, "KWD"))}, want: NewMoney(-9000, "KWD"), wantFound: true},
+		{name: "sum across transactions", transactions: []*LedgerTransaction{held(NewMoney(-9000, "KWD")), held(NewMoney(9000, "KWD"), NewMoney(-500, "KWD"))}, want: NewMoney(-500, "KWD"), wantFound: true},
+		{name: "mixed currencies", transactions: []*LedgerTransaction{held(NewMoney(-9000, "KWD"), NewMoney(100, "USD"))}, wantErr: ErrCurrencyMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found, err := AccountBalance(tt.transactions, AccountMerchantHeld)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AccountBalance() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want || found != tt.wantFound {
+				t.Errorf("AccountBalance() = %+v, %v, want %+v, %v", got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Money errors
+var (
+	ErrInvalidAmount       = errors.New("invalid amount")
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrCurrencyMismatch    = errors.New("currency mismatch")
+)
+
+// DefaultCurrency is the currency of amounts that do not state one
+const DefaultCurrency = "KWD"
+
+// AmountScale is the number of decimal places amounts are stored with,
+// enough for the three decimal currencies
+const AmountScale = 3
+
+// maxAmountDigits bounds the integer digits of an amount to what DECIMAL(15,3) can store
+const maxAmountDigits = 12
+
+// currencyExponents holds the number of decimal places of each supported ISO 4217 currency
+var currencyExponents = map[string]int{
+	"KWD": 3,
+	"BHD": 3,
+	"OMR": 3,
+	"JOD": 3,
+	"SAR": 2,
+	"AED": 2,
+	"QAR": 2,
+	"EGP": 2,
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+}
+
+// CurrencyExponent returns the number of decimal places of currency
+func CurrencyExponent(currency string) (int, bool) {
+	exponent, ok := currencyExponents[currency]
+	return exponent, ok
+}
+
+// IsSupportedCurrency reports whether amounts can be held in currency
+func IsSupportedCurrency(currency string) bool {
+	_, ok := currencyExponents[currency]
+	return ok
+}
+
+// SupportedCurrencies returns the supported currency codes in alphabetical order
+func SupportedCurrencies() []string {
+	currencies := make([]string, 0, len(currencyExponents))
+	for currency := range currencyExponents {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	return currencies
+}
+
+// Money is an exact amount in the minor unit of its currency, e.g. fils for KWD
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// NewMoney creates an amount of minor units in currency
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// ParseMoney parses a decimal amount such as "12.500" in currency. Trailing zeros
+// beyond the currency's decimal places are accepted; other extra digits are not.
+func ParseMoney(amount, currency string) (Money, error) {
+	exponent, ok := CurrencyExponent(currency)
+	if !ok {
+		return Money{}, ErrUnsupportedCurrency
+	}
+
+	minor, err := parseDecimal(amount, exponent)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: minor, Currency: currency}, nil
+}
+
+// parseDecimal parses a decimal string into an integer of 10^-scale units
+func parseDecimal(amount string, scale int) (int64, error) {
+	negative := strings.HasPrefix(amount, "-")
+	digits := strings.TrimPrefix(amount, "-")
+	whole, fraction := digits, ""
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		whole, fraction = digits[:i], digits[i+1:]
+	}
+	if whole == "" || len(whole) > maxAmountDigits || !isDigits(whole) || !isDigits(fraction) {
+		return 0, ErrInvalidAmount
+	}
+
+	if len(fraction) > scale {
+		if strings.Trim(fraction[scale:], "0") != "" {
+			return 0, ErrInvalidAmount
+		}
+		fraction = fraction[:scale]
+	}
+	fraction += strings.Repeat("0", scale-len(fraction))
+
+	units, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+	if negative {
+		units = -units
+	}
+	return units, nil
+}
+
+// isDigits reports whether s holds only ASCII digits
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Decimal formats the amount with the currency's decimal places, e.g. "12.500"
+func (m Money) Decimal() string {
+	exponent, ok := CurrencyExponent(m.Currency)
+	if !ok {
+		exponent = AmountScale
+	}
+
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	digits := strconv.FormatInt(amount, 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+// String formats the amount followed by its currency, e.g. "12.500 KWD"
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsPositive reports whether the amount is greater than zero
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// IsNegative reports whether the amount is less than zero
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Neg returns the amount with its sign flipped
+func (m Money) Neg() Money {
+	return Money{Amount: -m.Amount, Currency: m.Currency}
+}
+
+// Add returns m + other; both must be in the same currency
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+// Sub returns m - other; both must be in the same currency
+func (m Money) Sub(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	return Money{Amount: m.Amount - other.Amount, Currency: m.Currency}, nil
+}
+
+// Cmp compares m and other, returning -1, 0 or +1; both must be in the same currency
+func (m Money) Cmp(other Money) (int, error) {
+	if m.Currency != other.Currency {
+		return 0, ErrCurrencyMismatch
+	}
+	switch {
+	case m.Amount < other.Amount:
+		return -1, nil
+	case m.Amount > other.Amount:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// Percent returns basisPoints hundredths of a percent of m, rounded half away from zero
+// to the minor unit
+func (m Money) Percent(basisPoints int64) Money {
+	amount := m.Amount
+	negative := amount < 0
+	if negative {
+		amount = -amount
+	}
+
+	// Split the amount so the intermediate products stay within int64
+	result := amount/10000*basisPoints + (amount%10000*basisPoints+5000)/10000
+	if negative {
+		result = -result
+	}
+	return Money{Amount: result, Currency: m.Currency}
+}
+
+// moneyJSON is the wire form of Money. The amount is written as a string so clients
+// never round it through a float.
+type moneyJSON struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON encodes the amount as a decimal string, e.g. {"amount":"12.500","currency":"KWD"}
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.Decimal(), Currency: m.Currency})
+}
+
+// UnmarshalJSON decodes an amount given as a decimal string or number
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var wire struct {
+		Amount   json.Number `json:"amount"`
+		Currency string      `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+
+	parsed, err := ParseMoney(wire.Amount.String(), wire.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   string
+		currency string
+		want     int64
+		wantErr  error
+	}{
+		{name: "three decimal currency", amount: "12.500", currency: "KWD", want: 12500},
+		{name: "two decimal currency", amount: "12.50", currency: "USD", want: 1250},
+		{name: "whole amount", amount: "7", currency: "KWD", want: 7000},
+		{name: "short fraction is padded", amount: "0.5", currency: "KWD", want: 500},
+		{name: "trailing zeros beyond exponent", amount: "1.2500", currency: "SAR", want: 125},
+		{name: "negative amount", amount: "-3.250", currency: "BHD", want: -3250},
+		{name: "largest amount", amount: "999999999999.999", currency: "KWD", want: 999999999999999},
+		{name: "extra significant digit", amount: "1.255", currency: "USD", wantErr: ErrInvalidAmount},
+		{name: "too many whole digits", amount: "1000000000000", currency: "KWD", wantErr: ErrInvalidAmount},
+		{name: "missing whole part", amount: ".5", currency: "KWD", wantErr: ErrInvalidAmount},
+		{name: "empty", amount: "", currency: "KWD", wantErr: ErrInvalidAmount},
+		{name: "letters", amount: "12a", currency: "KWD", wantErr: ErrInvalidAmount},
+		{name: "exponent notation", amount: "1e3", currency: "KWD", wantErr: ErrInvalidAmount},
+		{name: "unsupported currency", amount: "1.000", currency: "XYZ", wantErr: ErrUnsupportedCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMoney(tt.amount, tt.currency)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ParseMoney(%q, %q) error = %v, want %v", tt.amount, tt.currency, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseMoney(%q, %q) unexpected error: %v", tt.amount, tt.currency, err)
+			}
+			if got.Amount != tt.want || got.Currency != tt.currency {
+				t.Errorf("ParseMoney(%q, %q) = %+v, want %d %s", tt.amount, tt.currency, got, tt.want, tt.currency)
+			}
+		})
+	}
+}
+
+func TestMoneyDecimal(t *testing.T) {
+	tests := []struct {
+		name  string
+		money Money
+		want  string
+	}{
+		{name: "three decimal currency", money: NewMoney(12500, "KWD"), want: "12.500"},
+		{name: "two decimal currency", money: NewMoney(1250, "USD"), want: "12.50"},
+		{name: "less than one unit", money: NewMoney(5, "KWD"), want: "0.005"},
+		{name: "zero", money: NewMoney(0, "SAR"), want: "0.00"},
+		{name: "negative", money: NewMoney(-1250, "KWD"), want: "-1.250"},
+		{name: "unknown currency uses amount scale", money: NewMoney(1234, "XYZ"), want: "1.234"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.money.Decimal(); got != tt.want {
+				t.Errorf("Decimal() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyPercent(t *testing.T) {
+	tests := []struct {
+		name        string
+		money       Money
+		basisPoints int64
+		want        int64
+	}{
+		{name: "ten percent", money: NewMoney(12500, "KWD"), basisPoints: 1000, want: 1250},
+		{name: "whole amount", money: NewMoney(12500, "KWD"), basisPoints: 10000, want: 12500},
+		{name: "zero percent", money: NewMoney(12500, "KWD"), basisPoints: 0, want: 0},
+		{name: "half rounds up", money: NewMoney(1, "KWD"), basisPoints: 5000, want: 1},
+		{name: "below half rounds down", money: NewMoney(1, "KWD"), basisPoints: 4999, want: 0},
+		{name: "half rounds away from zero when negative", money: NewMoney(-1, "KWD"), basisPoints: 5000, want: -1},
+		{name: "fractional basis points", money: NewMoney(999, "USD"), basisPoints: 250, want: 25},
+		{name: "large amount stays exact", money: NewMoney(999999999999999, "KWD"), basisPoints: 10000, want: 999999999999999},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.money.Percent(tt.basisPoints)
+			if got.Amount != tt.want || got.Currency != tt.money.Currency {
+				t.Errorf("Percent(%d) = %+v, want %d %s", tt.basisPoints, got, tt.want, tt.money.Currency)
+			}
+		})
+	}
+}
+
+func TestMoneyArithmeticRequiresSameCurrency(t *testing.T) {
+	kwd := NewMoney(1000, "KWD")
+	usd := NewMoney(1000, "USD")
+
+	if _, err := kwd.Add(usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add() error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+	if _, err := kwd.Sub(usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Sub() error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+	if _, err := kwd.Cmp(usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Cmp() error = %v, want %v", err, ErrCurrencyMismatch)
+	}
+
+	sum, err := kwd.Add(NewMoney(250, "KWD"))
+	if err != nil || sum != NewMoney(1250, "KWD") {
+		t.Errorf("Add() = %+v, %v, want 1250 KWD", sum, err)
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    Money
+		wantErr bool
+	}{
+		{name: "string amount", data: `{"amount":"12.500","currency":"KWD"}`, want: NewMoney(12500, "KWD")},
+		{name: "number amount", data: `{"amount":12.5,"currency":"USD"}`, want: NewMoney(1250, "USD")},
+		{name: "too precise", data: `{"amount":"12.505","currency":"USD"}`, wantErr: true},
+		{name: "unsupported currency", data: `{"amount":"1","currency":"XYZ"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Money
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Unmarshal(%s) = %+v, want error", tt.data, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal(%s) unexpected error: %v", tt.data, err)
+			}
+			if got != tt.want {
+				t.Errorf("Unmarshal(%s) = %+v, want %+v", tt.data, got, tt.want)
+			}
+
+			encoded, err := json.Marshal(got)
+			if err != nil {
+				t.Fatalf("Marshal() unexpected error: %v", err)
+			}
+			var roundTrip Money
+			if err := json.Unmarshal(encoded, &roundTrip); err != nil || roundTrip != got {
+				t.Errorf("round trip of %s = %+v, %v, want %+v", encoded, roundTrip, err, got)
+			}
+		})
+	}
+}
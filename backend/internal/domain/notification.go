@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrPreferencesNotFound is returned when a user has not saved notification preferences
+var ErrPreferencesNotFound = errors.New("notification preferences not found")
+
+// Notification channels
+const (
+	ChannelPush  = "push"
+	ChannelSMS   = "sms"
+	ChannelEmail = "email"
+)
+
+// Notification kinds
+const (
+	NotificationPurchased = "voucher_purchased"
+	NotificationRedeemed  = "voucher_redeemed"
+	NotificationExpiring  = "voucher_expiring"
+)
+
+// NotificationPreferences are the channels a user is notified on, in which language,
+// and the contact details the SMS and email channels deliver to
+type NotificationPreferences struct {
+	UserID       int64     `json:"user_id"`
+	Push         bool      `json:"push"`
+	SMS          bool      `json:"sms"`
+	Email        bool      `json:"email"`
+	Language     string    `json:"language"`
+	Phone        *string   `json:"phone"`
+	EmailAddress *string   `json:"email_address"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// DefaultNotificationPreferences are used for users who have not saved any: push only
+func DefaultNotificationPreferences(userID int64) *NotificationPreferences {
+	return &NotificationPreferences{
+		UserID:   userID,
+		Push:     true,
+		Language: DefaultLanguage,
+	}
+}
+
+// Recipients returns the enabled channels the user can be reached on, with the address
+// each delivers to. Push notifications are addressed by user ID, so their address is empty.
+func (p *NotificationPreferences) Recipients() map[string]string {
+	recipients := make(map[string]string)
+	if p.Push {
+		recipients[ChannelPush] = ""
+	}
+	if p.SMS && p.Phone != nil {
+		recipients[ChannelSMS] = *p.Phone
+	}
+	if p.Email && p.EmailAddress != nil {
+		recipients[ChannelEmail] = *p.EmailAddress
+	}
+	return recipients
+}
+
+// Notification is one message to a user on one channel
+type Notification struct {
+	UserID     int64     `json:"user_id"`
+	Kind       string    `json:"kind"`
+	Channel    string    `json:"channel"`
+	Recipient  string    `json:"recipient,omitempty"`
+	Language   string    `json:"language"`
+	Title      string    `json:"title"`
+	Body       string    `json:"body"`
+	PurchaseID uuid.UUID `json:"purchase_id"`
+}
+
+// NotificationParams are the values filled into a notification template
+type NotificationParams struct {
+	PurchaseID     uuid.UUID
+	VoucherTitle   string
+	VoucherTitleAR *string
+	ExpiresAt      *time.Time
+}
+
+// notificationTemplate is the title and body of a notification kind in one language.
+// {voucher} is replaced by the voucher title and {expires_on} by its expiry date.
+type notificationTemplate struct {
+	title string
+	body  string
+}
+
+// notificationTemplates holds the templates by kind and language
+var notificationTemplates = map[string]map[string]notificationTemplate{
+	NotificationPurchased: {
+		LanguageEnglish: {title: "Voucher purchased", body: "Your voucher {voucher} is ready to use."},
+		LanguageArabic:  {title: "تم شراء القسيمة", body: "قسيمتك {voucher} جاهزة للاستخدام."},
+	},
+	NotificationRedeemed: {
+		LanguageEnglish: {title: "Voucher redeemed", body: "Your voucher {voucher} has been redeemed."},
+		LanguageArabic:  {title: "تم استخدام القسيمة", body: "تم استخدام قسيمتك {voucher}."},
+	},
+	NotificationExpiring: {
+		LanguageEnglish: {title: "Voucher expiring soon", body: "Your voucher {voucher} expires on {expires_on}. Use it before then."},
+		LanguageArabic:  {title: "قسيمتك على وشك الانتهاء", body: "تنتهي صلاحية قسيمتك {voucher} في {expires_on}. استخدمها قبل ذلك."},
+	},
+}
+
+// NewNotification renders the notification of a kind for a user in their language,
+// falling back to English for unsupported languages
+func NewNotification(kind, channel, recipient string, prefs *NotificationPreferences, params *NotificationParams) *Notification {
+	language := prefs.Language
+	if !IsSupportedLanguage(language) {
+		language = DefaultLanguage
+	}
+	template := notificationTemplates[kind][language]
+
+	expiresOn := ""
+	if params.ExpiresAt != nil {
+		expiresOn = params.ExpiresAt.UTC().Format("2006-01-02")
+	}
+	replacer := strings.NewReplacer(
+		"{voucher}", LocalizedText(language, params.VoucherTitle, params.VoucherTitleAR),
+		"{expires_on}", expiresOn,
+	)
+
+	return &Notification{
+		UserID:     prefs.UserID,
+		Kind:       kind,
+		Channel:    channel,
+		Recipient:  recipient,
+		Language:   language,
+		Title:      template.title,
+		Body:       replacer.Replace(template.body),
+		PurchaseID: params.PurchaseID,
+	}
+}
+
+// ExpiringPurchase is an active purchase whose voucher expires soon
+type ExpiringPurchase struct {
+	PurchaseID     uuid.UUID
+	BuyerID        int64
+	VoucherTitle   string
+	VoucherTitleAR *string
+	ExpiresAt      time.Time
+}
@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// User voucher list sort orders
+const (
+	SortNewest    = "newest"
+	SortOldest    = "oldest"
+	SortPriceAsc  = "price_asc"
+	SortPriceDesc = "price_desc"
+)
+
+// UserVoucherQuery selects one page of the vouchers purchased by a user
+type UserVoucherQuery struct {
+	BuyerID       int64
+	Statuses      []string
+	PurchasedFrom *time.Time
+	PurchasedTo   *time.Time
+	// CategoryIDs holds the requested category and its descendants
+	CategoryIDs []int64
+	Tag         string
+	Sort        string
+	Limit       int
+	After       *PageCursor
+	Language    string
+	// Now is the reference time for deriving the expired status
+	Now time.Time
+}
+
+// UserVoucherPage is one page of a user's vouchers; Next is nil on the last page
+type UserVoucherPage struct {
+	Vouchers []*UserVoucherResponse
+	Next     *PageCursor
+}
+
+// PageCursor marks the last row of a page for keyset pagination.
+// Key holds the sort column of that row and ID breaks ties.
+type PageCursor struct {
+	Sort string    `json:"s"`
+	Key  string    `json:"k"`
+	ID   uuid.UUID `json:"id"`
+}
+
+// NewTimeCursor creates a cursor for a row sorted by a timestamp
+func NewTimeCursor(sort string, key time.Time, id uuid.UUID) *PageCursor {
+	return &PageCursor{Sort: sort, Key: key.UTC().Format(time.RFC3339Nano), ID: id}
+}
+
+// NewCountCursor creates a cursor for a row sorted by a count such as sales
+func NewCountCursor(sort string, key int64, id uuid.UUID) *PageCursor {
+	return &PageCursor{Sort: sort, Key: strconv.FormatInt(key, 10), ID: id}
+}
+
+// NewDecimalCursor creates a cursor for a row sorted by a decimal such as a relevance score,
+// keeping the decimal string the database returned so no precision is lost
+func NewDecimalCursor(sort string, key string, id uuid.UUID) *PageCursor {
+	return &PageCursor{Sort: sort, Key: key, ID: id}
+}
+
+// NewAmountCursor creates a cursor for a row sorted by an amount such as price
+func NewAmountCursor(sort string, key Money, id uuid.UUID) *PageCursor {
+	return &PageCursor{Sort: sort, Key: key.Decimal(), ID: id}
+}
+
+// TimeKey returns the key of a cursor created with NewTimeCursor
+func (c *PageCursor) TimeKey() (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, c.Key)
+	if err != nil {
+		return time.Time{}, ErrInvalidCursor
+	}
+	return t, nil
+}
+
+// CountKey returns the key of a cursor created with NewCountCursor
+func (c *PageCursor) CountKey() (int64, error) {
+	count, err := strconv.ParseInt(c.Key, 10, 64)
+	if err != nil || count < 0 {
+		return 0, ErrInvalidCursor
+	}
+	return count, nil
+}
+
+// DecimalKey returns the key of a cursor created with NewDecimalCursor, checking it is a
+// decimal with at most scale decimal places
+func (c *PageCursor) DecimalKey(scale int) (string, error) {
+	if _, err := parseDecimal(c.Key, scale); err != nil {
+		return "", ErrInvalidCursor
+	}
+	return c.Key, nil
+}
+
+// AmountKey returns the key of a cursor created with NewAmountCursor as a decimal string
+func (c *PageCursor) AmountKey() (string, error) {
+	if _, err := parseDecimal(c.Key, AmountScale); err != nil {
+		return "", ErrInvalidCursor
+	}
+	return c.Key, nil
+}
+
+// Encode returns the opaque form of the cursor handed to clients
+func (c *PageCursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodePageCursor parses a cursor produced by Encode
+func DecodePageCursor(s string) (*PageCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor PageCursor
+	if err := json.Unmarshal(b, &cursor); err != nil || cursor.Sort == "" || cursor.ID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
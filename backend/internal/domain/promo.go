@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Promo code errors
+var (
+	ErrPromoNotFound      = errors.New("promo code not found")
+	ErrPromoNotApplicable = errors.New("promo code does not apply to this purchase")
+	ErrPromoExhausted     = errors.New("promo code usage limit reached")
+	ErrPromoCodeExists    = errors.New("promo code already exists")
+)
+
+// Promo discount types
+const (
+	DiscountPercentage = "percentage"
+	DiscountFixed      = "fixed"
+)
+
+// Promo funding sources. A merchant funded discount comes out of the merchant's share;
+// a platform funded one is paid by the platform and the merchant is settled on the full price.
+const (
+	FundedByMerchant = "merchant"
+	FundedByPlatform = "platform"
+)
+
+// MaxPromoCodeLength is the longest promo code that can be created
+const MaxPromoCodeLength = 32
+
+// PromoCode is a discount buyers can apply when purchasing a voucher
+type PromoCode struct {
+	ID           int64  `json:"id"`
+	Code         string `json:"code"`
+	DiscountType string `json:"discount_type"`
+	// PercentBasisPoints is the discount of percentage codes in hundredths of a percent
+	PercentBasisPoints int64 `json:"percent_basis_points,omitempty"`
+	// Amount is the discount of fixed codes, which only apply to prices in its currency
+	Amount   *Money `json:"amount,omitempty"`
+	FundedBy string `json:"funded_by"`
+	// MerchantID limits the code to the vouchers of one merchant
+	MerchantID *int64 `json:"merchant_id"`
+	// MaxUses and MaxUsesPerUser are unlimited when nil
+	MaxUses        *int64     `json:"max_uses"`
+	MaxUsesPerUser *int64     `json:"max_uses_per_user"`
+	Uses           int64      `json:"uses"`
+	StartsAt       *time.Time `json:"starts_at"`
+	EndsAt         *time.Time `json:"ends_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// NormalizePromoCode returns code in the form it is stored and looked up in
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// CheckApplies reports ErrPromoNotApplicable when the code cannot be used on voucher at the given time
+func (p *PromoCode) CheckApplies(voucher *Voucher, at time.Time) error {
+	if p.StartsAt != nil && at.Before(*p.StartsAt) {
+		return ErrPromoNotApplicable
+	}
+	if p.EndsAt != nil && !at.Before(*p.EndsAt) {
+		return ErrPromoNotApplicable
+	}
+	if p.MerchantID != nil && *p.MerchantID != voucher.UserID {
+		return ErrPromoNotApplicable
+	}
+	if p.DiscountType == DiscountFixed && (p.Amount == nil || p.Amount.Currency != voucher.Price.Currency) {
+		return ErrPromoNotApplicable
+	}
+	return nil
+}
+
+// CheckUses reports ErrPromoExhausted when the code has been used up overall,
+// or by a user who has already used it userUses times
+func (p *PromoCode) CheckUses(userUses int64) error {
+	if p.MaxUses != nil && p.Uses >= *p.MaxUses {
+		return ErrPromoExhausted
+	}
+	if p.MaxUsesPerUser != nil && userUses >= *p.MaxUsesPerUser {
+		return ErrPromoExhausted
+	}
+	return nil
+}
+
+// Discount returns the amount the code takes off price, which never exceeds price
+func (p *PromoCode) Discount(price Money) (Money, error) {
+	discount := price.Percent(p.PercentBasisPoints)
+	if p.DiscountType == DiscountFixed {
+		if p.Amount == nil || p.Amount.Currency != price.Currency {
+			return Money{}, ErrPromoNotApplicable
+		}
+		discount = *p.Amount
+	}
+
+	if discount.Amount > price.Amount {
+		discount = price
+	}
+	return discount, nil
+}
+
+// PromoRedemption records one use of a promo code on a purchase
+type PromoRedemption struct {
+	ID          uuid.UUID `json:"id"`
+	PromoCodeID int64     `json:"promo_code_id"`
+	PurchaseID  uuid.UUID `json:"purchase_id"`
+	UserID      int64     `json:"user_id"`
+	Discount    Money     `json:"discount"`
+	FundedBy    string    `json:"funded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Real-time event types
+const (
+	EventPurchaseCreated  = "purchase.created"
+	EventPurchaseRedeemed = "purchase.redeemed"
+	// EventRedemption is sent to merchants for each redemption at their branches
+	EventRedemption = "redemption"
+	// EventReset tells a reconnecting client that events it missed are no longer
+	// available, so it has to reload its state
+	EventReset = "reset"
+)
+
+// Event is a real-time event published to the subscribers of a topic. IDs increase
+// within one run of the server and are what clients resume from with Last-Event-ID.
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Subscription is a subscriber's view of a topic: the events it missed since the
+// Last-Event-ID it resumed from, then the live events. Events is closed when the
+// subscriber falls too far behind, and the client should reconnect.
+type Subscription struct {
+	Replay []*Event
+	// Reset is set when missed events could not be replayed
+	Reset  bool
+	Events <-chan *Event
+	Close  func()
+}
+
+// UserTopic is the topic of the events of one user's purchases
+func UserTopic(userID int64) string {
+	return "user:" + strconv.FormatInt(userID, 10)
+}
+
+// MerchantTopic is the topic of the redemptions of one merchant's vouchers
+func MerchantTopic(merchantID int64) string {
+	return "merchant:" + strconv.FormatInt(merchantID, 10)
+}
+
+// PurchaseEvent is the data of purchase events sent to the buyer
+type PurchaseEvent struct {
+	PurchaseID uuid.UUID  `json:"purchase_id"`
+	VoucherID  uuid.UUID  `json:"voucher_id"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RedeemedAt *time.Time `json:"redeemed_at,omitempty"`
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Redemption is a voucher redeemed at one of its merchant's branches
+type Redemption struct {
+	PurchaseID   uuid.UUID `json:"purchase_id"`
+	VoucherID    uuid.UUID `json:"voucher_id"`
+	VoucherTitle string    `json:"voucher_title"`
+	MerchantID   int64     `json:"merchant_id"`
+	// BranchID and StaffID are the branch and cashier that scanned the voucher, when reported
+	BranchID *int64 `json:"branch_id"`
+	StaffID  *int64 `json:"staff_id"`
+	// Amount is the price the buyer paid for the voucher
+	Amount     Money     `json:"amount"`
+	RedeemedAt time.Time `json:"redeemed_at"`
+}
+
+// RedemptionQuery selects one page of a merchant's redemptions, newest first
+type RedemptionQuery struct {
+	MerchantID int64
+	BranchID   *int64
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	After      *PageCursor
+}
+
+// RedemptionPage is one page of a merchant's redemptions; Next is nil on the last page
+type RedemptionPage struct {
+	Redemptions []*Redemption
+	Next        *PageCursor
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reservation errors
+var (
+	ErrReservationNotFound  = errors.New("reservation not found")
+	ErrReservationNotActive = errors.New("reservation is no longer held")
+	ErrVoucherReserved      = errors.New("voucher is reserved by another buyer")
+)
+
+// Reservation statuses. A held reservation past its expiry reads as expired.
+const (
+	ReservationHeld      = "held"
+	ReservationConfirmed = "confirmed"
+	ReservationReleased  = "released"
+	ReservationExpired   = "expired"
+)
+
+// Reservation is a time-limited hold on a voucher for one buyer while the marketplace
+// takes payment. Confirming it purchases the voucher; until it expires nobody else can.
+type Reservation struct {
+	ID         uuid.UUID  `json:"id"`
+	VoucherID  uuid.UUID  `json:"voucher_id"`
+	BuyerID    int64      `json:"buyer_id"`
+	Status     string     `json:"status"`
+	PurchaseID *uuid.UUID `json:"purchase_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// IsHeld reports whether the reservation still holds its voucher at now
+func (r *Reservation) IsHeld(now time.Time) bool {
+	return r.Status == ReservationHeld && now.Before(r.ExpiresAt)
+}
+
+// ExpireAt marks a held reservation past its expiry as expired, for reading
+func (r *Reservation) ExpireAt(now time.Time) {
+	if r.Status == ReservationHeld && !now.Before(r.ExpiresAt) {
+		r.Status = ReservationExpired
+	}
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Transfer errors
+var (
+	ErrTransferNotFound   = errors.New("voucher transfer not found")
+	ErrTransferNotPending = errors.New("voucher transfer is no longer pending")
+	ErrTransferPending    = errors.New("voucher already has a pending transfer")
+	// ErrTransferPooledCode is returned for purchases holding a merchant's pool code, which the
+	// merchant's point of sale would keep accepting from the sender after a transfer
+	ErrTransferPooledCode = errors.New("vouchers with a merchant code cannot be transferred")
+)
+
+// Transfer statuses. A pending transfer past its expiry reads as expired.
+const (
+	TransferPending   = "pending"
+	TransferAccepted  = "accepted"
+	TransferCancelled = "cancelled"
+	TransferExpired   = "expired"
+)
+
+// PurchaseTransfer is an offer by a purchase's buyer to give it to another user, identified
+// by 4Sale user ID or by phone number. Accepted transfers stay as the purchase's ownership history.
+type PurchaseTransfer struct {
+	ID          uuid.UUID  `json:"id"`
+	PurchaseID  uuid.UUID  `json:"purchase_id"`
+	FromBuyerID int64      `json:"from_buyer_id"`
+	ToUserID    *int64     `json:"to_user_id,omitempty"`
+	ToPhone     *string    `json:"to_phone,omitempty"`
+	Status      string     `json:"status"`
+	AcceptedBy  *int64     `json:"accepted_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
+	// TokenHash is the SHA-256 of the claim token a phone recipient presents to accept
+	TokenHash string `json:"-"`
+	// ClaimToken is returned once, when the transfer is created, for the sender to share
+	ClaimToken string `json:"claim_token,omitempty"`
+}
+
+// IsOpen reports whether the transfer can still be accepted or cancelled at now
+func (t *PurchaseTransfer) IsOpen(now time.Time) bool {
+	return t.Status == TransferPending && now.Before(t.ExpiresAt)
+}
+
+// ExpireAt marks a pending transfer past its expiry as expired, for reading
+func (t *PurchaseTransfer) ExpireAt(now time.Time) {
+	if t.Status == TransferPending && !now.Before(t.ExpiresAt) {
+		t.Status = TransferExpired
+	}
+}
+
+// CheckTransferable rejects transfers of purchases that were redeemed or refunded
+func CheckTransferable(purchaseStatus string) error {
+	switch purchaseStatus {
+	case StatusRedeemed:
+		return ErrAlreadyRedeemed
+	case StatusRefunded:
+		return ErrPurchaseRefunded
+	default:
+		return nil
+	}
+}
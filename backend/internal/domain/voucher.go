@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"crypto/subtle"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,47 +10,159 @@ import (
 
 // Voucher represents the core voucher entity
 type Voucher struct {
-	ID          uuid.UUID `json:"id"`
-	AdvID       int64     `json:"adv_id"`
-	UserID      int64     `json:"user_id"`
-	Title       string    `json:"title"`
-	Description *string   `json:"description"`
-	Price       float64   `json:"price"`
-	PhotoURL    *string   `json:"photo_url"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID            uuid.UUID  `json:"id"`
+	AdvID         int64      `json:"adv_id"`
+	UserID        int64      `json:"user_id"`
+	Title         string     `json:"title"`
+	TitleAR       *string    `json:"title_ar"`
+	Description   *string    `json:"description"`
+	DescriptionAR *string    `json:"description_ar"`
+	Price         Money      `json:"price"`
+	PhotoURL      *string    `json:"photo_url"`
+	CategoryID    *int64     `json:"category_id"`
+	Tags          []string   `json:"tags"`
+	Status        string     `json:"status"`
+	Version       int        `json:"version"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     *time.Time `json:"updated_at"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty"`
+}
+
+// IsAvailable reports whether the voucher can currently be sold
+func (v *Voucher) IsAvailable() bool {
+	return v.Status == VoucherStatusActive && v.DeletedAt == nil
+}
+
+// IsExpired reports whether the voucher can no longer be used at the given time
+func (v *Voucher) IsExpired(at time.Time) bool {
+	return v.ExpiresAt != nil && !at.Before(*v.ExpiresAt)
+}
+
+// MerchantVoucher represents a voucher with its sales figures for the owning merchant
+type MerchantVoucher struct {
+	Voucher
+	SalesCount      int `json:"sales_count"`
+	RedemptionCount int `json:"redemption_count"`
+}
+
+// VoucherChange records one field of a voucher changed by an upstream listing event or its merchant
+type VoucherChange struct {
+	ID        uuid.UUID `json:"id"`
+	VoucherID uuid.UUID `json:"voucher_id"`
+	Source    string    `json:"source"`
+	Field     string    `json:"field"`
+	OldValue  *string   `json:"old_value"`
+	NewValue  *string   `json:"new_value"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// VoucherWithdrawal describes the outcome of a listing deletion
+type VoucherWithdrawal struct {
+	VoucherID        uuid.UUID `json:"voucher_id"`
+	DeletedAt        time.Time `json:"deleted_at"`
+	PurchaseRefunded bool      `json:"purchase_refunded"`
+	AlreadyWithdrawn bool      `json:"already_withdrawn"`
 }
 
 // VoucherPurchase represents a voucher purchase entity
 type VoucherPurchase struct {
-	ID         uuid.UUID  `json:"id"`
-	VoucherID  uuid.UUID  `json:"voucher_id"`
-	BuyerID    int64      `json:"buyer_id"`
-	QRCode     string     `json:"qr_code"`
+	ID        uuid.UUID `json:"id"`
+	VoucherID uuid.UUID `json:"voucher_id"`
+	BuyerID   int64     `json:"buyer_id"`
+	QRCode    string    `json:"qr_code"`
+	// QRPayload is the data encoded in QRCode; purchases recorded before it was kept have none
+	QRPayload  *string    `json:"-"`
 	Status     string     `json:"status"`
 	RedeemedAt *time.Time `json:"redeemed_at"`
-	CreatedAt  time.Time  `json:"created_at"`
+	RefundedAt *time.Time `json:"refunded_at,omitempty"`
+	Price      Money      `json:"price"`
+	// Discount is what PromoCode took off the voucher's price; Price is what the buyer paid
+	Discount  *Money  `json:"discount,omitempty"`
+	PromoCode *string `json:"promo_code,omitempty"`
+	// ReportingPrice is Price converted at ExchangeRate into the reporting currency
+	ReportingPrice *Money        `json:"reporting_price,omitempty"`
+	ExchangeRate   *ExchangeRate `json:"exchange_rate,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// NewQRPayload returns the data of a QR code issued to a buyer. Each code carries a fresh
+// nonce, so a reissued code never matches the one it replaces.
+func NewQRPayload(voucherID uuid.UUID, buyerID int64) string {
+	return fmt.Sprintf("voucher:%s:buyer:%d:%s", voucherID, buyerID, uuid.New())
 }
 
-// UserVoucherResponse represents the response for user voucher list
+// QRPayloadMatches reports whether scanned is the payload of the current QR code.
+// Without a stored payload any scanned code is accepted.
+func QRPayloadMatches(current *string, scanned string) bool {
+	return current == nil || subtle.ConstantTimeCompare([]byte(*current), []byte(scanned)) == 1
+}
+
+// UserVoucherResponse represents the response for user voucher list.
+// Title and Description hold the variant for Language after Localize.
 type UserVoucherResponse struct {
-	ID          uuid.UUID  `json:"id"`
-	Title       string     `json:"title"`
-	Description *string    `json:"description"`
-	Status      string     `json:"status"`
-	PhotoURL    *string    `json:"photo_url"`
-	QRCode      *string    `json:"qr_code,omitempty"`
-	Price       float64    `json:"price"`
-	PurchasedAt time.Time  `json:"purchased_at"`
-	RedeemedAt  *time.Time `json:"redeemed_at"`
+	ID            uuid.UUID  `json:"id"`
+	VoucherID     uuid.UUID  `json:"voucher_id"`
+	Language      string     `json:"language"`
+	Title         string     `json:"title"`
+	Description   *string    `json:"description"`
+	TitleAR       *string    `json:"-"`
+	DescriptionAR *string    `json:"-"`
+	Status        string     `json:"status"`
+	PhotoURL      *string    `json:"photo_url"`
+	QRCode        *string    `json:"qr_code,omitempty"`
+	Price         Money      `json:"price"`
+	CategoryID    *int64     `json:"category_id"`
+	Tags          []string   `json:"tags"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	PurchasedAt   time.Time  `json:"purchased_at"`
+	RedeemedAt    *time.Time `json:"redeemed_at"`
+}
+
+// Localize picks the title and description for lang, falling back to English
+func (v *UserVoucherResponse) Localize(lang string) {
+	v.Language = lang
+	v.Title = LocalizedText(lang, v.Title, v.TitleAR)
+	v.Description = LocalizedOptionalText(lang, v.Description, v.DescriptionAR)
 }
 
 // Validation constants
 const (
 	StatusActive   = "active"
 	StatusRedeemed = "redeemed"
+	StatusRefunded = "refunded"
+
+	// StatusExpired is derived for active purchases whose voucher has expired; it is never stored
+	StatusExpired = "expired"
+)
+
+// Voucher sale statuses
+const (
+	VoucherStatusActive = "active"
+	VoucherStatusPaused = "paused"
+)
+
+// Sources of voucher changes
+const (
+	ChangeSourceListing  = "listing"
+	ChangeSourceMerchant = "merchant"
 )
 
+// RefundPolicy decides what happens to an unredeemed purchase when its listing is deleted
+type RefundPolicy string
+
+// Refund policies
+const (
+	RefundPolicyNone       RefundPolicy = "none"
+	RefundPolicyUnredeemed RefundPolicy = "refund_unredeemed"
+)
+
+// IsValid reports whether p is a known refund policy
+func (p RefundPolicy) IsValid() bool {
+	return p == RefundPolicyNone || p == RefundPolicyUnredeemed
+}
+
 // ValidateStatus checks if the status is valid
 func ValidateStatus(status string) bool {
-	return status == StatusActive || status == StatusRedeemed
-}
\ No newline at end of file
+	return status == StatusActive || status == StatusRedeemed || status == StatusRefunded
+}
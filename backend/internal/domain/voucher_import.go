@@ -0,0 +1,42 @@
+package domain
+
+import "github.com/google/uuid"
+
+// ImportRowError lists why one row of a voucher import was rejected
+type ImportRowError struct {
+	Row    int          `json:"row"`
+	Errors []FieldError `json:"errors"`
+}
+
+// ImportedVoucher is a voucher created from one row of an import
+type ImportedVoucher struct {
+	Row       int       `json:"row"`
+	VoucherID uuid.UUID `json:"voucher_id"`
+}
+
+// VoucherImportReport summarizes a bulk voucher import. Rows are numbered from 1 in file
+// order, not counting a CSV header. A dry run validates every row but imports none.
+type VoucherImportReport struct {
+	DryRun   bool              `json:"dry_run"`
+	Total    int               `json:"total"`
+	Valid    int               `json:"valid"`
+	Imported int               `json:"imported"`
+	Failed   int               `json:"failed"`
+	Vouchers []ImportedVoucher `json:"vouchers"`
+	Errors   []ImportRowError  `json:"errors"`
+}
+
+// NewVoucherImportReport creates an empty report
+func NewVoucherImportReport(dryRun bool) *VoucherImportReport {
+	return &VoucherImportReport{
+		DryRun:   dryRun,
+		Vouchers: make([]ImportedVoucher, 0),
+		Errors:   make([]ImportRowError, 0),
+	}
+}
+
+// Reject records a row that was not imported
+func (r *VoucherImportReport) Reject(row int, fields ...FieldError) {
+	r.Failed++
+	r.Errors = append(r.Errors, ImportRowError{Row: row, Errors: fields})
+}
@@ -0,0 +1,29 @@
+package alerts
+
+import (
+	"context"
+
+	"4SaleBackendSkeleton/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+// LogAlerter raises operational alerts as warning logs for log based alerting to pick up
+type LogAlerter struct {
+	logger zerolog.Logger
+}
+
+// NewLogAlerter creates a new log alerter
+func NewLogAlerter(logger zerolog.Logger) *LogAlerter {
+	return &LogAlerter{logger: logger}
+}
+
+// CodePoolLow logs that a voucher's code pool is running low
+func (a *LogAlerter) CodePoolLow(ctx context.Context, merchantID int64, status *domain.CodePoolStatus) {
+	a.logger.Warn().
+		Str("alert", "code_pool_low").
+		Int64("merchant_id", merchantID).
+		Str("voucher_id", status.VoucherID.String()).
+		Int64("available", status.Available).
+		Int64("total", status.Total).
+		Msg("Voucher code pool is running low")
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"context"
+	"strings"
+)
+
+// claimsContextKey is the context key for validated session claims
+type claimsContextKey struct{}
+
+// ContextWithClaims returns a copy of ctx carrying the claims
+func ContextWithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims stored in ctx, if any
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(*Claims)
+	return claims, ok
+}
+
+// BearerToken extracts the token from an Authorization header
+func BearerToken(authHeader string) string {
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return ""
+	}
+	return parts[1]
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemorySessionStore implements SessionStore in process memory
+type MemorySessionStore struct {
+	mu              sync.Mutex
+	refreshTokens   map[string]*RefreshToken
+	revokedFamilies map[string]time.Time
+	revokedAccess   map[string]time.Time
+	now             func() time.Time
+}
+
+// NewMemorySessionStore creates a new in-memory session store
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{
+		refreshTokens:   make(map[string]*RefreshToken),
+		revokedFamilies: make(map[string]time.Time),
+		revokedAccess:   make(map[string]time.Time),
+		now:             time.Now,
+	}
+}
+
+// SaveRefreshToken stores a refresh token
+func (s *MemorySessionStore) SaveRefreshToken(ctx context.Context, token *RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep()
+
+	stored := *token
+	s.refreshTokens[token.Hash] = &stored
+	return nil
+}
+
+// GetRefreshToken returns the refresh token with the given hash, or nil if unknown
+func (s *MemorySessionStore) GetRefreshToken(ctx context.Context, hash string) (*RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.refreshTokens[hash]
+	if !ok {
+		return nil, nil
+	}
+
+	token := *stored
+	return &token, nil
+}
+
+// MarkRefreshTokenUsed marks the token used and reports whether it was unused before
+func (s *MemorySessionStore) MarkRefreshTokenUsed(ctx context.Context, hash string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.refreshTokens[hash]
+	if !ok || stored.Used {
+		return false, nil
+	}
+
+	stored.Used = true
+	return true, nil
+}
+
+// RevokeFamily revokes every refresh token issued in the session family
+func (s *MemorySessionStore) RevokeFamily(ctx context.Context, familyID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expiresAt time.Time
+	for _, token := range s.refreshTokens {
+		if token.FamilyID == familyID && token.ExpiresAt.After(expiresAt) {
+			expiresAt = token.ExpiresAt
+		}
+	}
+
+	s.revokedFamilies[familyID] = expiresAt
+	return nil
+}
+
+// IsFamilyRevoked reports whether the session family was revoked
+func (s *MemorySessionStore) IsFamilyRevoked(ctx context.Context, familyID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, revoked := s.revokedFamilies[familyID]
+	return revoked, nil
+}
+
+// RevokeAccessToken revokes an access token until it expires
+func (s *MemorySessionStore) RevokeAccessToken(ctx context.Context, id string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.revokedAccess[id] = expiresAt
+	return nil
+}
+
+// IsAccessTokenRevoked reports whether the access token was revoked
+func (s *MemorySessionStore) IsAccessTokenRevoked(ctx context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, revoked := s.revokedAccess[id]
+	return revoked, nil
+}
+
+// sweep drops expired tokens and revocations. Callers must hold mu.
+func (s *MemorySessionStore) sweep() {
+	now := s.now()
+
+	for hash, token := range s.refreshTokens {
+		if now.After(token.ExpiresAt) {
+			delete(s.refreshTokens, hash)
+		}
+	}
+	for familyID, expiresAt := range s.revokedFamilies {
+		if now.After(expiresAt) {
+			delete(s.revokedFamilies, familyID)
+		}
+	}
+	for id, expiresAt := range s.revokedAccess {
+		if now.After(expiresAt) {
+			delete(s.revokedAccess, id)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session token errors
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrExpiredToken = errors.New("token expired")
+	ErrRevokedToken = errors.New("token revoked")
+)
+
+// jwtHeader is the fixed header of every issued access token
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// Claims holds the identity carried by an access token
+type Claims struct {
+	Subject   string `json:"sub"`
+	UserID    int64  `json:"uid"`
+	UserType  string `json:"utype,omitempty"`
+	Issuer    string `json:"iss"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+	ID        string `json:"jti"`
+}
+
+// Expiry returns the expiry time of the claims
+func (c *Claims) Expiry() time.Time {
+	return time.Unix(c.ExpiresAt, 0)
+}
+
+// SessionUser identifies the user a session is issued for
+type SessionUser struct {
+	UserID   int64
+	UserType string
+}
+
+// TokenPair is returned to the client after login or refresh
+type TokenPair struct {
+	AccessToken      string    `json:"access_token"`
+	RefreshToken     string    `json:"refresh_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// RefreshToken is the stored form of a refresh token; only its hash is kept
+type RefreshToken struct {
+	Hash      string
+	FamilyID  string
+	UserID    int64
+	UserType  string
+	ExpiresAt time.Time
+	Used      bool
+}
+
+// SessionStore persists refresh tokens and access token revocations
+type SessionStore interface {
+	SaveRefreshToken(ctx context.Context, token *RefreshToken) error
+	GetRefreshToken(ctx context.Context, hash string) (*RefreshToken, error)
+	// MarkRefreshTokenUsed atomically marks the token used and reports whether it was unused before
+	MarkRefreshTokenUsed(ctx context.Context, hash string) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string) error
+	IsFamilyRevoked(ctx context.Context, familyID string) (bool, error)
+	RevokeAccessToken(ctx context.Context, id string, expiresAt time.Time) error
+	IsAccessTokenRevoked(ctx context.Context, id string) (bool, error)
+}
+
+// TokenConfig configures token issuing
+type TokenConfig struct {
+	SigningKey []byte
+	Issuer     string
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+// TokenIssuer issues and validates locally signed session tokens
+type TokenIssuer struct {
+	config TokenConfig
+	store  SessionStore
+	now    func() time.Time
+}
+
+// NewTokenIssuer creates a new token issuer
+func NewTokenIssuer(config TokenConfig, store SessionStore) (*TokenIssuer, error) {
+	if len(config.SigningKey) < 32 {
+		return nil, fmt.Errorf("signing key must be at least 32 bytes")
+	}
+
+	return &TokenIssuer{
+		config: config,
+		store:  store,
+		now:    time.Now,
+	}, nil
+}
+
+// Issue starts a new session for the user
+func (t *TokenIssuer) Issue(ctx context.Context, user SessionUser) (*TokenPair, error) {
+	return t.issue(ctx, user, uuid.New().String())
+}
+
+// Refresh rotates a refresh token into a new token pair.
+// Presenting an already used refresh token revokes the whole session.
+func (t *TokenIssuer) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	hash := hashToken(refreshToken)
+
+	stored, err := t.store.GetRefreshToken(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	if stored == nil {
+		return nil, ErrInvalidToken
+	}
+
+	revoked, err := t.store.IsFamilyRevoked(ctx, stored.FamilyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check session revocation: %w", err)
+	}
+	if revoked {
+		return nil, ErrRevokedToken
+	}
+
+	if t.now().After(stored.ExpiresAt) {
+		return nil, ErrExpiredToken
+	}
+
+	unused, err := t.store.MarkRefreshTokenUsed(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark refresh token used: %w", err)
+	}
+	if !unused {
+		// Reuse of a rotated token means it leaked; end the session for everyone holding it
+		if err := t.store.RevokeFamily(ctx, stored.FamilyID); err != nil {
+			return nil, fmt.Errorf("failed to revoke session: %w", err)
+		}
+		return nil, ErrRevokedToken
+	}
+
+	return t.issue(ctx, SessionUser{UserID: stored.UserID, UserType: stored.UserType}, stored.FamilyID)
+}
+
+// Revoke ends the session of the refresh token and, if given, the current access token.
+// A refresh token of another user than the claims' is rejected with ErrInvalidToken.
+func (t *TokenIssuer) Revoke(ctx context.Context, refreshToken string, claims *Claims) error {
+	if refreshToken != "" {
+		stored, err := t.store.GetRefreshToken(ctx, hashToken(refreshToken))
+		if err != nil {
+			return fmt.Errorf("failed to get refresh token: %w", err)
+		}
+		if stored != nil && claims != nil && stored.UserID != claims.UserID {
+			return ErrInvalidToken
+		}
+		if stored != nil {
+			if err := t.store.RevokeFamily(ctx, stored.FamilyID); err != nil {
+				return fmt.Errorf("failed to revoke session: %w", err)
+			}
+		}
+	}
+
+	if claims != nil {
+		if err := t.store.RevokeAccessToken(ctx, claims.ID, claims.Expiry()); err != nil {
+			return fmt.Errorf("failed to revoke access token: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Validate verifies an access token locally and returns its claims
+func (t *TokenIssuer) Validate(ctx context.Context, token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != jwtHeader {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, t.sign(parts[0]+"."+parts[1])) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if claims.Issuer != t.config.Issuer {
+		return nil, ErrInvalidToken
+	}
+	if !t.now().Before(claims.Expiry()) {
+		return nil, ErrExpiredToken
+	}
+
+	revoked, err := t.store.IsAccessTokenRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	if revoked {
+		return nil, ErrRevokedToken
+	}
+
+	return &claims, nil
+}
+
+// issue creates an access token and a stored refresh token within a session family
+func (t *TokenIssuer) issue(ctx context.Context, user SessionUser, familyID string) (*TokenPair, error) {
+	now := t.now()
+
+	claims := Claims{
+		Subject:   strconv.FormatInt(user.UserID, 10),
+		UserID:    user.UserID,
+		UserType:  user.UserType,
+		Issuer:    t.config.Issuer,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(t.config.AccessTTL).Unix(),
+		ID:        uuid.New().String(),
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal claims: %w", err)
+	}
+
+	signingInput := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	accessToken := signingInput + "." + base64.RawURLEncoding.EncodeToString(t.sign(signingInput))
+
+	refreshToken, err := randomToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	stored := &RefreshToken{
+		Hash:      hashToken(refreshToken),
+		FamilyID:  familyID,
+		UserID:    user.UserID,
+		UserType:  user.UserType,
+		ExpiresAt: now.Add(t.config.RefreshTTL),
+	}
+	if err := t.store.SaveRefreshToken(ctx, stored); err != nil {
+		return nil, fmt.Errorf("failed to save refresh token: %w", err)
+	}
+
+	return &TokenPair{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        claims.Expiry(),
+		RefreshExpiresAt: stored.ExpiresAt,
+	}, nil
+}
+
+// sign returns the HMAC-SHA256 signature of the input
+func (t *TokenIssuer) sign(input string) []byte {
+	mac := hmac.New(sha256.New, t.config.SigningKey)
+	mac.Write([]byte(input))
+	return mac.Sum(nil)
+}
+
+// GenerateSigningKey returns a random signing key for when none is configured.
+// Tokens signed with it do not survive a restart.
+func GenerateSigningKey() ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	return key, nil
+}
+
+// randomToken returns 32 random bytes encoded for use in URLs and headers
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hex SHA-256 hash of a token, so raw tokens are never stored
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
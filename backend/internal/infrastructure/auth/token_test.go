@@ -0,0 +1,297 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testClock is a settable clock for the issuer
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func newTestIssuer(t *testing.T) (*TokenIssuer, *testClock) {
+	t.Helper()
+	issuer, err := NewTokenIssuer(TokenConfig{
+		SigningKey: []byte(strings.Repeat("k", 32)),
+		Issuer:     "vouchers-test",
+		AccessTTL:  15 * time.Minute,
+		RefreshTTL: 24 * time.Hour,
+	}, NewMemorySessionStore())
+	if err != nil {
+		t.Fatalf("NewTokenIssuer() unexpected error: %v", err)
+	}
+	clock := &testClock{now: time.Now().Truncate(time.Second)}
+	issuer.now = clock.Now
+	return issuer, clock
+}
+
+func TestNewTokenIssuerRejectsShortKey(t *testing.T) {
+	if _, err := NewTokenIssuer(TokenConfig{SigningKey: []byte("short")}, NewMemorySessionStore()); err == nil {
+		t.Fatal("NewTokenIssuer() with a short key succeeded, want error")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	ctx := context.Background()
+	user := SessionUser{UserID: 42, UserType: "merchant"}
+
+	tests := []struct {
+		name    string
+		token   func(t *testing.T, issuer *TokenIssuer, clock *testClock) string
+		wantErr error
+	}{
+		{
+			name: "valid token",
+			token: func(t *testing.T, issuer *TokenIssuer, clock *testClock) string {
+				return issue(t, issuer, user).AccessToken
+			},
+		},
+		{
+			name: "expired token",
+			token: func(t *testing.T, issuer *TokenIssuer, clock *testClock) string {
+				token := issue(t, issuer, user).AccessToken
+				clock.now = clock.now.Add(15 * time.Minute)
+				return token
+			},
+			wantErr: ErrExpiredToken,
+		},
+		{
+			name: "tampered payload",
+			token: func(t *testing.T, issuer *TokenIssuer, clock *testClock) string {
+				parts := strings.Split(issue(t, issuer, user).AccessToken, ".")
+				parts[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"1","uid":1,"iss":"vouchers-test","exp":9999999999}`))
+				return strings.Join(parts, ".")
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "signed with another key",
+			token: func(t *testing.T, issuer *TokenIssuer, clock *testClock) string {
+				other, _ := newTestIssuer(t)
+				other.config.SigningKey = []byte(strings.Repeat("x", 32))
+				return issue(t, other, user).AccessToken
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "other issuer",
+			token: func(t *testing.T, issuer *TokenIssuer, clock *testClock) string {
+				other, _ := newTestIssuer(t)
+				other.config.Issuer = "someone-else"
+				return issue(t, other, user).AccessToken
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "other algorithm header",
+			token: func(t *testing.T, issuer *TokenIssuer, clock *testClock) string {
+				parts := strings.Split(issue(t, issuer, user).AccessToken, ".")
+				parts[0] = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+				return strings.Join(parts, ".")
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "malformed token",
+			token: func(t *testing.T, issuer *TokenIssuer, clock *testClock) string {
+				return "not-a-token"
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "revoked token",
+			token: func(t *testing.T, issuer *TokenIssuer, clock *testClock) string {
+				token := issue(t, issuer, user).AccessToken
+				claims, err := issuer.Validate(ctx, token)
+				if err != nil {
+					t.Fatalf("Validate() unexpected error: %v", err)
+				}
+				if err := issuer.Revoke(ctx, "", claims); err != nil {
+					t.Fatalf("Revoke() unexpected error: %v", err)
+				}
+				return token
+			},
+			wantErr: ErrRevokedToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer, clock := newTestIssuer(t)
+			claims, err := issuer.Validate(ctx, tt.token(t, issuer, clock))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (claims.UserID != user.UserID || claims.UserType != user.UserType || claims.Subject != "42") {
+				t.Errorf("Validate() = %+v, want the claims of %+v", claims, user)
+			}
+		})
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	ctx := context.Background()
+	user := SessionUser{UserID: 42, UserType: "buyer"}
+
+	tests := []struct {
+		name string
+		// prepare returns the refresh token to present
+		prepare func(t *testing.T, issuer *TokenIssuer, clock *testClock) string
+		wantErr error
+	}{
+		{
+			name: "rotates an unused token",
+			prepare: func(t *testing.T, issuer *TokenIssuer, clock *testClock) string {
+				return issue(t, issuer, user).RefreshToken
+			},
+		},
+		{
+			name: "rotated token can be refreshed again",
+			prepare: func(t *testing.T, issuer *TokenIssuer, clock *testClock) string {
+				return refresh(t, issuer, issue(t, issuer, user).RefreshToken).RefreshToken
+			},
+		},
+		{
+			name: "unknown token",
+			prepare: func(t *testing.T, issuer *TokenIssuer, clock *testClock) string {
+				return "unknown"
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "expired token",
+			prepare: func(t *testing.T, issuer *TokenIssuer, clock *testClock) string {
+				token := issue(t, issuer, user).RefreshToken
+				clock.now = clock.now.Add(24*time.Hour + time.Second)
+				return token
+			},
+			wantErr: ErrExpiredToken,
+		},
+		{
+			name: "reused token",
+			prepare: func(t *testing.T, issuer *TokenIssuer, clock *testClock) string {
+				token := issue(t, issuer, user).RefreshToken
+				refresh(t, issuer, token)
+				return token
+			},
+			wantErr: ErrRevokedToken,
+		},
+		{
+			name: "revoked session",
+			prepare: func(t *testing.T, issuer *TokenIssuer, clock *testClock) string {
+				token := issue(t, issuer, user).RefreshToken
+				if err := issuer.Revoke(ctx, token, nil); err != nil {
+					t.Fatalf("Revoke() unexpected error: %v", err)
+				}
+				return token
+			},
+			wantErr: ErrRevokedToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer, clock := newTestIssuer(t)
+			token := tt.prepare(t, issuer, clock)
+
+			pair, err := issuer.Refresh(ctx, token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Refresh() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if pair.RefreshToken == token {
+				t.Errorf("Refresh() returned the presented refresh token, want a new one")
+			}
+			claims, err := issuer.Validate(ctx, pair.AccessToken)
+			if err != nil {
+				t.Fatalf("Validate() of the refreshed access token unexpected error: %v", err)
+			}
+			if claims.UserID != user.UserID || claims.UserType != user.UserType {
+				t.Errorf("refreshed claims = %+v, want the claims of %+v", claims, user)
+			}
+		})
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	ctx := context.Background()
+	owner := SessionUser{UserID: 42, UserType: "merchant"}
+
+	tests := []struct {
+		name    string
+		caller  SessionUser
+		wantErr error
+	}{
+		{name: "own session", caller: owner, wantErr: nil},
+		{name: "another user's session", caller: SessionUser{UserID: 7}, wantErr: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer, _ := newTestIssuer(t)
+			refreshToken := issue(t, issuer, owner).RefreshToken
+			claims, err := issuer.Validate(ctx, issue(t, issuer, tt.caller).AccessToken)
+			if err != nil {
+				t.Fatalf("Validate() unexpected error: %v", err)
+			}
+
+			if err := issuer.Revoke(ctx, refreshToken, claims); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Revoke() error = %v, want %v", err, tt.wantErr)
+			}
+
+			// The owner's session ends only when the owner revokes it
+			wantRefreshErr := ErrRevokedToken
+			if tt.wantErr != nil {
+				wantRefreshErr = nil
+			}
+			if _, err := issuer.Refresh(ctx, refreshToken); !errors.Is(err, wantRefreshErr) {
+				t.Errorf("Refresh() of the owner's token error = %v, want %v", err, wantRefreshErr)
+			}
+		})
+	}
+}
+
+func TestRefreshReuseRevokesRotatedTokens(t *testing.T) {
+	ctx := context.Background()
+	issuer, _ := newTestIssuer(t)
+
+	first := issue(t, issuer, SessionUser{UserID: 42}).RefreshToken
+	second := refresh(t, issuer, first).RefreshToken
+
+	if _, err := issuer.Refresh(ctx, first); !errors.Is(err, ErrRevokedToken) {
+		t.Fatalf("Refresh() of a reused token error = %v, want %v", err, ErrRevokedToken)
+	}
+	if _, err := issuer.Refresh(ctx, second); !errors.Is(err, ErrRevokedToken) {
+		t.Errorf("Refresh() of the token rotated from a reused one error = %v, want %v", err, ErrRevokedToken)
+	}
+}
+
+func issue(t *testing.T, issuer *TokenIssuer, user SessionUser) *TokenPair {
+	t.Helper()
+	pair, err := issuer.Issue(context.Background(), user)
+	if err != nil {
+		t.Fatalf("Issue() unexpected error: %v", err)
+	}
+	return pair
+}
+
+func refresh(t *testing.T, issuer *TokenIssuer, refreshToken string) *TokenPair {
+	t.Helper()
+	pair, err := issuer.Refresh(context.Background(), refreshToken)
+	if err != nil {
+		t.Fatalf("Refresh() unexpected error: %v", err)
+	}
+	return pair
+}
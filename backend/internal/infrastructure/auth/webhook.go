@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// WebhookSignatureHeader carries the signature of a webhook body: "sha256=" followed by the
+// hex HMAC-SHA256 of the raw body under the secret shared with the marketplace
+const WebhookSignatureHeader = "X-Webhook-Signature"
+
+// webhookSignaturePrefix names the algorithm of a webhook signature
+const webhookSignaturePrefix = "sha256="
+
+// SignWebhook returns the signature of body under secret, as sent in WebhookSignatureHeader
+func SignWebhook(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return webhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook reports whether signature is the signature of body under secret. Nothing
+// verifies without a secret, so signed webhooks stay closed until one is configured.
+func VerifyWebhook(secret, body []byte, signature string) bool {
+	if len(secret) == 0 || !strings.HasPrefix(signature, webhookSignaturePrefix) {
+		return false
+	}
+	return hmac.Equal([]byte(SignWebhook(secret, body)), []byte(signature))
+}
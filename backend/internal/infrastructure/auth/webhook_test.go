@@ -0,0 +1,32 @@
+package auth
+
+import "testing"
+
+func TestVerifyWebhook(t *testing.T) {
+	secret := []byte("webhook-secret")
+	body := []byte(`{"merchant_id":42}`)
+	signature := SignWebhook(secret, body)
+
+	tests := []struct {
+		name      string
+		secret    []byte
+		body      []byte
+		signature string
+		want      bool
+	}{
+		{name: "valid signature", secret: secret, body: body, signature: signature, want: true},
+		{name: "changed body", secret: secret, body: []byte(`{"merchant_id":43}`), signature: signature},
+		{name: "other secret", secret: []byte("other-secret"), body: body, signature: signature},
+		{name: "no secret configured", secret: nil, body: body, signature: SignWebhook(nil, body)},
+		{name: "missing signature", secret: secret, body: body, signature: ""},
+		{name: "signature without prefix", secret: secret, body: body, signature: signature[len("sha256="):]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyWebhook(tt.secret, tt.body, tt.signature); got != tt.want {
+				t.Errorf("VerifyWebhook() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
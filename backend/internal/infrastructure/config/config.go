@@ -3,14 +3,30 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 // Config holds all configuration values
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
+	Database  DatabaseConfig
+	Server    ServerConfig
+	Auth      AuthConfig
+	Listing   ListingConfig
+	Rates     RatesConfig
+	Analytics AnalyticsConfig
+	Export    ExportConfig
+	Import    ImportConfig
+	CodePool  CodePoolConfig
+	Transfer  TransferConfig
+	Checkout  CheckoutConfig
+	Fraud     FraudConfig
+	Audit     AuditConfig
+	Notify    NotifyConfig
+	Realtime  RealtimeConfig
+	Webhook   WebhookConfig
 }
 
 // DatabaseConfig holds database configuration
@@ -29,6 +45,123 @@ type ServerConfig struct {
 	Host string
 }
 
+// AuthConfig holds session token configuration
+type AuthConfig struct {
+	SigningKey string
+	Issuer     string
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+// ListingConfig holds configuration for 4Sale listing events
+type ListingConfig struct {
+	// DeletionRefundPolicy is "none" or "refund_unredeemed"
+	DeletionRefundPolicy string
+}
+
+// RatesConfig holds exchange-rate configuration
+type RatesConfig struct {
+	// File is the JSON file the static rate provider reads
+	File string
+	// ReportingCurrency is the currency purchases are reported in
+	ReportingCurrency string
+}
+
+// AnalyticsConfig holds merchant analytics configuration
+type AnalyticsConfig struct {
+	// RollupEnabled serves daily sales from the rollup table refreshed every RollupInterval
+	RollupEnabled  bool
+	RollupInterval time.Duration
+}
+
+// ExportConfig holds purchase export configuration
+type ExportConfig struct {
+	// StaffUserIDs are the finance and support users allowed to export every merchant
+	StaffUserIDs []int64
+}
+
+// ImportConfig holds bulk voucher import configuration
+type ImportConfig struct {
+	// BatchSize is the number of vouchers stored per transaction
+	BatchSize int
+}
+
+// CodePoolConfig holds merchant code pool configuration
+type CodePoolConfig struct {
+	// LowThreshold is the number of unassigned codes at which the merchant is alerted
+	LowThreshold int
+}
+
+// TransferConfig holds voucher gift transfer configuration
+type TransferConfig struct {
+	// TTL is how long a recipient has to accept a transfer
+	TTL time.Duration
+}
+
+// CheckoutConfig holds checkout reservation configuration
+type CheckoutConfig struct {
+	// HoldTTL is how long a reservation holds its voucher for the buyer
+	HoldTTL time.Duration
+	// ReleaseInterval is how often expired holds are released
+	ReleaseInterval time.Duration
+}
+
+// AuditConfig holds audit log configuration
+type AuditConfig struct {
+	// StaffUserIDs are the support users allowed to search the audit log
+	StaffUserIDs []int64
+}
+
+// NotifyConfig holds notification delivery configuration. Channels without a gateway
+// or SMTP host configured are written to the notification log instead.
+type NotifyConfig struct {
+	PushURL      string
+	PushAPIKey   string
+	SMSURL       string
+	SMSAPIKey    string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	EmailFrom    string
+	// LogFile receives logged notifications; they go to the application log when empty
+	LogFile string
+	// ReminderLead is how long before a voucher expires its buyer is reminded
+	ReminderLead time.Duration
+	// ReminderInterval is how often due expiry reminders are sent
+	ReminderInterval time.Duration
+}
+
+// RealtimeConfig holds real-time event stream configuration
+type RealtimeConfig struct {
+	// Heartbeat is how often idle streams send a comment to keep connections open
+	Heartbeat time.Duration
+	// HistorySize and Retention bound the events each topic keeps for reconnecting clients
+	HistorySize int
+	Retention   time.Duration
+}
+
+// WebhookConfig holds the configuration of signed webhooks
+type WebhookConfig struct {
+	// SigningSecret is shared with the marketplace to sign the webhooks that move money or
+	// create promo codes; those webhooks are refused while it is empty
+	SigningSecret string
+}
+
+// FraudConfig holds the fraud rule thresholds and the action each rule takes:
+// allow (disabled), flag, delay or block
+type FraudConfig struct {
+	PurchaseVelocityMax    int
+	PurchaseVelocityWindow time.Duration
+	PurchaseVelocityAction string
+	SelfRedemptionAction   string
+	QuickRedemptionWindow  time.Duration
+	QuickRedemptionAction  string
+	FailedLookupsMax       int
+	FailedLookupsWindow    time.Duration
+	FailedLookupsAction    string
+}
+
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
 	// Load .env file if it exists (optional)
@@ -47,6 +180,75 @@ func Load() (*Config, error) {
 			Port: getEnv("PORT", "5000"),
 			Host: getEnv("HOST", "0.0.0.0"),
 		},
+		Auth: AuthConfig{
+			SigningKey: getEnv("SESSION_SIGNING_KEY", ""),
+			Issuer:     getEnv("SESSION_ISSUER", "voucher-system"),
+			AccessTTL:  getEnvAsDuration("SESSION_ACCESS_TTL", 15*time.Minute),
+			RefreshTTL: getEnvAsDuration("SESSION_REFRESH_TTL", 30*24*time.Hour),
+		},
+		Listing: ListingConfig{
+			DeletionRefundPolicy: getEnv("LISTING_DELETION_REFUND_POLICY", "none"),
+		},
+		Rates: RatesConfig{
+			File:              getEnv("EXCHANGE_RATES_FILE", "configs/exchange_rates.json"),
+			ReportingCurrency: getEnv("REPORTING_CURRENCY", "KWD"),
+		},
+		Analytics: AnalyticsConfig{
+			RollupEnabled:  getEnvAsBool("ANALYTICS_ROLLUP_ENABLED", false),
+			RollupInterval: getEnvAsDuration("ANALYTICS_ROLLUP_INTERVAL", 15*time.Minute),
+		},
+		Export: ExportConfig{
+			StaffUserIDs: getEnvAsInt64List("EXPORT_STAFF_USER_IDS"),
+		},
+		Import: ImportConfig{
+			BatchSize: getEnvAsInt("IMPORT_BATCH_SIZE", 100),
+		},
+		CodePool: CodePoolConfig{
+			LowThreshold: getEnvAsInt("CODE_POOL_LOW_THRESHOLD", 10),
+		},
+		Transfer: TransferConfig{
+			TTL: getEnvAsDuration("TRANSFER_TTL", 72*time.Hour),
+		},
+		Checkout: CheckoutConfig{
+			HoldTTL:         getEnvAsDuration("RESERVATION_HOLD_TTL", 10*time.Minute),
+			ReleaseInterval: getEnvAsDuration("RESERVATION_RELEASE_INTERVAL", time.Minute),
+		},
+		Fraud: FraudConfig{
+			PurchaseVelocityMax:    getEnvAsInt("FRAUD_PURCHASE_VELOCITY_MAX", 5),
+			PurchaseVelocityWindow: getEnvAsDuration("FRAUD_PURCHASE_VELOCITY_WINDOW", 10*time.Minute),
+			PurchaseVelocityAction: getEnv("FRAUD_PURCHASE_VELOCITY_ACTION", "delay"),
+			SelfRedemptionAction:   getEnv("FRAUD_SELF_REDEMPTION_ACTION", "block"),
+			QuickRedemptionWindow:  getEnvAsDuration("FRAUD_QUICK_REDEMPTION_WINDOW", 30*time.Second),
+			QuickRedemptionAction:  getEnv("FRAUD_QUICK_REDEMPTION_ACTION", "flag"),
+			FailedLookupsMax:       getEnvAsInt("FRAUD_FAILED_LOOKUPS_MAX", 10),
+			FailedLookupsWindow:    getEnvAsDuration("FRAUD_FAILED_LOOKUPS_WINDOW", 15*time.Minute),
+			FailedLookupsAction:    getEnv("FRAUD_FAILED_LOOKUPS_ACTION", "block"),
+		},
+		Audit: AuditConfig{
+			StaffUserIDs: getEnvAsInt64List("AUDIT_STAFF_USER_IDS"),
+		},
+		Notify: NotifyConfig{
+			PushURL:          getEnv("NOTIFY_PUSH_URL", ""),
+			PushAPIKey:       getEnv("NOTIFY_PUSH_API_KEY", ""),
+			SMSURL:           getEnv("NOTIFY_SMS_URL", ""),
+			SMSAPIKey:        getEnv("NOTIFY_SMS_API_KEY", ""),
+			SMTPHost:         getEnv("NOTIFY_SMTP_HOST", ""),
+			SMTPPort:         getEnv("NOTIFY_SMTP_PORT", "587"),
+			SMTPUsername:     getEnv("NOTIFY_SMTP_USERNAME", ""),
+			SMTPPassword:     getEnv("NOTIFY_SMTP_PASSWORD", ""),
+			EmailFrom:        getEnv("NOTIFY_EMAIL_FROM", "vouchers@4sale.example"),
+			LogFile:          getEnv("NOTIFY_LOG_FILE", ""),
+			ReminderLead:     getEnvAsDuration("NOTIFY_EXPIRY_REMINDER_LEAD", 72*time.Hour),
+			ReminderInterval: getEnvAsDuration("NOTIFY_EXPIRY_REMINDER_INTERVAL", time.Hour),
+		},
+		Realtime: RealtimeConfig{
+			Heartbeat:   getEnvAsDuration("REALTIME_HEARTBEAT_INTERVAL", 15*time.Second),
+			HistorySize: getEnvAsInt("REALTIME_HISTORY_SIZE", 100),
+			Retention:   getEnvAsDuration("REALTIME_RETENTION", 10*time.Minute),
+		},
+		Webhook: WebhookConfig{
+			SigningSecret: getEnv("WEBHOOK_SIGNING_SECRET", ""),
+		},
 	}
 
 	return config, nil
@@ -68,4 +270,35 @@ func getEnvAsInt(key string, defaultValue int) int {
 		}
 	}
 	return defaultValue
+}
+
+// getEnvAsBool gets an environment variable as boolean with a default value
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsInt64List gets a comma-separated environment variable as integers, skipping invalid entries
+func getEnvAsInt64List(key string) []int64 {
+	var values []int64
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if intValue, err := strconv.ParseInt(strings.TrimSpace(item), 10, 64); err == nil {
+			values = append(values, intValue)
+		}
+	}
+	return values
+}
+
+// getEnvAsDuration gets an environment variable as duration with a default value
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
 }
\ No newline at end of file
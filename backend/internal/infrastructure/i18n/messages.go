@@ -0,0 +1,121 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+
+	"4SaleBackendSkeleton/internal/domain"
+)
+
+// arabicMessages translates the English error messages written by the handlers
+var arabicMessages = map[string]string{
+	"An unexpected error occurred":                     "حدث خطأ غير متوقع",
+	"Internal server error":                            "خطأ داخلي في الخادم",
+	"Authentication service unavailable":               "خدمة المصادقة غير متاحة",
+	"Authorization token required":                     "رمز التفويض مطلوب",
+	"Access not allowed":                               "غير مسموح بالوصول",
+	"Access to this merchant is not allowed":           "غير مسموح بالوصول إلى بيانات هذا التاجر",
+	"Access to these vouchers is not allowed":          "غير مسموح بالوصول إلى هذه القسائم",
+	"Invalid or expired token":                         "الرمز غير صالح أو منتهي الصلاحية",
+	"Invalid webhook signature":                        "توقيع الطلب غير صالح",
+	"Invalid or expired refresh token":                 "رمز التحديث غير صالح أو منتهي الصلاحية",
+	"Refresh token is required":                        "رمز التحديث مطلوب",
+	"Invalid credentials":                              "بيانات الدخول غير صحيحة",
+	"Too many login attempts, please try again later":  "محاولات تسجيل دخول كثيرة، يرجى المحاولة لاحقاً",
+	"Failed to log out":                                "تعذر تسجيل الخروج",
+	"Method not allowed":                               "الطريقة غير مسموح بها",
+	"Invalid request body":                             "محتوى الطلب غير صالح",
+	"Request validation failed":                        "فشل التحقق من صحة الطلب",
+	"Invalid pagination cursor":                        "مؤشر الصفحات غير صالح",
+	"Invalid user ID":                                  "معرف المستخدم غير صالح",
+	"user_id is required":                              "معرف المستخدم مطلوب",
+	"Failed to create voucher":                         "تعذر إنشاء القسيمة",
+	"Failed to purchase voucher":                       "تعذر شراء القسيمة",
+	"Failed to redeem voucher":                         "تعذر استخدام القسيمة",
+	"Failed to get vouchers":                           "تعذر جلب القسائم",
+	"Voucher not found":                                "القسيمة غير موجودة",
+	"Voucher purchase not found":                       "عملية شراء القسيمة غير موجودة",
+	"Voucher already purchased":                        "تم شراء القسيمة مسبقاً",
+	"Voucher already redeemed":                         "تم استخدام القسيمة مسبقاً",
+	"Voucher purchase was refunded":                    "تم استرداد مبلغ شراء القسيمة",
+	"QR code is no longer valid for this voucher":      "رمز QR لم يعد صالحاً لهذه القسيمة",
+	"Voucher has expired":                              "انتهت صلاحية القسيمة",
+	"Voucher is not available for sale":                "القسيمة غير متاحة للبيع",
+	"Voucher cannot be changed after it has been sold": "لا يمكن تعديل القسيمة بعد بيعها",
+	"Voucher was modified by another request":          "تم تعديل القسيمة بواسطة طلب آخر",
+	"Exchange rate unavailable":                        "سعر الصرف غير متاح",
+	"Ledger transaction not found":                     "القيد المحاسبي غير موجود",
+	"Ledger transaction already reversed":              "تم عكس القيد المحاسبي مسبقاً",
+	"Purchase must be refunded before reversal":        "يجب استرداد عملية الشراء قبل عكس القيد",
+	"Payouts cannot be reversed":                       "لا يمكن عكس قيود صرف المستحقات",
+	"Voucher code pool is empty":                       "نفدت أكواد هذه القسيمة",
+	"Voucher code was taken, try again":                "تم استخدام كود القسيمة، حاول مرة أخرى",
+	"Voucher transfer not found":                       "عملية تحويل القسيمة غير موجودة",
+	"Voucher transfer is no longer pending":            "لم تعد عملية تحويل القسيمة بانتظار القبول",
+	"Voucher already has a pending transfer":           "توجد عملية تحويل معلقة لهذه القسيمة",
+	"Merchant code vouchers cannot be transferred":     "لا يمكن تحويل القسائم التي تحمل كود التاجر",
+	"Promo code not found":                             "رمز الخصم غير موجود",
+	"Promo code does not apply to this purchase":       "رمز الخصم لا ينطبق على عملية الشراء هذه",
+	"Promo code usage limit reached":                   "تم بلوغ الحد الأقصى لاستخدام رمز الخصم",
+	"Promo code already exists":                        "رمز الخصم موجود مسبقاً",
+	"Reservation not found":                            "الحجز غير موجود",
+	"Reservation is no longer held":                    "انتهى الحجز",
+	"Voucher is reserved by another buyer":             "القسيمة محجوزة لمشترٍ آخر",
+	"Operation blocked for review":                     "تم إيقاف العملية للمراجعة",
+	"Operation delayed, try again later":               "تم تأجيل العملية، حاول مرة أخرى لاحقاً",
+}
+
+// arabicFieldMessages holds the Arabic field error templates by error code.
+// Templates take the field name and the rule parameter.
+var arabicFieldMessages = map[string]string{
+	"required":  "%[1]s مطلوب",
+	"min":       "%[1]s يجب ألا يقل عن %[2]s",
+	"max":       "%[1]s يجب ألا يزيد عن %[2]s",
+	"gt":        "%[1]s يجب أن يكون أكبر من %[2]s",
+	"url":       "%[1]s يجب أن يكون رابط http أو https صالحاً",
+	"oneof":     "%[1]s يجب أن يكون إحدى القيم: %[2]s",
+	"date":      "%[1]s يجب أن يكون تاريخاً بصيغة YYYY-MM-DD",
+	"amount":    "%[1]s يجب أن يكون مبلغاً عشرياً بحد أقصى %[2]s منازل عشرية",
+	"range":     "%[1]s يجب ألا يكون أقل من %[2]s",
+	"cursor":    "%[1]s غير صالح أو صادر لترتيب مختلف",
+	"future":    "%[1]s يجب أن يكون في المستقبل",
+	"exists":    "%[1]s غير موجود",
+	"tag":       "%[1]s يجب أن يكون طول كل منها بين 1 و %[2]s حرفاً",
+	"relevance": "%[1]s حسب الصلة يتطلب عبارة بحث",
+	"code":      "%[1]s يجب أن يكون طول كل منها بين 1 و %[2]s حرفاً",
+	"phone":     "%[1]s يجب أن يكون رقم هاتف من 8 إلى 15 رقماً",
+	"exclusive": "%[1]s لا يمكن استخدامه مع %[2]s",
+	"self":      "%[1]s لا يمكن أن يكون أنت",
+	"charset":   "%[1]s يجب أن يحتوي على أحرف وأرقام وشرطات فقط",
+	"after":     "%[1]s يجب أن يكون بعد %[2]s",
+	"email":     "%[1]s يجب أن يكون عنوان بريد إلكتروني",
+}
+
+// invalidPrefix starts the messages written for malformed path and query parameters
+const invalidPrefix = "Invalid "
+
+// Message translates an English error message into lang.
+// Messages without a translation are returned unchanged.
+func Message(lang, message string) string {
+	if lang != domain.LanguageArabic {
+		return message
+	}
+	if translated, ok := arabicMessages[message]; ok {
+		return translated
+	}
+	if strings.HasPrefix(message, invalidPrefix) {
+		return "قيمة غير صالحة: " + strings.TrimPrefix(message, invalidPrefix)
+	}
+	return message
+}
+
+// FieldMessage translates the message of a field error into lang
+func FieldMessage(lang string, field domain.FieldError) string {
+	if lang != domain.LanguageArabic {
+		return field.Message
+	}
+	if template, ok := arabicFieldMessages[field.Code]; ok {
+		return fmt.Sprintf(template, field.Field, field.Param)
+	}
+	return field.Message
+}
@@ -0,0 +1,61 @@
+package i18n
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"4SaleBackendSkeleton/internal/domain"
+)
+
+// LanguageParam is the query parameter that overrides Accept-Language
+const LanguageParam = "lang"
+
+// FromRequest returns the language a response to r should be written in
+func FromRequest(r *http.Request) string {
+	return Negotiate(r.URL.Query().Get(LanguageParam), r.Header.Get("Accept-Language"))
+}
+
+// Negotiate picks a supported language. A supported lang wins, then the
+// Accept-Language entry with the highest q-value; English is the fallback.
+func Negotiate(lang, acceptLanguage string) string {
+	if base := baseLanguage(lang); domain.IsSupportedLanguage(base) {
+		return base
+	}
+
+	best, bestQuality := domain.DefaultLanguage, 0.0
+	for _, entry := range strings.Split(acceptLanguage, ",") {
+		params := strings.Split(entry, ";")
+		base := baseLanguage(params[0])
+		if !domain.IsSupportedLanguage(base) {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(param[len("q="):], 64)
+			if err != nil {
+				parsed = 0
+			}
+			quality = parsed
+		}
+
+		if quality > bestQuality {
+			best, bestQuality = base, quality
+		}
+	}
+	return best
+}
+
+// baseLanguage returns the lower-cased primary subtag of a language tag, e.g. "ar" for "ar-KW"
+func baseLanguage(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	return tag
+}
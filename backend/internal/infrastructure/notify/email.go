@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"4SaleBackendSkeleton/internal/domain"
+)
+
+// EmailNotifier delivers notifications as plain text email over SMTP
+type EmailNotifier struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewEmailNotifier creates an email notifier sending through the SMTP server at host:port.
+// Servers are authenticated with PLAIN auth when a username is given.
+func NewEmailNotifier(host, port, username, password, from string) *EmailNotifier {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &EmailNotifier{
+		addr: net.JoinHostPort(host, port),
+		auth: auth,
+		from: from,
+	}
+}
+
+// Notify emails the notification to the recipient's address
+func (n *EmailNotifier) Notify(ctx context.Context, notification *domain.Notification) error {
+	// Header values come from templates and user data, so line breaks are stripped
+	// to keep them from injecting headers
+	to := stripLineBreaks(notification.Recipient)
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", n.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", stripLineBreaks(notification.Title)))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	msg.WriteString(notification.Body)
+	msg.WriteString("\r\n")
+
+	if err := smtp.SendMail(n.addr, n.auth, n.from, []string{to}, []byte(msg.String())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// stripLineBreaks removes CR and LF from a header value
+func stripLineBreaks(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// gatewayTimeout bounds each call to a push or SMS gateway
+const gatewayTimeout = 10 * time.Second
+
+// gateway posts JSON messages to an HTTP delivery gateway authenticated with an API key
+type gateway struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+// newGateway creates a gateway client for url
+func newGateway(url, apiKey string) *gateway {
+	return &gateway{
+		url:    url,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: gatewayTimeout},
+	}
+}
+
+// post sends payload to the gateway and fails on any non-2xx response
+func (g *gateway) post(ctx context.Context, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode gateway request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build gateway request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+g.apiKey)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call gateway: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("gateway responded %d", resp.StatusCode)
+	}
+	return nil
+}
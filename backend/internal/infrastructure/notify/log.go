@@ -0,0 +1,34 @@
+package notify
+
+import (
+	"context"
+
+	"4SaleBackendSkeleton/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+// LogNotifier writes notifications to a log instead of delivering them, for local use
+// and for channels without a configured gateway
+type LogNotifier struct {
+	logger zerolog.Logger
+}
+
+// NewLogNotifier creates a log notifier writing to logger
+func NewLogNotifier(logger zerolog.Logger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+// Notify logs the notification
+func (n *LogNotifier) Notify(ctx context.Context, notification *domain.Notification) error {
+	n.logger.Info().
+		Str("channel", notification.Channel).
+		Str("kind", notification.Kind).
+		Int64("user_id", notification.UserID).
+		Str("recipient", notification.Recipient).
+		Str("language", notification.Language).
+		Str("title", notification.Title).
+		Str("body", notification.Body).
+		Str("purchase_id", notification.PurchaseID.String()).
+		Msg("Notification")
+	return nil
+}
@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"context"
+
+	"4SaleBackendSkeleton/internal/domain"
+)
+
+// pushMessage is the push gateway payload; the gateway resolves the user's devices
+type pushMessage struct {
+	UserID int64             `json:"user_id"`
+	Title  string            `json:"title"`
+	Body   string            `json:"body"`
+	Data   map[string]string `json:"data"`
+}
+
+// PushNotifier delivers notifications to the user's devices through the push gateway
+type PushNotifier struct {
+	gateway *gateway
+}
+
+// NewPushNotifier creates a push notifier posting to the gateway at url
+func NewPushNotifier(url, apiKey string) *PushNotifier {
+	return &PushNotifier{gateway: newGateway(url, apiKey)}
+}
+
+// Notify sends a push notification to every device of the user
+func (n *PushNotifier) Notify(ctx context.Context, notification *domain.Notification) error {
+	return n.gateway.post(ctx, pushMessage{
+		UserID: notification.UserID,
+		Title:  notification.Title,
+		Body:   notification.Body,
+		Data: map[string]string{
+			"kind":        notification.Kind,
+			"purchase_id": notification.PurchaseID.String(),
+		},
+	})
+}
@@ -0,0 +1,31 @@
+package notify
+
+import (
+	"context"
+
+	"4SaleBackendSkeleton/internal/domain"
+)
+
+// smsMessage is the SMS gateway payload
+type smsMessage struct {
+	To      string `json:"to"`
+	Message string `json:"message"`
+}
+
+// SMSNotifier delivers notifications as text messages through the SMS gateway
+type SMSNotifier struct {
+	gateway *gateway
+}
+
+// NewSMSNotifier creates an SMS notifier posting to the gateway at url
+func NewSMSNotifier(url, apiKey string) *SMSNotifier {
+	return &SMSNotifier{gateway: newGateway(url, apiKey)}
+}
+
+// Notify texts the notification to the recipient's phone number; SMS carries the body only
+func (n *SMSNotifier) Notify(ctx context.Context, notification *domain.Notification) error {
+	return n.gateway.post(ctx, smsMessage{
+		To:      notification.Recipient,
+		Message: notification.Body,
+	})
+}
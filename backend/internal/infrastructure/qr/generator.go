@@ -3,9 +3,7 @@ package qr
 import (
 	"encoding/base64"
 	"fmt"
-	"time"
 
-	"github.com/google/uuid"
 	"github.com/skip2/go-qrcode"
 )
 
@@ -17,19 +15,16 @@ func NewQRGenerator() *QRGenerator {
 	return &QRGenerator{}
 }
 
-// GenerateQRCode generates a unique QR code for a voucher purchase
-func (q *QRGenerator) GenerateQRCode(data string) (string, error) {
-	// Create unique QR data with timestamp and UUID
-	uniqueData := fmt.Sprintf("%s-%d-%s", data, time.Now().Unix(), uuid.New().String())
-	
+// EncodeQRCode generates a QR code holding exactly data
+func (q *QRGenerator) EncodeQRCode(data string) (string, error) {
 	// Generate QR code as PNG bytes
-	qrBytes, err := qrcode.Encode(uniqueData, qrcode.Medium, 256)
+	qrBytes, err := qrcode.Encode(data, qrcode.Medium, 256)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate QR code: %w", err)
 	}
 
 	// Encode to base64
 	qrBase64 := base64.StdEncoding.EncodeToString(qrBytes)
-	
+
 	return qrBase64, nil
 }
\ No newline at end of file
@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Lockout describes the lockout state of a rate limited key
+type Lockout struct {
+	Until   time.Time
+	Strikes int
+}
+
+// Store persists attempts and lockouts for rate limited keys.
+// The in-memory implementation is suitable for a single instance;
+// a shared implementation (e.g. Redis) can be plugged in for multiple instances.
+type Store interface {
+	// AddAttempt records an attempt at the given time, discards attempts older
+	// than the window and returns the number of attempts left in the window
+	AddAttempt(ctx context.Context, key string, at time.Time, window time.Duration) (int, error)
+	GetLockout(ctx context.Context, key string) (Lockout, error)
+	SetLockout(ctx context.Context, key string, lockout Lockout) error
+	Reset(ctx context.Context, key string) error
+}
+
+// Policy configures a sliding window limit with exponential lockout
+type Policy struct {
+	// MaxAttempts is the number of attempts allowed within Window
+	MaxAttempts int
+	Window      time.Duration
+	// BaseLockout is the first lockout duration; it doubles on every further lockout
+	BaseLockout time.Duration
+	MaxLockout  time.Duration
+	// StrikeDecay is how long after a lockout expires before its strikes are forgotten
+	StrikeDecay time.Duration
+}
+
+// Limiter enforces a Policy over a Store
+type Limiter struct {
+	store  Store
+	policy Policy
+	now    func() time.Time
+}
+
+// NewLimiter creates a new limiter
+func NewLimiter(store Store, policy Policy) *Limiter {
+	return &Limiter{
+		store:  store,
+		policy: policy,
+		now:    time.Now,
+	}
+}
+
+// Check returns how long the key is locked out for, or zero if it is allowed
+func (l *Limiter) Check(ctx context.Context, key string) (time.Duration, error) {
+	lockout, err := l.store.GetLockout(ctx, key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get lockout: %w", err)
+	}
+
+	if retryAfter := lockout.Until.Sub(l.now()); retryAfter > 0 {
+		return retryAfter, nil
+	}
+	return 0, nil
+}
+
+// Hit records an attempt for the key and locks it out once the policy is exceeded.
+// It returns the lockout duration, or zero if the key is still allowed.
+func (l *Limiter) Hit(ctx context.Context, key string) (time.Duration, error) {
+	now := l.now()
+
+	count, err := l.store.AddAttempt(ctx, key, now, l.policy.Window)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record attempt: %w", err)
+	}
+
+	if count <= l.policy.MaxAttempts {
+		return 0, nil
+	}
+
+	lockout, err := l.store.GetLockout(ctx, key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get lockout: %w", err)
+	}
+
+	// Forget old strikes once the key has behaved for long enough
+	if !lockout.Until.IsZero() && now.Sub(lockout.Until) > l.policy.StrikeDecay {
+		lockout.Strikes = 0
+	}
+
+	duration := l.lockoutDuration(lockout.Strikes)
+	lockout = Lockout{
+		Until:   now.Add(duration),
+		Strikes: lockout.Strikes + 1,
+	}
+
+	if err := l.store.SetLockout(ctx, key, lockout); err != nil {
+		return 0, fmt.Errorf("failed to set lockout: %w", err)
+	}
+
+	return duration, nil
+}
+
+// Reset clears all attempts and lockouts for the key
+func (l *Limiter) Reset(ctx context.Context, key string) error {
+	if err := l.store.Reset(ctx, key); err != nil {
+		return fmt.Errorf("failed to reset key: %w", err)
+	}
+	return nil
+}
+
+// lockoutDuration returns BaseLockout doubled once per previous strike, capped at MaxLockout
+func (l *Limiter) lockoutDuration(strikes int) time.Duration {
+	duration := l.policy.BaseLockout
+	for i := 0; i < strikes && duration < l.policy.MaxLockout; i++ {
+		duration *= 2
+	}
+	if duration > l.policy.MaxLockout {
+		duration = l.policy.MaxLockout
+	}
+	return duration
+}
@@ -0,0 +1,135 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+var testPolicy = Policy{
+	MaxAttempts: 3,
+	Window:      time.Minute,
+	BaseLockout: time.Minute,
+	MaxLockout:  4 * time.Minute,
+	StrikeDecay: 10 * time.Minute,
+}
+
+// newTestLimiter returns a limiter over a memory store whose clock is set by the returned function
+func newTestLimiter() (*Limiter, func(time.Duration)) {
+	start := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	now := start
+	limiter := NewLimiter(NewMemoryStore(time.Hour), testPolicy)
+	limiter.now = func() time.Time { return now }
+	return limiter, func(offset time.Duration) { now = start.Add(offset) }
+}
+
+func TestLimiterHit(t *testing.T) {
+	tests := []struct {
+		name string
+		// hits are the times of the attempts since the first one
+		hits []time.Duration
+		// want are the lockouts returned by each hit
+		want []time.Duration
+	}{
+		{
+			name: "attempts within the limit",
+			hits: []time.Duration{0, time.Second, 2 * time.Second},
+			want: []time.Duration{0, 0, 0},
+		},
+		{
+			name: "attempt over the limit locks out",
+			hits: []time.Duration{0, time.Second, 2 * time.Second, 3 * time.Second},
+			want: []time.Duration{0, 0, 0, time.Minute},
+		},
+		{
+			name: "attempts leave the sliding window",
+			hits: []time.Duration{0, time.Second, 2 * time.Second, 61 * time.Second},
+			want: []time.Duration{0, 0, 0, 0},
+		},
+		{
+			name: "attempt on the window boundary has left it",
+			hits: []time.Duration{0, 30 * time.Second, 31 * time.Second, time.Minute},
+			want: []time.Duration{0, 0, 0, 0},
+		},
+		{
+			name: "lockout doubles up to the maximum",
+			hits: []time.Duration{0, time.Second, 2 * time.Second, 3 * time.Second, 4 * time.Second, 5 * time.Second, 6 * time.Second},
+			want: []time.Duration{0, 0, 0, time.Minute, 2 * time.Minute, 4 * time.Minute, 4 * time.Minute},
+		},
+		{
+			name: "strikes are kept within the decay period",
+			hits: []time.Duration{0, time.Second, 2 * time.Second, 3 * time.Second, 300 * time.Second, 301 * time.Second, 302 * time.Second, 303 * time.Second},
+			want: []time.Duration{0, 0, 0, time.Minute, 0, 0, 0, 2 * time.Minute},
+		},
+		{
+			name: "strikes are forgotten after the decay period",
+			hits: []time.Duration{0, time.Second, 2 * time.Second, 3 * time.Second, 664 * time.Second, 665 * time.Second, 666 * time.Second, 667 * time.Second},
+			want: []time.Duration{0, 0, 0, time.Minute, 0, 0, 0, time.Minute},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			limiter, setClock := newTestLimiter()
+
+			for i, hit := range tt.hits {
+				setClock(hit)
+				got, err := limiter.Hit(ctx, "login:42")
+				if err != nil {
+					t.Fatalf("Hit() at %s unexpected error: %v", hit, err)
+				}
+				if got != tt.want[i] {
+					t.Errorf("Hit() at %s = %s, want %s", hit, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLimiterCheck(t *testing.T) {
+	tests := []struct {
+		name  string
+		check time.Duration
+		reset bool
+		want  time.Duration
+	}{
+		{name: "during the lockout", check: 33 * time.Second, want: 30 * time.Second},
+		{name: "when the lockout ends", check: 63 * time.Second, want: 0},
+		{name: "after a reset", check: 33 * time.Second, reset: true, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			limiter, setClock := newTestLimiter()
+
+			// The fourth attempt at 3s locks the key out until 63s
+			for i := 0; i <= testPolicy.MaxAttempts; i++ {
+				setClock(time.Duration(i) * time.Second)
+				if _, err := limiter.Hit(ctx, "login:42"); err != nil {
+					t.Fatalf("Hit() unexpected error: %v", err)
+				}
+			}
+			if tt.reset {
+				if err := limiter.Reset(ctx, "login:42"); err != nil {
+					t.Fatalf("Reset() unexpected error: %v", err)
+				}
+			}
+
+			setClock(tt.check)
+			got, err := limiter.Check(ctx, "login:42")
+			if err != nil {
+				t.Fatalf("Check() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Check() = %s, want %s", got, tt.want)
+			}
+
+			other, err := limiter.Check(ctx, "login:43")
+			if err != nil || other != 0 {
+				t.Errorf("Check() of another key = %s, %v, want it allowed", other, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryEntry holds the attempts and lockout of a single key
+type memoryEntry struct {
+	attempts   []time.Time
+	lockout    Lockout
+	lastSeenAt time.Time
+}
+
+// MemoryStore implements Store in process memory
+type MemoryStore struct {
+	mu          sync.Mutex
+	entries     map[string]*memoryEntry
+	retention   time.Duration
+	lastSweepAt time.Time
+}
+
+// NewMemoryStore creates a new in-memory store.
+// Entries untouched for longer than retention are swept away.
+func NewMemoryStore(retention time.Duration) *MemoryStore {
+	return &MemoryStore{
+		entries:   make(map[string]*memoryEntry),
+		retention: retention,
+	}
+}
+
+// AddAttempt records an attempt and returns the attempts within the window
+func (s *MemoryStore) AddAttempt(ctx context.Context, key string, at time.Time, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(at)
+
+	entry := s.entry(key)
+	entry.lastSeenAt = at
+
+	windowStart := at.Add(-window)
+	attempts := entry.attempts[:0]
+	for _, attempt := range entry.attempts {
+		if attempt.After(windowStart) {
+			attempts = append(attempts, attempt)
+		}
+	}
+	entry.attempts = append(attempts, at)
+
+	return len(entry.attempts), nil
+}
+
+// GetLockout returns the lockout state of the key
+func (s *MemoryStore) GetLockout(ctx context.Context, key string) (Lockout, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok {
+		return entry.lockout, nil
+	}
+	return Lockout{}, nil
+}
+
+// SetLockout stores the lockout state of the key
+func (s *MemoryStore) SetLockout(ctx context.Context, key string, lockout Lockout) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.entry(key)
+	entry.lockout = lockout
+	if lockout.Until.After(entry.lastSeenAt) {
+		entry.lastSeenAt = lockout.Until
+	}
+
+	return nil
+}
+
+// Reset removes the key
+func (s *MemoryStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// entry returns the entry for key, creating it if needed. Callers must hold mu.
+func (s *MemoryStore) entry(key string) *memoryEntry {
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = &memoryEntry{}
+		s.entries[key] = entry
+	}
+	return entry
+}
+
+// sweep drops stale entries at most once per retention period. Callers must hold mu.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweepAt) < s.retention {
+		return
+	}
+	s.lastSweepAt = now
+
+	for key, entry := range s.entries {
+		if now.Sub(entry.lastSeenAt) > s.retention {
+			delete(s.entries, key)
+		}
+	}
+}
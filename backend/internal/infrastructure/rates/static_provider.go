@@ -0,0 +1,81 @@
+package rates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"4SaleBackendSkeleton/internal/domain"
+)
+
+// rateFile is the format of a static exchange rate file. Rates holds the value
+// of one unit of each currency in Base, as decimal strings.
+type rateFile struct {
+	Base  string            `json:"base"`
+	AsOf  time.Time         `json:"as_of"`
+	Rates map[string]string `json:"rates"`
+}
+
+// StaticProvider serves exchange rates read once from a JSON file
+type StaticProvider struct {
+	base  string
+	asOf  time.Time
+	rates map[string]*domain.ExchangeRate
+}
+
+// NewStaticProvider loads the exchange rates in the file at path
+func NewStaticProvider(path string) (*StaticProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exchange rates: %w", err)
+	}
+
+	var file rateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse exchange rates: %w", err)
+	}
+	if !domain.IsSupportedCurrency(file.Base) {
+		return nil, fmt.Errorf("unsupported exchange rate base currency %q", file.Base)
+	}
+
+	provider := &StaticProvider{
+		base:  file.Base,
+		asOf:  file.AsOf,
+		rates: map[string]*domain.ExchangeRate{file.Base: domain.IdentityRate(file.Base, file.AsOf)},
+	}
+	for currency, value := range file.Rates {
+		if !domain.IsSupportedCurrency(currency) {
+			return nil, fmt.Errorf("unsupported exchange rate currency %q", currency)
+		}
+		rate, err := domain.ParseRate(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exchange rate %q for %s", value, currency)
+		}
+		provider.rates[currency] = &domain.ExchangeRate{From: currency, To: file.Base, Rate: rate, AsOf: file.AsOf}
+	}
+
+	return provider, nil
+}
+
+// Rate returns the value of one unit of from in to, crossing through the base currency
+func (p *StaticProvider) Rate(ctx context.Context, from, to string) (*domain.ExchangeRate, error) {
+	if from == to {
+		return domain.IdentityRate(from, p.asOf), nil
+	}
+
+	fromRate, ok := p.rates[from]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s to %s", domain.ErrRateUnavailable, from, to)
+	}
+	if to == p.base {
+		return fromRate, nil
+	}
+	toRate, ok := p.rates[to]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s to %s", domain.ErrRateUnavailable, from, to)
+	}
+
+	return domain.CrossRate(fromRate, toRate)
+}
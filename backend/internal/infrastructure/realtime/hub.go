@@ -0,0 +1,193 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"4SaleBackendSkeleton/internal/domain"
+	"github.com/google/uuid"
+)
+
+// subscriberBuffer is the number of events a subscriber can fall behind before it is dropped
+const subscriberBuffer = 64
+
+// Hub is an in-process publish/subscribe hub for real-time events. Each topic keeps its
+// recent events so reconnecting subscribers can resume from their Last-Event-ID.
+type Hub struct {
+	mu sync.Mutex
+	// epoch identifies this run of the hub; event IDs from an earlier run cannot be resumed
+	epoch  string
+	seq    uint64
+	topics map[string]*topic
+	// historySize and retention bound the events each topic keeps for replay
+	historySize int
+	retention   time.Duration
+	// closed is set once the hub stops accepting subscribers
+	closed bool
+}
+
+// topic holds the subscribers and recent events of one topic
+type topic struct {
+	subscribers map[chan *domain.Event]struct{}
+	history     []*historyEntry
+	// dropped is the sequence number of the newest event dropped from history
+	dropped uint64
+}
+
+// historyEntry is an event kept for replay with its sequence number
+type historyEntry struct {
+	seq   uint64
+	event *domain.Event
+}
+
+// NewHub creates a hub keeping up to historySize events per topic for up to retention
+func NewHub(historySize int, retention time.Duration) *Hub {
+	return &Hub{
+		epoch:       uuid.New().String()[:8],
+		topics:      make(map[string]*topic),
+		historySize: historySize,
+		retention:   retention,
+	}
+}
+
+// Publish sends an event with data encoded as JSON to the subscribers of a topic.
+// Subscribers that cannot keep up are dropped so publishing never blocks.
+func (h *Hub) Publish(topicName, eventType string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	event := &domain.Event{
+		ID:        h.epoch + "-" + strconv.FormatUint(h.seq, 10),
+		Type:      eventType,
+		Data:      payload,
+		CreatedAt: time.Now(),
+	}
+
+	t := h.topic(topicName)
+	t.history = append(t.history, &historyEntry{seq: h.seq, event: event})
+	if len(t.history) > h.historySize {
+		t.dropped = t.history[0].seq
+		t.history = t.history[1:]
+	}
+
+	for ch := range t.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delete(t.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe subscribes to a topic. With a lastEventID the events published after it are
+// replayed first; when some of them are gone the subscription is marked Reset instead.
+func (h *Hub) Subscribe(topicName, lastEventID string) *domain.Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	t := h.topic(topicName)
+	sub := &domain.Subscription{}
+	if lastEventID != "" {
+		lastSeq, ok := h.parseEventID(lastEventID)
+		switch {
+		case !ok || lastSeq < t.dropped:
+			sub.Reset = true
+		default:
+			for _, entry := range t.history {
+				if entry.seq > lastSeq {
+					sub.Replay = append(sub.Replay, entry.event)
+				}
+			}
+		}
+	}
+
+	ch := make(chan *domain.Event, subscriberBuffer)
+	sub.Events = ch
+	if h.closed {
+		close(ch)
+		sub.Close = func() {}
+		return sub
+	}
+	t.subscribers[ch] = struct{}{}
+	sub.Close = func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := t.subscribers[ch]; ok {
+			delete(t.subscribers, ch)
+			close(ch)
+		}
+	}
+
+	return sub
+}
+
+// Sweep drops events past the retention and forgets topics with nothing left to keep
+func (h *Hub) Sweep(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	cutoff := time.Now().Add(-h.retention)
+	for name, t := range h.topics {
+		kept := 0
+		for kept < len(t.history) && t.history[kept].event.CreatedAt.Before(cutoff) {
+			t.dropped = t.history[kept].seq
+			kept++
+		}
+		t.history = t.history[kept:]
+
+		if len(t.history) == 0 && len(t.subscribers) == 0 {
+			delete(h.topics, name)
+		}
+	}
+	return nil
+}
+
+// Close ends every subscription so open streams finish, and refuses new subscribers
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for _, t := range h.topics {
+		for ch := range t.subscribers {
+			delete(t.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// topic returns the named topic, creating it when needed; h.mu must be held.
+// A forgotten topic comes back with every event up to now marked dropped, so
+// subscribers resuming from before it was forgotten are reset.
+func (h *Hub) topic(name string) *topic {
+	t, ok := h.topics[name]
+	if !ok {
+		t = &topic{subscribers: make(map[chan *domain.Event]struct{}), dropped: h.seq}
+		h.topics[name] = t
+	}
+	return t
+}
+
+// parseEventID returns the sequence number of an event ID issued by this run of the hub
+func (h *Hub) parseEventID(id string) (uint64, bool) {
+	epoch, seq, found := strings.Cut(id, "-")
+	if !found || epoch != h.epoch {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil || n > h.seq {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,155 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Cell is one worksheet cell; numeric cells hold a decimal number, others are text
+type Cell struct {
+	Value   string
+	Numeric bool
+}
+
+// Text returns a text cell
+func Text(value string) Cell {
+	return Cell{Value: value}
+}
+
+// Number returns a numeric cell holding a decimal number such as "12.500"
+func Number(value string) Cell {
+	return Cell{Value: value, Numeric: true}
+}
+
+// Writer streams a single-sheet workbook row by row, so a sheet of any size is
+// written without holding its rows in memory
+type Writer struct {
+	zw    *zip.Writer
+	sheet io.Writer
+	rows  int
+}
+
+// The static parts of a workbook with one worksheet
+const (
+	contentTypesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+	rootRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	workbookXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`
+	workbookRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`
+	sheetHeaderXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	sheetFooterXML = `</sheetData></worksheet>`
+)
+
+// NewWriter writes the workbook parts to w and opens a worksheet with the given name.
+// Close must be called to complete the workbook.
+func NewWriter(w io.Writer, sheetName string) (*Writer, error) {
+	var name escaped
+	xml.EscapeText(&name, []byte(sheetName))
+
+	zw := zip.NewWriter(w)
+	parts := []struct{ path, content string }{
+		{"[Content_Types].xml", contentTypesXML},
+		{"_rels/.rels", rootRelsXML},
+		{"xl/workbook.xml", fmt.Sprintf(workbookXML, name)},
+		{"xl/_rels/workbook.xml.rels", workbookRelsXML},
+	}
+	for _, part := range parts {
+		f, err := zw.Create(part.path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", part.path, err)
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", part.path, err)
+		}
+	}
+
+	sheet, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create worksheet: %w", err)
+	}
+	if _, err := io.WriteString(sheet, sheetHeaderXML); err != nil {
+		return nil, fmt.Errorf("failed to write worksheet: %w", err)
+	}
+
+	return &Writer{zw: zw, sheet: sheet}, nil
+}
+
+// WriteRow appends a row to the worksheet; empty cells are left blank
+func (x *Writer) WriteRow(cells ...Cell) error {
+	x.rows++
+	row := strconv.Itoa(x.rows)
+
+	var buf escaped
+	buf = append(buf, `<row r="`+row+`">`...)
+	for i, cell := range cells {
+		if cell.Value == "" {
+			continue
+		}
+		ref := columnName(i) + row
+		if cell.Numeric {
+			buf = append(buf, `<c r="`+ref+`"><v>`...)
+			xml.EscapeText(&buf, []byte(cell.Value))
+			buf = append(buf, `</v></c>`...)
+			continue
+		}
+		buf = append(buf, `<c r="`+ref+`" t="inlineStr"><is><t xml:space="preserve">`...)
+		xml.EscapeText(&buf, []byte(cell.Value))
+		buf = append(buf, `</t></is></c>`...)
+	}
+	buf = append(buf, `</row>`...)
+
+	if _, err := x.sheet.Write(buf); err != nil {
+		return fmt.Errorf("failed to write row %d: %w", x.rows, err)
+	}
+	return nil
+}
+
+// Close completes the worksheet and the workbook; it does not close the underlying writer
+func (x *Writer) Close() error {
+	if _, err := io.WriteString(x.sheet, sheetFooterXML); err != nil {
+		return fmt.Errorf("failed to write worksheet: %w", err)
+	}
+	return x.zw.Close()
+}
+
+// columnName returns the spreadsheet column name of a zero-based index: A, B, ..., Z, AA, ...
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// escaped collects escaped XML text
+type escaped []byte
+
+// Write appends p
+func (e *escaped) Write(p []byte) (int, error) {
+	*e = append(*e, p...)
+	return len(p), nil
+}
+
+// String returns the collected text
+func (e escaped) String() string {
+	return string(e)
+}
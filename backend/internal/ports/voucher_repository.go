@@ -11,15 +11,147 @@ import (
 // VoucherRepository defines the interface for voucher data operations
 type VoucherRepository interface {
         CreateVoucher(ctx context.Context, voucher *domain.Voucher) error
+        // CreateVouchers stores all of the vouchers in one transaction, or none of them
+        CreateVouchers(ctx context.Context, vouchers []*domain.Voucher) error
         GetVoucherByID(ctx context.Context, id uuid.UUID) (*domain.Voucher, error)
         GetVouchersByUserID(ctx context.Context, userID int64) ([]*domain.Voucher, error)
+        GetMerchantVouchers(ctx context.Context, userID int64) ([]*domain.MerchantVoucher, error)
+        GetMerchantVoucherByID(ctx context.Context, id uuid.UUID) (*domain.MerchantVoucher, error)
+        UpdateVoucher(ctx context.Context, voucher *domain.Voucher, expectedVersion int, changes []*domain.VoucherChange) error
+        UpdateVoucherStatus(ctx context.Context, id uuid.UUID, status string, updatedAt time.Time, changes []*domain.VoucherChange) error
+        DeleteVoucher(ctx context.Context, id uuid.UUID, deletedAt time.Time, changes []*domain.VoucherChange) error
+        GetVoucherByAdvID(ctx context.Context, advID int64) (*domain.Voucher, error)
+        // ApplyVoucherChanges fails with ErrVersionConflict when the voucher was changed or
+        // deleted since it was read at expectedVersion
+        ApplyVoucherChanges(ctx context.Context, voucher *domain.Voucher, expectedVersion int, changes []*domain.VoucherChange) error
+        GetVoucherChanges(ctx context.Context, voucherID uuid.UUID) ([]*domain.VoucherChange, error)
 }
 
-// VoucherPurchaseRepository defines the interface for voucher purchase operations
+// CatalogueRepository defines the interface for searching the public voucher catalogue
+type CatalogueRepository interface {
+        SearchCatalogue(ctx context.Context, query *domain.CatalogueQuery) (*domain.CataloguePage, error)
+}
+
+// CategoryRepository defines the interface for voucher category data operations
+type CategoryRepository interface {
+        GetCategories(ctx context.Context) ([]*domain.Category, error)
+}
+
+// LedgerRepository defines the interface for settlement ledger operations
+type LedgerRepository interface {
+        PostTransaction(ctx context.Context, transaction *domain.LedgerTransaction) error
+        GetTransaction(ctx context.Context, id uuid.UUID) (*domain.LedgerTransaction, error)
+        GetTransactionsByPurchaseID(ctx context.Context, purchaseID uuid.UUID) ([]*domain.LedgerTransaction, error)
+        GetCommissionRules(ctx context.Context, merchantID int64, categoryID *int64) ([]*domain.CommissionRule, error)
+        GetStatementLines(ctx context.Context, merchantID int64, from, to time.Time) ([]*domain.StatementLine, error)
+        GetPayableMovement(ctx context.Context, merchantID int64, from, to time.Time) ([]domain.Money, error)
+        SettlePayables(ctx context.Context, merchantID int64, from, to, settledAt time.Time) ([]*domain.Settlement, error)
+}
+
+// AnalyticsRepository defines the interface for merchant sales analytics queries
+type AnalyticsRepository interface {
+        GetDailySales(ctx context.Context, merchantID int64, from, to time.Time) ([]*domain.DailySales, error)
+        GetRollupDailySales(ctx context.Context, merchantID int64, from, to time.Time) ([]*domain.DailySales, error)
+        GetTopVouchers(ctx context.Context, merchantID int64, from, to time.Time, limit int) ([]*domain.TopVoucher, error)
+        RefreshDailySales(ctx context.Context, since time.Time) (time.Time, error)
+}
+
+// CodePoolRepository defines the interface for merchant supplied voucher code pools
+type CodePoolRepository interface {
+        AddCodes(ctx context.Context, voucherID uuid.UUID, codes []string, at time.Time, change *domain.VoucherChange) (int, error)
+        // NextCode reports false when the voucher has no code pool and ErrCodePoolEmpty when it is used up
+        NextCode(ctx context.Context, voucherID uuid.UUID) (string, bool, error)
+        GetPoolCounts(ctx context.Context, voucherID uuid.UUID) (total int64, available int64, err error)
+        // GetAssignedCode reports false when the purchase was not handed a pool code
+        GetAssignedCode(ctx context.Context, purchaseID uuid.UUID) (string, bool, error)
+}
+
+// TransferRepository defines the interface for purchase transfers between users
+type TransferRepository interface {
+        // CreateTransfer stores a transfer unless the purchase already has an open one
+        CreateTransfer(ctx context.Context, transfer *domain.PurchaseTransfer) error
+        GetTransfer(ctx context.Context, id uuid.UUID) (*domain.PurchaseTransfer, error)
+        GetTransfersByPurchaseID(ctx context.Context, purchaseID uuid.UUID) ([]*domain.PurchaseTransfer, error)
+        // AcceptTransfer moves the purchase to the recipient with a new QR code, provided the transfer
+        // is still open and the purchase is still active and owned by the sender
+        AcceptTransfer(ctx context.Context, transferID uuid.UUID, recipientID int64, qrCode, qrPayload string, at time.Time) (*domain.PurchaseTransfer, error)
+        CancelTransfer(ctx context.Context, transferID uuid.UUID, at time.Time) error
+}
+
+// PromoCodeRepository defines the interface for promo codes
+type PromoCodeRepository interface {
+        CreatePromoCode(ctx context.Context, promo *domain.PromoCode) error
+        GetPromoCodeByCode(ctx context.Context, code string) (*domain.PromoCode, error)
+        CountUserRedemptions(ctx context.Context, promoCodeID, userID int64) (int64, error)
+}
+
+// ReservationRepository defines the interface for checkout holds on vouchers
+type ReservationRepository interface {
+        // CreateReservation returns the buyer's existing hold instead when they already hold the voucher,
+        // and ErrVoucherReserved when another buyer does
+        CreateReservation(ctx context.Context, reservation *domain.Reservation) (*domain.Reservation, error)
+        GetReservation(ctx context.Context, id uuid.UUID) (*domain.Reservation, error)
+        GetActiveReservation(ctx context.Context, voucherID uuid.UUID, now time.Time) (*domain.Reservation, error)
+        ResolveReservation(ctx context.Context, id uuid.UUID, status string, purchaseID *uuid.UUID, at time.Time) error
+        ReleaseExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// FraudRepository defines the interface for the history fraud rules read and the decisions they make
+type FraudRepository interface {
+        CountPurchasesSince(ctx context.Context, buyerID int64, since time.Time) (int64, error)
+        CountFailedLookupsSince(ctx context.Context, deviceID string, since time.Time) (int64, error)
+        RecordFailedLookup(ctx context.Context, deviceID string, voucherID uuid.UUID, at time.Time) error
+        CreateDecision(ctx context.Context, decision *domain.FraudDecision) error
+}
+
+// AuditRepository defines the interface for the append-only audit log
+type AuditRepository interface {
+        // AppendEvent chains event after the last one and stores it
+        AppendEvent(ctx context.Context, event *domain.AuditEvent) error
+        ListEvents(ctx context.Context, filter *domain.AuditFilter) ([]*domain.AuditEvent, error)
+}
+
+// NotificationRepository defines the interface for notification preferences and sent reminders
+type NotificationRepository interface {
+        GetPreferences(ctx context.Context, userID int64) (*domain.NotificationPreferences, error)
+        SavePreferences(ctx context.Context, prefs *domain.NotificationPreferences) error
+        // ListExpiringPurchases lists active purchases whose voucher expires in [from, to) and
+        // that have not been reminded yet, soonest first
+        ListExpiringPurchases(ctx context.Context, from, to time.Time, limit int) ([]*domain.ExpiringPurchase, error)
+        // ClaimReminder records that a purchase is being reminded, and returns false when it already was
+        ClaimReminder(ctx context.Context, purchaseID uuid.UUID, kind string, at time.Time) (bool, error)
+}
+
+// RedemptionRepository defines the interface for merchant redemption history
+type RedemptionRepository interface {
+        ListRedemptions(ctx context.Context, query *domain.RedemptionQuery) (*domain.RedemptionPage, error)
+}
+
+// ExportRepository defines the interface for streaming purchase exports
+type ExportRepository interface {
+        // StreamPurchases calls fn for each row matching filter, in time order, without buffering the result;
+        // an error from fn stops the stream and is returned
+        StreamPurchases(ctx context.Context, filter *domain.ExportFilter, fn func(*domain.PurchaseExportRow) error) error
+}
+
+// VoucherPurchaseRepository defines the interface for voucher purchase operations.
+// Status changes are stored atomically with their ledger posting; a nil posting records none.
+// A purchase's promo redemption is counted in the same transaction, within the code's limits.
 type VoucherPurchaseRepository interface {
-        CreatePurchase(ctx context.Context, purchase *domain.VoucherPurchase) error
+        // CreatePurchase fails with ErrAlreadyPurchased or ErrVoucherReserved when the voucher was sold
+        // or is held for another buyer; the reservation given, if any, is confirmed into the purchase.
+        // A voucher with a code pool is sold once per code: the pool code given must be its next
+        // unassigned one and is claimed for the purchase, or ErrCodeTaken or ErrCodePoolEmpty is returned.
+        CreatePurchase(ctx context.Context, purchase *domain.VoucherPurchase, posting *domain.LedgerTransaction, redemption *domain.PromoRedemption, reservationID *uuid.UUID, code *string) error
         GetPurchaseByVoucherID(ctx context.Context, voucherID uuid.UUID) (*domain.VoucherPurchase, error)
+        GetPurchaseByQRPayload(ctx context.Context, voucherID uuid.UUID, payload string) (*domain.VoucherPurchase, error)
+        GetPurchasesByVoucherID(ctx context.Context, voucherID uuid.UUID) ([]*domain.VoucherPurchase, error)
+        GetPurchaseByID(ctx context.Context, id uuid.UUID) (*domain.VoucherPurchase, error)
         GetPurchasesByBuyerID(ctx context.Context, buyerID int64) ([]*domain.VoucherPurchase, error)
-        UpdatePurchaseStatus(ctx context.Context, voucherID uuid.UUID, status string, redeemedAt *time.Time) error
-        GetUserVouchers(ctx context.Context, userID int64) ([]*domain.UserVoucherResponse, error)
+        // RedeemPurchase posts the release of the held amount with the redemption and fails with
+        // ErrAlreadyRedeemed or ErrPurchaseRefunded when the purchase is no longer active, and
+        // ErrQRCodeMismatch when scanned is not its current QR code
+        RedeemPurchase(ctx context.Context, redemption *domain.Redemption, scanned string) error
+        RefundPurchase(ctx context.Context, id uuid.UUID, refundedAt time.Time, posting *domain.LedgerTransaction) error
+        GetUserVouchers(ctx context.Context, query *domain.UserVoucherQuery) (*domain.UserVoucherPage, error)
 }
\ No newline at end of file
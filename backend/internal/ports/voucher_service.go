@@ -2,20 +2,157 @@ package ports
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"4SaleBackendSkeleton/internal/application/dto"
 	"4SaleBackendSkeleton/internal/domain"
+	"github.com/google/uuid"
 )
 
 // VoucherService defines the interface for voucher business logic
 type VoucherService interface {
 	CreateVoucher(ctx context.Context, req *dto.CreateVoucherRequest) (*domain.Voucher, error)
 	PurchaseVoucher(ctx context.Context, req *dto.PurchaseVoucherRequest) (*domain.VoucherPurchase, error)
+	ReserveVoucher(ctx context.Context, req *dto.ReserveVoucherRequest) (*domain.Reservation, error)
+	ConfirmReservation(ctx context.Context, req *dto.ConfirmReservationRequest) (*domain.VoucherPurchase, error)
+	ReleaseReservation(ctx context.Context, req *dto.ReleaseReservationRequest) error
 	RedeemVoucher(ctx context.Context, req *dto.RedeemVoucherRequest) error
-	GetUserVouchers(ctx context.Context, userID int64) ([]*domain.UserVoucherResponse, error)
+	UpdateVoucherFromListing(ctx context.Context, req *dto.UpdateListingRequest) (*domain.Voucher, error)
+	DeleteVoucherFromListing(ctx context.Context, req *dto.DeleteListingRequest) (*domain.VoucherWithdrawal, error)
+	GetUserVouchers(ctx context.Context, req *dto.UserVoucherListRequest) (*dto.UserVoucherListResponse, error)
+}
+
+// MerchantVoucherService defines the interface for merchants managing their own vouchers
+type MerchantVoucherService interface {
+	ListVouchers(ctx context.Context, merchantID int64) ([]*domain.MerchantVoucher, error)
+	GetVoucher(ctx context.Context, merchantID int64, voucherID uuid.UUID) (*domain.MerchantVoucher, error)
+	UpdateVoucher(ctx context.Context, merchantID int64, voucherID uuid.UUID, req *dto.UpdateVoucherRequest) (*domain.MerchantVoucher, error)
+	SetVoucherStatus(ctx context.Context, merchantID int64, voucherID uuid.UUID, status string) (*domain.MerchantVoucher, error)
+	DeleteVoucher(ctx context.Context, merchantID int64, voucherID uuid.UUID) error
+	GetVoucherHistory(ctx context.Context, merchantID int64, voucherID uuid.UUID) ([]*domain.VoucherChange, error)
+	UploadCodes(ctx context.Context, merchantID int64, voucherID uuid.UUID, req *dto.UploadVoucherCodesRequest) (*domain.CodeUploadResult, error)
+	GetCodePool(ctx context.Context, merchantID int64, voucherID uuid.UUID) (*domain.CodePoolStatus, error)
+}
+
+// CatalogueService defines the interface for browsing vouchers available to buy
+type CatalogueService interface {
+	Search(ctx context.Context, req *dto.CatalogueRequest) (*dto.CatalogueResponse, error)
+}
+
+// CategoryService defines the interface for reading the voucher category tree
+type CategoryService interface {
+	GetCategoryTree(ctx context.Context) ([]*domain.Category, error)
+}
+
+// LedgerService defines the interface for merchant payout statements and ledger corrections
+type LedgerService interface {
+	GetStatement(ctx context.Context, req *dto.StatementRequest) (*domain.PayoutStatement, error)
+	SettleStatement(ctx context.Context, req *dto.StatementRequest) (*domain.PayoutStatement, error)
+	ReverseTransaction(ctx context.Context, req *dto.ReverseLedgerTransactionRequest) (*domain.LedgerTransaction, error)
+}
+
+// AnalyticsService defines the interface for merchant sales and redemption analytics
+type AnalyticsService interface {
+	GetMerchantAnalytics(ctx context.Context, req *dto.MerchantAnalyticsRequest) (*domain.MerchantAnalytics, error)
+}
+
+// VoucherImportService defines the interface for bulk voucher imports
+type VoucherImportService interface {
+	ImportVouchers(ctx context.Context, req *dto.ImportVouchersRequest, data io.Reader) (*domain.VoucherImportReport, error)
+}
+
+// ExportService defines the interface for purchase and redemption exports
+type ExportService interface {
+	ExportPurchases(ctx context.Context, req *dto.ExportRequest, fn func(*domain.PurchaseExportRow) error) error
+}
+
+// RedemptionService defines the interface for merchant redemption history
+type RedemptionService interface {
+	ListRedemptions(ctx context.Context, req *dto.RedemptionListRequest) (*dto.RedemptionListResponse, error)
+}
+
+// PromoService defines the interface for promo code management
+type PromoService interface {
+	CreatePromoCode(ctx context.Context, req *dto.CreatePromoCodeRequest) (*domain.PromoCode, error)
+}
+
+// TransferService defines the interface for gifting purchased vouchers to other users
+type TransferService interface {
+	CreateTransfer(ctx context.Context, userID int64, purchaseID uuid.UUID, req *dto.CreateTransferRequest) (*domain.PurchaseTransfer, error)
+	AcceptTransfer(ctx context.Context, userID int64, transferID uuid.UUID, req *dto.AcceptTransferRequest) (*domain.PurchaseTransfer, error)
+	CancelTransfer(ctx context.Context, userID int64, transferID uuid.UUID) error
+	GetTransferHistory(ctx context.Context, userID int64, purchaseID uuid.UUID) ([]*domain.PurchaseTransfer, error)
 }
 
 // QRCodeGenerator defines the interface for QR code generation
 type QRCodeGenerator interface {
-	GenerateQRCode(data string) (string, error)
+	// EncodeQRCode encodes data exactly, so redemptions can check what was scanned
+	EncodeQRCode(data string) (string, error)
+}
+
+// CodePoolAlerter is told when a voucher's code pool runs low
+type CodePoolAlerter interface {
+	CodePoolLow(ctx context.Context, merchantID int64, status *domain.CodePoolStatus)
+}
+
+// FraudChecker is consulted before purchases and redemptions go ahead
+type FraudChecker interface {
+	// Check evaluates the fraud rules on an operation and records the decision
+	Check(ctx context.Context, signals *domain.FraudSignals) (*domain.FraudDecision, error)
+	// RecordFailedLookup counts a redemption from a device that matched no purchase
+	RecordFailedLookup(ctx context.Context, deviceID string, voucherID uuid.UUID, at time.Time) error
+}
+
+// AuditRecorder appends state-changing operations to the audit log
+type AuditRecorder interface {
+	// Record appends event, filling in the request it came from. Failures are logged
+	// rather than returned, since the operation has already taken place.
+	Record(ctx context.Context, event *domain.AuditEvent)
+}
+
+// AuditService defines the interface for searching the audit log
+type AuditService interface {
+	ListAuditEvents(ctx context.Context, req *dto.AuditEventListRequest) (*dto.AuditEventListResponse, error)
+}
+
+// Notifier delivers notifications on one channel
+type Notifier interface {
+	Notify(ctx context.Context, notification *domain.Notification) error
+}
+
+// PurchaseNotifier tells buyers about their purchases. Delivery happens in the background,
+// so failures are logged rather than returned.
+type PurchaseNotifier interface {
+	PurchaseConfirmed(ctx context.Context, purchase *domain.VoucherPurchase, voucher *domain.Voucher)
+	VoucherRedeemed(ctx context.Context, purchase *domain.VoucherPurchase, voucher *domain.Voucher)
+}
+
+// EventPublisher publishes real-time events to the subscribers of a topic. Publishing
+// never blocks; subscribers that cannot keep up are dropped.
+type EventPublisher interface {
+	Publish(topic, eventType string, data interface{})
+}
+
+// EventSubscriber subscribes to the real-time events of a topic
+type EventSubscriber interface {
+	// Subscribe replays the events published after lastEventID, if any, then streams live ones
+	Subscribe(topic, lastEventID string) *domain.Subscription
+}
+
+// ExpiryReminderSender reminds buyers of vouchers about to expire
+type ExpiryReminderSender interface {
+	SendExpiryReminders(ctx context.Context) error
+}
+
+// NotificationService defines the interface for notification preference operations
+type NotificationService interface {
+	GetPreferences(ctx context.Context, userID int64) (*domain.NotificationPreferences, error)
+	UpdatePreferences(ctx context.Context, userID int64, req *dto.UpdateNotificationPreferencesRequest) (*domain.NotificationPreferences, error)
+}
+
+// RateProvider defines the interface for looking up exchange rates
+type RateProvider interface {
+	// Rate returns the current value of one unit of from in to
+	Rate(ctx context.Context, from, to string) (*domain.ExchangeRate, error)
 }
\ No newline at end of file
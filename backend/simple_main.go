@@ -14,6 +14,10 @@ import (
 	"strings"
 	"time"
 
+	"4SaleBackendSkeleton/internal/adapters/handlers"
+	"4SaleBackendSkeleton/internal/application/dto"
+	"4SaleBackendSkeleton/internal/application/validation"
+	"4SaleBackendSkeleton/internal/domain"
 	_ "github.com/go-sql-driver/mysql"
 )
 
@@ -24,7 +28,7 @@ type Voucher struct {
 	UserID      int64      `json:"user_id"`
 	Title       string     `json:"title"`
 	Description *string    `json:"description"`
-	Price       float64    `json:"price"`
+	Price       domain.Money `json:"price"`
 	PhotoURL    *string    `json:"photo_url"`
 	CreatedAt   time.Time  `json:"created_at"`
 }
@@ -36,6 +40,7 @@ type VoucherPurchase struct {
 	QRCode     string     `json:"qr_code"`
 	Status     string     `json:"status"`
 	RedeemedAt *time.Time `json:"redeemed_at"`
+	Price      domain.Money `json:"price"`
 	CreatedAt  time.Time  `json:"created_at"`
 }
 
@@ -46,29 +51,20 @@ type UserVoucherResponse struct {
 	Status      string     `json:"status"`
 	PhotoURL    *string    `json:"photo_url"`
 	QRCode      *string    `json:"qr_code,omitempty"`
-	Price       float64    `json:"price"`
+	Price       domain.Money `json:"price"`
 	PurchasedAt time.Time  `json:"purchased_at"`
 	RedeemedAt  *time.Time `json:"redeemed_at"`
 }
 
 // Request DTOs
-type CreateVoucherRequest struct {
-	AdvID       int64   `json:"adv_id"`
-	UserID      int64   `json:"user_id"`
-	Title       string  `json:"title"`
-	Description *string `json:"description"`
-	Price       float64 `json:"price"`
-	Photo       *string `json:"photo"`
-}
-
 type PurchaseVoucherRequest struct {
-	VoucherID string `json:"voucher_id"`
-	BuyerID   int64  `json:"buyer_id"`
+	VoucherID string `json:"voucher_id" validate:"required"`
+	BuyerID   int64  `json:"buyer_id" validate:"required,min=1"`
 }
 
 type RedeemVoucherRequest struct {
-	VoucherID  string    `json:"voucher_id"`
-	RedeemedAt time.Time `json:"redeemed_at"`
+	VoucherID  string    `json:"voucher_id" validate:"required"`
+	RedeemedAt time.Time `json:"redeemed_at" validate:"required"`
 }
 
 // Response DTOs
@@ -76,7 +72,6 @@ type APIResponse struct {
 	Success bool        `json:"success"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
 }
 
 // Database connection
@@ -99,40 +94,45 @@ func generateQRCode(data string) string {
 // Database operations
 func createVoucher(ctx context.Context, voucher *Voucher) error {
 	query := `
-		INSERT INTO vouchers (id, adv_id, user_id, title, description, price, photo_url, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+		INSERT INTO vouchers (id, adv_id, user_id, title, description, price, currency, photo_url, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	_, err := db.ExecContext(ctx, query,
 		voucher.ID, voucher.AdvID, voucher.UserID, voucher.Title,
-		voucher.Description, voucher.Price, voucher.PhotoURL, voucher.CreatedAt)
+		voucher.Description, voucher.Price.Decimal(), voucher.Price.Currency, voucher.PhotoURL, voucher.CreatedAt)
 
 	return err
 }
 
 func getVoucherByID(ctx context.Context, id string) (*Voucher, error) {
 	query := `
-		SELECT id, adv_id, user_id, title, description, price, photo_url, created_at
+		SELECT id, adv_id, user_id, title, description, price, currency, photo_url, created_at
 		FROM vouchers WHERE id = ?`
 
 	var voucher Voucher
+	var price, currency string
 	err := db.QueryRowContext(ctx, query, id).Scan(
 		&voucher.ID, &voucher.AdvID, &voucher.UserID, &voucher.Title,
-		&voucher.Description, &voucher.Price, &voucher.PhotoURL, &voucher.CreatedAt)
+		&voucher.Description, &price, &currency, &voucher.PhotoURL, &voucher.CreatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("voucher not found")
 	}
+	if err != nil {
+		return nil, err
+	}
+	voucher.Price, err = domain.ParseMoney(price, currency)
 	return &voucher, err
 }
 
 func createPurchase(ctx context.Context, purchase *VoucherPurchase) error {
 	query := `
-		INSERT INTO voucher_purchases (id, voucher_id, buyer_id, qr_code, status, redeemed_at, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)`
+		INSERT INTO voucher_purchases (id, voucher_id, buyer_id, qr_code, status, redeemed_at, price, currency, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
 	_, err := db.ExecContext(ctx, query,
 		purchase.ID, purchase.VoucherID, purchase.BuyerID, purchase.QRCode,
-		purchase.Status, purchase.RedeemedAt, purchase.CreatedAt)
+		purchase.Status, purchase.RedeemedAt, purchase.Price.Decimal(), purchase.Price.Currency, purchase.CreatedAt)
 
 	return err
 }
@@ -171,7 +171,7 @@ func getUserVouchers(ctx context.Context, userID int64) ([]*UserVoucherResponse,
 	query := `
 		SELECT vp.id, v.title, v.description, vp.status, v.photo_url,
 			CASE WHEN vp.status = 'active' THEN vp.qr_code ELSE NULL END as qr_code,
-			v.price, vp.created_at, vp.redeemed_at
+			v.price, v.currency, vp.created_at, vp.redeemed_at
 		FROM voucher_purchases vp
 		JOIN vouchers v ON vp.voucher_id = v.id
 		WHERE vp.buyer_id = ?
@@ -186,12 +186,16 @@ func getUserVouchers(ctx context.Context, userID int64) ([]*UserVoucherResponse,
 	var vouchers []*UserVoucherResponse
 	for rows.Next() {
 		var voucher UserVoucherResponse
+		var price, currency string
 		err := rows.Scan(&voucher.ID, &voucher.Title, &voucher.Description,
 			&voucher.Status, &voucher.PhotoURL, &voucher.QRCode,
-			&voucher.Price, &voucher.PurchasedAt, &voucher.RedeemedAt)
+			&price, &currency, &voucher.PurchasedAt, &voucher.RedeemedAt)
 		if err != nil {
 			return nil, err
 		}
+		if voucher.Price, err = domain.ParseMoney(price, currency); err != nil {
+			return nil, err
+		}
 		vouchers = append(vouchers, &voucher)
 	}
 	return vouchers, nil
@@ -204,30 +208,49 @@ func writeJSONResponse(w http.ResponseWriter, status int, response APIResponse)
 	json.NewEncoder(w).Encode(response)
 }
 
+// writeErrorResponse writes the shared error envelope with an explicit code
+func writeErrorResponse(w http.ResponseWriter, r *http.Request, status int, code dto.ErrorCode, message string) {
+	handlers.WriteErrorResponse(w, r, status, dto.NewErrorResponse(code, message))
+}
+
+// parseVoucherPrice parses a webhook price in currency, or the default currency when none
+// is given; it must be greater than zero
+func parseVoucherPrice(value json.Number, currency string) (domain.Money, error) {
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+	if !domain.IsSupportedCurrency(currency) {
+		options := strings.Join(domain.SupportedCurrencies(), ",")
+		return domain.Money{}, domain.NewValidationError(domain.FieldError{Field: "currency", Code: "oneof", Message: "currency must be one of " + options, Param: options})
+	}
+	price, err := domain.ParseMoney(value.String(), currency)
+	if err != nil || !price.IsPositive() {
+		return domain.Money{}, domain.NewValidationError(domain.FieldError{Field: "price", Code: "gt", Message: "price must be a decimal amount greater than 0", Param: "0"})
+	}
+	return price, nil
+}
+
 func createVoucherHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		writeJSONResponse(w, http.StatusMethodNotAllowed, APIResponse{
-			Success: false,
-			Error:   "Method not allowed",
-		})
+		writeErrorResponse(w, r, http.StatusMethodNotAllowed, dto.ErrorCodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	var req CreateVoucherRequest
+	var req dto.CreateVoucherRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSONResponse(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   "Invalid request body",
-		})
+		writeErrorResponse(w, r, http.StatusBadRequest, dto.ErrorCodeInvalidRequest, "Invalid request body")
 		return
 	}
 
 	// Validation
-	if req.AdvID <= 0 || req.UserID <= 0 || req.Title == "" || req.Price < 0 {
-		writeJSONResponse(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   "Invalid request data",
-		})
+	if err := validation.Validate(&req); err != nil {
+		handlers.WriteError(w, r, err)
+		return
+	}
+
+	price, err := parseVoucherPrice(req.Price, req.Currency)
+	if err != nil {
+		handlers.WriteError(w, r, err)
 		return
 	}
 
@@ -237,17 +260,14 @@ func createVoucherHandler(w http.ResponseWriter, r *http.Request) {
 		UserID:      req.UserID,
 		Title:       req.Title,
 		Description: req.Description,
-		Price:       req.Price,
+		Price:       price,
 		PhotoURL:    req.Photo,
 		CreatedAt:   time.Now(),
 	}
 
 	if err := createVoucher(r.Context(), voucher); err != nil {
 		log.Printf("Failed to create voucher: %v", err)
-		writeJSONResponse(w, http.StatusInternalServerError, APIResponse{
-			Success: false,
-			Error:   "Failed to create voucher",
-		})
+		writeErrorResponse(w, r, http.StatusInternalServerError, dto.ErrorCodeInternal, "Failed to create voucher")
 		return
 	}
 
@@ -262,48 +282,33 @@ func createVoucherHandler(w http.ResponseWriter, r *http.Request) {
 
 func purchaseVoucherHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		writeJSONResponse(w, http.StatusMethodNotAllowed, APIResponse{
-			Success: false,
-			Error:   "Method not allowed",
-		})
+		writeErrorResponse(w, r, http.StatusMethodNotAllowed, dto.ErrorCodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
 	var req PurchaseVoucherRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSONResponse(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   "Invalid request body",
-		})
+		writeErrorResponse(w, r, http.StatusBadRequest, dto.ErrorCodeInvalidRequest, "Invalid request body")
 		return
 	}
 
 	// Validation
-	if req.VoucherID == "" || req.BuyerID <= 0 {
-		writeJSONResponse(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   "Invalid request data",
-		})
+	if err := validation.Validate(&req); err != nil {
+		handlers.WriteError(w, r, err)
 		return
 	}
 
 	// Check if voucher exists
-	_, err := getVoucherByID(r.Context(), req.VoucherID)
+	voucher, err := getVoucherByID(r.Context(), req.VoucherID)
 	if err != nil {
-		writeJSONResponse(w, http.StatusNotFound, APIResponse{
-			Success: false,
-			Error:   "Voucher not found",
-		})
+		handlers.WriteError(w, r, domain.ErrVoucherNotFound)
 		return
 	}
 
 	// Check if already purchased
 	_, err = getPurchaseByVoucherID(r.Context(), req.VoucherID)
 	if err == nil {
-		writeJSONResponse(w, http.StatusConflict, APIResponse{
-			Success: false,
-			Error:   "Voucher already purchased",
-		})
+		handlers.WriteError(w, r, domain.ErrAlreadyPurchased)
 		return
 	}
 
@@ -318,15 +323,13 @@ func purchaseVoucherHandler(w http.ResponseWriter, r *http.Request) {
 		QRCode:     qrCode,
 		Status:     "active",
 		RedeemedAt: nil,
+		Price:      voucher.Price,
 		CreatedAt:  time.Now(),
 	}
 
 	if err := createPurchase(r.Context(), purchase); err != nil {
 		log.Printf("Failed to create purchase: %v", err)
-		writeJSONResponse(w, http.StatusInternalServerError, APIResponse{
-			Success: false,
-			Error:   "Failed to purchase voucher",
-		})
+		writeErrorResponse(w, r, http.StatusInternalServerError, dto.ErrorCodeInternal, "Failed to purchase voucher")
 		return
 	}
 
@@ -341,47 +344,32 @@ func purchaseVoucherHandler(w http.ResponseWriter, r *http.Request) {
 
 func redeemVoucherHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		writeJSONResponse(w, http.StatusMethodNotAllowed, APIResponse{
-			Success: false,
-			Error:   "Method not allowed",
-		})
+		writeErrorResponse(w, r, http.StatusMethodNotAllowed, dto.ErrorCodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
 	var req RedeemVoucherRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeJSONResponse(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   "Invalid request body",
-		})
+		writeErrorResponse(w, r, http.StatusBadRequest, dto.ErrorCodeInvalidRequest, "Invalid request body")
 		return
 	}
 
 	// Validation
-	if req.VoucherID == "" {
-		writeJSONResponse(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   "Invalid request data",
-		})
+	if err := validation.Validate(&req); err != nil {
+		handlers.WriteError(w, r, err)
 		return
 	}
 
 	// Check if purchase exists
 	purchase, err := getPurchaseByVoucherID(r.Context(), req.VoucherID)
 	if err != nil {
-		writeJSONResponse(w, http.StatusNotFound, APIResponse{
-			Success: false,
-			Error:   "Purchase not found",
-		})
+		handlers.WriteError(w, r, domain.ErrPurchaseNotFound)
 		return
 	}
 
 	// Check if already redeemed
 	if purchase.Status == "redeemed" {
-		writeJSONResponse(w, http.StatusConflict, APIResponse{
-			Success: false,
-			Error:   "Voucher already redeemed",
-		})
+		handlers.WriteError(w, r, domain.ErrAlreadyRedeemed)
 		return
 	}
 
@@ -389,10 +377,7 @@ func redeemVoucherHandler(w http.ResponseWriter, r *http.Request) {
 	redeemedAt := req.RedeemedAt
 	if err := updatePurchaseStatus(r.Context(), req.VoucherID, "redeemed", &redeemedAt); err != nil {
 		log.Printf("Failed to redeem voucher: %v", err)
-		writeJSONResponse(w, http.StatusInternalServerError, APIResponse{
-			Success: false,
-			Error:   "Failed to redeem voucher",
-		})
+		writeErrorResponse(w, r, http.StatusInternalServerError, dto.ErrorCodeInternal, "Failed to redeem voucher")
 		return
 	}
 
@@ -406,10 +391,7 @@ func redeemVoucherHandler(w http.ResponseWriter, r *http.Request) {
 
 func getUserVouchersHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		writeJSONResponse(w, http.StatusMethodNotAllowed, APIResponse{
-			Success: false,
-			Error:   "Method not allowed",
-		})
+		writeErrorResponse(w, r, http.StatusMethodNotAllowed, dto.ErrorCodeMethodNotAllowed, "Method not allowed")
 		return
 	}
 
@@ -419,20 +401,14 @@ func getUserVouchersHandler(w http.ResponseWriter, r *http.Request) {
 
 	userID, err := strconv.ParseInt(userIDStr, 10, 64)
 	if err != nil || userID <= 0 {
-		writeJSONResponse(w, http.StatusBadRequest, APIResponse{
-			Success: false,
-			Error:   "Invalid user ID",
-		})
+		writeErrorResponse(w, r, http.StatusBadRequest, dto.ErrorCodeInvalidRequest, "Invalid user ID")
 		return
 	}
 
 	vouchers, err := getUserVouchers(r.Context(), userID)
 	if err != nil {
 		log.Printf("Failed to get user vouchers: %v", err)
-		writeJSONResponse(w, http.StatusInternalServerError, APIResponse{
-			Success: false,
-			Error:   "Failed to get vouchers",
-		})
+		writeErrorResponse(w, r, http.StatusInternalServerError, dto.ErrorCodeInternal, "Failed to get vouchers")
 		return
 	}
 
@@ -0,0 +1,12 @@
+-- Migration: 004_add_voucher_management_columns.sql
+-- Description: Add sale status, optimistic locking version and soft delete to vouchers
+-- Date: 2026-10-19
+
+ALTER TABLE vouchers
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'active' AFTER photo_url,
+    ADD COLUMN version INT NOT NULL DEFAULT 1 AFTER status,
+    ADD COLUMN updated_at TIMESTAMP NULL AFTER created_at,
+    ADD COLUMN deleted_at TIMESTAMP NULL AFTER updated_at,
+
+    -- Merchant listings filter out deleted vouchers
+    ADD INDEX idx_vouchers_user_id_deleted_at (user_id, deleted_at);
@@ -0,0 +1,22 @@
+-- Migration: 005_create_voucher_changes_table.sql
+-- Description: Record voucher changes made by listing events and track refunded purchases
+-- Date: 2026-10-19
+
+CREATE TABLE IF NOT EXISTS voucher_changes (
+    id VARCHAR(36) PRIMARY KEY,
+    voucher_id VARCHAR(36) NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    field VARCHAR(50) NOT NULL,
+    old_value TEXT NULL,
+    new_value TEXT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    -- Foreign key constraint
+    FOREIGN KEY (voucher_id) REFERENCES vouchers(id) ON DELETE CASCADE,
+
+    -- History is read per voucher in chronological order
+    INDEX idx_voucher_changes_voucher_id_changed_at (voucher_id, changed_at)
+);
+
+ALTER TABLE voucher_purchases
+    ADD COLUMN refunded_at TIMESTAMP NULL AFTER redeemed_at;
@@ -0,0 +1,12 @@
+-- Migration: 006_add_user_voucher_list_indexes.sql
+-- Description: Add voucher expiry and composite indexes for paginated user voucher lists
+-- Date: 2026-10-19
+
+ALTER TABLE vouchers
+    ADD COLUMN expires_at TIMESTAMP NULL AFTER version;
+
+-- Keyset pagination walks a buyer's purchases by (created_at, id); the status
+-- variant serves the status filters without scanning other statuses
+ALTER TABLE voucher_purchases
+    ADD INDEX idx_voucher_purchases_buyer_created_id (buyer_id, created_at, id),
+    ADD INDEX idx_voucher_purchases_buyer_status_created_id (buyer_id, status, created_at, id);
@@ -0,0 +1,13 @@
+-- Migration: 007_add_catalogue_indexes.sql
+-- Description: Add full-text and browsing indexes for the public voucher catalogue
+-- Date: 2026-10-19
+
+-- The ngram parser tokenizes by character sequences rather than by language rules,
+-- so English and Arabic titles match without a language specific stemmer
+ALTER TABLE vouchers
+    ADD FULLTEXT INDEX ft_vouchers_title_description (title, description) WITH PARSER ngram;
+
+-- Browsing lists active, non-deleted vouchers sorted by date or price
+ALTER TABLE vouchers
+    ADD INDEX idx_vouchers_catalogue_created (status, deleted_at, created_at, id),
+    ADD INDEX idx_vouchers_catalogue_price (status, deleted_at, price, id);
@@ -0,0 +1,46 @@
+-- Migration: 008_create_categories_and_tags.sql
+-- Description: Create the voucher category tree and voucher tags
+-- Date: 2026-10-19
+
+CREATE TABLE IF NOT EXISTS categories (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    parent_id BIGINT NULL,
+    slug VARCHAR(100) NOT NULL,
+    name_en VARCHAR(255) NOT NULL,
+    name_ar VARCHAR(255) NOT NULL,
+    sort_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    -- Removing a category is only allowed once it has no subcategories
+    FOREIGN KEY (parent_id) REFERENCES categories(id) ON DELETE RESTRICT,
+
+    UNIQUE INDEX idx_categories_slug (slug),
+    INDEX idx_categories_parent_id (parent_id)
+);
+
+ALTER TABLE vouchers
+    ADD COLUMN category_id BIGINT NULL AFTER photo_url,
+    ADD INDEX idx_vouchers_category_id (category_id),
+    ADD FOREIGN KEY fk_vouchers_category_id (category_id) REFERENCES categories(id) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS voucher_tags (
+    voucher_id VARCHAR(36) NOT NULL,
+    tag VARCHAR(50) NOT NULL,
+
+    PRIMARY KEY (voucher_id, tag),
+
+    -- Foreign key constraint
+    FOREIGN KEY (voucher_id) REFERENCES vouchers(id) ON DELETE CASCADE,
+
+    -- Tag filters look vouchers up by tag
+    INDEX idx_voucher_tags_tag (tag)
+);
+
+-- Top level categories
+INSERT IGNORE INTO categories (slug, name_en, name_ar, sort_order) VALUES
+    ('food-dining', 'Food & Dining', 'المطاعم والمأكولات', 10),
+    ('beauty-wellness', 'Beauty & Wellness', 'الجمال والعناية', 20),
+    ('entertainment', 'Entertainment', 'الترفيه', 30),
+    ('travel', 'Travel', 'السفر', 40),
+    ('services', 'Services', 'الخدمات', 50),
+    ('shopping', 'Shopping', 'التسوق', 60);
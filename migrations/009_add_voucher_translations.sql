@@ -0,0 +1,16 @@
+-- Migration: 009_add_voucher_translations.sql
+-- Description: Add Arabic title and description to vouchers and include them in catalogue search
+-- Date: 2026-10-19
+
+-- The original title and description columns hold the English content;
+-- NULL Arabic columns fall back to English
+ALTER TABLE vouchers
+    ADD COLUMN title_ar TEXT NULL AFTER title,
+    ADD COLUMN description_ar TEXT NULL AFTER description;
+
+-- A MATCH must list exactly the columns of one full-text index
+ALTER TABLE vouchers
+    DROP INDEX ft_vouchers_title_description;
+
+ALTER TABLE vouchers
+    ADD FULLTEXT INDEX ft_vouchers_content (title, title_ar, description, description_ar) WITH PARSER ngram;
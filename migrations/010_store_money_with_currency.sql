@@ -0,0 +1,9 @@
+-- Migration: 010_store_money_with_currency.sql
+-- Description: Store voucher prices with three decimal places and their ISO 4217 currency
+-- Date: 2026-10-19
+
+-- Kuwaiti dinar and the other Gulf three decimal currencies need fils precision;
+-- DECIMAL keeps amounts exact and the application reads them as integer minor units
+ALTER TABLE vouchers
+    MODIFY COLUMN price DECIMAL(15,3) NOT NULL,
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'KWD' AFTER price;
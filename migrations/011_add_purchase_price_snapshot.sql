@@ -0,0 +1,30 @@
+-- Migration: 011_add_purchase_price_snapshot.sql
+-- Description: Record the price paid for each purchase and its exchange-rate snapshot to the reporting currency
+-- Date: 2026-10-19
+
+ALTER TABLE voucher_purchases
+    ADD COLUMN price DECIMAL(15,3) NULL AFTER refunded_at,
+    ADD COLUMN currency CHAR(3) NULL AFTER price,
+    ADD COLUMN reporting_price DECIMAL(15,3) NULL AFTER currency,
+    ADD COLUMN reporting_currency CHAR(3) NULL AFTER reporting_price,
+    ADD COLUMN exchange_rate DECIMAL(18,8) NULL AFTER reporting_currency,
+    ADD COLUMN rate_as_of TIMESTAMP NULL AFTER exchange_rate;
+
+-- Existing purchases were paid at the voucher's current price
+UPDATE voucher_purchases vp
+JOIN vouchers v ON vp.voucher_id = v.id
+SET vp.price = v.price,
+    vp.currency = v.currency;
+
+-- Every voucher so far was priced in Kuwaiti dinar, the default reporting currency
+UPDATE voucher_purchases
+SET reporting_price = price,
+    reporting_currency = currency,
+    exchange_rate = 1,
+    rate_as_of = created_at
+WHERE currency = 'KWD';
+
+-- The snapshot stays optional for purchases recorded without a rate provider
+ALTER TABLE voucher_purchases
+    MODIFY COLUMN price DECIMAL(15,3) NOT NULL,
+    MODIFY COLUMN currency CHAR(3) NOT NULL;
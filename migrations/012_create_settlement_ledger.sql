@@ -0,0 +1,69 @@
+-- Migration: 012_create_settlement_ledger.sql
+-- Description: Create the double-entry settlement ledger, commission rules and merchant settlements
+-- Date: 2026-10-19
+
+-- A rule without merchant_id or category_id applies to every merchant or category;
+-- the most specific matching rule wins and sales matching no rule carry no commission
+CREATE TABLE IF NOT EXISTS commission_rules (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    merchant_id BIGINT NULL,
+    category_id BIGINT NULL,
+    rate_bps INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE,
+
+    UNIQUE INDEX idx_commission_rules_scope (merchant_id, category_id),
+    CHECK (rate_bps BETWEEN 0 AND 10000)
+);
+
+CREATE TABLE IF NOT EXISTS settlements (
+    id VARCHAR(36) PRIMARY KEY,
+    merchant_id BIGINT NOT NULL,
+    amount DECIMAL(15,3) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    period_from DATE NOT NULL,
+    period_to DATE NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    INDEX idx_settlements_merchant_id_created_at (merchant_id, created_at)
+);
+
+-- Transactions and entries are append-only; corrections are posted as reversals
+CREATE TABLE IF NOT EXISTS ledger_transactions (
+    id VARCHAR(36) PRIMARY KEY,
+    type VARCHAR(20) NOT NULL,
+    purchase_id VARCHAR(36) NOT NULL,
+    merchant_id BIGINT NOT NULL,
+    reversal_of VARCHAR(36) NULL,
+    posted_at TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (purchase_id) REFERENCES voucher_purchases(id),
+    FOREIGN KEY (reversal_of) REFERENCES ledger_transactions(id),
+
+    -- A transaction can only be reversed once
+    UNIQUE INDEX idx_ledger_transactions_reversal_of (reversal_of),
+    INDEX idx_ledger_transactions_purchase_id (purchase_id, posted_at)
+);
+
+-- Amounts are positive for debits and negative for credits; the entries of a
+-- transaction sum to zero in each currency
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id VARCHAR(36) PRIMARY KEY,
+    transaction_id VARCHAR(36) NOT NULL,
+    line_no INT NOT NULL,
+    account VARCHAR(32) NOT NULL,
+    merchant_id BIGINT NOT NULL,
+    amount DECIMAL(15,3) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    settlement_id VARCHAR(36) NULL,
+    posted_at TIMESTAMP NOT NULL,
+
+    FOREIGN KEY (transaction_id) REFERENCES ledger_transactions(id),
+    FOREIGN KEY (settlement_id) REFERENCES settlements(id),
+
+    UNIQUE INDEX idx_ledger_entries_transaction_line (transaction_id, line_no),
+    -- Statements read one merchant's account over a period
+    INDEX idx_ledger_entries_account_merchant_posted (account, merchant_id, posted_at),
+    INDEX idx_ledger_entries_settlement_id (settlement_id)
+);
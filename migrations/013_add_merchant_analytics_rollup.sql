@@ -0,0 +1,23 @@
+-- Migration: 013_add_merchant_analytics_rollup.sql
+-- Description: Track purchase changes and add the merchant daily sales rollup used by analytics
+-- Date: 2026-10-19
+
+-- The rollup job refreshes only the days with purchases changed since its last run
+ALTER TABLE voucher_purchases
+    ADD COLUMN updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP AFTER created_at,
+    ADD INDEX idx_voucher_purchases_updated_at (updated_at);
+
+-- One row per merchant, purchase day and currency; refunds and redemptions count
+-- towards the day the voucher was bought
+CREATE TABLE IF NOT EXISTS merchant_daily_sales (
+    merchant_id BIGINT NOT NULL,
+    day DATE NOT NULL,
+    currency CHAR(3) NOT NULL,
+    sold_count BIGINT NOT NULL DEFAULT 0,
+    refunded_count BIGINT NOT NULL DEFAULT 0,
+    redeemed_count BIGINT NOT NULL DEFAULT 0,
+    redeem_seconds BIGINT NOT NULL DEFAULT 0,
+    revenue DECIMAL(15,3) NOT NULL DEFAULT 0,
+    refreshed_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (merchant_id, day, currency)
+);
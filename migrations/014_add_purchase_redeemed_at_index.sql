@@ -0,0 +1,6 @@
+-- Migration: 014_add_purchase_redeemed_at_index.sql
+-- Description: Index redemption times for redemption exports by date range
+-- Date: 2026-10-19
+
+ALTER TABLE voucher_purchases
+    ADD INDEX idx_voucher_purchases_redeemed_at (redeemed_at);
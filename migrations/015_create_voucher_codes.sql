@@ -0,0 +1,21 @@
+-- Migration: 015_create_voucher_codes.sql
+-- Description: Create the pools of merchant supplied codes handed out on purchase
+-- Date: 2026-10-19
+
+-- A voucher with codes sells only while one is unassigned; each purchase takes the
+-- oldest unassigned code and its QR encodes that code
+CREATE TABLE IF NOT EXISTS voucher_codes (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    voucher_id VARCHAR(36) NOT NULL,
+    code VARCHAR(64) NOT NULL,
+    purchase_id VARCHAR(36) NULL,
+    assigned_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (voucher_id) REFERENCES vouchers(id) ON DELETE CASCADE,
+
+    UNIQUE INDEX idx_voucher_codes_voucher_code (voucher_id, code),
+    UNIQUE INDEX idx_voucher_codes_purchase_id (purchase_id),
+    -- Assignment picks the first unassigned code of a voucher
+    INDEX idx_voucher_codes_voucher_purchase (voucher_id, purchase_id, id)
+);
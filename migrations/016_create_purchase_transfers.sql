@@ -0,0 +1,25 @@
+-- Migration: 016_create_purchase_transfers.sql
+-- Description: Create the gift transfers of purchased vouchers between users
+-- Date: 2026-10-19
+
+-- Each row is one offer of a purchase to another user, kept after it resolves as the
+-- purchase's ownership history. A transfer to a phone number is accepted with the
+-- claim token whose SHA-256 is stored in token_hash.
+CREATE TABLE IF NOT EXISTS purchase_transfers (
+    id VARCHAR(36) PRIMARY KEY,
+    purchase_id VARCHAR(36) NOT NULL,
+    from_buyer_id BIGINT NOT NULL,
+    to_user_id BIGINT NULL,
+    to_phone VARCHAR(20) NULL,
+    token_hash CHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    accepted_by BIGINT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    resolved_at TIMESTAMP NULL,
+
+    FOREIGN KEY (purchase_id) REFERENCES voucher_purchases(id) ON DELETE CASCADE,
+
+    INDEX idx_purchase_transfers_purchase_created (purchase_id, created_at),
+    INDEX idx_purchase_transfers_to_user_status (to_user_id, status)
+);
@@ -0,0 +1,49 @@
+-- Migration: 017_create_promo_codes.sql
+-- Description: Create promo codes and their redemptions, and record the discount taken on each purchase
+-- Date: 2026-10-19
+
+-- Percentage codes discount percent_basis_points hundredths of a percent of the price;
+-- fixed codes discount amount, and only apply to prices in its currency.
+-- NULL limits and windows are unbounded.
+CREATE TABLE IF NOT EXISTS promo_codes (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    code VARCHAR(32) NOT NULL,
+    discount_type VARCHAR(20) NOT NULL,
+    percent_basis_points INT NOT NULL DEFAULT 0,
+    amount DECIMAL(15,3) NULL,
+    currency CHAR(3) NULL,
+    funded_by VARCHAR(20) NOT NULL,
+    merchant_id BIGINT NULL,
+    max_uses BIGINT NULL,
+    max_uses_per_user BIGINT NULL,
+    uses BIGINT NOT NULL DEFAULT 0,
+    starts_at TIMESTAMP NULL,
+    ends_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE INDEX idx_promo_codes_code (code)
+);
+
+-- One row per purchase a code was used on, stored with the purchase
+CREATE TABLE IF NOT EXISTS promo_redemptions (
+    id VARCHAR(36) PRIMARY KEY,
+    promo_code_id BIGINT NOT NULL,
+    purchase_id VARCHAR(36) NOT NULL,
+    user_id BIGINT NOT NULL,
+    discount DECIMAL(15,3) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    funded_by VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (promo_code_id) REFERENCES promo_codes(id),
+    FOREIGN KEY (purchase_id) REFERENCES voucher_purchases(id),
+
+    UNIQUE INDEX idx_promo_redemptions_purchase_id (purchase_id),
+    -- Per-user limits count a user's redemptions of a code
+    INDEX idx_promo_redemptions_code_user (promo_code_id, user_id)
+);
+
+-- price is what the buyer paid; discount, in the same currency, is what the promo code took off
+ALTER TABLE voucher_purchases
+    ADD COLUMN discount DECIMAL(15,3) NULL AFTER currency,
+    ADD COLUMN promo_code VARCHAR(32) NULL AFTER discount;
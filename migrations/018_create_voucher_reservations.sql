@@ -0,0 +1,24 @@
+-- Migration: 018_create_voucher_reservations.sql
+-- Description: Create the checkout holds placed on vouchers while payment is taken
+-- Date: 2026-10-19
+
+-- A held reservation blocks other buyers until expires_at; confirmed ones record the
+-- purchase they became. Expired holds are marked by a background job.
+CREATE TABLE IF NOT EXISTS voucher_reservations (
+    id VARCHAR(36) PRIMARY KEY,
+    voucher_id VARCHAR(36) NOT NULL,
+    buyer_id BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'held',
+    purchase_id VARCHAR(36) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    resolved_at TIMESTAMP NULL,
+
+    FOREIGN KEY (voucher_id) REFERENCES vouchers(id) ON DELETE CASCADE,
+    FOREIGN KEY (purchase_id) REFERENCES voucher_purchases(id),
+
+    -- Purchases and new holds look up the hold in force on a voucher
+    INDEX idx_voucher_reservations_voucher_status (voucher_id, status, expires_at),
+    -- The release job finds held reservations past their expiry
+    INDEX idx_voucher_reservations_status_expires (status, expires_at)
+);
@@ -0,0 +1,32 @@
+-- Migration: 019_create_fraud_tables.sql
+-- Description: Create the fraud rule decisions and the failed QR lookups they count
+-- Date: 2026-10-19
+
+-- Every purchase or redemption a fraud rule flagged, delayed or blocked, kept for review.
+-- rules lists the matched rule names, comma separated.
+CREATE TABLE IF NOT EXISTS fraud_decisions (
+    id VARCHAR(36) PRIMARY KEY,
+    operation VARCHAR(20) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    rules VARCHAR(255) NOT NULL,
+    buyer_id BIGINT NOT NULL,
+    merchant_id BIGINT NOT NULL,
+    voucher_id VARCHAR(36) NOT NULL,
+    purchase_id VARCHAR(36) NULL,
+    device_id VARCHAR(128) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    -- Reviewers list recent decisions by action
+    INDEX idx_fraud_decisions_action_created (action, created_at),
+    INDEX idx_fraud_decisions_buyer_created (buyer_id, created_at)
+);
+
+-- Redemptions of vouchers with no purchase, counted per scanning device
+CREATE TABLE IF NOT EXISTS qr_lookup_failures (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    device_id VARCHAR(128) NOT NULL,
+    voucher_id VARCHAR(36) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    INDEX idx_qr_lookup_failures_device_created (device_id, created_at)
+);
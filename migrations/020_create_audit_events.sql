@@ -0,0 +1,44 @@
+-- Migration: 020_create_audit_events.sql
+-- Description: Create the append-only, hash-chained audit log of state-changing operations
+-- Date: 2026-10-19
+
+-- Each event's hash covers its fields and prev_hash, the hash of the event before it.
+-- before_state and after_state are kept as text so the hashed JSON is read back byte for byte.
+CREATE TABLE IF NOT EXISTS audit_events (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    actor_type VARCHAR(20) NOT NULL,
+    actor_id BIGINT NULL,
+    action VARCHAR(50) NOT NULL,
+    target_type VARCHAR(50) NOT NULL,
+    target_id VARCHAR(64) NOT NULL,
+    before_state MEDIUMTEXT NULL,
+    after_state MEDIUMTEXT NULL,
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    created_at TIMESTAMP(6) NOT NULL,
+    prev_hash CHAR(64) NOT NULL,
+    hash CHAR(64) NOT NULL,
+
+    -- Support staff search by target, by actor and by action
+    INDEX idx_audit_events_target (target_type, target_id, id),
+    INDEX idx_audit_events_actor (actor_id, id),
+    INDEX idx_audit_events_action (action, id),
+    INDEX idx_audit_events_created (created_at)
+);
+
+-- The hash of the last event; its row is locked while an event is appended
+CREATE TABLE IF NOT EXISTS audit_chain (
+    id TINYINT PRIMARY KEY,
+    last_hash CHAR(64) NOT NULL
+);
+
+INSERT IGNORE INTO audit_chain (id, last_hash) VALUES (1, '');
+
+-- Audit events cannot be changed or removed once written
+DROP TRIGGER IF EXISTS audit_events_no_update;
+CREATE TRIGGER audit_events_no_update BEFORE UPDATE ON audit_events
+FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_events is append-only';
+
+DROP TRIGGER IF EXISTS audit_events_no_delete;
+CREATE TRIGGER audit_events_no_delete BEFORE DELETE ON audit_events
+FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_events is append-only';
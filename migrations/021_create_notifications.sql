@@ -0,0 +1,29 @@
+-- Migration: 021_create_notifications.sql
+-- Description: Create notification preferences and the record of expiry reminders sent
+-- Date: 2026-10-19
+
+-- Users without a row get push notifications only, in English
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id BIGINT PRIMARY KEY,
+    push BOOLEAN NOT NULL DEFAULT TRUE,
+    sms BOOLEAN NOT NULL DEFAULT FALSE,
+    email BOOLEAN NOT NULL DEFAULT FALSE,
+    language VARCHAR(2) NOT NULL DEFAULT 'en',
+    phone VARCHAR(16) NULL,
+    email_address VARCHAR(254) NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One row per reminder sent, so each purchase is reminded once
+CREATE TABLE IF NOT EXISTS notification_reminders (
+    purchase_id VARCHAR(36) NOT NULL,
+    kind VARCHAR(30) NOT NULL,
+    sent_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (purchase_id, kind),
+    FOREIGN KEY (purchase_id) REFERENCES voucher_purchases(id) ON DELETE CASCADE
+);
+
+-- The reminder job looks up vouchers expiring within the reminder lead
+ALTER TABLE vouchers
+    ADD INDEX idx_vouchers_expires_at (expires_at);
@@ -0,0 +1,7 @@
+-- Migration: 022_add_redemption_branch_staff.sql
+-- Description: Record the branch and cashier behind each redemption for merchant redemption feeds
+-- Date: 2026-10-19
+
+ALTER TABLE voucher_purchases
+    ADD COLUMN redeemed_branch_id BIGINT NULL AFTER redeemed_at,
+    ADD COLUMN redeemed_by BIGINT NULL AFTER redeemed_branch_id;
@@ -0,0 +1,7 @@
+-- Migration: 023_add_purchase_qr_payload.sql
+-- Description: Keep the data encoded in each purchase's current QR code so redemptions can check the scanned code
+-- Date: 2026-10-19
+
+-- Purchases recorded before this migration have no payload and are redeemed without the check
+ALTER TABLE voucher_purchases
+    ADD COLUMN qr_payload VARCHAR(255) NULL AFTER qr_code;
@@ -0,0 +1,10 @@
+-- Migration: 024_add_ledger_payouts.sql
+-- Description: Post each settlement as a payout transaction moving the merchant's payable balance to payout cash
+-- Date: 2026-10-19
+
+-- Payout transactions belong to a settlement rather than a purchase
+ALTER TABLE ledger_transactions
+    MODIFY COLUMN purchase_id VARCHAR(36) NULL,
+    ADD COLUMN settlement_id VARCHAR(36) NULL AFTER reversal_of,
+    ADD CONSTRAINT fk_ledger_transactions_settlement_id FOREIGN KEY (settlement_id) REFERENCES settlements(id),
+    ADD UNIQUE INDEX idx_ledger_transactions_settlement_id (settlement_id);
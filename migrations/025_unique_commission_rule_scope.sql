@@ -0,0 +1,11 @@
+-- Migration: 025_unique_commission_rule_scope.sql
+-- Description: Allow a single commission rule per merchant and category scope, including rules for every merchant or category
+-- Date: 2026-10-19
+
+-- MySQL treats NULLs as distinct in unique indexes, so the scope is indexed through
+-- non-NULL keys where 0 stands for every merchant or every category
+ALTER TABLE commission_rules
+    ADD COLUMN merchant_key BIGINT AS (COALESCE(merchant_id, 0)) STORED,
+    ADD COLUMN category_key BIGINT AS (COALESCE(category_id, 0)) STORED,
+    DROP INDEX idx_commission_rules_scope,
+    ADD UNIQUE INDEX idx_commission_rules_scope (merchant_key, category_key);
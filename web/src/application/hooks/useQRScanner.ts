@@ -230,7 +230,7 @@ export const useQRScanner = (options: UseQRScannerOptions = {}) => {
       onScanSuccess?.(scanResult);
 
       if (validation.isValid && validation.voucherData) {
-        const { voucherId } = validation.voucherData;
+        const { voucherId, qrCode } = validation.voucherData;
 
         // Check if already redeemed recently
         if (redemptionHistory.includes(voucherId)) {
@@ -243,7 +243,7 @@ export const useQRScanner = (options: UseQRScannerOptions = {}) => {
 
         // Auto-redeem if enabled
         if (finalConfig.autoRedemption) {
-          await redeemVoucher(voucherId);
+          await redeemVoucher(voucherId, qrCode);
         }
       } else {
         setState(prev => ({ 
@@ -260,13 +260,13 @@ export const useQRScanner = (options: UseQRScannerOptions = {}) => {
   }, [redemptionHistory, finalConfig.autoRedemption, onScanSuccess, onScanError]);
 
   // Redeem voucher
-  const redeemVoucher = useCallback(async (voucherId: string) => {
+  const redeemVoucher = useCallback(async (voucherId: string, qrCode: string) => {
     if (isRedeeming) return;
 
     setIsRedeeming(true);
     
     try {
-      const response = await qrService.redeemVoucher(voucherId);
+      const response = await qrService.redeemVoucher(voucherId, qrCode);
       
       if (response.success) {
         setRedemptionHistory(prev => [...prev, voucherId]);
@@ -1,5 +1,6 @@
 // Application service for voucher operations
-import { Voucher, VoucherListResponse } from '../../domain/models/voucher';
+import { Money, Voucher, VoucherListResponse } from '../../domain/models/voucher';
+import { getSessionToken } from '../utils/getUserId';
 
 export class VoucherService {
   private readonly baseUrl: string;
@@ -11,10 +12,13 @@ export class VoucherService {
 
   async getUserVouchers(userId: string): Promise<Voucher[]> {
     try {
+      // The voucher list only serves the signed-in user's own vouchers
+      const sessionToken = getSessionToken();
       const response = await fetch(`${this.baseUrl}/vouchers/${userId}`, {
         method: 'GET',
         headers: {
           'Content-Type': 'application/json',
+          ...(sessionToken ? { 'Authorization': `Bearer ${sessionToken}` } : {}),
         },
       });
 
@@ -35,8 +39,8 @@ export class VoucherService {
     }
   }
 
-  formatPrice(price: number): string {
-    return `$${price.toFixed(2)}`;
+  formatPrice(price: Money): string {
+    return `${price.amount} ${price.currency}`;
   }
 
   formatDate(dateString: string): string {
@@ -42,6 +42,19 @@ export const getAuthToken = (): string | null => {
   return sessionStorage.getItem('auth_token');
 };
 
+// Utility to extract the session token issued by the voucher API login, sent on API calls
+export const getSessionToken = (): string | null => {
+  const urlParams = new URLSearchParams(window.location.search);
+  const token = urlParams.get('session_token');
+
+  if (token) {
+    sessionStorage.setItem('session_token', token);
+    return token;
+  }
+
+  return sessionStorage.getItem('session_token');
+};
+
 // Utility to extract additional params that Android might send
 export const getAppParams = () => {
   const urlParams = new URLSearchParams(window.location.search);
@@ -50,6 +63,7 @@ export const getAppParams = () => {
   return {
     userId: getUserId(),
     token: getAuthToken(),
+    sessionToken: getSessionToken(),
     language: urlParams.get('lang') || hashParams.get('lang') || 'en',
     theme: urlParams.get('theme') || hashParams.get('theme') || 'light',
     returnUrl: urlParams.get('return_url') || hashParams.get('return_url'),
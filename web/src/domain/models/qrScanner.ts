@@ -8,6 +8,7 @@ export interface QRScanResult {
 export interface VoucherQRData {
   voucherId: string;
   buyerId: number;
+  qrCode: string; // decoded data of the QR code, checked against the purchase's current code
   format: 'voucher' | 'unknown';
 }
 
@@ -22,6 +23,7 @@ export interface QRScannerState {
 
 export interface QRRedemptionRequest {
   voucher_id: string;
+  qr_code: string;
   redeemed_at: string; // ISO string format
 }
 
@@ -6,11 +6,17 @@ export interface Voucher {
   status: VoucherStatus;
   photo_url: string;
   qr_code?: string;
-  price: number;
+  price: Money;
   purchased_at: string;
   redeemed_at: string | null;
 }
 
+// Amounts are decimal strings in the currency's minor unit precision, e.g. "12.500" KWD
+export interface Money {
+  amount: string;
+  currency: string;
+}
+
 export type VoucherStatus = 'active' | 'redeemed';
 
 export interface VoucherListResponse {
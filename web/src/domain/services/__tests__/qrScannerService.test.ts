@@ -25,6 +25,7 @@ describe('QRScannerService', () => {
       expect(result.voucherData).toEqual({
         voucherId,
         buyerId,
+        qrCode: qrData,
         format: 'voucher'
       });
     });
@@ -41,10 +42,22 @@ describe('QRScannerService', () => {
       expect(result.voucherData).toEqual({
         voucherId,
         buyerId,
+        qrCode: qrData,
         format: 'voucher'
       });
     });
 
+    it('should validate a reissued voucher QR code with a nonce', () => {
+      const voucherId = '123e4567-e89b-12d3-a456-426614174000';
+      const qrData = `voucher:${voucherId}:buyer:12345:9b2f4c1e-3d5a-4e6b-8c7d-0a1b2c3d4e5f`;
+
+      const result = service.validateAndParseQRCode(qrData);
+
+      expect(result.isValid).toBe(true);
+      expect(result.voucherData?.voucherId).toBe(voucherId);
+      expect(result.voucherData?.qrCode).toBe(qrData);
+    });
+
     it('should reject invalid QR code format', () => {
       const result = service.validateAndParseQRCode('invalid-qr-code');
       
@@ -117,6 +130,7 @@ describe('QRScannerService', () => {
   describe('redeemVoucher', () => {
     it('should successfully redeem a voucher', async () => {
       const voucherId = '123e4567-e89b-12d3-a456-426614174000';
+      const qrCode = `voucher:${voucherId}:buyer:12345`;
       const mockResponse = {
         success: true,
         message: 'Voucher redeemed successfully'
@@ -127,7 +141,7 @@ describe('QRScannerService', () => {
         json: async () => mockResponse
       });
 
-      const result = await service.redeemVoucher(voucherId);
+      const result = await service.redeemVoucher(voucherId, qrCode);
 
       expect(result.success).toBe(true);
       expect(result.message).toBe('Voucher redeemed successfully');
@@ -141,10 +155,12 @@ describe('QRScannerService', () => {
           body: expect.stringContaining(voucherId)
         })
       );
+      expect(JSON.parse((global.fetch as any).mock.calls[0][1].body).qr_code).toBe(qrCode);
     });
 
     it('should handle redemption failure', async () => {
       const voucherId = '123e4567-e89b-12d3-a456-426614174000';
+      const qrCode = `voucher:${voucherId}:buyer:12345`;
 
       (global.fetch as any).mockResolvedValueOnce({
         ok: false,
@@ -152,7 +168,7 @@ describe('QRScannerService', () => {
         text: async () => 'Voucher not found'
       });
 
-      const result = await service.redeemVoucher(voucherId);
+      const result = await service.redeemVoucher(voucherId, qrCode);
 
       expect(result.success).toBe(false);
       expect(result.message).toContain('HTTP 404');
@@ -160,10 +176,11 @@ describe('QRScannerService', () => {
 
     it('should handle network errors', async () => {
       const voucherId = '123e4567-e89b-12d3-a456-426614174000';
+      const qrCode = `voucher:${voucherId}:buyer:12345`;
 
       (global.fetch as any).mockRejectedValueOnce(new Error('Network error'));
 
-      const result = await service.redeemVoucher(voucherId);
+      const result = await service.redeemVoucher(voucherId, qrCode);
 
       expect(result.success).toBe(false);
       expect(result.message).toContain('Network error');
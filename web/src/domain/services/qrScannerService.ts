@@ -15,7 +15,7 @@ export class QRScannerService {
 
   /**
    * Validates and parses QR code data for voucher format
-   * Expected format: Base64(voucher:{UUID}:buyer:{number})
+   * Expected format: Base64(voucher:{UUID}:buyer:{number}[:{nonce}])
    */
   validateAndParseQRCode(qrData: string): QRValidationResult {
     try {
@@ -28,8 +28,8 @@ export class QRScannerService {
         decodedData = qrData;
       }
 
-      // Parse voucher format: voucher:{UUID}:buyer:{number}
-      const voucherRegex = /^voucher:([a-f0-9-]{36}):buyer:(\d+)$/i;
+      // Parse voucher format: voucher:{UUID}:buyer:{number}, followed by a nonce on reissued codes
+      const voucherRegex = /^voucher:([a-f0-9-]{36}):buyer:(\d+)(?::[a-f0-9-]{36})?$/i;
       const match = decodedData.match(voucherRegex);
 
       if (!match) {
@@ -71,6 +71,7 @@ export class QRScannerService {
         voucherData: {
           voucherId,
           buyerId,
+          qrCode: decodedData,
           format: 'voucher'
         }
       };
@@ -96,10 +97,11 @@ export class QRScannerService {
   /**
    * Redeems a voucher via the webhook endpoint
    */
-  async redeemVoucher(voucherId: string): Promise<QRRedemptionResponse> {
+  async redeemVoucher(voucherId: string, qrCode: string): Promise<QRRedemptionResponse> {
     try {
       const request: QRRedemptionRequest = {
         voucher_id: voucherId,
+        qr_code: qrCode,
         redeemed_at: new Date().toISOString()
       };
 
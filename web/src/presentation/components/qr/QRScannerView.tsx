@@ -94,7 +94,7 @@ export const QRScannerView: React.FC<QRScannerViewProps> = ({
       const validation = qrService.validateAndParseQRCode(lastScanResult.data);
       
       if (validation.isValid && validation.voucherData) {
-        await redeemVoucher(validation.voucherData.voucherId);
+        await redeemVoucher(validation.voucherData.voucherId, validation.voucherData.qrCode);
       }
     }
   };
@@ -3,6 +3,7 @@ import { BrowserMultiFormatReader } from '@zxing/library';
 import { QrCode, CheckCircle, XCircle, Camera } from 'lucide-react';
 import { Button } from '../../components/ui/core/Button/Button';
 import { Card } from '../../components/ui/data-display/Card/Card';
+import { QRScannerService } from '../../../domain/services/qrScannerService';
 
 type ScanState = 'idle' | 'scanning' | 'processing' | 'success' | 'error';
 
@@ -93,6 +94,17 @@ export const ScanQRCodePage: React.FC<ScanQRCodePageProps> = ({
     
     try {
       console.log('Processing QR code:', qrData);
+
+      // The code names the voucher and is checked against the purchase's current QR code
+      const validation = new QRScannerService().validateAndParseQRCode(qrData);
+      if (!validation.isValid || !validation.voucherData) {
+        setScanState('error');
+        setRedemptionResult({
+          success: false,
+          message: validation.error || 'This voucher is invalid or has already been redeemed.'
+        });
+        return;
+      }
       
       // Call real API to redeem voucher
       const response = await fetch('http://localhost:3001/webhook/voucher-redeemed', {
@@ -101,7 +113,8 @@ export const ScanQRCodePage: React.FC<ScanQRCodePageProps> = ({
           'Content-Type': 'application/json',
         },
         body: JSON.stringify({
-          voucher_id: qrData,
+          voucher_id: validation.voucherData.voucherId,
+          qr_code: validation.voucherData.qrCode,
           redeemed_at: new Date().toISOString()
         })
       });